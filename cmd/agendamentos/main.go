@@ -0,0 +1,137 @@
+// Command agendamentos é o job agendado (EventBridge) que executa as
+// transações agendadas cuja data venceu, pelo fluxo normal de autorização.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"authorizer/internal/buildinfo"
+	"authorizer/internal/core/domain"
+	"authorizer/internal/core/service"
+	"authorizer/internal/observability/logger"
+	"authorizer/internal/observability/metrics"
+	"authorizer/internal/observability/tracing"
+	dynamorepo "authorizer/internal/repository/dynamodb"
+)
+
+func main() {
+	dynamoClient := &dynamodb.Client{} // Em produção, seria configurado com credenciais
+
+	clientesTableName := getEnvOrDefault("CLIENTES_TABLE_NAME", "clientes")
+	transacoesTableName := getEnvOrDefault("TRANSACOES_TABLE_NAME", "transacoes")
+
+	structuredLogger := logger.NewStructuredLogger().WithCamposEstaticos(logger.CamposEstaticos{
+		Env:     getEnvOrDefault("AMBIENTE", "dev"),
+		Region:  os.Getenv("AWS_REGION"),
+		Service: "scheduled-transactions",
+		Version: buildinfo.Version,
+	})
+	metricsCollector := metrics.NewPrometheusCollector(
+		metrics.WithLabelsNegocio(getEnvListOrDefault("METRICAS_LABELS_NEGOCIO", metrics.LabelsNegocioPadrao)...),
+	)
+
+	opcoesTransacoes := []dynamorepo.Option{dynamorepo.WithObservabilidade(metricsCollector, structuredLogger)}
+	if getEnvOrDefault("TRANSACOES_LAYOUT", "SIMPLES") == "COMPOSTA" {
+		opcoesTransacoes = append(opcoesTransacoes, dynamorepo.WithLayoutTransacoes(dynamorepo.LayoutChaveComposta))
+	}
+	transacaoRepository := dynamorepo.NewTransacaoRepository(dynamoClient, transacoesTableName, opcoesTransacoes...)
+
+	// Mesmo fluxo de autorização do autorizador, contra o limite vigente
+	transacaoService := service.NewTransacaoService(
+		dynamorepo.NewLimiteRepository(dynamoClient, clientesTableName,
+			dynamorepo.WithObservabilidade(metricsCollector, structuredLogger),
+		),
+		transacaoRepository,
+		&SimpleEventPublisher{},
+		metricsCollector,
+		tracing.NewSimpleTracer("scheduled-transactions"),
+		structuredLogger,
+		service.WithParcelas(getEnvIntOrDefault("PARCELAS_MAX", 12), nil),
+	)
+
+	processador := service.NewProcessadorAgendamentos(transacaoRepository, transacaoService, metricsCollector, structuredLogger)
+
+	// Sem endpoint de scrape: as métricas vão ao Pushgateway ao fim de cada execução
+	pushgateway := metrics.NewPushgateway(os.Getenv("PUSHGATEWAY_URL"), "scheduled-transactions", metricsCollector)
+	if !pushgateway.Habilitado() {
+		log.Printf("CONFIG: PUSHGATEWAY_URL ausente, métricas do job não são exportadas")
+	}
+
+	lambda.Start(func(ctx context.Context, evento events.CloudWatchEvent) (*service.RelatorioAgendamentos, error) {
+		defer enviarMetricas(ctx, pushgateway)
+
+		agora := evento.Time
+		if agora.IsZero() {
+			agora = time.Now()
+		}
+
+		return processador.ProcessarVencidas(ctx, agora)
+	})
+}
+
+// getEnvOrDefault retorna variável de ambiente ou valor padrão
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvListOrDefault lê uma lista no formato "A,B,C"
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvIntOrDefault retorna variável de ambiente inteira ou valor padrão
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("CONFIG: valor inválido para %s (%q), usando padrão %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// SimpleEventPublisher implementação simplificada para eventos
+type SimpleEventPublisher struct{}
+
+func (s *SimpleEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	log.Printf("EVENT: Transação agendada aprovada - Cliente: %s, Valor: %.2f, ID: %s",
+		evento.ClienteID, evento.Valor, evento.TransacaoID)
+	return nil
+}
+
+func (s *SimpleEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	log.Printf("EVENT: Transação agendada rejeitada - Cliente: %s, Valor: %.2f, ID: %s",
+		evento.ClienteID, evento.Valor, evento.TransacaoID)
+	return nil
+}
+
+// enviarMetricas publica as métricas da execução no Pushgateway; a falha é
+// apenas registrada, sem afetar o resultado do job
+func enviarMetricas(ctx context.Context, pushgateway *metrics.Pushgateway) {
+	if err := pushgateway.Enviar(ctx); err != nil {
+		log.Printf("METRICAS: falha ao enviar ao Pushgateway: %v", err)
+	}
+}
@@ -1,59 +1,88 @@
 package main
 
 import (
-	"context"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 
-	"authorizer/internal/core/domain"
-	"authorizer/internal/core/service"
+	"authorizer/internal/app"
+	"authorizer/internal/buildinfo"
 	awslambda "authorizer/internal/handler/lambda"
 	"authorizer/internal/observability/logger"
+	"authorizer/internal/observability/metrics"
 	"authorizer/internal/observability/tracing"
-	dynamorepo "authorizer/internal/repository/dynamodb"
 )
 
+// ambientesDepuracao são os valores de AMBIENTE em que os cabeçalhos
+// X-Debug-* podem ser habilitados
+var ambientesDepuracao = map[string]bool{"dev": true, "local": true}
+
 func main() {
 	// Clientes AWS (configuração simplificada)
 	dynamoClient := &dynamodb.Client{} // Em produção, seria configurado com credenciais
 
-	// Configurações do ambiente
-	clientesTableName := getEnvOrDefault("CLIENTES_TABLE_NAME", "clientes")
-	transacoesTableName := getEnvOrDefault("TRANSACOES_TABLE_NAME", "transacoes")
-	snsTopicArn := getEnvOrDefault("SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:transacoes")
-
 	// Inicialização dos componentes de observabilidade
-	structuredLogger := logger.NewStructuredLogger()
-	simpleTracer := tracing.NewSimpleTracer("transaction-authorizer")
-
-	// Inicialização dos repositórios
-	limiteRepository := dynamorepo.NewLimiteRepository(dynamoClient, clientesTableName)
-	transacaoRepository := dynamorepo.NewTransacaoRepository(dynamoClient, transacoesTableName)
-	eventPublisher := &SimpleEventPublisher{topicArn: snsTopicArn}
-
-	// Métricas collector simplificado
-	metricsCollector := &SimpleMetricsCollector{}
-
-	// Inicialização do serviço principal
-	transacaoService := service.NewTransacaoService(
-		limiteRepository,
-		transacaoRepository,
-		eventPublisher,
-		metricsCollector,
-		simpleTracer,
-		structuredLogger,
+	structuredLogger := logger.NewStructuredLogger().WithCamposEstaticos(logger.CamposEstaticos{
+		Env:     getEnvOrDefault("AMBIENTE", "dev"),
+		Region:  os.Getenv("AWS_REGION"),
+		Service: "transaction-authorizer",
+		Version: buildinfo.Version,
+	})
+	tracer := tracing.NewNivelTracer(
+		tracing.NewSimpleTracer("transaction-authorizer", tracing.WithFiltroTags(tracing.FiltroTags{
+			Permitidas: getEnvListOrDefault("TRACE_TAGS_PERMITIDAS", nil),
+			Omitidas:   getEnvListOrDefault("TRACE_TAGS_OMITIDAS", nil),
+			Mascaradas: getEnvListOrDefault("TRACE_TAGS_MASCARADAS", nil),
+		})),
+		tracing.Nivel(getEnvOrDefault("OBSERVABILIDADE_NIVEL", string(tracing.NivelDetalhado))),
 	)
 
+	// Métricas collector simplificado, com os mesmos labels de negócio dos
+	// demais binários
+	metricsCollector := &SimpleMetricsCollector{
+		labelsNegocio: getEnvListOrDefault("METRICAS_LABELS_NEGOCIO", metrics.LabelsNegocioPadrao),
+	}
+
+	// Serviço com as mesmas regras do consumidor de fila (cmd/consumer)
+	transacaoService := app.NewTransacaoService(dynamoClient, "transaction-authorizer", metricsCollector, tracer, structuredLogger)
+
+	// Load shedding global por instância (0 desliga); rajada padrão = 1s de taxa
+	limiteGlobalRPS := getEnvIntOrDefault("LIMITE_GLOBAL_RPS", 0)
+
+	// Cabeçalhos X-Debug-* para QA: só nos ambientes liberados, declarados
+	// explicitamente (AMBIENTE ausente ou desconhecido desliga)
+	cabecalhosDepuracao := os.Getenv("DEBUG_HEADERS_ENABLED") == "true"
+	if cabecalhosDepuracao && !ambientesDepuracao[os.Getenv("AMBIENTE")] {
+		log.Printf("CONFIG: DEBUG_HEADERS_ENABLED ignorado no ambiente %q", os.Getenv("AMBIENTE"))
+		cabecalhosDepuracao = false
+	}
+
+	// Índice de rotas no 404 de GET: descoberta fora de produção
+	ambiente := getEnvOrDefault("AMBIENTE", "dev")
+	indiceRotas := os.Getenv("INDICE_ROTAS_ENABLED") == "true"
+	if indiceRotas && ambiente == "prod" {
+		log.Printf("CONFIG: INDICE_ROTAS_ENABLED ignorado em produção")
+		indiceRotas = false
+	}
+
 	// Inicialização do handler Lambda
-	handler := awslambda.NewLambdaHandler(
-		transacaoService,
-		structuredLogger,
-		simpleTracer,
-		metricsCollector,
+	opcoesHandler := append(app.OpcoesPayload(),
+		awslambda.WithMargemTimeout(time.Duration(getEnvIntOrDefault("TIMEOUT_MARGEM_MS", 100))*time.Millisecond),
+		awslambda.WithServerTiming(os.Getenv("SERVER_TIMING") == "true"),
+		awslambda.WithRetryAfterThrottling(time.Duration(getEnvIntOrDefault("RETRY_AFTER_THROTTLING_SEGUNDOS", 1))*time.Second),
+		awslambda.WithStatusClienteNaoEncontrado(getEnvIntOrDefault("CLIENTE_NAO_ENCONTRADO_STATUS", 404)),
+		awslambda.WithCabecalhosDepuracao(cabecalhosDepuracao),
+		awslambda.WithIndiceRotas(indiceRotas),
+		awslambda.WithSaldoNaResposta(os.Getenv("SALDO_NA_RESPOSTA") == "true"),
+		awslambda.WithLimiteRestanteNaResposta(os.Getenv("LIMITE_RESTANTE_NA_RESPOSTA") == "true"),
+		awslambda.WithLimiteGlobal(float64(limiteGlobalRPS), getEnvIntOrDefault("LIMITE_GLOBAL_RAJADA", limiteGlobalRPS)),
 	)
+	handler := awslambda.NewLambdaHandler(transacaoService, structuredLogger, tracer, metricsCollector, opcoesHandler...)
 
 	// Inicia o Lambda
 	lambda.Start(handler.HandleRequest)
@@ -67,8 +96,38 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
-// SimpleMetricsCollector implementação simplificada para metrics
-type SimpleMetricsCollector struct{}
+// getEnvIntOrDefault retorna variável de ambiente inteira ou valor padrão
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("CONFIG: valor inválido para %s (%q), usando padrão %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvListOrDefault lê uma lista no formato "A,B,C"
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// SimpleMetricsCollector implementação simplificada para metrics; métricas
+// de negócio registram apenas os labels de labelsNegocio
+type SimpleMetricsCollector struct {
+	labelsNegocio []string
+}
 
 func (s *SimpleMetricsCollector) IncrementTransactionCounter(status string) {
 	log.Printf("METRIC: transaction_count{status=%s} +1", status)
@@ -79,26 +138,25 @@ func (s *SimpleMetricsCollector) RecordTransactionLatency(duration float64) {
 }
 
 func (s *SimpleMetricsCollector) RecordBusinessMetric(metricName string, value float64, labels map[string]string) {
-	log.Printf("METRIC: %s{%v} %.2f", metricName, labels, value)
+	pares := make([]string, 0, len(s.labelsNegocio))
+	for _, label := range s.labelsNegocio {
+		pares = append(pares, label+"="+labels[label])
+	}
+	log.Printf("METRIC: %s{%s} %.2f", metricName, strings.Join(pares, ","), value)
 }
 
 func (s *SimpleMetricsCollector) IncrementErrorCounter(errorType string) {
 	log.Printf("METRIC: error_count{type=%s} +1", errorType)
 }
 
-// SimpleEventPublisher implementação simplificada para eventos
-type SimpleEventPublisher struct {
-	topicArn string
+func (s *SimpleMetricsCollector) IncrementOutcomeCounter(outcome string) {
+	log.Printf("METRIC: outcome_count{outcome=%s} +1", outcome)
 }
 
-func (s *SimpleEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
-	log.Printf("EVENT: Transação aprovada - Cliente: %s, Valor: %.2f, ID: %s",
-		evento.ClienteID, evento.Valor, evento.TransacaoID)
-	return nil
+func (s *SimpleMetricsCollector) RecordEventPublish(result string, duration float64) {
+	log.Printf("METRIC: event_publish_total{result=%s} +1 event_publish_duration %.3fms", result, duration*1000)
 }
 
-func (s *SimpleEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
-	log.Printf("EVENT: Transação rejeitada - Cliente: %s, Valor: %.2f, ID: %s",
-		evento.ClienteID, evento.Valor, evento.TransacaoID)
-	return nil
+func (s *SimpleMetricsCollector) RecordRejectedValue(reason string, value float64) {
+	log.Printf("METRIC: rejected_transaction_value{reason=%s} %.2f", reason, value)
 }
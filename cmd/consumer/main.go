@@ -0,0 +1,134 @@
+// Command consumer executa o autorizador como processo de longa duração
+// (container no ECS). O serviço é montado por app.NewTransacaoService, o
+// mesmo construtor do cmd/authorizer, com as mesmas variáveis de ambiente.
+// A única fonte disponível lê uma mensagem JSON por linha da entrada padrão
+// (ex.: `kcat -C -t transacoes | consumer`).
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"authorizer/internal/app"
+	"authorizer/internal/buildinfo"
+	"authorizer/internal/consumer"
+	awslambda "authorizer/internal/handler/lambda"
+	"authorizer/internal/observability/logger"
+	"authorizer/internal/observability/metrics"
+	"authorizer/internal/observability/tracing"
+)
+
+func main() {
+	dynamoClient := &dynamodb.Client{} // Em produção, seria configurado com credenciais
+
+	structuredLogger := logger.NewStructuredLogger().WithCamposEstaticos(logger.CamposEstaticos{
+		Env:     getEnvOrDefault("AMBIENTE", "dev"),
+		Region:  os.Getenv("AWS_REGION"),
+		Service: "transaction-authorizer-consumer",
+		Version: buildinfo.Version,
+	})
+	metricsCollector := metrics.NewPrometheusCollector(
+		metrics.WithLabelsNegocio(getEnvListOrDefault("METRICAS_LABELS_NEGOCIO", metrics.LabelsNegocioPadrao)...),
+	)
+	tracer := tracing.NewSimpleTracer("transaction-authorizer-consumer")
+
+	transacaoService := app.NewTransacaoService(dynamoClient, "transaction-authorizer-consumer", metricsCollector, tracer, structuredLogger)
+
+	// Mesmo modelo de requisição e mapeamento de erros do endpoint HTTP
+	handler := awslambda.NewLambdaHandler(transacaoService, structuredLogger, tracer, metricsCollector, app.OpcoesPayload()...)
+
+	consumidor := consumer.NewConsumidor(
+		consumer.NewFonteLeitor(os.Stdin),
+		handler,
+		metricsCollector,
+		structuredLogger,
+		consumer.WithPausaErro(time.Duration(getEnvIntOrDefault("CONSUMIDOR_PAUSA_ERRO_MS", 1000))*time.Millisecond),
+	)
+
+	// SIGTERM (parada da task no ECS) conclui a mensagem em andamento e encerra
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// Processo sem endpoint de scrape: as métricas vão ao Pushgateway a cada
+	// intervalo (0 envia só no encerramento) e no encerramento
+	pushgateway := metrics.NewPushgateway(os.Getenv("PUSHGATEWAY_URL"), "transaction-authorizer-consumer", metricsCollector)
+	intervaloPush := time.Duration(getEnvIntOrDefault("PUSHGATEWAY_INTERVALO_SEGUNDOS", 15)) * time.Second
+	if !pushgateway.Habilitado() {
+		log.Printf("CONFIG: PUSHGATEWAY_URL ausente, métricas do consumidor não são exportadas")
+	} else if intervaloPush > 0 {
+		go enviarMetricasPeriodicamente(ctx, pushgateway, intervaloPush)
+	}
+
+	err := consumidor.Executar(ctx)
+	enviarMetricas(context.Background(), pushgateway)
+	if err != nil {
+		log.Fatalf("consumidor encerrado com erro: %v", err)
+	}
+}
+
+// enviarMetricasPeriodicamente publica as métricas a cada intervalo até o
+// contexto encerrar
+func enviarMetricasPeriodicamente(ctx context.Context, pushgateway *metrics.Pushgateway, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			enviarMetricas(ctx, pushgateway)
+		}
+	}
+}
+
+// enviarMetricas publica as métricas no Pushgateway; a falha é apenas
+// registrada, sem interromper o consumo
+func enviarMetricas(ctx context.Context, pushgateway *metrics.Pushgateway) {
+	if err := pushgateway.Enviar(ctx); err != nil {
+		log.Printf("METRICAS: falha ao enviar ao Pushgateway: %v", err)
+	}
+}
+
+// getEnvOrDefault retorna variável de ambiente ou valor padrão
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvIntOrDefault retorna variável de ambiente inteira ou valor padrão
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("CONFIG: valor inválido para %s (%q), usando padrão %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvListOrDefault lê uma lista no formato "A,B,C"
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
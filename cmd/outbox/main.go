@@ -0,0 +1,96 @@
+// Command outbox é o job agendado (EventBridge) que publica os eventos
+// gravados no outbox pela ordem de publicação via outbox e os marca como
+// publicados; eventos com falha continuam pendentes para a próxima execução.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"authorizer/internal/app"
+	"authorizer/internal/buildinfo"
+	"authorizer/internal/core/service"
+	"authorizer/internal/observability/logger"
+	"authorizer/internal/observability/metrics"
+)
+
+func main() {
+	dynamoClient := &dynamodb.Client{} // Em produção, seria configurado com credenciais
+
+	lote := getEnvIntOrDefault("OUTBOX_LOTE", 100)
+
+	structuredLogger := logger.NewStructuredLogger().WithCamposEstaticos(logger.CamposEstaticos{
+		Env:     getEnvOrDefault("AMBIENTE", "dev"),
+		Region:  os.Getenv("AWS_REGION"),
+		Service: "outbox-relay",
+		Version: buildinfo.Version,
+	})
+	metricsCollector := metrics.NewPrometheusCollector(
+		metrics.WithLabelsNegocio(getEnvListOrDefault("METRICAS_LABELS_NEGOCIO", metrics.LabelsNegocioPadrao)...),
+	)
+
+	// Mesmos publicadores (rotas por motivo) e limite de payload da autorização
+	relay := app.NewOutboxRelayService(dynamoClient, metricsCollector, structuredLogger)
+
+	// Sem endpoint de scrape: as métricas vão ao Pushgateway ao fim de cada execução
+	pushgateway := metrics.NewPushgateway(os.Getenv("PUSHGATEWAY_URL"), "outbox-relay", metricsCollector)
+	if !pushgateway.Habilitado() {
+		log.Printf("CONFIG: PUSHGATEWAY_URL ausente, métricas do job não são exportadas")
+	}
+
+	lambda.Start(func(ctx context.Context, _ events.CloudWatchEvent) (*service.RelatorioOutbox, error) {
+		defer enviarMetricas(ctx, pushgateway)
+
+		return relay.PublicarPendentes(ctx, lote)
+	})
+}
+
+// getEnvOrDefault retorna variável de ambiente ou valor padrão
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvListOrDefault lê uma lista no formato "A,B,C"
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvIntOrDefault retorna variável de ambiente inteira ou valor padrão
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("CONFIG: valor inválido para %s (%q), usando padrão %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// enviarMetricas publica as métricas da execução no Pushgateway; a falha é
+// apenas registrada, sem afetar o resultado do job
+func enviarMetricas(ctx context.Context, pushgateway *metrics.Pushgateway) {
+	if err := pushgateway.Enviar(ctx); err != nil {
+		log.Printf("METRICAS: falha ao enviar ao Pushgateway: %v", err)
+	}
+}
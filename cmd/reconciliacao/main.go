@@ -0,0 +1,112 @@
+// Command reconciliacao é o job agendado (EventBridge) que compara as
+// transações aprovadas com os eventos de publicação confirmada e reporta a
+// divergência (métrica event_reconciliation_gap e log de alerta).
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"authorizer/internal/buildinfo"
+	"authorizer/internal/core/service"
+	"authorizer/internal/observability/logger"
+	"authorizer/internal/observability/metrics"
+	dynamorepo "authorizer/internal/repository/dynamodb"
+)
+
+func main() {
+	dynamoClient := &dynamodb.Client{} // Em produção, seria configurado com credenciais
+
+	transacoesTableName := getEnvOrDefault("TRANSACOES_TABLE_NAME", "transacoes")
+	acksTableName := getEnvOrDefault("EVENTOS_CONFIRMADOS_TABLE_NAME", "eventos_confirmados")
+
+	// Janela reconciliada e atraso em relação ao agora: a publicação é
+	// assíncrona, então transações muito recentes ainda podem ser confirmadas
+	janela := time.Duration(getEnvIntOrDefault("RECONCILIACAO_JANELA_MINUTOS", 60)) * time.Minute
+	atraso := time.Duration(getEnvIntOrDefault("RECONCILIACAO_ATRASO_MINUTOS", 5)) * time.Minute
+
+	metricsCollector := metrics.NewPrometheusCollector(
+		metrics.WithLabelsNegocio(getEnvListOrDefault("METRICAS_LABELS_NEGOCIO", metrics.LabelsNegocioPadrao)...),
+	)
+
+	reconciliacao := service.NewReconciliacaoService(
+		dynamorepo.NewTransacaoRepository(dynamoClient, transacoesTableName),
+		dynamorepo.NewEventAckRepository(dynamoClient, acksTableName),
+		metricsCollector,
+		logger.NewStructuredLogger().WithCamposEstaticos(logger.CamposEstaticos{
+			Env:     getEnvOrDefault("AMBIENTE", "dev"),
+			Region:  os.Getenv("AWS_REGION"),
+			Service: "event-reconciliation",
+			Version: buildinfo.Version,
+		}),
+	)
+
+	// Sem endpoint de scrape: as métricas vão ao Pushgateway ao fim de cada execução
+	pushgateway := metrics.NewPushgateway(os.Getenv("PUSHGATEWAY_URL"), "event-reconciliation", metricsCollector)
+	if !pushgateway.Habilitado() {
+		log.Printf("CONFIG: PUSHGATEWAY_URL ausente, métricas do job não são exportadas")
+	}
+
+	lambda.Start(func(ctx context.Context, evento events.CloudWatchEvent) (*service.RelatorioReconciliacao, error) {
+		defer enviarMetricas(ctx, pushgateway)
+
+		fim := evento.Time
+		if fim.IsZero() {
+			fim = time.Now()
+		}
+		fim = fim.Add(-atraso)
+
+		return reconciliacao.Reconciliar(ctx, fim.Add(-janela), fim)
+	})
+}
+
+// getEnvOrDefault retorna variável de ambiente ou valor padrão
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvIntOrDefault retorna variável de ambiente inteira ou valor padrão
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("CONFIG: valor inválido para %s (%q), usando padrão %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvListOrDefault lê uma lista no formato "A,B,C"
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// enviarMetricas publica as métricas da execução no Pushgateway; a falha é
+// apenas registrada, sem afetar o resultado do job
+func enviarMetricas(ctx context.Context, pushgateway *metrics.Pushgateway) {
+	if err := pushgateway.Enviar(ctx); err != nil {
+		log.Printf("METRICAS: falha ao enviar ao Pushgateway: %v", err)
+	}
+}
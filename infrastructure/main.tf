@@ -34,6 +34,24 @@ variable "project_name" {
   default     = "authorizer"
 }
 
+variable "transacoes_layout" {
+  description = "Layout da tabela de transações (SIMPLES ou COMPOSTA)"
+  type        = string
+  default     = "SIMPLES"
+}
+
+variable "publicacao_via_outbox" {
+  description = "Grava os eventos no outbox em vez de publicar direto (exige o relay cmd/outbox)"
+  type        = bool
+  default     = false
+}
+
+variable "politica_aprovacao_dinamica" {
+  description = "Lê a política de aprovação da tabela de configuração (item id=politica_aprovacao)"
+  type        = bool
+  default     = false
+}
+
 # Tags padrão para todos os recursos
 locals {
   common_tags = {
@@ -42,6 +60,23 @@ locals {
     Terraform   = "true"
     Team        = "payments"
   }
+
+  layout_composto = var.transacoes_layout == "COMPOSTA"
+
+  # Tabela de transações conforme o layout de chaves
+  transacoes_table_name = local.layout_composto ? one(aws_dynamodb_table.transacoes_composta[*].name) : aws_dynamodb_table.transacoes.name
+
+  # Tabelas acessadas pelas funções (índices incluídos na policy)
+  dynamodb_tables = concat([
+    aws_dynamodb_table.clientes.arn,
+    aws_dynamodb_table.transacoes.arn,
+    aws_dynamodb_table.outbox.arn,
+    aws_dynamodb_table.idempotencia.arn,
+    aws_dynamodb_table.auditoria.arn,
+    aws_dynamodb_table.stepup_desafios.arn,
+    aws_dynamodb_table.eventos_confirmados.arn,
+    aws_dynamodb_table.configuracoes.arn,
+  ], aws_dynamodb_table.transacoes_composta[*].arn)
 }
 
 # === DynamoDB Tables ===
@@ -128,6 +163,207 @@ resource "aws_dynamodb_table" "transacoes" {
   tags = local.common_tags
 }
 
+# Transações no layout de chave composta (TRANSACOES_LAYOUT=COMPOSTA): partição
+# por cliente, ordenação por "<timestamp>#<id>" e GSI id-index para buscas por ID
+resource "aws_dynamodb_table" "transacoes_composta" {
+  count = local.layout_composto ? 1 : 0
+
+  name         = "${var.project_name}-transacoes-composta-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "cliente_id"
+  range_key    = "sk"
+
+  attribute {
+    name = "cliente_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "sk"
+    type = "S"
+  }
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "id-index"
+    hash_key        = "id"
+    projection_type = "ALL"
+  }
+
+  ttl {
+    attribute_name = "ttl"
+    enabled        = true
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = local.common_tags
+}
+
+# Outbox de eventos gravados junto da transação; o índice esparso contém só
+# os pendentes ("pendente" é o fragmento, removido na publicação)
+resource "aws_dynamodb_table" "outbox" {
+  name         = "${var.project_name}-eventos-outbox-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "id"
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+
+  attribute {
+    name = "pendente"
+    type = "N"
+  }
+
+  global_secondary_index {
+    name            = "pendentes-index"
+    hash_key        = "pendente"
+    projection_type = "ALL"
+  }
+
+  ttl {
+    attribute_name = "ttl"
+    enabled        = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = local.common_tags
+}
+
+# Chaves de idempotência (Idempotency-Key ou ID da mensagem); expiram pelo TTL
+resource "aws_dynamodb_table" "idempotencia" {
+  name         = "${var.project_name}-idempotencia-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "chave"
+
+  attribute {
+    name = "chave"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ttl"
+    enabled        = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = local.common_tags
+}
+
+# Trilha de auditoria das decisões (só inclusão, sem TTL)
+resource "aws_dynamodb_table" "auditoria" {
+  name         = "${var.project_name}-auditoria-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "cliente_id"
+  range_key    = "registro"
+
+  attribute {
+    name = "cliente_id"
+    type = "S"
+  }
+
+  attribute {
+    name = "registro"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = local.common_tags
+}
+
+# Desafios de step-up resgatados; expiram junto com o token
+resource "aws_dynamodb_table" "stepup_desafios" {
+  name         = "${var.project_name}-stepup-desafios-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "desafio"
+
+  attribute {
+    name = "desafio"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ttl"
+    enabled        = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = local.common_tags
+}
+
+# Confirmações de eventos publicados, base da reconciliação de eventos
+resource "aws_dynamodb_table" "eventos_confirmados" {
+  name         = "${var.project_name}-eventos-confirmados-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "transacao_id"
+
+  attribute {
+    name = "transacao_id"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ttl"
+    enabled        = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = local.common_tags
+}
+
+# Configuração dinâmica (item id=politica_aprovacao)
+resource "aws_dynamodb_table" "configuracoes" {
+  name         = "${var.project_name}-configuracoes-${var.environment}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "id"
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  tags = local.common_tags
+}
+
 # === SNS Topic e SQS Queues ===
 
 # Tópico SNS principal para eventos de transação
@@ -264,19 +500,21 @@ resource "aws_iam_policy" "dynamodb_policy" {
     Statement = [
       {
         Effect = "Allow"
+        # TransactWriteItems é autorizada pelas ações de cada item
+        # (PutItem, UpdateItem, ConditionCheckItem)
         Action = [
           "dynamodb:GetItem",
+          "dynamodb:BatchGetItem",
           "dynamodb:PutItem",
           "dynamodb:UpdateItem",
+          "dynamodb:ConditionCheckItem",
           "dynamodb:Query",
           "dynamodb:Scan"
         ]
-        Resource = [
-          aws_dynamodb_table.clientes.arn,
-          aws_dynamodb_table.transacoes.arn,
-          "${aws_dynamodb_table.clientes.arn}/index/*",
-          "${aws_dynamodb_table.transacoes.arn}/index/*"
-        ]
+        Resource = concat(
+          local.dynamodb_tables,
+          [for arn in local.dynamodb_tables : "${arn}/index/*"]
+        )
       }
     ]
   })
@@ -334,12 +572,23 @@ resource "aws_lambda_function" "authorizer" {
 
   # Environment variables
   environment {
-    variables = {
-      CLIENTES_TABLE_NAME    = aws_dynamodb_table.clientes.name
-      TRANSACOES_TABLE_NAME  = aws_dynamodb_table.transacoes.name
-      SNS_TOPIC_ARN          = aws_sns_topic.transacoes.arn
-      ENVIRONMENT            = var.environment
-    }
+    variables = merge(
+      {
+        CLIENTES_TABLE_NAME            = aws_dynamodb_table.clientes.name
+        TRANSACOES_TABLE_NAME          = local.transacoes_table_name
+        TRANSACOES_LAYOUT              = var.transacoes_layout
+        SNS_TOPIC_ARN                  = aws_sns_topic.transacoes.arn
+        ENVIRONMENT                    = var.environment
+        IDEMPOTENCIA_TABLE_NAME        = aws_dynamodb_table.idempotencia.name
+        AUDITORIA_TABLE_NAME           = aws_dynamodb_table.auditoria.name
+        EVENTOS_CONFIRMADOS_TABLE_NAME = aws_dynamodb_table.eventos_confirmados.name
+        STEP_UP_DESAFIOS_TABLE_NAME    = aws_dynamodb_table.stepup_desafios.name
+      },
+      # Estas tabelas mudam o comportamento quando configuradas: só entram
+      # quando habilitadas
+      var.publicacao_via_outbox ? { OUTBOX_TABLE_NAME = aws_dynamodb_table.outbox.name } : {},
+      var.politica_aprovacao_dinamica ? { POLITICA_TABLE_NAME = aws_dynamodb_table.configuracoes.name } : {},
+    )
   }
 
   # X-Ray tracing
@@ -490,8 +739,14 @@ output "api_gateway_url" {
 output "dynamodb_tables" {
   description = "Nomes das tabelas DynamoDB"
   value = {
-    clientes   = aws_dynamodb_table.clientes.name
-    transacoes = aws_dynamodb_table.transacoes.name
+    clientes            = aws_dynamodb_table.clientes.name
+    transacoes          = local.transacoes_table_name
+    outbox              = aws_dynamodb_table.outbox.name
+    idempotencia        = aws_dynamodb_table.idempotencia.name
+    auditoria           = aws_dynamodb_table.auditoria.name
+    stepup_desafios     = aws_dynamodb_table.stepup_desafios.name
+    eventos_confirmados = aws_dynamodb_table.eventos_confirmados.name
+    configuracoes       = aws_dynamodb_table.configuracoes.name
   }
 }
 
@@ -0,0 +1,379 @@
+// Package app monta o serviço de autorização a partir das variáveis de
+// ambiente. É compartilhado pelos binários que autorizam transações (Lambda
+// em cmd/authorizer e consumidor de fila em cmd/consumer), para que ambos
+// apliquem exatamente as mesmas regras, e pelo relay do outbox (cmd/outbox),
+// que publica com os mesmos publicadores.
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"authorizer/internal/buildinfo"
+	"authorizer/internal/core/domain"
+	"authorizer/internal/core/service"
+	awslambda "authorizer/internal/handler/lambda"
+	"authorizer/internal/messaging"
+	dynamorepo "authorizer/internal/repository/dynamodb"
+	"authorizer/internal/security/stepup"
+	"authorizer/internal/webhook"
+)
+
+// NewTransacaoService cria o serviço de autorização com os repositórios
+// DynamoDB, os publicadores de eventos e as regras habilitadas no ambiente.
+// nomeServico identifica o binário na trilha de auditoria.
+func NewTransacaoService(
+	dynamoClient *dynamodb.Client,
+	nomeServico string,
+	metricsCollector domain.MetricsCollector,
+	tracer domain.DistributedTracer,
+	structuredLogger domain.Logger,
+) service.TransacaoService {
+	clientesTableName := getEnvOrDefault("CLIENTES_TABLE_NAME", "clientes")
+	transacoesTableName := getEnvOrDefault("TRANSACOES_TABLE_NAME", "transacoes")
+
+	// Inicialização dos repositórios; operações acima do limiar geram aviso
+	// e a métrica slow_operation (0 desliga)
+	operacaoLenta := dynamorepo.WithLimiarOperacaoLenta(
+		time.Duration(getEnvIntOrDefault("DYNAMODB_LIMIAR_LENTIDAO_MS", 100)) * time.Millisecond,
+	)
+	limiteRepository := dynamorepo.NewLimiteRepository(dynamoClient, clientesTableName,
+		dynamorepo.WithObservabilidade(metricsCollector, structuredLogger),
+		operacaoLenta,
+		dynamorepo.WithPoliticaLimiteNegativo(dynamorepo.PoliticaLimiteNegativo(
+			getEnvOrDefault("LIMITE_NEGATIVO_POLITICA", string(dynamorepo.LimiteNegativoPassthrough)),
+		)),
+	)
+	opcoesTransacoes := []dynamorepo.Option{
+		dynamorepo.WithObservabilidade(metricsCollector, structuredLogger),
+		operacaoLenta,
+		// TTL conta do horário do servidor quando o timestamp diverge além da tolerância
+		dynamorepo.WithToleranciaRelogioTTL(time.Duration(getEnvIntOrDefault("TTL_TOLERANCIA_RELOGIO_SEGUNDOS", 300)) * time.Second),
+		dynamorepo.WithMaxTransacoesConsulta(getEnvIntOrDefault("TRANSACOES_CONSULTA_MAX", 100)),
+	}
+	if getEnvOrDefault("TRANSACOES_LAYOUT", "SIMPLES") == "COMPOSTA" {
+		opcoesTransacoes = append(opcoesTransacoes, dynamorepo.WithLayoutTransacoes(dynamorepo.LayoutChaveComposta))
+	}
+	// Eventos gravados no outbox junto da transação (habilitado quando a tabela é configurada)
+	outboxTableName := os.Getenv("OUTBOX_TABLE_NAME")
+	if outboxTableName != "" {
+		opcoesTransacoes = append(opcoesTransacoes, dynamorepo.WithTabelaOutbox(outboxTableName))
+	}
+	transacaoRepository := dynamorepo.NewTransacaoRepository(dynamoClient, transacoesTableName, opcoesTransacoes...)
+	eventPublisher, limitePublisher := novosEventPublishers()
+
+	// Detecção de duplicidade (desligada quando a janela é zero)
+	janelaDuplicidade := time.Duration(getEnvIntOrDefault("DUPLICIDADE_JANELA_SEGUNDOS", 0)) * time.Second
+	modoDuplicidade := service.ModoDuplicidade(getEnvOrDefault("DUPLICIDADE_MODO", string(service.DuplicidadeRejeitar)))
+
+	opcoes := []service.Option{
+		service.WithDeteccaoDuplicidade(janelaDuplicidade, modoDuplicidade),
+		service.WithToleranciaTimestamp(
+			time.Duration(getEnvIntOrDefault("TIMESTAMP_TOLERANCIA_SEGUNDOS", 300))*time.Second,
+			service.ModoTimestampFuturo(getEnvOrDefault("TIMESTAMP_FUTURO_MODO", string(service.TimestampFuturoRejeitar))),
+		),
+		service.WithParcelas(
+			getEnvIntOrDefault("PARCELAS_MAX", 12),
+			getEnvIntMapOrDefault("PARCELAS_MAX_POR_TIER", nil),
+		),
+		// Sem store configurado, eventos acima do limite são truncados
+		service.WithLimitePayloadEvento(getEnvIntOrDefault("EVENTO_PAYLOAD_MAX_BYTES", 256*1024), nil),
+	}
+
+	// Política de aprovação dinâmica (habilitada quando a tabela é configurada)
+	if politicaTableName := os.Getenv("POLITICA_TABLE_NAME"); politicaTableName != "" {
+		intervalo := time.Duration(getEnvIntOrDefault("POLITICA_REFRESH_SEGUNDOS", 60)) * time.Second
+		policyRepository := dynamorepo.NewPolicyRepository(dynamoClient, politicaTableName)
+		opcoes = append(opcoes, service.WithPoliticaAprovacao(policyRepository, intervalo))
+	}
+
+	// Idempotência por header Idempotency-Key (ou ID da mensagem, no
+	// consumidor); expiradas, as chaves são reprocessadas
+	if idempotenciaTableName := os.Getenv("IDEMPOTENCIA_TABLE_NAME"); idempotenciaTableName != "" {
+		store := dynamorepo.NewIdempotenciaRepository(dynamoClient, idempotenciaTableName,
+			dynamorepo.WithObservabilidade(metricsCollector, structuredLogger),
+		)
+		ttl := time.Duration(getEnvIntOrDefault("IDEMPOTENCIA_TTL_HORAS", 24)) * time.Hour
+		// Cache local dos desfechos recentes: retentativas imediatas na mesma
+		// instância não voltam à tabela (tamanho 0 desliga)
+		cacheTTL := time.Duration(getEnvIntOrDefault("IDEMPOTENCIA_CACHE_TTL_SEGUNDOS", 30)) * time.Second
+		cacheStore := service.NewCacheIdempotencia(store, getEnvIntOrDefault("IDEMPOTENCIA_CACHE_TAMANHO", 0), cacheTTL)
+		opcoes = append(opcoes, service.WithIdempotencia(cacheStore, ttl))
+	}
+
+	// Trilha de auditoria das decisões (tabela só de inclusão)
+	if auditoriaTableName := os.Getenv("AUDITORIA_TABLE_NAME"); auditoriaTableName != "" {
+		opcoesAuditoria := []dynamorepo.Option{dynamorepo.WithObservabilidade(metricsCollector, structuredLogger)}
+		if os.Getenv("AUDITORIA_CADEIA_HASH") == "true" {
+			opcoesAuditoria = append(opcoesAuditoria, dynamorepo.WithCadeiaHashAuditoria())
+		}
+		opcoes = append(opcoes, service.WithAuditoria(
+			dynamorepo.NewAuditoriaRepository(dynamoClient, auditoriaTableName, opcoesAuditoria...),
+			nomeServico+"@"+buildinfo.Version,
+		))
+	}
+
+	// Confirmação de eventos publicados, base do job de reconciliação
+	if acksTableName := os.Getenv("EVENTOS_CONFIRMADOS_TABLE_NAME"); acksTableName != "" {
+		opcoes = append(opcoes, service.WithConfirmacaoEventos(dynamorepo.NewEventAckRepository(dynamoClient, acksTableName)))
+	}
+
+	// Step-up acima do limite suave (habilitado quando o segredo é configurado);
+	// cada token é resgatado uma única vez na tabela de desafios
+	if segredo := os.Getenv("STEP_UP_SECRET"); segredo != "" {
+		ttl := time.Duration(getEnvIntOrDefault("STEP_UP_TOKEN_TTL_SEGUNDOS", 300)) * time.Second
+		desafios := dynamorepo.NewStepUpDesafioRepository(dynamoClient,
+			getEnvOrDefault("STEP_UP_DESAFIOS_TABLE_NAME", "stepup-desafios"),
+			dynamorepo.WithObservabilidade(metricsCollector, structuredLogger),
+		)
+		verifier := stepup.NewHMACStepUp([]byte(segredo), ttl, desafios)
+		opcoes = append(opcoes, service.WithStepUp(getEnvIntOrDefault("STEP_UP_LIMITE_CENTAVOS", 0), verifier))
+	}
+
+	// Veto síncrono de sistema de risco externo (habilitado quando a URL é configurada)
+	if preAuthURL := os.Getenv("PRE_AUTH_URL"); preAuthURL != "" {
+		timeout := time.Duration(getEnvIntOrDefault("PRE_AUTH_TIMEOUT_MS", 150)) * time.Millisecond
+		politica := service.PoliticaFalha(getEnvOrDefault("PRE_AUTH_POLITICA_FALHA", string(service.FalhaAberta)))
+		opcoes = append(opcoes, service.WithPreAutorizacao(webhook.NewPreAuthWebhook(preAuthURL, nil), timeout, politica))
+	}
+
+	// Limites por tier; a entrada "PADRAO" vale para tiers não mapeados
+	if politicasTier := os.Getenv("POLITICAS_TIER"); politicasTier != "" {
+		var porTier map[string]domain.PoliticaTier
+		if err := json.Unmarshal([]byte(politicasTier), &porTier); err != nil {
+			log.Printf("CONFIG: valor inválido para POLITICAS_TIER (%v), políticas por tier desligadas", err)
+		} else {
+			opcoes = append(opcoes, service.WithPoliticasPorTier(porTier, porTier["PADRAO"]))
+		}
+	}
+
+	// Verificação de cartão com valor zero (opt-in; por padrão valor zero é rejeitado)
+	if os.Getenv("VERIFICACAO_VALOR_ZERO") == "true" {
+		opcoes = append(opcoes, service.WithVerificacaoValorZero())
+	}
+
+	// Cliente sem registro de limite: provisiona com o limite padrão em vez de
+	// rejeitar (0 mantém a rejeição com 404)
+	if limitePadrao := getEnvIntOrDefault("PROVISIONAMENTO_LIMITE_CENTAVOS", 0); limitePadrao > 0 {
+		opcoes = append(opcoes, service.WithProvisionamentoAutomatico(limitePadrao))
+	}
+
+	// Apenas eventos de aprovação (rejeições continuam salvas e nas métricas)
+	if os.Getenv("EVENTOS_REJEICAO_SUPRIMIDOS") == "true" {
+		opcoes = append(opcoes, service.WithSupressaoEventosRejeicao())
+	}
+
+	// Ordem entre persistência e publicação: outbox apenas com tabela configurada
+	if outboxTableName != "" {
+		opcoes = append(opcoes, service.WithOrdemPublicacao(service.PublicarViaOutbox))
+	}
+
+	// Nova tentativa única de débito após crédito concorrente (0 desliga)
+	if prazo := getEnvIntOrDefault("DEBITO_RETENTATIVA_MS", 0); prazo > 0 {
+		opcoes = append(opcoes, service.WithRetentativaDebito(time.Duration(prazo)*time.Millisecond))
+	}
+
+	// Evento LIMITE_ALTERADO a cada débito de limite_atual (auditoria de saldos)
+	if os.Getenv("EVENTOS_LIMITE") == "true" {
+		opcoes = append(opcoes, service.WithEventosLimite(limitePublisher))
+	}
+
+	// Moedas aceitas (ISO 4217); códigos malformados são ignorados e, sem
+	// nenhum válido, vale o padrão BRL
+	var moedas []string
+	for _, moeda := range getEnvListOrDefault("MOEDAS_SUPORTADAS", nil) {
+		if err := domain.ValidarCodigoMoeda(moeda); err != nil {
+			log.Printf("CONFIG: moeda inválida em MOEDAS_SUPORTADAS (%q), ignorada", moeda)
+			continue
+		}
+		moedas = append(moedas, moeda)
+	}
+	if len(moedas) > 0 {
+		opcoes = append(opcoes, service.WithMoedasSuportadas(moedas...))
+	}
+
+	return service.NewTransacaoService(
+		limiteRepository,
+		transacaoRepository,
+		eventPublisher,
+		metricsCollector,
+		tracer,
+		structuredLogger,
+		opcoes...,
+	)
+}
+
+// novosEventPublishers cria os publicadores de eventos de transação e de
+// limite: SNS (ou arquivo JSONL, em desenvolvimento) com as rotas por motivo
+// de rejeição. O relay do outbox usa os mesmos, para que as duas formas de
+// entrega publiquem igual.
+func novosEventPublishers() (domain.EventPublisher, domain.LimiteEventPublisher) {
+	snsTopicArn := getEnvOrDefault("SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:123456789012:transacoes")
+
+	var eventPublisher domain.EventPublisher = &SimpleEventPublisher{topicArn: snsTopicArn}
+	var limitePublisher domain.LimiteEventPublisher = &SimpleEventPublisher{topicArn: snsTopicArn}
+
+	// Desenvolvimento local: eventos gravados em JSONL no arquivo em vez do SNS
+	if caminho := os.Getenv("EVENTOS_ARQUIVO"); caminho != "" {
+		arquivo, err := messaging.NewArquivoEventPublisher(caminho,
+			messaging.WithTamanhoMaximo(int64(getEnvIntOrDefault("EVENTOS_ARQUIVO_MAX_MB", 0))<<20),
+		)
+		if err != nil {
+			log.Printf("CONFIG: EVENTOS_ARQUIVO ignorado: %v", err)
+		} else {
+			eventPublisher, limitePublisher = arquivo, arquivo
+		}
+	}
+
+	// Tópico por código de motivo das rejeições ("codigo=arn,codigo=arn");
+	// motivos sem rota seguem no tópico padrão
+	if rotas := getEnvListOrDefault("SNS_TOPICOS_REJEICAO", nil); len(rotas) > 0 {
+		porMotivo := make(map[string]domain.EventPublisher)
+		for _, rota := range rotas {
+			codigo, arn, ok := strings.Cut(rota, "=")
+			if !ok || codigo == "" || arn == "" {
+				log.Printf("CONFIG: entrada inválida em SNS_TOPICOS_REJEICAO (%q), ignorada", rota)
+				continue
+			}
+			porMotivo[codigo] = &SimpleEventPublisher{topicArn: arn}
+		}
+		eventPublisher = messaging.NewRoteadorRejeicoes(eventPublisher, porMotivo)
+	}
+
+	return eventPublisher, limitePublisher
+}
+
+// NewOutboxRelayService cria o relay do outbox com os publicadores e o
+// limite de payload do serviço de autorização
+func NewOutboxRelayService(
+	dynamoClient *dynamodb.Client,
+	metricsCollector domain.MetricsCollector,
+	structuredLogger domain.Logger,
+) *service.OutboxRelayService {
+	outboxTableName := getEnvOrDefault("OUTBOX_TABLE_NAME", "eventos_outbox")
+	eventPublisher, _ := novosEventPublishers()
+
+	// Confirmações para a reconciliação de eventos (opcional)
+	var ackStore domain.EventAckStore
+	if acksTableName := os.Getenv("EVENTOS_CONFIRMADOS_TABLE_NAME"); acksTableName != "" {
+		ackStore = dynamorepo.NewEventAckRepository(dynamoClient, acksTableName)
+	}
+
+	return service.NewOutboxRelayService(
+		dynamorepo.NewOutboxRepository(dynamoClient, outboxTableName,
+			dynamorepo.WithObservabilidade(metricsCollector, structuredLogger),
+		),
+		eventPublisher,
+		ackStore,
+		metricsCollector,
+		structuredLogger,
+		service.WithLimitePayloadRelay(limitePayloadEvento(), nil),
+	)
+}
+
+// limitePayloadEvento é o tamanho máximo, em bytes, do payload publicado
+func limitePayloadEvento() int {
+	return getEnvIntOrDefault("EVENTO_PAYLOAD_MAX_BYTES", 256*1024)
+}
+
+// OpcoesPayload são as opções do handler que definem como o payload vira
+// uma transação (precisão e arredondamento do valor, formato do ID); valem
+// igualmente para o endpoint HTTP e para as mensagens de fila
+func OpcoesPayload() []awslambda.Option {
+	// IDs de transação: UUIDv4 (padrão) ou ULID, ordenável pelo horário
+	var geradorID domain.IDGenerator = domain.GeradorUUID{}
+	if getEnvOrDefault("TRANSACAO_ID_FORMATO", "UUID") == "ULID" {
+		geradorID = domain.NewGeradorULID()
+	}
+
+	return []awslambda.Option{
+		awslambda.WithModoPrecisao(awslambda.ModoPrecisao(getEnvOrDefault("VALOR_PRECISAO_MODO", string(awslambda.PrecisaoLeniente)))),
+		awslambda.WithModoArredondamento(domain.ModoArredondamento(getEnvOrDefault("VALOR_ARREDONDAMENTO_MODO", string(domain.ArredondamentoMeioParaCima)))),
+		awslambda.WithGeradorID(geradorID),
+	}
+}
+
+// getEnvOrDefault retorna variável de ambiente ou valor padrão
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvIntOrDefault retorna variável de ambiente inteira ou valor padrão
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("CONFIG: valor inválido para %s (%q), usando padrão %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvListOrDefault lê uma lista no formato "A,B,C"
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvIntMapOrDefault lê um mapa no formato "CHAVE:N,CHAVE:N"
+func getEnvIntMapOrDefault(key string, defaultValue map[string]int) map[string]int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]int)
+	for _, par := range strings.Split(value, ",") {
+		chave, numero, ok := strings.Cut(strings.TrimSpace(par), ":")
+		parsed, err := strconv.Atoi(numero)
+		if !ok || err != nil {
+			log.Printf("CONFIG: entrada inválida em %s (%q), ignorada", key, par)
+			continue
+		}
+		result[chave] = parsed
+	}
+	return result
+}
+
+// SimpleEventPublisher implementação simplificada para eventos
+type SimpleEventPublisher struct {
+	topicArn string
+}
+
+func (s *SimpleEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	log.Printf("EVENT: Transação aprovada - Cliente: %s, Valor: %.2f, ID: %s",
+		evento.ClienteID, evento.Valor, evento.TransacaoID)
+	return nil
+}
+
+func (s *SimpleEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	log.Printf("EVENT: Transação rejeitada - Cliente: %s, Valor: %.2f, ID: %s",
+		evento.ClienteID, evento.Valor, evento.TransacaoID)
+	return nil
+}
+
+func (s *SimpleEventPublisher) PublishLimiteAlterado(ctx context.Context, evento *domain.LimiteAlteradoEvento) error {
+	log.Printf("EVENT: Limite alterado - Cliente: %s, Motivo: %s, %d -> %d centavos",
+		evento.ClienteID, evento.Motivo, evento.LimiteAnterior, evento.LimiteAtual)
+	return nil
+}
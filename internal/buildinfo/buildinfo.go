@@ -0,0 +1,13 @@
+// Package buildinfo expõe os metadados de build injetados via ldflags:
+//
+//	go build -ldflags "-X authorizer/internal/buildinfo.Version=1.4.0 \
+//	  -X authorizer/internal/buildinfo.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X authorizer/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+// Valores padrão usados em builds locais sem ldflags
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildTime = "unknown"
+)
@@ -0,0 +1,151 @@
+// Package consumer executa o autorizador como processo de longa duração
+// (ex.: container no ECS) consumindo transações de uma Fonte. A única
+// implementação é FonteLeitor (uma mensagem por linha de um io.Reader).
+package consumer
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrFonteEncerrada indica que a fonte não entregará mais mensagens (ex.:
+// fim da entrada); o consumidor termina normalmente
+var ErrFonteEncerrada = errors.New("fonte de mensagens encerrada")
+
+// pausaErroPadrao é a espera antes de consultar a fonte após uma falha
+const pausaErroPadrao = time.Second
+
+// Mensagem é uma mensagem recebida da fila. O ID é estável entre
+// reentregas e serve de chave de idempotência da transação.
+type Mensagem struct {
+	ID    string
+	Corpo []byte
+}
+
+// Fonte abstrai a fila consumida. Receber aguarda (long polling) até haver
+// mensagens ou o contexto ser cancelado; Confirmar remove a mensagem da fila
+// (delete no SQS, commit do offset no Kafka). Mensagens não confirmadas são
+// reentregues pela própria fila.
+type Fonte interface {
+	Receber(ctx context.Context) ([]Mensagem, error)
+	Confirmar(ctx context.Context, mensagem Mensagem) error
+}
+
+// Processador trata uma mensagem; erro indica que ela deve ser reentregue
+// (ex.: awslambda.LambdaHandler.ProcessarMensagem)
+type Processador interface {
+	ProcessarMensagem(ctx context.Context, mensagemID string, corpo []byte) error
+}
+
+// Consumidor é o laço de polling: recebe, processa e confirma mensagens até
+// o contexto ser cancelado
+type Consumidor struct {
+	fonte            Fonte
+	processador      Processador
+	metricsCollector domain.MetricsCollector
+	logger           domain.Logger
+	pausaErro        time.Duration
+}
+
+// Option configura o Consumidor
+type Option func(*Consumidor)
+
+// WithPausaErro define a espera antes de consultar a fonte novamente após
+// uma falha no recebimento
+func WithPausaErro(pausa time.Duration) Option {
+	return func(c *Consumidor) {
+		c.pausaErro = pausa
+	}
+}
+
+func NewConsumidor(
+	fonte Fonte,
+	processador Processador,
+	metricsCollector domain.MetricsCollector,
+	logger domain.Logger,
+	opts ...Option,
+) *Consumidor {
+	c := &Consumidor{
+		fonte:            fonte,
+		processador:      processador,
+		metricsCollector: metricsCollector,
+		logger:           logger,
+		pausaErro:        pausaErroPadrao,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Executar consome a fonte até o contexto ser cancelado (desligamento
+// gracioso) ou a fonte se encerrar. No cancelamento, a mensagem em
+// processamento é concluída e confirmada; as demais do lote não são
+// processadas e ficam para a reentrega da fila. Retorna erro apenas se a
+// fonte se encerrou por falha.
+func (c *Consumidor) Executar(ctx context.Context) error {
+	c.logger.Info(ctx, "consumidor iniciado", nil)
+
+	var errFonte error
+	for ctx.Err() == nil {
+		mensagens, err := c.fonte.Receber(ctx)
+		if errors.Is(err, ErrFonteEncerrada) {
+			if err != ErrFonteEncerrada {
+				errFonte = err
+			}
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			c.logger.Error(ctx, "erro ao receber mensagens", err, nil)
+			c.metricsCollector.IncrementErrorCounter("consumer_receive_error")
+			c.aguardar(ctx, c.pausaErro)
+			continue
+		}
+
+		for _, mensagem := range mensagens {
+			if ctx.Err() != nil {
+				break
+			}
+			c.processar(ctx, mensagem)
+		}
+	}
+
+	c.logger.Info(ctx, "consumidor encerrado", nil)
+	return errFonte
+}
+
+// processar trata uma mensagem sem propagar o cancelamento do desligamento,
+// para que ela não seja interrompida no meio da autorização
+func (c *Consumidor) processar(ctx context.Context, mensagem Mensagem) {
+	ctx = context.WithoutCancel(ctx)
+	campos := map[string]interface{}{"mensagem_id": mensagem.ID}
+
+	if err := c.processador.ProcessarMensagem(ctx, mensagem.ID, mensagem.Corpo); err != nil {
+		c.logger.Warn(ctx, "mensagem não confirmada, será reentregue", campos)
+		c.metricsCollector.IncrementErrorCounter("consumer_message_error")
+		return
+	}
+
+	if err := c.fonte.Confirmar(ctx, mensagem); err != nil {
+		c.logger.Error(ctx, "erro ao confirmar mensagem", err, campos)
+		c.metricsCollector.IncrementErrorCounter("consumer_ack_error")
+	}
+}
+
+// aguardar dorme pela duração informada ou até o contexto ser cancelado
+func (c *Consumidor) aguardar(ctx context.Context, pausa time.Duration) {
+	timer := time.NewTimer(pausa)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,183 @@
+package consumer
+
+import (
+	"authorizer/internal/observability/noop"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFonte entrega os lotes configurados e depois se encerra (ou bloqueia
+// até o cancelamento, com bloquear)
+type fakeFonte struct {
+	mu           sync.Mutex
+	lotes        [][]Mensagem
+	errReceber   []error
+	bloquear     bool
+	confirmadas  []string
+	recebimentos int
+}
+
+func (f *fakeFonte) Receber(ctx context.Context) ([]Mensagem, error) {
+	f.mu.Lock()
+	f.recebimentos++
+	if len(f.errReceber) > 0 {
+		err := f.errReceber[0]
+		f.errReceber = f.errReceber[1:]
+		f.mu.Unlock()
+		return nil, err
+	}
+	if len(f.lotes) > 0 {
+		lote := f.lotes[0]
+		f.lotes = f.lotes[1:]
+		f.mu.Unlock()
+		return lote, nil
+	}
+	f.mu.Unlock()
+
+	if f.bloquear {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return nil, ErrFonteEncerrada
+}
+
+func (f *fakeFonte) Confirmar(ctx context.Context, mensagem Mensagem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.confirmadas = append(f.confirmadas, mensagem.ID)
+	return nil
+}
+
+// fakeProcessador registra os IDs e corpos processados e falha nos marcados
+type fakeProcessador struct {
+	mu          sync.Mutex
+	ids         []string
+	processados []string
+	falhar      map[string]bool
+	// aoProcessar é chamado durante o processamento (ex.: cancelar o contexto)
+	aoProcessar func(ctx context.Context)
+}
+
+func (p *fakeProcessador) ProcessarMensagem(ctx context.Context, mensagemID string, corpo []byte) error {
+	if p.aoProcessar != nil {
+		p.aoProcessar(ctx)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ids = append(p.ids, mensagemID)
+	p.processados = append(p.processados, string(corpo))
+	if p.falhar[string(corpo)] {
+		return errors.New("dynamodb indisponível")
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func mensagens(ids ...string) []Mensagem {
+	lote := make([]Mensagem, 0, len(ids))
+	for _, id := range ids {
+		lote = append(lote, Mensagem{ID: id, Corpo: []byte(id)})
+	}
+	return lote
+}
+
+func TestExecutar_ProcessaEConfirma(t *testing.T) {
+	fonte := &fakeFonte{
+		lotes:      [][]Mensagem{mensagens("m1", "m2"), mensagens("m3")},
+		errReceber: []error{errors.New("fila indisponível")},
+	}
+	processador := &fakeProcessador{falhar: map[string]bool{"m2": true}}
+
+	consumidor := NewConsumidor(fonte, processador, noop.MetricsCollector{}, noop.Logger{}, WithPausaErro(time.Millisecond))
+	if err := consumidor.Executar(context.Background()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if got := strings.Join(processador.processados, ","); got != "m1,m2,m3" {
+		t.Errorf("processadas esperado m1,m2,m3, got %s", got)
+	}
+
+	// m2 falhou: não é confirmada e fica para a reentrega da fila
+	if got := strings.Join(fonte.confirmadas, ","); got != "m1,m3" {
+		t.Errorf("confirmadas esperado m1,m3, got %s", got)
+	}
+}
+
+func TestExecutar_DesligamentoGracioso(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fonte := &fakeFonte{lotes: [][]Mensagem{mensagens("m1", "m2")}, bloquear: true}
+
+	// O sinal de desligamento chega durante o processamento de m1
+	processador := &fakeProcessador{aoProcessar: func(context.Context) { cancel() }}
+
+	terminou := make(chan error, 1)
+	go func() {
+		terminou <- NewConsumidor(fonte, processador, noop.MetricsCollector{}, noop.Logger{}).Executar(ctx)
+	}()
+
+	select {
+	case err := <-terminou:
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("consumidor não encerrou após o cancelamento")
+	}
+
+	// m1 em andamento é concluída e confirmada; m2 fica para a reentrega
+	if got := strings.Join(processador.processados, ","); got != "m1" {
+		t.Errorf("processadas esperado m1, got %s", got)
+	}
+	if got := strings.Join(fonte.confirmadas, ","); got != "m1" {
+		t.Errorf("confirmadas esperado m1, got %s", got)
+	}
+	if fonte.recebimentos != 1 {
+		t.Errorf("não deveria receber novos lotes após o cancelamento, got %d recebimentos", fonte.recebimentos)
+	}
+}
+
+func TestExecutar_CancelamentoDuranteRecebimento(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fonte := &fakeFonte{bloquear: true}
+
+	terminou := make(chan error, 1)
+	go func() {
+		terminou <- NewConsumidor(fonte, &fakeProcessador{}, noop.MetricsCollector{}, noop.Logger{}).Executar(ctx)
+	}()
+	cancel()
+
+	select {
+	case err := <-terminou:
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("consumidor não encerrou após o cancelamento")
+	}
+}
+
+func TestFonteLeitor(t *testing.T) {
+	fonte := NewFonteLeitor(strings.NewReader("{\"a\":1}\n\n{\"b\":2}\n{\"a\":1}\n"))
+	processador := &fakeProcessador{}
+
+	if err := NewConsumidor(fonte, processador, noop.MetricsCollector{}, noop.Logger{}).Executar(context.Background()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if got := strings.Join(processador.processados, "|"); got != `{"a":1}|{"b":2}|{"a":1}` {
+		t.Errorf("linhas processadas inesperadas: %s", got)
+	}
+	// O ID deriva do conteúdo: a mesma linha relida tem o mesmo ID (e a
+	// mesma chave de idempotência)
+	ids := processador.ids
+	if ids[0] == "" || ids[0] != ids[2] || ids[0] == ids[1] {
+		t.Errorf("IDs de mensagem inesperados: %v", ids)
+	}
+}
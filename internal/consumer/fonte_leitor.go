@@ -0,0 +1,62 @@
+package consumer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// FonteLeitor lê uma mensagem por linha de um io.Reader (ex.: stdin
+// alimentado por `kcat -C` ou um arquivo JSONL). Não há reentrega:
+// Confirmar não tem efeito. Sem ID de fila, o ID da mensagem é o SHA-256 da
+// linha, de modo que a mesma linha relida (ex.: reprocessamento a partir de
+// um offset anterior) não autoriza a transação de novo.
+type FonteLeitor struct {
+	linhas chan Mensagem
+	erro   error
+}
+
+// NewFonteLeitor inicia a leitura em segundo plano; linhas vazias são ignoradas
+func NewFonteLeitor(leitor io.Reader) *FonteLeitor {
+	f := &FonteLeitor{linhas: make(chan Mensagem)}
+	scanner := bufio.NewScanner(leitor)
+
+	go func() {
+		defer close(f.linhas)
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			corpo := append([]byte(nil), scanner.Bytes()...)
+			hash := sha256.Sum256(corpo)
+			f.linhas <- Mensagem{ID: hex.EncodeToString(hash[:]), Corpo: corpo}
+		}
+		f.erro = scanner.Err()
+	}()
+
+	return f
+}
+
+// Receber devolve a próxima linha; ErrFonteEncerrada ao fim da entrada
+// (com a causa, se a leitura falhou)
+func (f *FonteLeitor) Receber(ctx context.Context) ([]Mensagem, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case mensagem, ok := <-f.linhas:
+		if !ok {
+			if f.erro != nil {
+				return nil, fmt.Errorf("%w: %v", ErrFonteEncerrada, f.erro)
+			}
+			return nil, ErrFonteEncerrada
+		}
+		return []Mensagem{mensagem}, nil
+	}
+}
+
+func (f *FonteLeitor) Confirmar(ctx context.Context, mensagem Mensagem) error {
+	return nil
+}
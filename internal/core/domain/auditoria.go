@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+// AuditEntry é o registro imutável de uma decisão de autorização para a
+// trilha de auditoria: entradas, decisão, motivo, autor e instante
+type AuditEntry struct {
+	TransacaoID   string
+	ClienteID     string
+	ValorCentavos int64
+	Moeda         string
+	Parcelas      int
+	Canal         string
+	Tipo          string
+	CorrelationID string
+	// Decisao é o status resultante (APROVADA, REJEITADA ou PENDENTE)
+	Decisao      string
+	CodigoMotivo string
+	// Ator identifica quem decidiu (o serviço autorizador que aplicou as regras)
+	Ator      string
+	Timestamp time.Time
+	// HashAnterior e Hash encadeiam as entradas do cliente quando o
+	// repositório mantém a cadeia de hashes (vazios caso contrário)
+	HashAnterior string
+	Hash         string
+}
+
+// CamposAuditoria são os campos canônicos da entrada cobertos pelo hash,
+// incluindo o hash da entrada anterior
+func CamposAuditoria(e *AuditEntry) CamposChave {
+	return CamposChave{
+		"transacao_id":   e.TransacaoID,
+		"cliente_id":     e.ClienteID,
+		"valor_centavos": strconv.FormatInt(e.ValorCentavos, 10),
+		"moeda":          e.Moeda,
+		"parcelas":       strconv.Itoa(e.Parcelas),
+		"canal":          e.Canal,
+		"tipo":           e.Tipo,
+		"correlation_id": e.CorrelationID,
+		"decisao":        e.Decisao,
+		"codigo_motivo":  e.CodigoMotivo,
+		"ator":           e.Ator,
+		"timestamp":      e.Timestamp.UTC().Format(time.RFC3339Nano),
+		"hash_anterior":  e.HashAnterior,
+	}
+}
+
+// Encadear liga a entrada à anterior: define HashAnterior e calcula Hash.
+// Usa sempre SHA-256, para que a cadeia possa ser verificada fora do serviço.
+func (e *AuditEntry) Encadear(hashAnterior string) {
+	e.HashAnterior = hashAnterior
+	e.Hash = HasherSHA256{}.Chave(CamposAuditoria(e))
+}
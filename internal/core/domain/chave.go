@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CamposChave são os campos canônicos de uma chave derivada (idempotência,
+// deduplicação de eventos, detecção de duplicidade)
+type CamposChave map[string]string
+
+// serializar produz a forma canônica dos campos: objeto JSON com as chaves
+// em ordem lexicográfica, independente da ordem de inserção
+func (c CamposChave) serializar() []byte {
+	// encoding/json ordena as chaves de mapas; strings não falham
+	serializado, _ := json.Marshal(map[string]string(c))
+	return serializado
+}
+
+// HasherSHA256 deriva chaves como SHA-256 (hex) da forma canônica dos campos
+type HasherSHA256 struct{}
+
+// Chave retorna o hash hexadecimal dos campos canônicos
+func (HasherSHA256) Chave(campos CamposChave) string {
+	soma := sha256.Sum256(campos.serializar())
+	return hex.EncodeToString(soma[:])
+}
+
+// CamposDuplicidade identifica transações logicamente idênticas: mesmo
+// cliente, valor e moeda, independente de ID, horário ou correlação
+func CamposDuplicidade(t *Transacao) CamposChave {
+	return CamposChave{
+		"cliente_id":     strings.TrimSpace(t.ClienteID),
+		"valor_centavos": strconv.FormatInt(t.ValorCentavos(), 10),
+		"moeda":          t.MoedaOuPadrao(),
+	}
+}
+
+// CamposDeduplicacaoEvento identifica o evento lógico: republicações do
+// mesmo evento da mesma transação geram a mesma chave
+func CamposDeduplicacaoEvento(e *TransacaoEvento) CamposChave {
+	return CamposChave{
+		"evento":       e.Evento,
+		"transacao_id": e.TransacaoID,
+	}
+}
+
+// CamposPayloadIdempotencia são os campos da requisição comparados quando
+// uma chave de idempotência é reutilizada: mudá-los muda a operação
+func CamposPayloadIdempotencia(t *Transacao) CamposChave {
+	campos := CamposChave{
+		"valor_centavos": strconv.FormatInt(t.ValorCentavos(), 10),
+		"moeda":          t.MoedaOuPadrao(),
+		"parcelas":       strconv.Itoa(t.NumeroParcelas()),
+		"tipo":           t.Tipo,
+		"canal":          t.Canal,
+	}
+	if t.DataAgendamento != nil {
+		campos["data_agendamento"] = t.DataAgendamento.UTC().Format(time.RFC3339)
+	}
+	return campos
+}
+
+// CamposIdempotencia identifica uma requisição pela chave de idempotência
+// informada pelo cliente, com escopo no próprio cliente
+func CamposIdempotencia(clienteID, chaveIdempotencia string) CamposChave {
+	return CamposChave{
+		"cliente_id":         strings.TrimSpace(clienteID),
+		"chave_idempotencia": strings.TrimSpace(chaveIdempotencia),
+	}
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHasherSHA256_EstavelNaOrdemDosCampos(t *testing.T) {
+	primeira := CamposChave{}
+	primeira["cliente_id"] = "c1"
+	primeira["valor_centavos"] = "1000"
+	primeira["moeda"] = "BRL"
+
+	segunda := CamposChave{}
+	segunda["moeda"] = "BRL"
+	segunda["valor_centavos"] = "1000"
+	segunda["cliente_id"] = "c1"
+
+	hasher := HasherSHA256{}
+	if hasher.Chave(primeira) != hasher.Chave(segunda) {
+		t.Error("a ordem de inserção não deveria alterar a chave")
+	}
+	if len(hasher.Chave(primeira)) != 64 {
+		t.Errorf("chave esperada com 64 caracteres hex, got %q", hasher.Chave(primeira))
+	}
+	// Fronteiras entre campos fazem parte da forma canônica
+	if hasher.Chave(CamposChave{"a": "bc"}) == hasher.Chave(CamposChave{"ab": "c"}) {
+		t.Error("campos diferentes não deveriam colidir")
+	}
+}
+
+func TestCamposDuplicidade_TransacoesLogicamenteIdenticas(t *testing.T) {
+	hasher := HasherSHA256{}
+
+	original := NewTransacao("c1", 10.5, "corr-1")
+	repetida := NewTransacao(" c1 ", 10.50, "corr-2")
+	repetida.Timestamp = original.Timestamp.Add(time.Minute)
+	outroValor := NewTransacao("c1", 10.51, "corr-1")
+
+	if hasher.Chave(CamposDuplicidade(original)) != hasher.Chave(CamposDuplicidade(repetida)) {
+		t.Error("transações logicamente idênticas deveriam ter a mesma chave")
+	}
+	if hasher.Chave(CamposDuplicidade(original)) == hasher.Chave(CamposDuplicidade(outroValor)) {
+		t.Error("valores diferentes deveriam gerar chaves diferentes")
+	}
+}
+
+func TestCamposDeduplicacaoEvento_RepublicacaoMantemChave(t *testing.T) {
+	hasher := HasherSHA256{}
+	transacao := NewTransacao("c1", 10.00, "corr")
+	transacao.Aprovar()
+
+	primeiro := transacao.ToEvento()
+	republicado := transacao.ToEvento()
+	republicado.Truncado = true
+
+	if hasher.Chave(CamposDeduplicacaoEvento(primeiro)) != hasher.Chave(CamposDeduplicacaoEvento(republicado)) {
+		t.Error("republicação do mesmo evento deveria manter a chave")
+	}
+
+	transacao.Rejeitar()
+	if hasher.Chave(CamposDeduplicacaoEvento(primeiro)) == hasher.Chave(CamposDeduplicacaoEvento(transacao.ToEvento())) {
+		t.Error("eventos diferentes da mesma transação deveriam ter chaves diferentes")
+	}
+}
+
+func TestCamposIdempotencia_EscopoDoCliente(t *testing.T) {
+	hasher := HasherSHA256{}
+
+	if hasher.Chave(CamposIdempotencia("c1", "k-1")) != hasher.Chave(CamposIdempotencia("c1", " k-1")) {
+		t.Error("espaços ao redor não deveriam alterar a chave")
+	}
+	if hasher.Chave(CamposIdempotencia("c1", "k-1")) == hasher.Chave(CamposIdempotencia("c2", "k-1")) {
+		t.Error("a mesma chave de clientes diferentes não deveria colidir")
+	}
+}
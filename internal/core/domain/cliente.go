@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// DisponivelPercentual retorna a fração do limite de crédito ainda
+// disponível, entre 0 e 100. Cliente sem limite de crédito não tem nada
+// disponível (0%), evitando a divisão por zero.
+func (c *Cliente) DisponivelPercentual() float64 {
+	if c.LimiteCredit <= 0 {
+		return 0
+	}
+
+	percentual := float64(c.LimiteAtual) / float64(c.LimiteCredit) * 100
+	switch {
+	case percentual < 0:
+		return 0
+	case percentual > 100:
+		return 100
+	}
+	return percentual
+}
+
+// UtilizacaoPercentual retorna a fração do limite de crédito já utilizada,
+// complementar a DisponivelPercentual (cliente sem crédito = 100%)
+func (c *Cliente) UtilizacaoPercentual() float64 {
+	return 100 - c.DisponivelPercentual()
+}
+
+// NormalizarEmail valida o e-mail (formato RFC 5322 via net/mail) e o
+// devolve em minúsculas. Só é aceito o endereço puro: formas com nome
+// ("Fulano <a@b.com>") ou texto além do endereço não sobrevivem à ida e
+// volta pelo parser e retornam ErrEmailInvalido.
+func NormalizarEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+
+	endereco, err := mail.ParseAddress(email)
+	if err != nil || endereco.Name != "" || endereco.Address != email {
+		return "", ErrEmailInvalido
+	}
+
+	return strings.ToLower(endereco.Address), nil
+}
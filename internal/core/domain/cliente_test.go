@@ -0,0 +1,63 @@
+package domain
+
+import "testing"
+
+func TestCliente_Percentuais(t *testing.T) {
+	tests := []struct {
+		name               string
+		cliente            Cliente
+		expectedDisponivel float64
+		expectedUtilizacao float64
+	}{
+		{name: "sem limite de crédito", cliente: Cliente{LimiteCredit: 0, LimiteAtual: 0}, expectedDisponivel: 0, expectedUtilizacao: 100},
+		{name: "totalmente utilizado", cliente: Cliente{LimiteCredit: 100000, LimiteAtual: 0}, expectedDisponivel: 0, expectedUtilizacao: 100},
+		{name: "metade utilizada", cliente: Cliente{LimiteCredit: 100000, LimiteAtual: 50000}, expectedDisponivel: 50, expectedUtilizacao: 50},
+		{name: "nada utilizado", cliente: Cliente{LimiteCredit: 100000, LimiteAtual: 100000}, expectedDisponivel: 100, expectedUtilizacao: 0},
+		{name: "limite atual negativo", cliente: Cliente{LimiteCredit: 100000, LimiteAtual: -500}, expectedDisponivel: 0, expectedUtilizacao: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cliente.DisponivelPercentual(); got != tt.expectedDisponivel {
+				t.Errorf("DisponivelPercentual esperado %.2f, got %.2f", tt.expectedDisponivel, got)
+			}
+			if got := tt.cliente.UtilizacaoPercentual(); got != tt.expectedUtilizacao {
+				t.Errorf("UtilizacaoPercentual esperado %.2f, got %.2f", tt.expectedUtilizacao, got)
+			}
+		})
+	}
+}
+
+func TestNormalizarEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		esperado string
+		erro     bool
+	}{
+		{name: "válido", email: "fulano@exemplo.com", esperado: "fulano@exemplo.com"},
+		{name: "maiúsculas e minúsculas", email: "Fulano.Silva@Exemplo.COM", esperado: "fulano.silva@exemplo.com"},
+		{name: "espaços nas bordas", email: "  fulano@exemplo.com ", esperado: "fulano@exemplo.com"},
+		{name: "sem arroba", email: "fulano.exemplo.com", erro: true},
+		{name: "sem domínio", email: "fulano@", erro: true},
+		{name: "vazio", email: "", erro: true},
+		{name: "com nome de exibição", email: "Fulano <fulano@exemplo.com>", erro: true},
+		{name: "entre sinais de menor e maior", email: "<fulano@exemplo.com>", erro: true},
+		{name: "dois endereços", email: "a@exemplo.com, b@exemplo.com", erro: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email, err := NormalizarEmail(tt.email)
+			if tt.erro {
+				if err != ErrEmailInvalido {
+					t.Errorf("esperado ErrEmailInvalido, got %v (%q)", err, email)
+				}
+				return
+			}
+			if err != nil || email != tt.esperado {
+				t.Errorf("esperado %q, got %q (erro %v)", tt.esperado, email, err)
+			}
+		})
+	}
+}
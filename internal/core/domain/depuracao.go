@@ -0,0 +1,29 @@
+package domain
+
+import "context"
+
+// SobrescritasDepuracao desligam regras numa única requisição de QA. São
+// informadas por cabeçalhos X-Debug-* e nunca aceitas em produção.
+type SobrescritasDepuracao struct {
+	// DesligarVelocidade pula a verificação de limite diário
+	DesligarVelocidade bool
+	// DesligarDuplicidade pula a detecção de transação duplicada
+	DesligarDuplicidade bool
+}
+
+// Ativas indica se alguma sobrescrita foi solicitada
+func (s SobrescritasDepuracao) Ativas() bool {
+	return s.DesligarVelocidade || s.DesligarDuplicidade
+}
+
+// ComSobrescritasDepuracao anexa as sobrescritas ao contexto da requisição
+func ComSobrescritasDepuracao(ctx context.Context, s SobrescritasDepuracao) context.Context {
+	return context.WithValue(ctx, "sobrescritas_depuracao", s)
+}
+
+// SobrescritasDepuracaoDoContexto retorna as sobrescritas da requisição
+// (valor zero, sem sobrescritas, quando ausentes)
+func SobrescritasDepuracaoDoContexto(ctx context.Context) SobrescritasDepuracao {
+	s, _ := ctx.Value("sobrescritas_depuracao").(SobrescritasDepuracao)
+	return s
+}
@@ -1,9 +1,96 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrLimiteInsuficiente   = errors.New("limite insuficiente para autorizar a transação")
 	ErrClienteNaoEncontrado = errors.New("cliente não encontrado")
 	ErrTransacaoDuplicada   = errors.New("transação duplicada")
+	// ErrTransacaoNaoEncontrada indica ID de transação sem registro persistido
+	ErrTransacaoNaoEncontrada = errors.New("transação não encontrada")
+	// ErrTransacaoDuplicadaSuspeita indica transação idêntica (mesmo cliente e valor)
+	// aprovada há poucos segundos, provável clique duplo
+	ErrTransacaoDuplicadaSuspeita = errors.New("transação idêntica aprovada recentemente, possível duplicidade")
+	// ErrTimeoutOperacao indica que uma operação de persistência estourou
+	// seu orçamento de tempo próprio (e não o prazo da requisição)
+	ErrTimeoutOperacao = errors.New("tempo limite da operação de persistência excedido")
+	// ErrResultadoIndeterminado indica escrita que estourou o tempo sem
+	// confirmação: pode ter sido aplicada, e repeti-la pode duplicar o efeito
+	ErrResultadoIndeterminado = errors.New("resultado da escrita indeterminado")
+	// ErrThrottled indica throttling da persistência que persistiu após as
+	// retentativas do SDK; o cliente deve tentar novamente mais tarde
+	ErrThrottled = errors.New("capacidade da persistência excedida (throttling)")
+	// ErrConfiguracaoInvalida indica erro operacional de configuração (ex.:
+	// tabela inexistente); não é transitório e não adianta repetir
+	ErrConfiguracaoInvalida = errors.New("configuração inválida da persistência")
+	// ErrLimiteInconsistente indica limite_atual negativo, estado que o débito
+	// atômico deveria impedir
+	ErrLimiteInconsistente = errors.New("limite atual do cliente em estado inconsistente")
+	// ErrLimiteAlteradoConcorrente indica que limite_atual mudou entre a
+	// leitura e um ajuste condicionado ao valor lido
+	ErrLimiteAlteradoConcorrente = errors.New("limite atual alterado concorrentemente")
+	// ErrDesafioStepUpConsumido indica token de step-up cujo desafio já foi
+	// resgatado por outra transação
+	ErrDesafioStepUpConsumido = errors.New("desafio de step-up já consumido")
+	// ErrPreAutorizacaoNegada indica veto do callback de pré-autorização
+	ErrPreAutorizacaoNegada = errors.New("transação vetada pela pré-autorização")
+	// ErrPreAutorizacaoIndisponivel indica falha ou timeout do callback com política fail-closed
+	ErrPreAutorizacaoIndisponivel = errors.New("pré-autorização indisponível")
+	// ErrStepUpNecessario indica valor acima do limite suave sem token de step-up válido
+	ErrStepUpNecessario = errors.New("confirmação adicional (step-up) necessária")
+	// ErrAgendamentoJaIniciado indica que outra execução já assumiu a transação agendada
+	ErrAgendamentoJaIniciado = errors.New("execução da transação agendada já iniciada")
+	// ErrItensCorrompidos indica itens persistidos que não puderam ser lidos
+	ErrItensCorrompidos = errors.New("itens corrompidos na leitura")
+	// ErrEmailInvalido indica e-mail de cliente fora do formato de endereço
+	ErrEmailInvalido = errors.New("e-mail do cliente inválido")
+	// ErrLimiteConsultaInvalido indica quantidade máxima de itens não positiva numa consulta
+	ErrLimiteConsultaInvalido = errors.New("limite de itens da consulta deve ser positivo")
+	// ErrConflitoIdempotencia indica chave de idempotência reutilizada com
+	// outro payload: erro do cliente, e não uma repetição da requisição
+	ErrConflitoIdempotencia = errors.New("chave de idempotência reutilizada com payload diferente")
+	// ErrIdempotenciaEmProcessamento indica repetição recebida enquanto a
+	// requisição original com a mesma chave ainda está sendo autorizada
+	ErrIdempotenciaEmProcessamento = errors.New("requisição com a mesma chave de idempotência em processamento")
+	// ErrReservaIdempotencia indica chave de idempotência já reservada por
+	// outra requisição (ou reserva perdida ao finalizar)
+	ErrReservaIdempotencia = errors.New("chave de idempotência reservada por outra requisição")
 )
+
+// StepUpRequeridoError carrega a referência do desafio que o cliente deve
+// confirmar para obter o token de step-up
+type StepUpRequeridoError struct {
+	Desafio string
+}
+
+func (e *StepUpRequeridoError) Error() string {
+	return fmt.Sprintf("%s: desafio %s", ErrStepUpNecessario.Error(), e.Desafio)
+}
+
+// Is permite errors.Is(err, ErrStepUpNecessario)
+func (e *StepUpRequeridoError) Is(target error) bool {
+	return target == ErrStepUpNecessario
+}
+
+// ItensCorrompidosError acompanha os resultados válidos de uma leitura
+// parcial, identificando as chaves dos itens que falharam
+type ItensCorrompidosError struct {
+	Chaves []string
+	Causas []error
+}
+
+func (e *ItensCorrompidosError) Error() string {
+	return fmt.Sprintf("%s: %d item(ns) %v", ErrItensCorrompidos.Error(), len(e.Chaves), e.Chaves)
+}
+
+// Is permite errors.Is(err, ErrItensCorrompidos)
+func (e *ItensCorrompidosError) Is(target error) bool {
+	return target == ErrItensCorrompidos
+}
+
+func (e *ItensCorrompidosError) Unwrap() []error {
+	return e.Causas
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GeradorUUID gera UUIDv4 (padrão)
+type GeradorUUID struct{}
+
+// NovoID retorna um UUIDv4 aleatório
+func (GeradorUUID) NovoID() string {
+	return uuid.New().String()
+}
+
+// alfabetoCrockford é o base32 de Crockford usado na codificação do ULID
+const alfabetoCrockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// GeradorULID gera ULIDs (26 caracteres, 48 bits de timestamp em ms + 80
+// bits aleatórios). IDs gerados no mesmo milissegundo incrementam a parte
+// aleatória, mantendo a ordenação lexicográfica igual à ordem de geração.
+type GeradorULID struct {
+	mu       sync.Mutex
+	agora    func() time.Time
+	ultimoMs uint64
+	entropia [10]byte
+}
+
+// NewGeradorULID cria um gerador de ULID monotônico baseado no relógio do sistema
+func NewGeradorULID() *GeradorULID {
+	return NewGeradorULIDComRelogio(time.Now)
+}
+
+// NewGeradorULIDComRelogio cria um gerador de ULID com fonte de tempo própria
+// (útil em testes)
+func NewGeradorULIDComRelogio(agora func() time.Time) *GeradorULID {
+	return &GeradorULID{agora: agora}
+}
+
+// NovoID retorna o próximo ULID
+func (g *GeradorULID) NovoID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Relógio que volta no tempo mantém o último ms para não quebrar a ordem;
+	// estouro da parte aleatória avança o timestamp em 1ms
+	ms := uint64(g.agora().UnixMilli())
+	switch {
+	case ms > g.ultimoMs:
+		g.ultimoMs = ms
+		rand.Read(g.entropia[:])
+	case !g.incrementarEntropia():
+		g.ultimoMs++
+		rand.Read(g.entropia[:])
+	}
+
+	var bytes [16]byte
+	for i := 0; i < 6; i++ {
+		bytes[i] = byte(g.ultimoMs >> (8 * (5 - i)))
+	}
+	copy(bytes[6:], g.entropia[:])
+
+	return codificarULID(bytes)
+}
+
+// incrementarEntropia soma 1 à parte aleatória; retorna false em estouro
+func (g *GeradorULID) incrementarEntropia() bool {
+	for i := len(g.entropia) - 1; i >= 0; i-- {
+		g.entropia[i]++
+		if g.entropia[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// codificarULID converte os 128 bits em 26 caracteres base32 (o primeiro
+// caractere carrega apenas 3 bits)
+func codificarULID(bytes [16]byte) string {
+	saida := make([]byte, 26)
+	var acumulador uint16
+	bits := 2 // 130 bits codificados: dois bits zero à esquerda
+	pos := 0
+	for _, b := range bytes {
+		acumulador = acumulador<<8 | uint16(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			saida[pos] = alfabetoCrockford[(acumulador>>bits)&0x1F]
+			pos++
+		}
+	}
+	return string(saida)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// geradorSequencial produz IDs determinísticos para testes
+type geradorSequencial struct {
+	proximo int
+}
+
+func (g *geradorSequencial) NovoID() string {
+	g.proximo++
+	return fmt.Sprintf("tx-%d", g.proximo)
+}
+
+func TestNewTransacaoComGerador_Deterministico(t *testing.T) {
+	gerador := &geradorSequencial{}
+
+	primeira := NewTransacaoComGerador(gerador, "c1", 10.00, "corr")
+	segunda := NewTransacaoComGerador(gerador, "c1", 10.00, "corr")
+
+	if primeira.ID != "tx-1" || segunda.ID != "tx-2" {
+		t.Errorf("IDs esperados tx-1 e tx-2, got %s e %s", primeira.ID, segunda.ID)
+	}
+}
+
+func TestGeradorULID_Formato(t *testing.T) {
+	instante := time.UnixMilli(1469918176385)
+	id := NewGeradorULIDComRelogio(func() time.Time { return instante }).NovoID()
+
+	if len(id) != 26 {
+		t.Fatalf("ULID com 26 caracteres esperado, got %q", id)
+	}
+	// Timestamp codificado nos 10 primeiros caracteres (exemplo da especificação)
+	if id[:10] != "01ARYZ6S41" {
+		t.Errorf("prefixo de timestamp esperado 01ARYZ6S41, got %s", id[:10])
+	}
+}
+
+func TestGeradorULID_MonotonicoNoMesmoMilissegundo(t *testing.T) {
+	instante := time.Now()
+	gerador := NewGeradorULIDComRelogio(func() time.Time { return instante })
+
+	anterior := gerador.NovoID()
+	for i := 0; i < 1000; i++ {
+		id := gerador.NovoID()
+		if id <= anterior {
+			t.Fatalf("ULID não monotônico: %s após %s", id, anterior)
+		}
+		anterior = id
+	}
+}
+
+func TestGeradorULID_OrdenadoPorTempo(t *testing.T) {
+	instante := time.Now()
+	gerador := NewGeradorULIDComRelogio(func() time.Time { return instante })
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, gerador.NovoID())
+		instante = instante.Add(time.Millisecond)
+	}
+	// Relógio que volta no tempo não quebra a ordem
+	instante = instante.Add(-time.Hour)
+	ids = append(ids, gerador.NovoID())
+
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("ULIDs fora da ordem de geração: %v", ids)
+	}
+}
@@ -0,0 +1,20 @@
+package domain
+
+// StatusIdempotenciaEmProcessamento marca a chave reservada por uma
+// requisição cuja autorização ainda não terminou
+const StatusIdempotenciaEmProcessamento = "EM_PROCESSAMENTO"
+
+// RegistroIdempotencia é o desfecho guardado de uma requisição, devolvido
+// quando o cliente a repete com a mesma chave de idempotência
+type RegistroIdempotencia struct {
+	// Chave é a chave derivada (CamposIdempotencia), não a informada pelo cliente
+	Chave        string
+	TransacaoID  string
+	Status       string
+	CodigoMotivo string
+	// LimiteDisponivel é o limite após o débito original, em centavos
+	LimiteDisponivel *int
+	// HashPayload identifica o payload original (CamposPayloadIdempotencia);
+	// vazio em registros anteriores à verificação, que não são comparados
+	HashPayload string
+}
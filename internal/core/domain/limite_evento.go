@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// EventoLimiteAlterado identifica o evento de alteração de limite_atual
+const EventoLimiteAlterado = "LIMITE_ALTERADO"
+
+// Motivos de alteração do limite atual
+const (
+	MotivoLimiteDebito  = "DEBITO"
+	MotivoLimiteCredito = "CREDITO"
+	MotivoLimiteAjuste  = "AJUSTE"
+)
+
+// LimiteAlteradoEvento registra uma movimentação de limite_atual, em
+// centavos. A sequência de eventos forma a trilha de auditoria dos saldos
+// para ledgers downstream.
+type LimiteAlteradoEvento struct {
+	Evento    string `json:"evento"`
+	ClienteID string `json:"cliente_id"`
+	// LimiteAnterior e LimiteAtual são o limite_atual antes e depois da alteração
+	LimiteAnterior int64 `json:"limite_anterior_centavos"`
+	LimiteAtual    int64 `json:"limite_atual_centavos"`
+	// Delta é LimiteAtual - LimiteAnterior (negativo no débito)
+	Delta  int64  `json:"delta_centavos"`
+	Motivo string `json:"motivo"`
+	// TransacaoID identifica a transação que causou a alteração, quando houver
+	TransacaoID   string    `json:"transacao_id,omitempty"`
+	CorrelationID string    `json:"correlation_id"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// NewLimiteAlteradoEvento monta o evento calculando o delta; timestamp vem
+// do relógio de quem alterou o limite
+func NewLimiteAlteradoEvento(clienteID string, anterior, atual int64, motivo, correlationID string, timestamp time.Time) *LimiteAlteradoEvento {
+	return &LimiteAlteradoEvento{
+		Evento:         EventoLimiteAlterado,
+		ClienteID:      clienteID,
+		LimiteAnterior: anterior,
+		LimiteAtual:    atual,
+		Delta:          atual - anterior,
+		Motivo:         motivo,
+		CorrelationID:  correlationID,
+		Timestamp:      timestamp,
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+// LimitePolicy centraliza a regra de suficiência do débito, em centavos: o
+// limite atual pode ficar negativo até ChequeEspecial e cada acumulado (ex.:
+// gasto por categoria) não pode ultrapassar seu máximo. Repositórios
+// traduzem a política em uma única condição atômica.
+type LimitePolicy struct {
+	ChequeEspecial int
+	Acumulados     []LimiteAcumulado
+}
+
+// LimiteAcumulado limita um acumulador persistido no registro do cliente:
+// após o débito, o acumulado não pode passar de Maximo. O acumulador só
+// cresce; nada o zera por período, então não serve como limite diário.
+type LimiteAcumulado struct {
+	Atributo string
+	Maximo   int
+}
+
+// MinimoLimiteAtual é o menor limite atual que ainda comporta o débito de valor
+func (p LimitePolicy) MinimoLimiteAtual(valor int) int {
+	return valor - p.ChequeEspecial
+}
+
+// PermiteLimiteAtual indica se o limite atual comporta o débito de valor
+func (p LimitePolicy) PermiteLimiteAtual(limiteAtual, valor int) bool {
+	return limiteAtual >= p.MinimoLimiteAtual(valor)
+}
+
+// MaximoAntesDoDebito é o maior acumulado que ainda comporta o débito de valor
+func (a LimiteAcumulado) MaximoAntesDoDebito(valor int) int {
+	return a.Maximo - valor
+}
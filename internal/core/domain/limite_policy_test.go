@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestLimitePolicy_PermiteLimiteAtual(t *testing.T) {
+	tests := []struct {
+		name        string
+		politica    LimitePolicy
+		limiteAtual int
+		valor       int
+		esperado    bool
+	}{
+		{name: "limite exato", politica: LimitePolicy{}, limiteAtual: 1000, valor: 1000, esperado: true},
+		{name: "um centavo a menos", politica: LimitePolicy{}, limiteAtual: 999, valor: 1000, esperado: false},
+		{name: "cheque especial cobre a diferença", politica: LimitePolicy{ChequeEspecial: 500}, limiteAtual: 500, valor: 1000, esperado: true},
+		{name: "cheque especial insuficiente", politica: LimitePolicy{ChequeEspecial: 500}, limiteAtual: 499, valor: 1000, esperado: false},
+		{name: "cheque especial já em uso", politica: LimitePolicy{ChequeEspecial: 500}, limiteAtual: -200, valor: 300, esperado: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.politica.PermiteLimiteAtual(tt.limiteAtual, tt.valor); got != tt.esperado {
+				t.Errorf("esperado %v, got %v (mínimo %d)", tt.esperado, got, tt.politica.MinimoLimiteAtual(tt.valor))
+			}
+		})
+	}
+}
+
+func TestLimiteAcumulado_MaximoAntesDoDebito(t *testing.T) {
+	acumulado := LimiteAcumulado{Atributo: "gasto_categoria_mercado", Maximo: 20000}
+
+	if got := acumulado.MaximoAntesDoDebito(3000); got != 17000 {
+		t.Errorf("máximo antes do débito esperado 17000, got %d", got)
+	}
+}
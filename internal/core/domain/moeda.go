@@ -0,0 +1,33 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrMoedaInvalida indica código de moeda fora do formato ISO 4217
+	// (três letras maiúsculas)
+	ErrMoedaInvalida = errors.New("código de moeda inválido (ISO 4217)")
+	// ErrMoedaNaoSuportada indica moeda válida fora das moedas configuradas
+	ErrMoedaNaoSuportada = errors.New("moeda não suportada")
+)
+
+// ValidarCodigoMoeda verifica o formato ISO 4217 do código (ex.: BRL)
+func ValidarCodigoMoeda(codigo string) error {
+	if len(codigo) != 3 {
+		return ErrMoedaInvalida
+	}
+	for _, letra := range codigo {
+		if letra < 'A' || letra > 'Z' {
+			return ErrMoedaInvalida
+		}
+	}
+	return nil
+}
+
+// MoedaOuPadrao retorna a moeda da transação; transações sem moeda
+// (anteriores ao campo) são em MoedaPadrao
+func (t *Transacao) MoedaOuPadrao() string {
+	if t.Moeda == "" {
+		return MoedaPadrao
+	}
+	return t.Moeda
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "testing"
+
+func TestValidarCodigoMoeda(t *testing.T) {
+	tests := []struct {
+		codigo string
+		valido bool
+	}{
+		{"BRL", true},
+		{"USD", true},
+		{"brl", false},
+		{"BR", false},
+		{"BRLL", false},
+		{"B1L", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.codigo, func(t *testing.T) {
+			if err := ValidarCodigoMoeda(tt.codigo); (err == nil) != tt.valido {
+				t.Errorf("ValidarCodigoMoeda(%q) = %v, esperado válido %v", tt.codigo, err, tt.valido)
+			}
+		})
+	}
+}
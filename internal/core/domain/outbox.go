@@ -0,0 +1,9 @@
+package domain
+
+// EventoOutbox é um evento gravado no outbox junto da transação e ainda
+// não publicado pelo relay
+type EventoOutbox struct {
+	// ID é a chave do item no outbox (chave de deduplicação do evento)
+	ID     string
+	Evento *TransacaoEvento
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "errors"
+
+// PoliticaAprovacao reúne os limites de risco ajustáveis sem novo deploy
+type PoliticaAprovacao struct {
+	// ValorMaximoCentavos é o maior valor aceito em uma única transação
+	ValorMaximoCentavos int `json:"valor_maximo_centavos" dynamodbav:"valor_maximo_centavos"`
+	// MultiplicadorLimiteDiario limita o total aprovado no dia a
+	// limite_credito * multiplicador (zero desliga a verificação)
+	MultiplicadorLimiteDiario float64 `json:"multiplicador_limite_diario" dynamodbav:"multiplicador_limite_diario"`
+}
+
+// PoliticaTier define limites de risco por segmento (tier) do cliente.
+// Campos zerados desligam a verificação correspondente.
+type PoliticaTier struct {
+	// ValorMaximoCentavos é o maior valor aceito em uma única transação
+	ValorMaximoCentavos int `json:"valor_maximo_centavos"`
+	// MultiplicadorLimiteDiario limita a velocidade de gasto: total aprovado
+	// no dia até limite_credito * multiplicador
+	MultiplicadorLimiteDiario float64 `json:"multiplicador_limite_diario"`
+	// ChequeEspecialCentavos é quanto o limite atual pode ficar negativo
+	ChequeEspecialCentavos int `json:"cheque_especial_centavos"`
+}
+
+// Erros de política de aprovação
+var (
+	ErrValorAcimaDoMaximo   = errors.New("valor da transação acima do máximo permitido")
+	ErrLimiteDiarioExcedido = errors.New("limite diário de transações excedido")
+	ErrPoliticaInvalida     = errors.New("política de aprovação inválida")
+)
+
+// PoliticaAprovacaoPadrao retorna a política conservadora usada quando a
+// política configurada está ausente ou corrompida
+func PoliticaAprovacaoPadrao() *PoliticaAprovacao {
+	return &PoliticaAprovacao{
+		ValorMaximoCentavos:       500000, // R$ 5.000,00
+		MultiplicadorLimiteDiario: 1.0,
+	}
+}
+
+// Valida verifica se a política carregada é utilizável
+func (p *PoliticaAprovacao) Valida() error {
+	if p.ValorMaximoCentavos <= 0 || p.MultiplicadorLimiteDiario < 0 {
+		return ErrPoliticaInvalida
+	}
+	return nil
+}
@@ -1,34 +1,154 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // LimiteRepository gerencia os limites de crédito dos clientes
 type LimiteRepository interface {
 	GetCliente(ctx context.Context, clienteID string) (*Cliente, error)
 	UpdateLimite(ctx context.Context, clienteID string, novoLimite int) error
-	// Operação atômica para debitar limite com verificação de race condition
-	DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) error
+	// Operação atômica para debitar limite com verificação de race condition;
+	// retorna o limite atual resultante do débito
+	DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (novoLimite int, err error)
+}
+
+// LimiteChequeEspecialRepository é implementado por repositórios de limite
+// capazes de debitar deixando o limite atual negativo até o cheque especial
+type LimiteChequeEspecialRepository interface {
+	DebitarLimiteComChequeEspecial(ctx context.Context, clienteID string, valor, chequeEspecial int) (novoLimite int, err error)
+}
+
+// LimiteProvisionamentoRepository é implementado por repositórios de limite
+// capazes de criar o registro de um cliente novo, apenas se ainda não existir
+type LimiteProvisionamentoRepository interface {
+	// ProvisionarCliente não sobrescreve cliente existente (nem criado
+	// concorrentemente): nesse caso retorna criado = false, sem erro
+	ProvisionarCliente(ctx context.Context, clienteID string, limiteCentavos int) (criado bool, err error)
+}
+
+// LimiteAjusteRepository é implementado por repositórios de limite capazes
+// de regravar limite_atual apenas se ele ainda for o valor lido; caso
+// contrário (débito ou crédito concorrente), falha com
+// ErrLimiteAlteradoConcorrente sem alterar nada
+type LimiteAjusteRepository interface {
+	AjustarLimite(ctx context.Context, clienteID string, lido, novoLimite int) error
+}
+
+// IDGenerator gera os identificadores de transação (ex.: GeradorUUID,
+// GeradorULID)
+type IDGenerator interface {
+	NovoID() string
+}
+
+// KeyHasher deriva chaves estáveis (idempotência, deduplicação de eventos,
+// detecção de duplicidade) da forma canônica dos campos (ex.: HasherSHA256)
+type KeyHasher interface {
+	Chave(campos CamposChave) string
 }
 
 // TransacaoRepository gerencia as transações
 type TransacaoRepository interface {
 	Save(ctx context.Context, transacao *Transacao) error
 	GetByID(ctx context.Context, transacaoID string) (*Transacao, error)
+	// GetByClienteID lê de um índice secundário eventualmente consistente:
+	// uma transação recém-salva pode demorar a aparecer. GetByID é fortemente
+	// consistente e deve ser usado para garantir leitura após escrita. limit
+	// não positivo falha com ErrLimiteConsultaInvalido; acima do máximo da
+	// implementação, é reduzido a ele.
 	GetByClienteID(ctx context.Context, clienteID string, limit int) ([]*Transacao, error)
 }
 
+// TransacaoHistoricoRepository percorre o histórico completo de um cliente
+// página a página, sem carregá-lo inteiro em memória (exportações)
+type TransacaoHistoricoRepository interface {
+	PercorrerPorClienteID(ctx context.Context, clienteID string, tamanhoPagina int, fn func(pagina []*Transacao) error) error
+}
+
+// TransacaoOutboxRepository grava a transação e o evento a publicar numa
+// única escrita atômica (padrão outbox): o registro de intenção sobrevive a
+// uma falha entre a persistência e a publicação
+type TransacaoOutboxRepository interface {
+	SaveComOutbox(ctx context.Context, transacao *Transacao, evento *TransacaoEvento) error
+}
+
+// OutboxRelayRepository lê os eventos pendentes do outbox e marca os
+// publicados; é a porta do relay que publica os eventos gravados por
+// TransacaoOutboxRepository
+type OutboxRelayRepository interface {
+	ListarPendentes(ctx context.Context, limite int) ([]*EventoOutbox, error)
+	MarcarPublicado(ctx context.Context, id string) error
+}
+
+// TransacaoPeriodoRepository lista transações por janela de tempo (usado
+// por jobs de reconciliação, fora do caminho crítico)
+type TransacaoPeriodoRepository interface {
+	ListarAprovadasNoPeriodo(ctx context.Context, inicio, fim time.Time) ([]*Transacao, error)
+}
+
+// TransacaoAgendadaRepository recupera transações agendadas vencidas e
+// garante que cada uma seja executada uma única vez
+type TransacaoAgendadaRepository interface {
+	ListarAgendadasAte(ctx context.Context, ate time.Time) ([]*Transacao, error)
+	// IniciarExecucao muda o status de AGENDADA para PENDENTE; retorna
+	// ErrAgendamentoJaIniciado se a transação não está mais agendada
+	IniciarExecucao(ctx context.Context, transacao *Transacao) error
+}
+
+// IdempotencyStore guarda o desfecho das requisições por chave de
+// idempotência (ex.: DynamoDB, Redis). Registros expirados não são
+// devolvidos: Get retorna nil, nil e a chave pode ser reutilizada.
+type IdempotencyStore interface {
+	Get(ctx context.Context, chave string) (*RegistroIdempotencia, error)
+	// Reservar grava o registro (status EM_PROCESSAMENTO) somente se a chave
+	// não existir ou tiver expirado; do contrário retorna ErrReservaIdempotencia
+	Reservar(ctx context.Context, registro *RegistroIdempotencia, ttl time.Duration) error
+	// Finalizar grava o desfecho sobre a reserva da mesma transação, mantido
+	// por ttl; ErrReservaIdempotencia se a reserva não for mais dela
+	Finalizar(ctx context.Context, registro *RegistroIdempotencia, ttl time.Duration) error
+	// Liberar descarta a reserva da transação, permitindo reprocessar a chave
+	Liberar(ctx context.Context, chave, transacaoID string) error
+}
+
+// AuditRepository grava as decisões de autorização numa trilha de auditoria
+// só de inclusão: entradas gravadas não são alteradas nem removidas
+type AuditRepository interface {
+	RecordDecision(ctx context.Context, entry AuditEntry) error
+}
+
+// EventAckStore registra os eventos cuja publicação foi confirmada
+type EventAckStore interface {
+	RegistrarConfirmacao(ctx context.Context, transacaoID string) error
+	// Confirmados retorna o subconjunto de IDs com publicação confirmada
+	Confirmados(ctx context.Context, transacaoIDs []string) (map[string]bool, error)
+}
+
 // EventPublisher publica eventos de transação para sistemas downstream
 type EventPublisher interface {
 	PublishTransacaoAprovada(ctx context.Context, evento *TransacaoEvento) error
 	PublishTransacaoRejeitada(ctx context.Context, evento *TransacaoEvento) error
 }
 
+// LimiteEventPublisher publica as alterações de limite_atual
+type LimiteEventPublisher interface {
+	PublishLimiteAlterado(ctx context.Context, evento *LimiteAlteradoEvento) error
+}
+
 // MetricsCollector coleta métricas para observabilidade
 type MetricsCollector interface {
 	IncrementTransactionCounter(status string)
 	RecordTransactionLatency(duration float64)
 	RecordBusinessMetric(metricName string, value float64, labels map[string]string)
 	IncrementErrorCounter(errorType string)
+	// IncrementOutcomeCounter conta desfechos que não são erro (ex.:
+	// idempotent_replay), para não inflar a taxa de erros
+	IncrementOutcomeCounter(outcome string)
+	// RecordEventPublish registra duração e resultado ("success"/"failure") da publicação de um evento
+	RecordEventPublish(result string, duration float64)
+	// RecordRejectedValue registra o valor (em reais) de uma transação
+	// rejeitada, por código de motivo (ex.: insufficient_limit)
+	RecordRejectedValue(reason string, value float64)
 }
 
 // DistributedTracer gerencia tracing distribuído
@@ -36,6 +156,8 @@ type DistributedTracer interface {
 	StartSpan(ctx context.Context, operationName string) (context.Context, interface{})
 	FinishSpan(span interface{}, err error)
 	AddTag(span interface{}, key string, value interface{})
+	// ExtractTraceID retorna o trace ID propagado no contexto (vazio se não houver)
+	ExtractTraceID(ctx context.Context) string
 }
 
 // Logger interface para logging estruturado
@@ -45,3 +167,35 @@ type Logger interface {
 	Warn(ctx context.Context, msg string, fields map[string]interface{})
 	Debug(ctx context.Context, msg string, fields map[string]interface{})
 }
+
+// EventPayloadStore guarda payloads de eventos grandes demais para o tópico
+// (ex.: S3) e devolve a referência publicada no lugar do payload
+type EventPayloadStore interface {
+	ArmazenarPayload(ctx context.Context, evento *TransacaoEvento) (string, error)
+}
+
+// PreAuthCallback consulta um sistema externo de risco que pode vetar a
+// transação de forma síncrona, antes do débito
+type PreAuthCallback interface {
+	Autorizar(ctx context.Context, transacao *Transacao) (aprovada bool, err error)
+}
+
+// StepUpVerifier emite desafios e valida tokens de confirmação adicional
+// (step-up) exigidos acima do limite suave
+type StepUpVerifier interface {
+	EmitirDesafio(ctx context.Context, transacao *Transacao) (string, error)
+	VerificarToken(ctx context.Context, token string, transacao *Transacao) bool
+}
+
+// StepUpDesafioStore registra os desafios de step-up já resgatados, para
+// que cada token de confirmação valha uma única vez
+type StepUpDesafioStore interface {
+	// Consumir marca o desafio como resgatado pela transação até expiraEm;
+	// ErrDesafioStepUpConsumido se outra transação já o resgatou
+	Consumir(ctx context.Context, desafio, transacaoID string, expiraEm time.Time) error
+}
+
+// PolicyRepository carrega a política de aprovação vigente
+type PolicyRepository interface {
+	GetPoliticaAprovacao(ctx context.Context) (*PoliticaAprovacao, error)
+}
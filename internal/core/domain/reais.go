@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrValorReaisInvalido indica valor em reais que não é número decimal
+var ErrValorReaisInvalido = errors.New("valor em reais inválido")
+
+// Reais é um valor monetário na fronteira da API. Valores de limite são
+// sempre informados e devolvidos em reais (número JSON ou string, ex.:
+// 1500.5 ou "1500.50") e persistidos em centavos; a conversão acontece só
+// aqui. Internamente guarda centavos, então a ida e volta não acumula erro
+// de ponto flutuante.
+type Reais struct {
+	centavos int64
+}
+
+// ReaisDeCentavos converte um valor persistido (centavos) para a API
+func ReaisDeCentavos(centavos int64) Reais {
+	return Reais{centavos: centavos}
+}
+
+// ParseReais interpreta o texto decimal sem passar por ponto flutuante.
+// Mais de duas casas decimais retorna ErrPrecisaoInvalida.
+func ParseReais(texto string) (Reais, error) {
+	texto = strings.TrimSpace(texto)
+
+	negativo := strings.HasPrefix(texto, "-")
+	texto = strings.TrimPrefix(texto, "-")
+
+	inteiro, fracao, _ := strings.Cut(texto, ".")
+	if inteiro == "" || strings.Trim(inteiro, "0123456789") != "" || strings.Trim(fracao, "0123456789") != "" {
+		return Reais{}, fmt.Errorf("%w: %q", ErrValorReaisInvalido, texto)
+	}
+
+	// Zeros à direita não alteram o valor (1.500 = 1.50)
+	fracao = strings.TrimRight(fracao, "0")
+	if len(fracao) > 2 {
+		return Reais{}, ErrPrecisaoInvalida
+	}
+
+	unidades, err := strconv.ParseInt(inteiro, 10, 64)
+	if err != nil {
+		return Reais{}, fmt.Errorf("%w: %q", ErrValorReaisInvalido, texto)
+	}
+
+	centavos := int64(0)
+	if fracao != "" {
+		centavos, _ = strconv.ParseInt((fracao + "0")[:2], 10, 64)
+	}
+
+	total := unidades*100 + centavos
+	if negativo {
+		total = -total
+	}
+	return Reais{centavos: total}, nil
+}
+
+// Centavos é o valor canônico usado na persistência
+func (r Reais) Centavos() int64 {
+	return r.centavos
+}
+
+// String formata com duas casas decimais (ex.: "1500.50")
+func (r Reais) String() string {
+	sinal := ""
+	centavos := r.centavos
+	if centavos < 0 {
+		sinal = "-"
+		centavos = -centavos
+	}
+	return fmt.Sprintf("%s%d.%02d", sinal, centavos/100, centavos%100)
+}
+
+// MarshalJSON devolve número JSON com duas casas decimais
+func (r Reais) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON aceita número ou string JSON
+func (r *Reais) UnmarshalJSON(data []byte) error {
+	texto := string(data)
+	if unquoted, err := strconv.Unquote(texto); err == nil {
+		texto = unquoted
+	}
+
+	valor, err := ParseReais(texto)
+	if err != nil {
+		return err
+	}
+	*r = valor
+	return nil
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestReais_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		entrada     string
+		centavos    int64
+		expectedErr error
+	}{
+		{entrada: `1500.5`, centavos: 150050},
+		{entrada: `"1500.50"`, centavos: 150050},
+		{entrada: `19.99`, centavos: 1999},
+		{entrada: `0.29`, centavos: 29},
+		{entrada: `100`, centavos: 10000},
+		{entrada: `"-10.05"`, centavos: -1005},
+		{entrada: `1.500`, centavos: 150},
+		{entrada: `19.999`, expectedErr: ErrPrecisaoInvalida},
+		{entrada: `"dez reais"`, expectedErr: ErrValorReaisInvalido},
+		{entrada: `1e3`, expectedErr: ErrValorReaisInvalido},
+		{entrada: `""`, expectedErr: ErrValorReaisInvalido},
+	}
+
+	for _, tt := range tests {
+		var valor Reais
+		err := json.Unmarshal([]byte(tt.entrada), &valor)
+		if !errors.Is(err, tt.expectedErr) {
+			t.Errorf("Unmarshal(%s) erro esperado %v, got %v", tt.entrada, tt.expectedErr, err)
+			continue
+		}
+		if err == nil && valor.Centavos() != tt.centavos {
+			t.Errorf("Unmarshal(%s) esperado %d centavos, got %d", tt.entrada, tt.centavos, valor.Centavos())
+		}
+	}
+}
+
+func TestReais_IdaEVoltaSemDeriva(t *testing.T) {
+	// Valores cuja conversão via float64 * 100 com truncamento perde um centavo
+	for _, centavos := range []int64{0, 1, 29, 1999, 150050, 999999999, -1005} {
+		corpo, err := json.Marshal(ReaisDeCentavos(centavos))
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+
+		var volta Reais
+		if err := json.Unmarshal(corpo, &volta); err != nil {
+			t.Fatalf("erro inesperado ao ler %s: %v", corpo, err)
+		}
+
+		if volta.Centavos() != centavos {
+			t.Errorf("ida e volta de %d centavos (%s) resultou em %d", centavos, corpo, volta.Centavos())
+		}
+
+		// O número JSON lido como float e convertido pela regra canônica também confere
+		var comoFloat float64
+		json.Unmarshal(corpo, &comoFloat)
+		if ValorEmCentavos(comoFloat) != centavos {
+			t.Errorf("%s como float converteu para %d centavos", corpo, ValorEmCentavos(comoFloat))
+		}
+	}
+}
+
+func TestReais_String(t *testing.T) {
+	if got := ReaisDeCentavos(150050).String(); got != "1500.50" {
+		t.Errorf("esperado 1500.50, got %s", got)
+	}
+	if got := ReaisDeCentavos(-5).String(); got != "-0.05" {
+		t.Errorf("esperado -0.05, got %s", got)
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SegmentoTempo é a duração de uma etapa da autorização
+type SegmentoTempo struct {
+	Nome    string
+	Duracao time.Duration
+}
+
+// Temporizacao acumula a duração das etapas de uma requisição (para o header
+// Server-Timing). Métodos em receptor nil são no-op, então o serviço mede
+// sem verificar se a coleta está habilitada.
+type Temporizacao struct {
+	mu        sync.Mutex
+	segmentos []SegmentoTempo
+}
+
+// Registrar soma a duração ao segmento (etapas repetidas são acumuladas)
+func (t *Temporizacao) Registrar(nome string, duracao time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.segmentos {
+		if t.segmentos[i].Nome == nome {
+			t.segmentos[i].Duracao += duracao
+			return
+		}
+	}
+	t.segmentos = append(t.segmentos, SegmentoTempo{Nome: nome, Duracao: duracao})
+}
+
+// Segmentos retorna cópia dos segmentos na ordem em que foram registrados
+func (t *Temporizacao) Segmentos() []SegmentoTempo {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SegmentoTempo(nil), t.segmentos...)
+}
+
+// ComTemporizacao anexa a temporização ao contexto da requisição
+func ComTemporizacao(ctx context.Context, t *Temporizacao) context.Context {
+	return context.WithValue(ctx, "temporizacao", t)
+}
+
+// TemporizacaoDoContexto retorna a temporização da requisição, ou nil
+func TemporizacaoDoContexto(ctx context.Context) *Temporizacao {
+	t, _ := ctx.Value("temporizacao").(*Temporizacao)
+	return t
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTemporizacao_AcumulaSegmentosNaOrdem(t *testing.T) {
+	temporizacao := &Temporizacao{}
+	temporizacao.Registrar("validation", time.Millisecond)
+	temporizacao.Registrar("save", 2*time.Millisecond)
+	temporizacao.Registrar("save", 3*time.Millisecond)
+
+	segmentos := temporizacao.Segmentos()
+	if len(segmentos) != 2 || segmentos[0].Nome != "validation" || segmentos[1].Nome != "save" {
+		t.Fatalf("segmentos inesperados: %+v", segmentos)
+	}
+
+	if segmentos[1].Duracao != 5*time.Millisecond {
+		t.Errorf("duração acumulada esperada 5ms, got %s", segmentos[1].Duracao)
+	}
+}
+
+func TestTemporizacao_ContextoSemTemporizacao(t *testing.T) {
+	temporizacao := TemporizacaoDoContexto(context.Background())
+
+	// Receptor nil é no-op
+	temporizacao.Registrar("debit", time.Millisecond)
+	if segmentos := temporizacao.Segmentos(); segmentos != nil {
+		t.Errorf("esperado nil, got %+v", segmentos)
+	}
+
+	ctx := ComTemporizacao(context.Background(), &Temporizacao{})
+	if TemporizacaoDoContexto(ctx) == nil {
+		t.Error("temporização anexada deveria ser recuperada do contexto")
+	}
+}
@@ -2,19 +2,54 @@ package domain
 
 import (
 	"errors"
+	"strings"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // Transacao representa uma transação financeira
 type Transacao struct {
-	ID            string    `json:"id" dynamodbav:"id"`
-	ClienteID     string    `json:"cliente_id" dynamodbav:"cliente_id"`
-	Valor         float64   `json:"valor" dynamodbav:"valor"`
+	ID        string  `json:"id" dynamodbav:"id"`
+	ClienteID string  `json:"cliente_id" dynamodbav:"cliente_id"`
+	Valor     float64 `json:"valor" dynamodbav:"valor"`
+	// Moeda é o código ISO 4217 do valor (MoedaPadrao quando não informada)
+	Moeda         string    `json:"moeda,omitempty" dynamodbav:"moeda,omitempty"`
 	Status        string    `json:"status" dynamodbav:"status"`
 	Timestamp     time.Time `json:"timestamp" dynamodbav:"timestamp"`
 	CorrelationID string    `json:"correlation_id" dynamodbav:"correlation_id"`
+	Canal         string    `json:"canal" dynamodbav:"canal"`
+	// Parcelas é o número de parcelas (0 ou 1 = à vista)
+	Parcelas int `json:"parcelas,omitempty" dynamodbav:"parcelas,omitempty"`
+	// DataAgendamento, quando definida, adia a execução da autorização
+	DataAgendamento *time.Time `json:"data_agendamento,omitempty" dynamodbav:"data_agendamento,omitempty"`
+	// Tipo distingue operações especiais (ex.: TipoVerificacao); vazio = compra
+	Tipo string `json:"tipo,omitempty" dynamodbav:"tipo,omitempty"`
+	// Tier é o segmento do cliente resolvido na autorização (rótulo de métricas)
+	Tier string `json:"-" dynamodbav:"-"`
+	// TokenStepUp é o token de confirmação enviado pelo cliente (não persistido)
+	TokenStepUp string `json:"-" dynamodbav:"-"`
+	// TimestampInformado indica que Timestamp veio da origem (sujeito à
+	// tolerância de relógio) e não do instante de criação
+	TimestampInformado bool `json:"-" dynamodbav:"-"`
+	// ChaveIdempotencia é a chave informada pelo cliente para repetições
+	// seguras da mesma requisição (não persistida na transação)
+	ChaveIdempotencia string `json:"-" dynamodbav:"-"`
+	// LimiteDisponivel é o limite atual do cliente após o débito, em centavos
+	// (nil quando não houve débito ou o repositório não o informou)
+	LimiteDisponivel *int `json:"-" dynamodbav:"-"`
+	// LimiteRestante é quanto o cliente ainda pode gastar após o débito, em
+	// centavos: LimiteDisponivel mais o cheque especial concedido no débito
+	LimiteRestante *int `json:"-" dynamodbav:"-"`
+	// EsperaRecomendada é o tempo até a janela de velocidade liberar, nas
+	// recusas por limite diário que uma nova tentativa pode reverter
+	EsperaRecomendada time.Duration `json:"-" dynamodbav:"-"`
+	// CodigoMotivo identifica o motivo da rejeição (ex.: insufficient_limit);
+	// repassado ao evento para roteamento
+	CodigoMotivo string `json:"-" dynamodbav:"-"`
+	// SuspeitaDuplicidade sinaliza transação muito parecida com outra recente
+	SuspeitaDuplicidade bool `json:"suspeita_duplicidade,omitempty" dynamodbav:"suspeita_duplicidade,omitempty"`
+	// Tags são metadados livres (ex.: campanha, dispositivo), limitados por
+	// MaxTags, MaxTamanhoChaveTag e MaxTamanhoValorTag
+	Tags map[string]string `json:"tags,omitempty" dynamodbav:"tags,omitempty"`
 }
 
 // Cliente representa um cliente no sistema
@@ -24,25 +59,56 @@ type Cliente struct {
 	Email        string    `json:"email" dynamodbav:"email"`
 	LimiteCredit int       `json:"limite_credito" dynamodbav:"limite_credito"` // em centavos
 	LimiteAtual  int       `json:"limite_atual" dynamodbav:"limite_atual"`     // em centavos
+	Tier         string    `json:"tier,omitempty" dynamodbav:"tier,omitempty"`
+	MaxParcelas  int       `json:"max_parcelas,omitempty" dynamodbav:"max_parcelas,omitempty"` // 0 = deriva do tier/padrão
 	CreatedAt    time.Time `json:"created_at" dynamodbav:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" dynamodbav:"updated_at"`
 }
 
 // TransacaoEvento representa um evento de transação para publicação
 type TransacaoEvento struct {
-	Evento        string    `json:"evento"`
-	TransacaoID   string    `json:"transacao_id"`
-	ClienteID     string    `json:"cliente_id"`
+	Evento      string `json:"evento"`
+	TransacaoID string `json:"transacao_id"`
+	ClienteID   string `json:"cliente_id"`
+	// Deprecated: Valor em ponto flutuante é mantido apenas durante a janela de
+	// migração dos consumidores; use ValorCentavos e Moeda.
 	Valor         float64   `json:"valor"`
+	ValorCentavos int64     `json:"valor_centavos"`
+	Moeda         string    `json:"moeda"`
 	Timestamp     time.Time `json:"timestamp"`
 	CorrelationID string    `json:"correlation_id"`
+	Canal         string    `json:"canal"`
+	Parcelas      int       `json:"parcelas"`
+	Tipo          string    `json:"tipo,omitempty"`
+	// Tags repassa os metadados livres da transação
+	Tags map[string]string `json:"tags,omitempty"`
+	// PayloadRef aponta para o payload completo quando ele excede o limite do tópico
+	PayloadRef string `json:"payload_ref,omitempty"`
+	// Truncado indica que campos não essenciais foram removidos por tamanho
+	Truncado bool `json:"truncado,omitempty"`
+	// ChaveDeduplicacao é igual para republicações do mesmo evento lógico
+	ChaveDeduplicacao string `json:"chave_deduplicacao,omitempty"`
+	// CodigoMotivo identifica o motivo da rejeição (apenas em rejeições)
+	CodigoMotivo string `json:"codigo_motivo,omitempty"`
 }
 
+// Limites das tags de transação
+const (
+	MaxTags            = 10
+	MaxTamanhoChaveTag = 64
+	MaxTamanhoValorTag = 256
+)
+
+// TipoVerificacao marca a autorização de valor zero para verificação de cartão
+const TipoVerificacao = "VERIFICACAO"
+
 // Status de transação
 const (
 	StatusAprovada  = "APROVADA"
 	StatusRejeitada = "REJEITADA"
 	StatusPendente  = "PENDENTE"
+	// StatusAgendada: aguardando a data de agendamento, sem débito de limite
+	StatusAgendada = "AGENDADA"
 )
 
 // Tipos de evento
@@ -51,25 +117,68 @@ const (
 	EventoTransacaoRejeitada = "TRANSACAO_REJEITADA"
 )
 
+// Canais de origem da transação
+const (
+	CanalWeb          = "WEB"
+	CanalMobile       = "MOBILE"
+	CanalPOS          = "POS"
+	CanalDesconhecido = "DESCONHECIDO"
+)
+
+// canaisPermitidos é o conjunto de canais aceitos; demais viram DESCONHECIDO
+var canaisPermitidos = map[string]bool{
+	CanalWeb:    true,
+	CanalMobile: true,
+	CanalPOS:    true,
+}
+
+// NormalizarCanal valida o canal informado contra o conjunto permitido,
+// retornando CanalDesconhecido para valores ausentes ou fora do conjunto
+func NormalizarCanal(canal string) string {
+	canal = strings.ToUpper(strings.TrimSpace(canal))
+	if canaisPermitidos[canal] {
+		return canal
+	}
+	return CanalDesconhecido
+}
+
 // Erros estruturados do domínio
 var (
-	ErrValorNegativo   = errors.New("o valor da transação não pode ser negativo")
-	ErrValorZero       = errors.New("o valor da transação não pode ser zero")
-	ErrClienteInvalido = errors.New("o ID do cliente é inválido ou não foi fornecido")
+	ErrValorNegativo            = errors.New("o valor da transação não pode ser negativo")
+	ErrValorZero                = errors.New("o valor da transação não pode ser zero")
+	ErrClienteInvalido          = errors.New("o ID do cliente é inválido ou não foi fornecido")
+	ErrParcelasInvalidas        = errors.New("o número de parcelas não pode ser negativo")
+	ErrParcelasAcimaDoPermitido = errors.New("número de parcelas acima do permitido para o cliente")
+	ErrDataAgendamentoInvalida  = errors.New("a data de agendamento deve estar no futuro")
+	ErrTagsExcedidas            = errors.New("número de tags acima do permitido")
+	ErrTagInvalida              = errors.New("tag com chave vazia ou tamanho acima do permitido")
+	ErrTimestampInvalido        = errors.New("o timestamp da transação está no futuro além da tolerância")
 )
 
-// NewTransacao cria uma nova transação com ID e timestamp
+// NewTransacao cria uma nova transação com ID (UUIDv4) e timestamp
 func NewTransacao(clienteID string, valor float64, correlationID string) *Transacao {
+	return NewTransacaoComGerador(GeradorUUID{}, clienteID, valor, correlationID)
+}
+
+// NewTransacaoComGerador cria uma nova transação com ID obtido do gerador
+func NewTransacaoComGerador(gerador IDGenerator, clienteID string, valor float64, correlationID string) *Transacao {
 	return &Transacao{
-		ID:            uuid.New().String(),
+		ID:            gerador.NovoID(),
 		ClienteID:     clienteID,
 		Valor:         valor,
+		Moeda:         MoedaPadrao,
 		Status:        StatusPendente,
 		Timestamp:     time.Now(),
 		CorrelationID: correlationID,
+		Canal:         CanalDesconhecido,
 	}
 }
 
+// DefinirCanal define o canal de origem já normalizado
+func (t *Transacao) DefinirCanal(canal string) {
+	t.Canal = NormalizarCanal(canal)
+}
+
 // Valida verifica se a transação é válida
 func (t *Transacao) Valida() error {
 	if t.Valor <= 0 {
@@ -86,19 +195,78 @@ func (t *Transacao) Valida() error {
 		return ErrClienteInvalido
 	}
 
+	if t.Parcelas < 0 {
+		return ErrParcelasInvalidas
+	}
+
+	return validarTags(t.Tags)
+}
+
+// validarTags aplica os limites de quantidade e tamanho (em bytes) das tags
+func validarTags(tags map[string]string) error {
+	if len(tags) > MaxTags {
+		return ErrTagsExcedidas
+	}
+	for chave, valor := range tags {
+		if chave == "" || len(chave) > MaxTamanhoChaveTag || len(valor) > MaxTamanhoValorTag {
+			return ErrTagInvalida
+		}
+	}
 	return nil
 }
 
+// EhVerificacao indica autorização de valor zero usada pelas bandeiras para
+// verificar o cartão; não debita limite
+func (t *Transacao) EhVerificacao() bool {
+	return t.Tipo == TipoVerificacao && t.Valor == 0
+}
+
+// NumeroParcelas retorna o número efetivo de parcelas (mínimo 1, à vista)
+func (t *Transacao) NumeroParcelas() int {
+	if t.Parcelas < 1 {
+		return 1
+	}
+	return t.Parcelas
+}
+
 // Aprovar marca a transação como aprovada
 func (t *Transacao) Aprovar() {
 	t.Status = StatusAprovada
 }
 
+// Agendar marca a transação como agendada para DataAgendamento
+func (t *Transacao) Agendar() {
+	t.Status = StatusAgendada
+}
+
 // Rejeitar marca a transação como rejeitada
 func (t *Transacao) Rejeitar() {
 	t.Status = StatusRejeitada
 }
 
+// Essencial retorna cópia do evento apenas com os campos essenciais
+// (identificação, valor, rastreabilidade e roteamento)
+func (e *TransacaoEvento) Essencial() *TransacaoEvento {
+	return &TransacaoEvento{
+		Evento:        e.Evento,
+		TransacaoID:   e.TransacaoID,
+		ClienteID:     e.ClienteID,
+		Valor:         e.Valor,
+		ValorCentavos: e.ValorCentavos,
+		Moeda:         e.Moeda,
+		Timestamp:     e.Timestamp,
+		CorrelationID: e.CorrelationID,
+
+		ChaveDeduplicacao: e.ChaveDeduplicacao,
+		CodigoMotivo:      e.CodigoMotivo,
+	}
+}
+
+// ValorCentavos retorna o valor da transação em centavos
+func (t *Transacao) ValorCentavos() int64 {
+	return ValorEmCentavos(t.Valor)
+}
+
 // ToEvento converte a transação em um evento para publicação
 func (t *Transacao) ToEvento() *TransacaoEvento {
 	var evento string
@@ -116,7 +284,14 @@ func (t *Transacao) ToEvento() *TransacaoEvento {
 		TransacaoID:   t.ID,
 		ClienteID:     t.ClienteID,
 		Valor:         t.Valor,
+		ValorCentavos: t.ValorCentavos(),
+		Moeda:         t.MoedaOuPadrao(),
 		Timestamp:     t.Timestamp,
 		CorrelationID: t.CorrelationID,
+		Canal:         t.Canal,
+		Parcelas:      t.NumeroParcelas(),
+		Tipo:          t.Tipo,
+		Tags:          t.Tags,
+		CodigoMotivo:  t.CodigoMotivo,
 	}
 }
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -86,6 +88,60 @@ func TestTransacao_Valida(t *testing.T) {
 			},
 			expectedErr: ErrClienteInvalido,
 		},
+		{
+			name: "parcelas negativas",
+			transacao: &Transacao{
+				ClienteID: "12345",
+				Valor:     99.90,
+				Parcelas:  -1,
+			},
+			expectedErr: ErrParcelasInvalidas,
+		},
+		{
+			name: "tags no limite",
+			transacao: &Transacao{
+				ClienteID: "12345",
+				Valor:     99.90,
+				Tags:      tagsDeTeste(MaxTags, MaxTamanhoChaveTag, MaxTamanhoValorTag),
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "tags acima da quantidade máxima",
+			transacao: &Transacao{
+				ClienteID: "12345",
+				Valor:     99.90,
+				Tags:      tagsDeTeste(MaxTags+1, 8, 8),
+			},
+			expectedErr: ErrTagsExcedidas,
+		},
+		{
+			name: "chave de tag longa demais",
+			transacao: &Transacao{
+				ClienteID: "12345",
+				Valor:     99.90,
+				Tags:      tagsDeTeste(1, MaxTamanhoChaveTag+1, 8),
+			},
+			expectedErr: ErrTagInvalida,
+		},
+		{
+			name: "valor de tag longo demais",
+			transacao: &Transacao{
+				ClienteID: "12345",
+				Valor:     99.90,
+				Tags:      tagsDeTeste(1, 8, MaxTamanhoValorTag+1),
+			},
+			expectedErr: ErrTagInvalida,
+		},
+		{
+			name: "chave de tag vazia",
+			transacao: &Transacao{
+				ClienteID: "12345",
+				Valor:     99.90,
+				Tags:      map[string]string{"": "x"},
+			},
+			expectedErr: ErrTagInvalida,
+		},
 	}
 
 	for _, tt := range tests {
@@ -99,6 +155,16 @@ func TestTransacao_Valida(t *testing.T) {
 	}
 }
 
+// tagsDeTeste gera quantidade tags com chaves e valores do tamanho informado
+func tagsDeTeste(quantidade, tamanhoChave, tamanhoValor int) map[string]string {
+	tags := make(map[string]string, quantidade)
+	for i := 0; i < quantidade; i++ {
+		chave := fmt.Sprintf("%02d", i) + strings.Repeat("k", tamanhoChave-2)
+		tags[chave] = strings.Repeat("v", tamanhoValor)
+	}
+	return tags
+}
+
 func TestTransacao_Aprovar(t *testing.T) {
 	transacao := NewTransacao("12345", 99.90, "test")
 
@@ -158,6 +224,77 @@ func TestTransacao_ToEvento_Rejeitada(t *testing.T) {
 	}
 }
 
+func TestTransacao_ToEvento_Tags(t *testing.T) {
+	transacao := NewTransacao("12345", 99.90, "corr")
+	transacao.Tags = map[string]string{"campanha": "black-friday"}
+
+	evento := transacao.ToEvento()
+	if evento.Tags["campanha"] != "black-friday" {
+		t.Errorf("tags deveriam ser repassadas ao evento, got %v", evento.Tags)
+	}
+
+	if essencial := evento.Essencial(); essencial.Tags != nil {
+		t.Errorf("evento essencial não deveria carregar tags, got %v", essencial.Tags)
+	}
+}
+
+func TestNormalizarCanal(t *testing.T) {
+	tests := []struct {
+		canal    string
+		esperado string
+	}{
+		{canal: "WEB", esperado: CanalWeb},
+		{canal: "mobile", esperado: CanalMobile},
+		{canal: " pos ", esperado: CanalPOS},
+		{canal: "", esperado: CanalDesconhecido},
+		{canal: "TELEFONE", esperado: CanalDesconhecido},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizarCanal(tt.canal); got != tt.esperado {
+			t.Errorf("NormalizarCanal(%q) esperado %s, got %s", tt.canal, tt.esperado, got)
+		}
+	}
+}
+
+func TestTransacao_ToEvento_Canal(t *testing.T) {
+	transacao := NewTransacao("12345", 99.90, "test-correlation")
+
+	if transacao.Canal != CanalDesconhecido {
+		t.Errorf("Canal padrão esperado %s, got %s", CanalDesconhecido, transacao.Canal)
+	}
+
+	transacao.DefinirCanal("mobile")
+
+	if evento := transacao.ToEvento(); evento.Canal != CanalMobile {
+		t.Errorf("Canal esperado %s, got %s", CanalMobile, evento.Canal)
+	}
+}
+
+func TestTransacao_ToEvento_ValorCentavos(t *testing.T) {
+	tests := []struct {
+		valor    float64
+		centavos int64
+	}{
+		{valor: 99.90, centavos: 9990},
+		{valor: 19.99, centavos: 1999},
+		{valor: 0.29, centavos: 29},
+		{valor: 1234.56, centavos: 123456},
+	}
+
+	for _, tt := range tests {
+		evento := NewTransacao("12345", tt.valor, "test-correlation").ToEvento()
+
+		if evento.ValorCentavos != tt.centavos {
+			t.Errorf("ValorCentavos(%v) esperado %d, got %d", tt.valor, tt.centavos, evento.ValorCentavos)
+		}
+
+		if evento.Moeda != MoedaPadrao {
+			t.Errorf("Moeda esperada %s, got %s", MoedaPadrao, evento.Moeda)
+		}
+	}
+}
+
 // Benchmarks para performance
 func BenchmarkNewTransacao(b *testing.B) {
 	clienteID := "12345"
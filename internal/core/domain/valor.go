@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+// MoedaPadrao é a moeda (ISO 4217) das transações que não informam outra
+const MoedaPadrao = "BRL"
+
+// ErrPrecisaoInvalida indica valor com mais casas decimais que centavos
+var ErrPrecisaoInvalida = errors.New("o valor da transação deve ter no máximo duas casas decimais")
+
+// ErrValorAmbiguo indica valor informado em reais e em centavos ao mesmo tempo
+var ErrValorAmbiguo = errors.New("o valor deve ser informado em reais ou em centavos, não em ambos")
+
+// toleranciaPrecisao absorve o erro de representação binária de valores
+// como 19.99 (que vira 1998.9999999999998 centavos)
+const toleranciaPrecisao = 1e-6
+
+// ValidarPrecisao rejeita valores com mais de duas casas decimais
+func ValidarPrecisao(valor float64) error {
+	centavos := valor * 100
+	if math.Abs(centavos-math.Round(centavos)) > toleranciaPrecisao {
+		return ErrPrecisaoInvalida
+	}
+	return nil
+}
+
+// ModoArredondamento define como frações de centavo são arredondadas
+type ModoArredondamento string
+
+const (
+	// ArredondamentoMeioParaCima arredonda o meio centavo para longe do zero (padrão)
+	ArredondamentoMeioParaCima ModoArredondamento = "HALF_UP"
+	// ArredondamentoMeioParaPar arredonda o meio centavo para o centavo par
+	// (arredondamento bancário)
+	ArredondamentoMeioParaPar ModoArredondamento = "HALF_EVEN"
+	// ArredondamentoTruncar descarta a fração de centavo
+	ArredondamentoTruncar ModoArredondamento = "DOWN"
+)
+
+// ValorEmCentavos é a conversão canônica de reais para centavos. Arredonda
+// em vez de truncar: 19.99 * 100 vale 1998.9999999999998 em ponto flutuante.
+func ValorEmCentavos(valor float64) int64 {
+	return ValorEmCentavosComModo(valor, ArredondamentoMeioParaCima)
+}
+
+// ValorEmCentavosComModo converte para centavos arredondando a fração pelo
+// modo informado (modo desconhecido vale HALF_UP). O valor é tratado como o
+// decimal digitado: 2.345 é meio centavo, embora em ponto flutuante seja
+// 234.49999999999997 centavos.
+func ValorEmCentavosComModo(valor float64, modo ModoArredondamento) int64 {
+	centavos := math.Abs(valor * 100)
+	sinal := int64(1)
+	if valor < 0 {
+		sinal = -1
+	}
+
+	// Centavos exatos, a menos do erro de representação
+	if inteiro := math.Round(centavos); math.Abs(centavos-inteiro) <= toleranciaPrecisao {
+		return sinal * int64(inteiro)
+	}
+
+	piso := math.Floor(centavos)
+	meio := math.Abs(centavos-piso-0.5) <= toleranciaPrecisao
+
+	switch {
+	case modo == ArredondamentoTruncar:
+		return sinal * int64(piso)
+	case meio && modo == ArredondamentoMeioParaPar:
+		if math.Mod(piso, 2) == 0 {
+			return sinal * int64(piso)
+		}
+		return sinal * int64(piso+1)
+	case meio:
+		return sinal * int64(piso+1)
+	default:
+		return sinal * int64(math.Round(centavos))
+	}
+}
+
+// ArredondarValor arredonda o valor para centavos (meio para cima)
+func ArredondarValor(valor float64) float64 {
+	return float64(ValorEmCentavos(valor)) / 100
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "testing"
+
+func TestValidarPrecisao(t *testing.T) {
+	tests := []struct {
+		valor       float64
+		expectedErr error
+	}{
+		{valor: 19.99, expectedErr: nil},
+		{valor: 0.29, expectedErr: nil},
+		{valor: 100, expectedErr: nil},
+		{valor: 19.999, expectedErr: ErrPrecisaoInvalida},
+		{valor: 0.001, expectedErr: ErrPrecisaoInvalida},
+	}
+
+	for _, tt := range tests {
+		if err := ValidarPrecisao(tt.valor); err != tt.expectedErr {
+			t.Errorf("ValidarPrecisao(%v) esperado %v, got %v", tt.valor, tt.expectedErr, err)
+		}
+	}
+}
+
+func TestArredondarValor(t *testing.T) {
+	if got := ArredondarValor(19.999); got != 20.00 {
+		t.Errorf("ArredondarValor(19.999) esperado 20.00, got %v", got)
+	}
+
+	if got := ArredondarValor(19.994); got != 19.99 {
+		t.Errorf("ArredondarValor(19.994) esperado 19.99, got %v", got)
+	}
+}
+
+func TestValorEmCentavosComModo(t *testing.T) {
+	tests := []struct {
+		valor    float64
+		modo     ModoArredondamento
+		esperado int64
+	}{
+		{valor: 2.345, modo: ArredondamentoMeioParaCima, esperado: 235},
+		{valor: 2.355, modo: ArredondamentoMeioParaCima, esperado: 236},
+		{valor: 2.345, modo: ArredondamentoMeioParaPar, esperado: 234},
+		{valor: 2.355, modo: ArredondamentoMeioParaPar, esperado: 236},
+		{valor: 2.345, modo: ArredondamentoTruncar, esperado: 234},
+		{valor: 2.355, modo: ArredondamentoTruncar, esperado: 235},
+		{valor: 2.349, modo: ArredondamentoMeioParaPar, esperado: 235},
+		{valor: 2.349, modo: ArredondamentoTruncar, esperado: 234},
+		// Centavos exatos não são afetados pelo modo
+		{valor: 19.99, modo: ArredondamentoTruncar, esperado: 1999},
+		{valor: 19.99, modo: ArredondamentoMeioParaPar, esperado: 1999},
+		{valor: 2.345, modo: "", esperado: 235},
+	}
+
+	for _, tt := range tests {
+		if got := ValorEmCentavosComModo(tt.valor, tt.modo); got != tt.esperado {
+			t.Errorf("ValorEmCentavosComModo(%v, %q) esperado %d, got %d", tt.valor, tt.modo, tt.esperado, got)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AgendarTransacao valida e persiste a transação como AGENDADA, sem debitar
+// o limite. O débito acontece na execução (ProcessadorAgendamentos), contra o
+// limite vigente na data agendada.
+func (s *transacaoService) AgendarTransacao(ctx context.Context, transacao *domain.Transacao) error {
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.AgendarTransacao")
+	defer s.tracer.FinishSpan(span, nil)
+
+	if err := s.validarTransacao(ctx, transacao); err != nil {
+		return err
+	}
+
+	if transacao.DataAgendamento == nil || !transacao.DataAgendamento.After(s.agora()) {
+		s.metricsCollector.IncrementErrorCounter("validation_error")
+		return domain.ErrDataAgendamentoInvalida
+	}
+
+	if _, err := s.limiteRepository.GetCliente(ctx, transacao.ClienteID); err != nil {
+		return err
+	}
+
+	// Na execução o cliente não está presente para confirmar o step-up
+	if err := s.verificarStepUp(ctx, transacao); err != nil {
+		return err
+	}
+
+	transacao.Agendar()
+
+	if err := s.transacaoRepository.Save(ctx, transacao); err != nil {
+		s.logger.Error(ctx, "erro ao salvar transação agendada", err, map[string]interface{}{
+			"transacao_id": transacao.ID,
+		})
+		s.metricsCollector.IncrementErrorCounter("transaction_save_error")
+		return err
+	}
+
+	s.logger.Info(ctx, "transação agendada", map[string]interface{}{
+		"transacao_id":     transacao.ID,
+		"cliente_id":       transacao.ClienteID,
+		"valor":            transacao.Valor,
+		"data_agendamento": *transacao.DataAgendamento,
+	})
+
+	s.metricsCollector.IncrementTransactionCounter(domain.StatusAgendada)
+
+	return nil
+}
+
+// RelatorioAgendamentos resume uma rodada de execução de transações agendadas
+type RelatorioAgendamentos struct {
+	Executadas int `json:"executadas"`
+	Aprovadas  int `json:"aprovadas"`
+	// Rejeitadas mapeia transação para o motivo (ex.: limite reduzido desde o agendamento)
+	Rejeitadas map[string]string `json:"rejeitadas"`
+}
+
+// ProcessadorAgendamentos executa, pelo fluxo normal de autorização, as
+// transações agendadas cuja data venceu. É acionado por um Lambda agendado.
+type ProcessadorAgendamentos struct {
+	agendadas        domain.TransacaoAgendadaRepository
+	autorizador      TransacaoService
+	metricsCollector domain.MetricsCollector
+	logger           domain.Logger
+}
+
+func NewProcessadorAgendamentos(
+	agendadas domain.TransacaoAgendadaRepository,
+	autorizador TransacaoService,
+	metricsCollector domain.MetricsCollector,
+	logger domain.Logger,
+) *ProcessadorAgendamentos {
+	return &ProcessadorAgendamentos{
+		agendadas:        agendadas,
+		autorizador:      autorizador,
+		metricsCollector: metricsCollector,
+		logger:           logger,
+	}
+}
+
+// ProcessarVencidas executa as transações agendadas até agora. O limite é
+// verificado e debitado no momento da execução: se o limite do cliente mudou
+// desde o agendamento, vale o limite atual (e a transação pode ser rejeitada).
+// Uma transação rejeitada não interrompe as demais.
+func (p *ProcessadorAgendamentos) ProcessarVencidas(ctx context.Context, agora time.Time) (*RelatorioAgendamentos, error) {
+	vencidas, err := p.agendadas.ListarAgendadasAte(ctx, agora)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar transações agendadas: %w", err)
+	}
+
+	relatorio := &RelatorioAgendamentos{Rejeitadas: make(map[string]string)}
+
+	for _, transacao := range vencidas {
+		// Garante execução única mesmo com rodadas concorrentes
+		if err := p.agendadas.IniciarExecucao(ctx, transacao); err != nil {
+			if !errors.Is(err, domain.ErrAgendamentoJaIniciado) {
+				p.logger.Error(ctx, "erro ao iniciar execução de transação agendada", err, map[string]interface{}{
+					"transacao_id": transacao.ID,
+				})
+				p.metricsCollector.IncrementErrorCounter("scheduled_transaction_error")
+			}
+			continue
+		}
+
+		transacao.Status = domain.StatusPendente
+		relatorio.Executadas++
+
+		resultado, err := p.autorizador.AutorizarTransacao(ctx, transacao)
+		if err == nil && !resultado.Aprovada() {
+			err = resultado.Motivo
+		}
+		if err != nil {
+			relatorio.Rejeitadas[transacao.ID] = err.Error()
+			p.logger.Warn(ctx, "transação agendada rejeitada na execução", map[string]interface{}{
+				"transacao_id":     transacao.ID,
+				"cliente_id":       transacao.ClienteID,
+				"data_agendamento": transacao.DataAgendamento,
+				"motivo":           err.Error(),
+			})
+			p.metricsCollector.IncrementErrorCounter("scheduled_transaction_rejected")
+			continue
+		}
+
+		relatorio.Aprovadas++
+	}
+
+	p.logger.Info(ctx, "transações agendadas processadas", map[string]interface{}{
+		"executadas": relatorio.Executadas,
+		"aprovadas":  relatorio.Aprovadas,
+		"rejeitadas": len(relatorio.Rejeitadas),
+	})
+
+	return relatorio, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func novaTransacaoAgendada(clienteID string, valor float64, data time.Time) *domain.Transacao {
+	transacao := domain.NewTransacao(clienteID, valor, "corr")
+	transacao.DataAgendamento = &data
+	return transacao
+}
+
+func TestAgendarTransacao(t *testing.T) {
+	agora := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		transacao   *domain.Transacao
+		expectedErr error
+	}{
+		{name: "data futura", transacao: novaTransacaoAgendada("c1", 50.00, agora.Add(24*time.Hour))},
+		{name: "data no passado", transacao: novaTransacaoAgendada("c1", 50.00, agora.Add(-time.Minute)), expectedErr: domain.ErrDataAgendamentoInvalida},
+		{name: "sem data", transacao: domain.NewTransacao("c1", 50.00, "corr"), expectedErr: domain.ErrDataAgendamentoInvalida},
+		{name: "valor inválido", transacao: novaTransacaoAgendada("c1", -1, agora.Add(time.Hour)), expectedErr: domain.ErrValorNegativo},
+		{name: "cliente inexistente", transacao: novaTransacaoAgendada("inexistente", 50.00, agora.Add(time.Hour)), expectedErr: domain.ErrClienteNaoEncontrado},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			svc := deps.service(WithRelogio(func() time.Time { return agora }))
+
+			if err := svc.AgendarTransacao(context.Background(), tt.transacao); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			if deps.limites.debitos != 0 {
+				t.Errorf("agendamento não deveria debitar limite, got %d débitos", deps.limites.debitos)
+			}
+
+			salva, _ := deps.transacoes.GetByID(context.Background(), tt.transacao.ID)
+			if tt.expectedErr != nil {
+				if salva != nil {
+					t.Errorf("transação inválida não deveria ser persistida")
+				}
+				return
+			}
+
+			if salva == nil || salva.Status != domain.StatusAgendada {
+				t.Fatalf("transação deveria ser persistida como %s, got %+v", domain.StatusAgendada, salva)
+			}
+
+			if len(deps.publisher.publicados) != 0 {
+				t.Errorf("agendamento não deveria publicar eventos")
+			}
+		})
+	}
+}
+
+func TestProcessarVencidas(t *testing.T) {
+	agora := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service(WithRelogio(func() time.Time { return agora.Add(-24 * time.Hour) }))
+
+	vencida := novaTransacaoAgendada("c1", 100.00, agora.Add(-time.Minute))
+	futura := novaTransacaoAgendada("c1", 200.00, agora.Add(time.Hour))
+	for _, transacao := range []*domain.Transacao{vencida, futura} {
+		if err := svc.AgendarTransacao(context.Background(), transacao); err != nil {
+			t.Fatalf("erro ao agendar: %v", err)
+		}
+	}
+
+	processador := NewProcessadorAgendamentos(deps.transacoes, svc, deps.metrics, &fakeLogger{})
+
+	relatorio, err := processador.ProcessarVencidas(context.Background(), agora)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if relatorio.Executadas != 1 || relatorio.Aprovadas != 1 {
+		t.Fatalf("esperada 1 execução aprovada, got %+v", relatorio)
+	}
+
+	cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+	if cliente.LimiteAtual != 90000 {
+		t.Errorf("Limite esperado 90000 após a execução, got %d", cliente.LimiteAtual)
+	}
+
+	if salva, _ := deps.transacoes.GetByID(context.Background(), vencida.ID); salva.Status != domain.StatusAprovada {
+		t.Errorf("transação vencida deveria estar %s, got %s", domain.StatusAprovada, salva.Status)
+	}
+
+	if salva, _ := deps.transacoes.GetByID(context.Background(), futura.ID); salva.Status != domain.StatusAgendada {
+		t.Errorf("transação futura deveria continuar %s, got %s", domain.StatusAgendada, salva.Status)
+	}
+
+	// Uma nova rodada não executa a mesma transação novamente
+	relatorio, _ = processador.ProcessarVencidas(context.Background(), agora)
+	if relatorio.Executadas != 0 {
+		t.Errorf("transação já executada não deveria ser reprocessada, got %+v", relatorio)
+	}
+}
+
+func TestProcessarVencidas_LimiteReduzidoDesdeOAgendamento(t *testing.T) {
+	agora := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service(WithRelogio(func() time.Time { return agora.Add(-24 * time.Hour) }))
+
+	transacao := novaTransacaoAgendada("c1", 800.00, agora.Add(-time.Minute))
+	if err := svc.AgendarTransacao(context.Background(), transacao); err != nil {
+		t.Fatalf("erro ao agendar: %v", err)
+	}
+
+	// Limite consumido por outras compras entre o agendamento e a execução
+	deps.limites.UpdateLimite(context.Background(), "c1", 50000)
+
+	processador := NewProcessadorAgendamentos(deps.transacoes, svc, deps.metrics, &fakeLogger{})
+
+	relatorio, err := processador.ProcessarVencidas(context.Background(), agora)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if relatorio.Executadas != 1 || relatorio.Aprovadas != 0 {
+		t.Fatalf("esperada 1 execução rejeitada, got %+v", relatorio)
+	}
+
+	if motivo := relatorio.Rejeitadas[transacao.ID]; motivo != domain.ErrLimiteInsuficiente.Error() {
+		t.Errorf("motivo esperado %q, got %q", domain.ErrLimiteInsuficiente.Error(), motivo)
+	}
+
+	if salva, _ := deps.transacoes.GetByID(context.Background(), transacao.ID); salva.Status != domain.StatusRejeitada {
+		t.Errorf("transação deveria estar %s, got %s", domain.StatusRejeitada, salva.Status)
+	}
+
+	cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+	if cliente.LimiteAtual != 50000 {
+		t.Errorf("Limite não deveria ser debitado, got %d", cliente.LimiteAtual)
+	}
+
+	if deps.metrics.errorCount("scheduled_transaction_rejected") != 1 {
+		t.Error("métrica scheduled_transaction_rejected deveria ser registrada")
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+)
+
+// registrarAuditoria grava a decisão na trilha de auditoria. Falhas de
+// infraestrutura não são decisões e não chegam aqui; repetições atendidas
+// pela idempotência também não, pois devolvem uma decisão já registrada.
+func (s *transacaoService) registrarAuditoria(ctx context.Context, transacao *domain.Transacao, resultado *Resultado) {
+	if s.auditoria == nil {
+		return
+	}
+
+	entrada := domain.AuditEntry{
+		TransacaoID:   transacao.ID,
+		ClienteID:     transacao.ClienteID,
+		ValorCentavos: transacao.ValorCentavos(),
+		Moeda:         transacao.MoedaOuPadrao(),
+		Parcelas:      transacao.Parcelas,
+		Canal:         transacao.Canal,
+		Tipo:          transacao.Tipo,
+		CorrelationID: transacao.CorrelationID,
+		Decisao:       resultado.Status,
+		CodigoMotivo:  resultado.CodigoMotivo,
+		Ator:          s.atorAuditoria,
+		Timestamp:     s.agora().UTC(),
+	}
+
+	if err := s.auditoria.RecordDecision(ctx, entrada); err != nil {
+		s.logger.Error(ctx, "erro ao gravar decisão na trilha de auditoria", err, map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"cliente_id":   transacao.ClienteID,
+			"decisao":      resultado.Status,
+		})
+		s.metricsCollector.IncrementErrorCounter("audit_record_error")
+	}
+}
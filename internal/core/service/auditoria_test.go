@@ -0,0 +1,102 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/testutil"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAuditRepository guarda as entradas gravadas, ou falha com err
+type fakeAuditRepository struct {
+	mu       sync.Mutex
+	entradas []domain.AuditEntry
+	err      error
+}
+
+func (r *fakeAuditRepository) RecordDecision(ctx context.Context, entry domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.entradas = append(r.entradas, entry)
+	return nil
+}
+
+func TestAutorizarTransacao_RegistraDecisaoNaAuditoria(t *testing.T) {
+	agora := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cliente *domain.Cliente
+		decisao string
+		codigo  string
+	}{
+		{name: "aprovação", cliente: testutil.ClientePadrao(), decisao: domain.StatusAprovada},
+		{name: "recusa", cliente: testutil.ClienteSemLimite(), decisao: domain.StatusRejeitada, codigo: "insufficient_limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(tt.cliente)
+			auditoria := &fakeAuditRepository{}
+			svc := deps.service(WithAuditoria(auditoria, "transaction-authorizer"), WithRelogio(func() time.Time { return agora }))
+
+			transacao := testutil.NewTransacaoBuilder().ComValor(150.25).Build()
+			transacao.Canal = "MOBILE"
+			if _, err := svc.AutorizarTransacao(context.Background(), transacao); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if len(auditoria.entradas) != 1 {
+				t.Fatalf("esperada uma entrada de auditoria, got %d", len(auditoria.entradas))
+			}
+
+			esperada := domain.AuditEntry{
+				TransacaoID:   transacao.ID,
+				ClienteID:     testutil.ClienteIDPadrao,
+				ValorCentavos: 15025,
+				Moeda:         domain.MoedaPadrao,
+				Canal:         "MOBILE",
+				CorrelationID: transacao.CorrelationID,
+				Decisao:       tt.decisao,
+				CodigoMotivo:  tt.codigo,
+				Ator:          "transaction-authorizer",
+				Timestamp:     agora,
+			}
+			if got := auditoria.entradas[0]; got != esperada {
+				t.Errorf("entrada esperada %+v, got %+v", esperada, got)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_FalhaNaAuditoriaNaoReverteDecisao(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	svc := deps.service(WithAuditoria(&fakeAuditRepository{err: errors.New("dynamodb indisponível")}, "transaction-authorizer"))
+
+	resultado, err := svc.AutorizarTransacao(context.Background(), testutil.NewTransacaoBuilder().Build())
+	if err != nil || !resultado.Aprovada() {
+		t.Fatalf("decisão deveria ser mantida: %+v, %v", resultado, err)
+	}
+	if deps.metrics.errorCount("audit_record_error") != 1 {
+		t.Error("métrica audit_record_error deveria ser incrementada")
+	}
+}
+
+func TestAutorizarTransacao_RepeticaoIdempotenteNaoDuplicaAuditoria(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	auditoria := &fakeAuditRepository{}
+	svc := deps.service(WithAuditoria(auditoria, "transaction-authorizer"), WithIdempotencia(newFakeIdempotencyStore(), time.Hour))
+
+	svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+
+	if len(auditoria.entradas) != 1 {
+		t.Errorf("repetição não é nova decisão: esperada uma entrada, got %d", len(auditoria.entradas))
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/observability/noop"
+	"context"
+	"math"
+	"testing"
+)
+
+// noopEventPublisher descarta eventos sem sinalizar (o fake com canal
+// bloquearia após o buffer encher)
+type noopEventPublisher struct{}
+
+func (p *noopEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return nil
+}
+
+func (p *noopEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return nil
+}
+
+// descarteTransacaoRepository descarta gravações para que o benchmark não
+// meça o crescimento do mapa em memória
+type descarteTransacaoRepository struct{}
+
+func (r *descarteTransacaoRepository) Save(ctx context.Context, transacao *domain.Transacao) error {
+	return nil
+}
+
+func (r *descarteTransacaoRepository) GetByID(ctx context.Context, transacaoID string) (*domain.Transacao, error) {
+	return nil, nil
+}
+
+func (r *descarteTransacaoRepository) GetByClienteID(ctx context.Context, clienteID string, limit int) ([]*domain.Transacao, error) {
+	return nil, nil
+}
+
+func newBenchmarkService(clientes ...*domain.Cliente) TransacaoService {
+	return NewTransacaoService(
+		newFakeLimiteRepository(clientes...),
+		&descarteTransacaoRepository{},
+		&noopEventPublisher{},
+		noop.MetricsCollector{},
+		noop.Tracer{},
+		noop.Logger{},
+	)
+}
+
+// BenchmarkAutorizarTransacao mede o caminho completo de autorização com
+// dependências em memória e observabilidade noop
+func BenchmarkAutorizarTransacao(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("aprovacao", func(b *testing.B) {
+		svc := newBenchmarkService(&domain.Cliente{ID: "c1", LimiteCredit: math.MaxInt, LimiteAtual: math.MaxInt})
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := autorizar(ctx, svc, domain.NewTransacao("c1", 99.90, "corr")); err != nil {
+				b.Fatalf("erro inesperado: %v", err)
+			}
+		}
+	})
+
+	b.Run("rejeicao_limite", func(b *testing.B) {
+		svc := newBenchmarkService(&domain.Cliente{ID: "c1", LimiteCredit: 1000, LimiteAtual: 0})
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := autorizar(ctx, svc, domain.NewTransacao("c1", 99.90, "corr")); err != domain.ErrLimiteInsuficiente {
+				b.Fatalf("erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+			}
+		}
+	})
+
+	b.Run("rejeicao_validacao", func(b *testing.B) {
+		svc := newBenchmarkService()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := autorizar(ctx, svc, domain.NewTransacao("c1", -1, "corr")); err != domain.ErrValorNegativo {
+				b.Fatalf("erro esperado %v, got %v", domain.ErrValorNegativo, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"strings"
+)
+
+// cadeiaRegras registra, em ordem, as regras avaliadas numa autorização e a
+// que decidiu a recusa. Usa os mesmos nomes de etapa de ExplicarDecisao.
+type cadeiaRegras struct {
+	avaliadas []string
+	decisiva  string
+	motivo    error
+}
+
+// avaliar registra o resultado da regra e devolve o erro inalterado; a
+// primeira regra que falha é a decisiva
+func (c *cadeiaRegras) avaliar(regra string, err error) error {
+	resultado := EtapaPassou
+	if err != nil {
+		resultado = EtapaFalhou
+		if c.decisiva == "" {
+			c.decisiva, c.motivo = regra, err
+		}
+	}
+	c.avaliadas = append(c.avaliadas, regra+"="+resultado)
+	return err
+}
+
+// registrarCadeia loga a avaliação da cadeia: Info na recusa (com a regra
+// decisiva, também marcada no span) e Debug na aprovação
+func (s *transacaoService) registrarCadeia(ctx context.Context, span interface{}, transacao *domain.Transacao, cadeia *cadeiaRegras) {
+	if len(cadeia.avaliadas) == 0 {
+		return
+	}
+
+	campos := map[string]interface{}{
+		"transacao_id": transacao.ID,
+		"regras":       strings.Join(cadeia.avaliadas, ","),
+	}
+
+	if cadeia.decisiva == "" {
+		s.logger.Debug(ctx, "cadeia de regras aprovou a transação", campos)
+		return
+	}
+
+	campos["regra_decisiva"] = cadeia.decisiva
+	campos["codigo_motivo"] = codigoMotivo(cadeia.motivo)
+	s.tracer.AddTag(span, "regra_decisiva", cadeia.decisiva)
+	s.logger.Info(ctx, "cadeia de regras recusou a transação", campos)
+}
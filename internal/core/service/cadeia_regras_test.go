@@ -0,0 +1,80 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logCadeiaRegistrador guarda Info e Debug da avaliação da cadeia de regras
+type logCadeiaRegistrador struct {
+	fakeLogger
+	mu       sync.Mutex
+	niveis   []string
+	entradas []map[string]interface{}
+}
+
+func (l *logCadeiaRegistrador) registrar(nivel, msg string, fields map[string]interface{}) {
+	if !strings.HasPrefix(msg, "cadeia de regras") {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.niveis = append(l.niveis, nivel)
+	l.entradas = append(l.entradas, fields)
+}
+
+func (l *logCadeiaRegistrador) Info(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.registrar("info", msg, fields)
+}
+
+func (l *logCadeiaRegistrador) Debug(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.registrar("debug", msg, fields)
+}
+
+func TestAutorizarTransacao_RegistraCadeiaDeRegras(t *testing.T) {
+	tests := []struct {
+		name     string
+		valor    float64
+		nivel    string
+		decisiva string
+		regras   string
+	}{
+		{
+			name: "aprovação em debug", valor: 10.00, nivel: "debug",
+			regras: "validacao=PASSOU,duplicidade=PASSOU,politica=PASSOU,tier=PASSOU,parcelas=PASSOU,step_up=PASSOU,pre_autorizacao=PASSOU,limite=PASSOU",
+		},
+		{
+			name: "recusa pelo limite em info", valor: 5000.00, nivel: "info", decisiva: "limite",
+			regras: "validacao=PASSOU,duplicidade=PASSOU,politica=PASSOU,tier=PASSOU,parcelas=PASSOU,step_up=PASSOU,pre_autorizacao=PASSOU,limite=FALHOU",
+		},
+		{name: "recusa na validação interrompe a cadeia", valor: -1, nivel: "info", decisiva: "validacao", regras: "validacao=FALHOU"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			logger := &logCadeiaRegistrador{}
+			svc := NewTransacaoService(deps.limites, deps.transacoes, deps.publisher, deps.metrics, &fakeTracer{}, logger)
+
+			autorizar(context.Background(), svc, domain.NewTransacao("c1", tt.valor, "corr"))
+
+			if len(logger.entradas) != 1 {
+				t.Fatalf("esperado um registro da cadeia, got %d", len(logger.entradas))
+			}
+			if logger.niveis[0] != tt.nivel {
+				t.Errorf("nível esperado %s, got %s", tt.nivel, logger.niveis[0])
+			}
+
+			campos := logger.entradas[0]
+			if campos["regras"] != tt.regras {
+				t.Errorf("regras esperadas %s, got %v", tt.regras, campos["regras"])
+			}
+			if decisiva, _ := campos["regra_decisiva"].(string); decisiva != tt.decisiva {
+				t.Errorf("regra decisiva esperada %q, got %q", tt.decisiva, decisiva)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"sort"
+)
+
+// ListarTransacoesCliente retorna as transações mais recentes do cliente.
+//
+// O índice por cliente é eventualmente consistente, então uma transação
+// recém-criada pode ainda não aparecer na consulta. Quando o chamador informa
+// o ID da transação que acabou de criar (transacaoCriadaID), ela é buscada
+// por leitura fortemente consistente na tabela base e incluída no resultado,
+// garantindo leitura após escrita para quem a criou.
+func (s *transacaoService) ListarTransacoesCliente(ctx context.Context, clienteID string, limit int, transacaoCriadaID string) ([]*domain.Transacao, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.ListarTransacoesCliente")
+	defer s.tracer.FinishSpan(span, nil)
+
+	transacoes, err := s.transacaoRepository.GetByClienteID(ctx, clienteID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if transacaoCriadaID == "" || contemTransacao(transacoes, transacaoCriadaID) {
+		return transacoes, nil
+	}
+
+	criada, err := s.transacaoRepository.GetByID(ctx, transacaoCriadaID)
+	if err != nil || criada == nil || criada.ClienteID != clienteID {
+		// A leitura complementar é best-effort: devolve o que o índice já tem
+		return transacoes, nil
+	}
+
+	s.logger.Debug(ctx, "transação recém-criada ausente do índice, incluída via leitura consistente", map[string]interface{}{
+		"transacao_id": transacaoCriadaID,
+		"cliente_id":   clienteID,
+	})
+
+	transacoes = append(transacoes, criada)
+	sort.SliceStable(transacoes, func(i, j int) bool {
+		return transacoes[i].Timestamp.After(transacoes[j].Timestamp)
+	})
+
+	if limit > 0 && len(transacoes) > limit {
+		transacoes = transacoes[:limit]
+	}
+
+	return transacoes, nil
+}
+
+func contemTransacao(transacoes []*domain.Transacao, transacaoID string) bool {
+	for _, t := range transacoes {
+		if t.ID == transacaoID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestListarTransacoesCliente_LeituraAposEscrita(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service()
+
+	antiga := domain.NewTransacao("c1", 10.00, "corr-1")
+	antiga.Timestamp = time.Now().Add(-time.Hour)
+	antiga.Status = domain.StatusAprovada
+	deps.transacoes.Save(context.Background(), antiga)
+
+	criada := domain.NewTransacao("c1", 20.00, "corr-2")
+	if err := autorizar(context.Background(), svc, criada); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	// O GSI ainda não propagou a transação recém-criada
+	deps.transacoes.indiceAtrasado[criada.ID] = true
+
+	semGarantia, _ := svc.ListarTransacoesCliente(context.Background(), "c1", 10, "")
+	if contemTransacao(semGarantia, criada.ID) {
+		t.Fatal("cenário inválido: transação não deveria aparecer sem a leitura consistente")
+	}
+
+	transacoes, err := svc.ListarTransacoesCliente(context.Background(), "c1", 10, criada.ID)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(transacoes) != 2 {
+		t.Fatalf("esperado 2 transações, got %d", len(transacoes))
+	}
+
+	if transacoes[0].ID != criada.ID {
+		t.Errorf("transação recém-criada deveria ser a primeira (mais recente), got %s", transacoes[0].ID)
+	}
+}
+
+func TestListarTransacoesCliente_IgnoraTransacaoDeOutroCliente(t *testing.T) {
+	deps := newDependencias()
+	svc := deps.service()
+
+	outra := domain.NewTransacao("c2", 20.00, "corr")
+	deps.transacoes.Save(context.Background(), outra)
+	deps.transacoes.indiceAtrasado[outra.ID] = true
+
+	transacoes, err := svc.ListarTransacoesCliente(context.Background(), "c1", 10, outra.ID)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(transacoes) != 0 {
+		t.Errorf("transação de outro cliente não deve ser incluída, got %d", len(transacoes))
+	}
+}
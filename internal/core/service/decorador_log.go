@@ -0,0 +1,66 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"time"
+)
+
+// decoradorLog registra cada chamada ao serviço decorado com operação,
+// duração e resultado, sem alterar o comportamento
+type decoradorLog struct {
+	servico TransacaoService
+	logger  domain.Logger
+}
+
+// NewDecoradorLog envolve o serviço com log estruturado de cada chamada.
+// É a implementação de referência para decoradores de TransacaoService.
+func NewDecoradorLog(servico TransacaoService, logger domain.Logger) TransacaoService {
+	return &decoradorLog{servico: servico, logger: logger}
+}
+
+func (d *decoradorLog) AutorizarTransacao(ctx context.Context, transacao *domain.Transacao) (*Resultado, error) {
+	inicio := time.Now()
+	resultado, err := d.servico.AutorizarTransacao(ctx, transacao)
+	campos := map[string]interface{}{"transacao_id": transacao.ID}
+	if resultado != nil {
+		campos["status"] = resultado.Status
+		if resultado.CodigoMotivo != "" {
+			campos["codigo_motivo"] = resultado.CodigoMotivo
+		}
+	}
+	d.registrar(ctx, "AutorizarTransacao", inicio, err, campos)
+	return resultado, err
+}
+
+func (d *decoradorLog) AgendarTransacao(ctx context.Context, transacao *domain.Transacao) error {
+	inicio := time.Now()
+	err := d.servico.AgendarTransacao(ctx, transacao)
+	d.registrar(ctx, "AgendarTransacao", inicio, err, map[string]interface{}{"transacao_id": transacao.ID})
+	return err
+}
+
+func (d *decoradorLog) ListarTransacoesCliente(ctx context.Context, clienteID string, limit int, transacaoCriadaID string) ([]*domain.Transacao, error) {
+	inicio := time.Now()
+	transacoes, err := d.servico.ListarTransacoesCliente(ctx, clienteID, limit, transacaoCriadaID)
+	d.registrar(ctx, "ListarTransacoesCliente", inicio, err, map[string]interface{}{"cliente_id": clienteID})
+	return transacoes, err
+}
+
+func (d *decoradorLog) ExplicarDecisao(ctx context.Context, transacaoID string) (*ExplicacaoDecisao, error) {
+	inicio := time.Now()
+	explicacao, err := d.servico.ExplicarDecisao(ctx, transacaoID)
+	d.registrar(ctx, "ExplicarDecisao", inicio, err, map[string]interface{}{"transacao_id": transacaoID})
+	return explicacao, err
+}
+
+// registrar usa Info mesmo com erro: falhas já são logadas pelo serviço no
+// nível adequado
+func (d *decoradorLog) registrar(ctx context.Context, operacao string, inicio time.Time, err error, campos map[string]interface{}) {
+	campos["operacao"] = operacao
+	campos["duracao_ms"] = time.Since(inicio).Milliseconds()
+	if err != nil {
+		campos["erro"] = err.Error()
+	}
+	d.logger.Info(ctx, "chamada ao serviço de transações", campos)
+}
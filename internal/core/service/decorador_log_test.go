@@ -0,0 +1,96 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// logInfoRegistrador guarda os campos de cada Info
+type logInfoRegistrador struct {
+	fakeLogger
+	mu     sync.Mutex
+	campos []map[string]interface{}
+}
+
+func (l *logInfoRegistrador) Info(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.campos = append(l.campos, fields)
+}
+
+// servicoIndisponivel falha toda autorização com erro de infraestrutura
+type servicoIndisponivel struct {
+	TransacaoService
+}
+
+func (s servicoIndisponivel) AutorizarTransacao(ctx context.Context, transacao *domain.Transacao) (*Resultado, error) {
+	return nil, domain.ErrTimeoutOperacao
+}
+
+func TestDecoradorLog(t *testing.T) {
+	tests := []struct {
+		name         string
+		valor        float64
+		indisponivel bool
+		status       string
+		codigo       string
+		esperaErr    error
+	}{
+		{name: "aprovada", valor: 10.00, status: domain.StatusAprovada},
+		{name: "recusada", valor: 5000.00, status: domain.StatusRejeitada, codigo: "insufficient_limit"},
+		{name: "falha de infraestrutura", valor: 10.00, indisponivel: true, esperaErr: domain.ErrTimeoutOperacao},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			var decorado TransacaoService = deps.service()
+			if tt.indisponivel {
+				decorado = servicoIndisponivel{}
+			}
+			logger := &logInfoRegistrador{}
+			svc := NewDecoradorLog(decorado, logger)
+
+			transacao := domain.NewTransacao("c1", tt.valor, "corr")
+			resultado, err := svc.AutorizarTransacao(context.Background(), transacao)
+			if !errors.Is(err, tt.esperaErr) {
+				t.Fatalf("esperado erro %v, got %v", tt.esperaErr, err)
+			}
+
+			if len(logger.campos) != 1 {
+				t.Fatalf("esperado 1 log, got %d", len(logger.campos))
+			}
+			campos := logger.campos[0]
+			if campos["operacao"] != "AutorizarTransacao" || campos["transacao_id"] != transacao.ID {
+				t.Errorf("campos inesperados: %v", campos)
+			}
+			if _, ok := campos["duracao_ms"]; !ok {
+				t.Error("duração deveria ser registrada")
+			}
+
+			if tt.esperaErr != nil {
+				if campos["erro"] != tt.esperaErr.Error() {
+					t.Errorf("erro registrado inesperado: %v", campos["erro"])
+				}
+				return
+			}
+
+			// A chamada chega ao serviço decorado e o resultado volta intacto
+			if salva, _ := deps.transacoes.GetByID(context.Background(), transacao.ID); salva == nil {
+				t.Fatal("transação deveria ser persistida pelo serviço decorado")
+			}
+			if resultado.Status != tt.status || campos["status"] != tt.status {
+				t.Errorf("status esperado %s, got %s (log %v)", tt.status, resultado.Status, campos["status"])
+			}
+			if codigo, _ := campos["codigo_motivo"].(string); codigo != tt.codigo {
+				t.Errorf("código de motivo esperado %q no log, got %q", tt.codigo, codigo)
+			}
+			if _, ok := campos["erro"]; ok {
+				t.Error("recusa de negócio não é erro")
+			}
+		})
+	}
+}
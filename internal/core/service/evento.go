@@ -0,0 +1,64 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+)
+
+// limitePayloadEventoPadrao é o limite de mensagem do SNS (256KB)
+const limitePayloadEventoPadrao = 256 * 1024
+
+// limitePayload é a proteção de tamanho do payload publicado, aplicada
+// igualmente pelo serviço e pelo relay do outbox
+type limitePayload struct {
+	maxBytes int
+	store    domain.EventPayloadStore
+}
+
+// ajustarTamanhoEvento garante que o payload caiba no limite do tópico
+func (s *transacaoService) ajustarTamanhoEvento(ctx context.Context, evento *domain.TransacaoEvento) *domain.TransacaoEvento {
+	return s.limitePayload.ajustar(ctx, evento, s.metricsCollector, s.logger)
+}
+
+// ajustar devolve o evento dentro do limite. Acima do limite, publica uma
+// referência ao payload completo guardado no store; sem store (ou se o store
+// falhar), remove os campos não essenciais.
+func (l limitePayload) ajustar(ctx context.Context, evento *domain.TransacaoEvento, metrics domain.MetricsCollector, logger domain.Logger) *domain.TransacaoEvento {
+	if l.maxBytes <= 0 {
+		return evento
+	}
+
+	payload, err := json.Marshal(evento)
+	if err != nil || len(payload) <= l.maxBytes {
+		return evento
+	}
+
+	campos := map[string]interface{}{
+		"transacao_id":   evento.TransacaoID,
+		"tamanho_bytes":  len(payload),
+		"limite_bytes":   l.maxBytes,
+		"possui_storage": l.store != nil,
+	}
+
+	if l.store != nil {
+		referencia, err := l.store.ArmazenarPayload(ctx, evento)
+		if err == nil {
+			logger.Warn(ctx, "payload de evento acima do limite, publicando referência", campos)
+			metrics.IncrementErrorCounter("event_payload_oversized_reference")
+
+			reduzido := evento.Essencial()
+			reduzido.PayloadRef = referencia
+			return reduzido
+		}
+
+		logger.Error(ctx, "falha ao armazenar payload de evento, truncando", err, campos)
+	}
+
+	logger.Warn(ctx, "payload de evento acima do limite, truncando campos não essenciais", campos)
+	metrics.IncrementErrorCounter("event_payload_oversized_truncated")
+
+	reduzido := evento.Essencial()
+	reduzido.Truncado = true
+	return reduzido
+}
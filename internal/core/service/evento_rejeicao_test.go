@@ -0,0 +1,85 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/testutil"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAutorizarTransacao_SupressaoEventosRejeicao(t *testing.T) {
+	deps := newDependencias(testutil.ClienteSemLimite())
+	svc := deps.service(WithSupressaoEventosRejeicao())
+
+	transacao := testutil.NewTransacaoBuilder().Build()
+	if err := autorizar(context.Background(), svc, transacao); err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+
+	select {
+	case <-deps.publisher.publicados:
+		t.Fatal("evento de rejeição não deveria ser publicado")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	salva, _ := deps.transacoes.GetByID(context.Background(), transacao.ID)
+	if salva == nil || salva.Status != domain.StatusRejeitada {
+		t.Errorf("rejeição deveria continuar persistida, got %+v", salva)
+	}
+
+	deps.metrics.mu.Lock()
+	defer deps.metrics.mu.Unlock()
+	if deps.metrics.transactions[domain.StatusRejeitada] != 1 {
+		t.Errorf("métrica de rejeição esperada 1, got %d", deps.metrics.transactions[domain.StatusRejeitada])
+	}
+}
+
+func TestAutorizarTransacao_SupressaoNaoAfetaAprovacoes(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	svc := deps.service(WithSupressaoEventosRejeicao())
+
+	if err := autorizar(context.Background(), svc, testutil.NewTransacaoBuilder().Build()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	aguardar(t, func() bool { return len(deps.publisher.publicados) == 1 })
+}
+
+func TestAutorizarTransacao_EventosRejeicaoPublicadosPorPadrao(t *testing.T) {
+	deps := newDependencias(testutil.ClienteSemLimite())
+	svc := deps.service()
+
+	if err := autorizar(context.Background(), svc, testutil.NewTransacaoBuilder().Build()); err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+
+	aguardar(t, func() bool { return len(deps.publisher.publicados) == 1 })
+}
+
+func TestAutorizarTransacao_EventoRejeicaoComCodigoMotivo(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		codigo string
+	}{
+		{name: "limite", codigo: "insufficient_limit"},
+		{name: "fraude", opts: []Option{WithPreAutorizacao(&fakePreAuth{aprovada: false}, time.Second, FalhaFechada)}, codigo: "pre_authorization_denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(testutil.ClienteSemLimite())
+			svc := deps.service(tt.opts...)
+
+			autorizar(context.Background(), svc, testutil.NewTransacaoBuilder().Build())
+
+			aguardar(t, func() bool { return len(deps.publisher.publicados) == 1 })
+			deps.publisher.mu.Lock()
+			defer deps.publisher.mu.Unlock()
+			if codigo := deps.publisher.rejeitados[0].CodigoMotivo; codigo != tt.codigo {
+				t.Errorf("código de motivo esperado %s, got %s", tt.codigo, codigo)
+			}
+		})
+	}
+}
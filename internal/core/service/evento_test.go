@@ -0,0 +1,209 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakePayloadStore devolve uma referência fixa ou o erro configurado
+type fakePayloadStore struct {
+	err        error
+	armazenado *domain.TransacaoEvento
+}
+
+func (f *fakePayloadStore) ArmazenarPayload(ctx context.Context, evento *domain.TransacaoEvento) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.armazenado = evento
+	return "s3://eventos/" + evento.TransacaoID, nil
+}
+
+func TestPublicarEvento_PayloadAcimaDoLimite(t *testing.T) {
+	tests := []struct {
+		name        string
+		store       *fakePayloadStore
+		folga       int
+		esperaRef   bool
+		esperaTrunc bool
+		metricaErro string
+	}{
+		{name: "dentro do limite", folga: 0},
+		{name: "sem store trunca", folga: -1, esperaTrunc: true, metricaErro: "event_payload_oversized_truncated"},
+		{name: "com store publica referência", store: &fakePayloadStore{}, folga: -1, esperaRef: true, metricaErro: "event_payload_oversized_reference"},
+		{name: "store indisponível trunca", store: &fakePayloadStore{err: errors.New("s3 indisponível")}, folga: -1, esperaTrunc: true, metricaErro: "event_payload_oversized_truncated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transacao := domain.NewTransacao("c1", 10.00, "corr")
+			transacao.DefinirCanal("mobile")
+			transacao.Parcelas = 3
+
+			// O limite fica exatamente no tamanho do evento aprovado (mais a folga)
+			aprovada := *transacao
+			aprovada.Aprovar()
+			publicado := aprovada.ToEvento()
+			publicado.ChaveDeduplicacao = domain.HasherSHA256{}.Chave(domain.CamposDeduplicacaoEvento(publicado))
+			payload, _ := json.Marshal(publicado)
+
+			var store domain.EventPayloadStore
+			if tt.store != nil {
+				store = tt.store
+			}
+
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			svc := deps.service(WithLimitePayloadEvento(len(payload)+tt.folga, store))
+
+			if err := autorizar(context.Background(), svc, transacao); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			aguardar(t, func() bool { return deps.metrics.eventPublishCount("success") == 1 })
+
+			deps.publisher.mu.Lock()
+			evento := deps.publisher.aprovados[0]
+			deps.publisher.mu.Unlock()
+
+			if (evento.PayloadRef != "") != tt.esperaRef {
+				t.Errorf("PayloadRef inesperado: %q", evento.PayloadRef)
+			}
+
+			if evento.Truncado != tt.esperaTrunc {
+				t.Errorf("Truncado esperado %v, got %v", tt.esperaTrunc, evento.Truncado)
+			}
+
+			if tt.esperaRef || tt.esperaTrunc {
+				if evento.Canal != "" || evento.Parcelas != 0 {
+					t.Error("campos não essenciais deveriam ser removidos")
+				}
+				if evento.TransacaoID != transacao.ID || evento.ValorCentavos != 1000 {
+					t.Error("campos essenciais deveriam ser mantidos")
+				}
+			} else if evento.Canal != domain.CanalMobile {
+				t.Error("evento dentro do limite não deveria ser alterado")
+			}
+
+			if tt.esperaRef && tt.store.armazenado.Canal != domain.CanalMobile {
+				t.Error("store deveria receber o payload completo")
+			}
+
+			if tt.metricaErro != "" && deps.metrics.errorCount(tt.metricaErro) != 1 {
+				t.Errorf("métrica %s deveria ser incrementada", tt.metricaErro)
+			}
+		})
+	}
+}
+
+// outboxTransacaoRepository registra as gravações simples e as com outbox
+type outboxTransacaoRepository struct {
+	*fakeTransacaoRepository
+	saves  int
+	outbox []*domain.TransacaoEvento
+}
+
+func (r *outboxTransacaoRepository) Save(ctx context.Context, transacao *domain.Transacao) error {
+	r.saves++
+	return r.fakeTransacaoRepository.Save(ctx, transacao)
+}
+
+func (r *outboxTransacaoRepository) SaveComOutbox(ctx context.Context, transacao *domain.Transacao, evento *domain.TransacaoEvento) error {
+	r.outbox = append(r.outbox, evento)
+	return r.fakeTransacaoRepository.Save(ctx, transacao)
+}
+
+func TestAutorizarTransacao_OrdemPublicacao(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		semOutbox    bool
+		savesSimples int
+		noOutbox     int
+	}{
+		{name: "após salvar (padrão)", savesSimples: 1},
+		{name: "via outbox", opts: []Option{WithOrdemPublicacao(PublicarViaOutbox)}, noOutbox: 1},
+		{name: "via outbox sem suporte no repositório", opts: []Option{WithOrdemPublicacao(PublicarViaOutbox)}, semOutbox: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			repo := &outboxTransacaoRepository{fakeTransacaoRepository: deps.transacoes}
+			var transacoes domain.TransacaoRepository = repo
+			if tt.semOutbox {
+				transacoes = deps.transacoes
+			}
+			svc := NewTransacaoService(deps.limites, transacoes, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{}, tt.opts...)
+
+			transacao := domain.NewTransacao("c1", 10.00, "corr")
+			if err := autorizar(context.Background(), svc, transacao); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if repo.saves != tt.savesSimples || len(repo.outbox) != tt.noOutbox {
+				t.Fatalf("esperados %d saves simples e %d no outbox, got %d e %d", tt.savesSimples, tt.noOutbox, repo.saves, len(repo.outbox))
+			}
+			if salva, _ := deps.transacoes.GetByID(context.Background(), transacao.ID); salva == nil {
+				t.Fatal("transação deveria ser persistida")
+			}
+
+			// Via outbox a publicação fica com o relay
+			if tt.noOutbox > 0 {
+				time.Sleep(20 * time.Millisecond)
+				if n := deps.metrics.eventPublishCount("success"); n != 0 {
+					t.Errorf("evento gravado no outbox não deveria ser publicado diretamente, got %d publicações", n)
+				}
+				return
+			}
+			aguardar(t, func() bool { return deps.metrics.eventPublishCount("success") == 1 })
+		})
+	}
+}
+
+func TestRejeitarTransacao_OutboxRespeitaSupressao(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 1000, LimiteAtual: 1000})
+	repo := &outboxTransacaoRepository{fakeTransacaoRepository: deps.transacoes}
+	svc := NewTransacaoService(deps.limites, repo, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{},
+		WithOrdemPublicacao(PublicarViaOutbox), WithSupressaoEventosRejeicao())
+
+	autorizar(context.Background(), svc, domain.NewTransacao("c1", 50.00, "corr"))
+
+	if repo.saves != 1 || len(repo.outbox) != 0 {
+		t.Errorf("rejeição sem evento não deveria gravar no outbox: %d saves, %d no outbox", repo.saves, len(repo.outbox))
+	}
+}
+
+func TestPublicarEvento_SpanNoTraceDaRequisicao(t *testing.T) {
+	tests := []struct {
+		name     string
+		valor    float64
+		operacao string
+	}{
+		{name: "aprovação", valor: 10.00, operacao: "TransacaoService.publicarEvento"},
+		{name: "rejeição", valor: 5000.00, operacao: "TransacaoService.publicarEventoRejeicao"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			tracer := &tracerRegistrador{}
+			svc := NewTransacaoService(deps.limites, deps.transacoes, deps.publisher, deps.metrics, tracer, &fakeLogger{})
+
+			// A requisição termina (e seu contexto é cancelado) antes da publicação
+			ctx, cancel := context.WithCancel(context.Background())
+			autorizar(ctx, svc, domain.NewTransacao("c1", tt.valor, "corr"))
+			cancel()
+
+			aguardar(t, func() bool { return deps.metrics.eventPublishCount("success") == 1 })
+
+			origem := tracer.traceDe("TransacaoService.AutorizarTransacao")
+			if publicacao := tracer.traceDe(tt.operacao); publicacao == "" || publicacao != origem {
+				t.Errorf("span de publicação deveria estar no trace %s, got %q", origem, publicacao)
+			}
+		})
+	}
+}
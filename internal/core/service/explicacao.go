@@ -0,0 +1,350 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/observability/noop"
+	"context"
+	"errors"
+)
+
+// Resultados de uma etapa na explicação da decisão
+const (
+	EtapaPassou        = "PASSOU"
+	EtapaFalhou        = "FALHOU"
+	EtapaNaoAplicavel  = "NAO_APLICAVEL"
+	EtapaNaoReavaliada = "NAO_REAVALIADA"
+)
+
+// EtapaDecisao descreve o resultado de uma etapa da autorização e os valores
+// usados para chegar nele
+type EtapaDecisao struct {
+	Etapa     string                 `json:"etapa"`
+	Resultado string                 `json:"resultado"`
+	Motivo    string                 `json:"motivo,omitempty"`
+	Valores   map[string]interface{} `json:"valores,omitempty"`
+}
+
+// ExplicacaoDecisao é o rastro reconstruído da decisão de uma transação
+type ExplicacaoDecisao struct {
+	TransacaoID    string `json:"transacao_id"`
+	StatusOriginal string `json:"status_original"`
+	// Decisao é a decisão reconstruída (APROVADA ou REJEITADA)
+	Decisao string `json:"decisao"`
+	// Motivo é o erro da primeira etapa que falhou, como na autorização
+	Motivo string `json:"motivo,omitempty"`
+	// Confere indica se a decisão reconstruída coincide com a original
+	Confere bool           `json:"confere"`
+	Etapas  []EtapaDecisao `json:"etapas"`
+}
+
+// ExplicarDecisao reexecuta as regras da autorização de uma transação
+// persistida sem alterar estado: as próprias funções de AutorizarTransacao
+// rodam sobre uma cópia do serviço com repositórios somente leitura (débito
+// apenas simulado) e observabilidade descartada. Nada é salvo, debitado ou
+// publicado, e callbacks externos (step-up, pré-autorização) não são chamados.
+//
+// O cliente é lido no estado atual. Para transações aprovadas, o próprio
+// débito é devolvido ao limite disponível; débitos posteriores de outras
+// transações não são desfeitos, então a reconstrução é uma aproximação.
+// Use contra um ambiente de sandbox.
+func (s *transacaoService) ExplicarDecisao(ctx context.Context, transacaoID string) (*ExplicacaoDecisao, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.ExplicarDecisao")
+	defer s.tracer.FinishSpan(span, nil)
+
+	transacao, err := s.transacaoRepository.GetByID(ctx, transacaoID)
+	if err != nil {
+		return nil, err
+	}
+	if transacao == nil {
+		return nil, domain.ErrTransacaoNaoEncontrada
+	}
+
+	cliente, err := s.limiteRepository.GetCliente(ctx, transacao.ClienteID)
+	if err != nil && !errors.Is(err, domain.ErrClienteNaoEncontrado) {
+		return nil, err
+	}
+
+	// Leitura parcial (itens corrompidos) ainda permite avaliar os válidos
+	anteriores, err := s.transacaoRepository.GetByClienteID(ctx, transacao.ClienteID, limiteConsultaDiaria)
+	if err != nil && !errors.Is(err, domain.ErrItensCorrompidos) {
+		return nil, err
+	}
+
+	// As regras anotam a transação (tier, suspeita, limite disponível):
+	// trabalham sobre uma cópia para não alterar a original
+	reavaliada := *transacao
+	sandbox := s.sandboxExplicacao(cliente, &reavaliada, transacoesAnteriores(anteriores, transacao))
+
+	var etapas []EtapaDecisao
+	if s.verificacaoValorZero && transacao.EhVerificacao() {
+		etapas = []EtapaDecisao{resultadoEtapa("verificacao", sandbox.verificarCartao(ctx, &reavaliada), nil)}
+	} else {
+		etapas = sandbox.explicarEtapas(ctx, &reavaliada)
+	}
+
+	explicacao := &ExplicacaoDecisao{
+		TransacaoID:    transacao.ID,
+		StatusOriginal: transacao.Status,
+		Decisao:        domain.StatusAprovada,
+		Etapas:         etapas,
+	}
+	for _, etapa := range etapas {
+		if etapa.Resultado == EtapaFalhou {
+			explicacao.Decisao = domain.StatusRejeitada
+			explicacao.Motivo = etapa.Motivo
+			break
+		}
+	}
+	explicacao.Confere = explicacao.Decisao == transacao.Status
+
+	s.logger.Info(ctx, "decisão de transação explicada", map[string]interface{}{
+		"transacao_id":    transacao.ID,
+		"status_original": transacao.Status,
+		"decisao":         explicacao.Decisao,
+		"confere":         explicacao.Confere,
+	})
+
+	return explicacao, nil
+}
+
+// sandboxExplicacao copia o serviço trocando as dependências com efeito
+// colateral: limite e transações passam a responder do estado já lido, e
+// provisionamento, retentativa, eventos de limite e callbacks externos ficam
+// desligados
+func (s *transacaoService) sandboxExplicacao(cliente *domain.Cliente, transacao *domain.Transacao, anteriores []*domain.Transacao) *transacaoService {
+	_, chequeEspecial := s.limiteRepository.(domain.LimiteChequeEspecialRepository)
+
+	var limite *domain.Cliente
+	if cliente != nil {
+		copia := *cliente
+		// Desfaz o débito da própria transação para recompor o limite da decisão
+		if transacao.Status == domain.StatusAprovada {
+			copia.LimiteAtual += int(transacao.ValorCentavos())
+		}
+		limite = &copia
+	}
+
+	sandbox := *s
+	sandbox.limiteRepository = &limiteSomenteLeitura{cliente: limite, chequeEspecial: chequeEspecial}
+	sandbox.transacaoRepository = &transacoesSomenteLeitura{anteriores: anteriores}
+	sandbox.metricsCollector = noop.MetricsCollector{}
+	sandbox.tracer = noop.Tracer{}
+	sandbox.logger = noop.Logger{}
+	sandbox.limiteProvisionamento = 0
+	sandbox.prazoRetentativaDebito = 0
+	sandbox.limiteEventos = nil
+	return &sandbox
+}
+
+// explicarEtapas segue a ordem de AutorizarTransacao, avaliando todas as
+// etapas mesmo depois da primeira falha
+func (s *transacaoService) explicarEtapas(ctx context.Context, transacao *domain.Transacao) []EtapaDecisao {
+	valorCentavos := int(transacao.ValorCentavos())
+	cliente, _ := s.limiteRepository.GetCliente(ctx, transacao.ClienteID)
+
+	etapas := []EtapaDecisao{
+		resultadoEtapa("validacao", s.validarTransacao(ctx, transacao), map[string]interface{}{
+			"valor":      transacao.Valor,
+			"moeda":      transacao.MoedaOuPadrao(),
+			"timestamp":  transacao.Timestamp,
+			"cliente_id": transacao.ClienteID,
+			"parcelas":   transacao.Parcelas,
+		}),
+		s.explicarDuplicidade(ctx, transacao),
+		s.explicarPolitica(ctx, transacao),
+	}
+
+	etapaTier, politicaTier := s.explicarTier(ctx, transacao)
+	etapas = append(etapas,
+		etapaTier,
+		s.explicarParcelas(ctx, transacao, cliente),
+		s.explicarStepUp(transacao, valorCentavos),
+		s.explicarPreAutorizacao(),
+		s.explicarLimite(ctx, transacao, cliente, valorCentavos, politicaTier.ChequeEspecialCentavos),
+	)
+
+	return etapas
+}
+
+func (s *transacaoService) explicarDuplicidade(ctx context.Context, transacao *domain.Transacao) EtapaDecisao {
+	if s.janelaDuplicidade <= 0 {
+		return EtapaDecisao{Etapa: "duplicidade", Resultado: EtapaNaoAplicavel}
+	}
+
+	err := s.verificarDuplicidade(ctx, transacao)
+	return resultadoEtapa("duplicidade", err, map[string]interface{}{
+		"janela_ms":  s.janelaDuplicidade.Milliseconds(),
+		"sinalizada": transacao.SuspeitaDuplicidade,
+	})
+}
+
+func (s *transacaoService) explicarPolitica(ctx context.Context, transacao *domain.Transacao) EtapaDecisao {
+	if s.politicas == nil {
+		return EtapaDecisao{Etapa: "politica", Resultado: EtapaNaoAplicavel}
+	}
+
+	politica := s.politicas.obter(ctx, s.agora(), s.logger)
+	return resultadoEtapa("politica", s.aplicarPolitica(ctx, transacao), map[string]interface{}{
+		"valor_centavos":              int(transacao.ValorCentavos()),
+		"valor_maximo_centavos":       politica.ValorMaximoCentavos,
+		"multiplicador_limite_diario": politica.MultiplicadorLimiteDiario,
+	})
+}
+
+func (s *transacaoService) explicarTier(ctx context.Context, transacao *domain.Transacao) (EtapaDecisao, domain.PoliticaTier) {
+	if s.politicasTier == nil {
+		return EtapaDecisao{Etapa: "tier", Resultado: EtapaNaoAplicavel}, domain.PoliticaTier{}
+	}
+
+	politica, err := s.aplicarPoliticaTier(ctx, transacao)
+	return resultadoEtapa("tier", err, map[string]interface{}{
+		"tier":                        transacao.Tier,
+		"valor_centavos":              int(transacao.ValorCentavos()),
+		"valor_maximo_centavos":       politica.ValorMaximoCentavos,
+		"multiplicador_limite_diario": politica.MultiplicadorLimiteDiario,
+	}), politica
+}
+
+func (s *transacaoService) explicarParcelas(ctx context.Context, transacao *domain.Transacao, cliente *domain.Cliente) EtapaDecisao {
+	if transacao.NumeroParcelas() == 1 {
+		return EtapaDecisao{Etapa: "parcelas", Resultado: EtapaNaoAplicavel}
+	}
+
+	valores := map[string]interface{}{"parcelas": transacao.NumeroParcelas()}
+	if cliente != nil {
+		valores["max_permitido"] = s.maxParcelasCliente(cliente)
+	}
+	return resultadoEtapa("parcelas", s.verificarParcelas(ctx, transacao), valores)
+}
+
+// explicarStepUp não verifica token: ele não é persistido
+func (s *transacaoService) explicarStepUp(transacao *domain.Transacao, valorCentavos int) EtapaDecisao {
+	if s.limiteSuaveCentavos <= 0 || s.stepUp == nil || valorCentavos <= s.limiteSuaveCentavos || transacao.DataAgendamento != nil {
+		return EtapaDecisao{Etapa: "step_up", Resultado: EtapaNaoAplicavel}
+	}
+
+	return EtapaDecisao{
+		Etapa:     "step_up",
+		Resultado: EtapaNaoReavaliada,
+		Motivo:    "token de step-up não é persistido",
+		Valores: map[string]interface{}{
+			"valor_centavos":        valorCentavos,
+			"limite_suave_centavos": s.limiteSuaveCentavos,
+		},
+	}
+}
+
+// explicarPreAutorizacao não chama o callback externo
+func (s *transacaoService) explicarPreAutorizacao() EtapaDecisao {
+	if s.preAuth == nil {
+		return EtapaDecisao{Etapa: "pre_autorizacao", Resultado: EtapaNaoAplicavel}
+	}
+
+	return EtapaDecisao{
+		Etapa:     "pre_autorizacao",
+		Resultado: EtapaNaoReavaliada,
+		Motivo:    "callback externo não é chamado na explicação",
+	}
+}
+
+// explicarLimite simula o débito contra o limite recomposto da decisão
+func (s *transacaoService) explicarLimite(ctx context.Context, transacao *domain.Transacao, cliente *domain.Cliente, valorCentavos, chequeEspecial int) EtapaDecisao {
+	valores := map[string]interface{}{
+		"valor_centavos":           valorCentavos,
+		"cheque_especial_centavos": chequeEspecial,
+	}
+	if cliente != nil {
+		valores["limite_decisao_centavos"] = cliente.LimiteAtual
+	}
+	return resultadoEtapa("limite", s.processarLimite(ctx, transacao, chequeEspecial), valores)
+}
+
+func resultadoEtapa(nome string, err error, valores map[string]interface{}) EtapaDecisao {
+	if err != nil {
+		return EtapaDecisao{Etapa: nome, Resultado: EtapaFalhou, Motivo: err.Error(), Valores: valores}
+	}
+	return EtapaDecisao{Etapa: nome, Resultado: EtapaPassou, Valores: valores}
+}
+
+// transacoesAnteriores mantém só as transações que já existiam no momento
+// da decisão, excluindo a própria
+func transacoesAnteriores(transacoes []*domain.Transacao, transacao *domain.Transacao) []*domain.Transacao {
+	anteriores := make([]*domain.Transacao, 0, len(transacoes))
+	for _, t := range transacoes {
+		if t.ID == transacao.ID || t.Timestamp.After(transacao.Timestamp) {
+			continue
+		}
+		anteriores = append(anteriores, t)
+	}
+	return anteriores
+}
+
+// errExplicacaoSomenteLeitura indica tentativa de escrita durante a explicação
+var errExplicacaoSomenteLeitura = errors.New("explicação de decisão é somente leitura")
+
+// limiteSomenteLeitura responde com o cliente já lido; o débito apenas
+// verifica se o limite o comportaria, sem gravar
+type limiteSomenteLeitura struct {
+	cliente        *domain.Cliente
+	chequeEspecial bool
+}
+
+func (l *limiteSomenteLeitura) GetCliente(ctx context.Context, clienteID string) (*domain.Cliente, error) {
+	if l.cliente == nil {
+		return nil, domain.ErrClienteNaoEncontrado
+	}
+	copia := *l.cliente
+	return &copia, nil
+}
+
+func (l *limiteSomenteLeitura) UpdateLimite(ctx context.Context, clienteID string, novoLimite int) error {
+	return errExplicacaoSomenteLeitura
+}
+
+func (l *limiteSomenteLeitura) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	return l.simularDebito(valor, 0)
+}
+
+// DebitarLimiteComChequeEspecial só considera o cheque especial quando o
+// repositório real o suporta, como em debitar
+func (l *limiteSomenteLeitura) DebitarLimiteComChequeEspecial(ctx context.Context, clienteID string, valor, chequeEspecial int) (int, error) {
+	if !l.chequeEspecial {
+		chequeEspecial = 0
+	}
+	return l.simularDebito(valor, chequeEspecial)
+}
+
+func (l *limiteSomenteLeitura) simularDebito(valor, chequeEspecial int) (int, error) {
+	if l.cliente == nil {
+		return 0, domain.ErrClienteNaoEncontrado
+	}
+	if !(domain.LimitePolicy{ChequeEspecial: chequeEspecial}).PermiteLimiteAtual(l.cliente.LimiteAtual, valor) {
+		return 0, domain.ErrLimiteInsuficiente
+	}
+	return l.cliente.LimiteAtual - valor, nil
+}
+
+// transacoesSomenteLeitura devolve as transações anteriores à decisão
+type transacoesSomenteLeitura struct {
+	anteriores []*domain.Transacao
+}
+
+func (t *transacoesSomenteLeitura) Save(ctx context.Context, transacao *domain.Transacao) error {
+	return errExplicacaoSomenteLeitura
+}
+
+func (t *transacoesSomenteLeitura) GetByID(ctx context.Context, transacaoID string) (*domain.Transacao, error) {
+	for _, anterior := range t.anteriores {
+		if anterior.ID == transacaoID {
+			return anterior, nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *transacoesSomenteLeitura) GetByClienteID(ctx context.Context, clienteID string, limit int) ([]*domain.Transacao, error) {
+	if limit > 0 && len(t.anteriores) > limit {
+		return t.anteriores[:limit], nil
+	}
+	return t.anteriores, nil
+}
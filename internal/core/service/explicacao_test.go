@@ -0,0 +1,172 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExplicarDecisao_ConfereComDecisaoOriginal(t *testing.T) {
+	tests := []struct {
+		name        string
+		tier        string
+		limiteAtual int
+		valor       float64
+		parcelas    int
+		anterior    float64
+		expectedErr error
+		etapa       string
+	}{
+		{name: "aprovada", valor: 100.00, limiteAtual: 50000},
+		{name: "limite insuficiente", valor: 600.00, limiteAtual: 50000, expectedErr: domain.ErrLimiteInsuficiente, etapa: "limite"},
+		{name: "acima do máximo do tier", tier: "BASICO", valor: 600.00, limiteAtual: 100000, expectedErr: domain.ErrValorAcimaDoMaximo, etapa: "tier"},
+		{name: "limite diário do tier", tier: "BASICO", valor: 200.00, anterior: 400.00, limiteAtual: 60000, expectedErr: domain.ErrLimiteDiarioExcedido, etapa: "tier"},
+		{name: "duplicidade", valor: 100.00, anterior: 100.00, limiteAtual: 50000, expectedErr: domain.ErrTransacaoDuplicadaSuspeita, etapa: "duplicidade"},
+		{name: "parcelas acima do permitido", valor: 100.00, parcelas: 24, limiteAtual: 50000, expectedErr: domain.ErrParcelasAcimaDoPermitido, etapa: "parcelas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", Tier: tt.tier, LimiteCredit: 100000, LimiteAtual: tt.limiteAtual})
+			if tt.anterior > 0 {
+				anterior := domain.NewTransacao("c1", tt.anterior, "corr-anterior")
+				anterior.Timestamp = time.Now().Add(-time.Second)
+				anterior.Aprovar()
+				deps.transacoes = newFakeTransacaoRepository(anterior)
+			}
+			svc := deps.service(politicasTierTeste(), WithDeteccaoDuplicidade(10*time.Second, DuplicidadeRejeitar))
+
+			transacao := domain.NewTransacao("c1", tt.valor, "corr")
+			transacao.Parcelas = tt.parcelas
+			if err := autorizar(context.Background(), svc, transacao); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+			debitos := deps.limites.debitos
+			totalTransacoes := len(deps.transacoes.transacoes)
+
+			explicacao, err := svc.ExplicarDecisao(context.Background(), transacao.ID)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if !explicacao.Confere || explicacao.Decisao != transacao.Status {
+				t.Fatalf("decisão reconstruída %s diverge da original %s: %+v", explicacao.Decisao, transacao.Status, explicacao.Etapas)
+			}
+
+			if tt.expectedErr != nil {
+				if explicacao.Motivo != tt.expectedErr.Error() {
+					t.Errorf("motivo esperado %q, got %q", tt.expectedErr.Error(), explicacao.Motivo)
+				}
+				if falha := primeiraFalha(explicacao); falha == nil || falha.Etapa != tt.etapa {
+					t.Errorf("etapa decisiva esperada %s, got %+v", tt.etapa, falha)
+				}
+			}
+
+			// Explicação não altera estado
+			depois, _ := deps.limites.GetCliente(context.Background(), "c1")
+			if depois.LimiteAtual != cliente.LimiteAtual || deps.limites.debitos != debitos {
+				t.Errorf("limite alterado pela explicação: %d -> %d", cliente.LimiteAtual, depois.LimiteAtual)
+			}
+			if len(deps.transacoes.transacoes) != totalTransacoes {
+				t.Errorf("explicação não deveria salvar transações")
+			}
+		})
+	}
+}
+
+// A explicação roda as mesmas regras da autorização: moeda e duplicidade
+// comparam todos os campos, não só o valor
+func TestExplicarDecisao_MesmasRegrasDaAutorizacao(t *testing.T) {
+	tests := []struct {
+		name        string
+		moeda       string
+		expectedErr error
+		etapa       string
+	}{
+		{name: "mesmo valor em outra moeda não é duplicidade", moeda: "USD"},
+		{name: "mesmo valor e moeda é duplicidade", moeda: "BRL", expectedErr: domain.ErrTransacaoDuplicadaSuspeita, etapa: "duplicidade"},
+		{name: "moeda não suportada", moeda: "EUR", expectedErr: domain.ErrMoedaNaoSuportada, etapa: "validacao"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			anterior := domain.NewTransacao("c1", 100.00, "corr-anterior")
+			anterior.Timestamp = time.Now().Add(-time.Second)
+			anterior.Aprovar()
+			deps.transacoes = newFakeTransacaoRepository(anterior)
+			svc := deps.service(WithMoedasSuportadas("BRL", "USD"), WithDeteccaoDuplicidade(10*time.Second, DuplicidadeRejeitar))
+
+			transacao := domain.NewTransacao("c1", 100.00, "corr")
+			transacao.Moeda = tt.moeda
+			if err := autorizar(context.Background(), svc, transacao); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			explicacao, err := svc.ExplicarDecisao(context.Background(), transacao.ID)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if !explicacao.Confere {
+				t.Fatalf("decisão reconstruída %s diverge da original %s: %+v", explicacao.Decisao, transacao.Status, explicacao.Etapas)
+			}
+			if tt.expectedErr != nil {
+				if falha := primeiraFalha(explicacao); falha == nil || falha.Etapa != tt.etapa {
+					t.Errorf("etapa decisiva esperada %s, got %+v", tt.etapa, falha)
+				}
+			}
+		})
+	}
+}
+
+func TestExplicarDecisao_CallbacksExternosNaoReavaliados(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	preAuth := &fakePreAuth{aprovada: false}
+	svc := deps.service(WithPreAutorizacao(preAuth, time.Second, FalhaFechada))
+
+	transacao := domain.NewTransacao("c1", 100.00, "corr")
+	if err := autorizar(context.Background(), svc, transacao); err != domain.ErrPreAutorizacaoNegada {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrPreAutorizacaoNegada, err)
+	}
+
+	chamadas := preAuth.chamadas
+	explicacao, err := svc.ExplicarDecisao(context.Background(), transacao.ID)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if preAuth.chamadas != chamadas {
+		t.Error("explicação não deveria chamar o callback de pré-autorização")
+	}
+
+	if explicacao.Confere {
+		t.Error("veto externo não é reconstruível; decisão não deveria conferir")
+	}
+
+	for _, etapa := range explicacao.Etapas {
+		if etapa.Etapa == "pre_autorizacao" && etapa.Resultado != EtapaNaoReavaliada {
+			t.Errorf("pré-autorização deveria ser %s, got %s", EtapaNaoReavaliada, etapa.Resultado)
+		}
+	}
+}
+
+func TestExplicarDecisao_TransacaoInexistente(t *testing.T) {
+	svc := newDependencias().service()
+
+	if _, err := svc.ExplicarDecisao(context.Background(), "nao-existe"); err != domain.ErrTransacaoNaoEncontrada {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrTransacaoNaoEncontrada, err)
+	}
+}
+
+func primeiraFalha(explicacao *ExplicacaoDecisao) *EtapaDecisao {
+	for i := range explicacao.Etapas {
+		if explicacao.Etapas[i].Resultado == EtapaFalhou {
+			return &explicacao.Etapas[i]
+		}
+	}
+	return nil
+}
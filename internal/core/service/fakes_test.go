@@ -0,0 +1,569 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// fakeLimiteRepository mantém limites em memória, inteiramente em centavos
+// (int): nenhuma comparação de limite usa float
+type fakeLimiteRepository struct {
+	mu       sync.Mutex
+	clientes map[string]*domain.Cliente
+	debitos  int
+}
+
+func newFakeLimiteRepository(clientes ...*domain.Cliente) *fakeLimiteRepository {
+	repo := &fakeLimiteRepository{clientes: make(map[string]*domain.Cliente)}
+	for _, c := range clientes {
+		repo.clientes[c.ID] = c
+	}
+	return repo
+}
+
+func (r *fakeLimiteRepository) GetCliente(ctx context.Context, clienteID string) (*domain.Cliente, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok {
+		return nil, domain.ErrClienteNaoEncontrado
+	}
+	copia := *cliente
+	return &copia, nil
+}
+
+func (r *fakeLimiteRepository) UpdateLimite(ctx context.Context, clienteID string, novoLimite int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok {
+		return domain.ErrClienteNaoEncontrado
+	}
+	cliente.LimiteAtual = novoLimite
+	return nil
+}
+
+func (r *fakeLimiteRepository) AjustarLimite(ctx context.Context, clienteID string, lido, novoLimite int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok || cliente.LimiteAtual != lido {
+		return domain.ErrLimiteAlteradoConcorrente
+	}
+	cliente.LimiteAtual = novoLimite
+	return nil
+}
+
+func (r *fakeLimiteRepository) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok {
+		return 0, domain.ErrClienteNaoEncontrado
+	}
+	if cliente.LimiteAtual < valor {
+		return 0, domain.ErrLimiteInsuficiente
+	}
+	cliente.LimiteAtual -= valor
+	r.debitos++
+	return cliente.LimiteAtual, nil
+}
+
+func (r *fakeLimiteRepository) DebitarLimiteComChequeEspecial(ctx context.Context, clienteID string, valor, chequeEspecial int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok {
+		return 0, domain.ErrClienteNaoEncontrado
+	}
+	if cliente.LimiteAtual+chequeEspecial < valor {
+		return 0, domain.ErrLimiteInsuficiente
+	}
+	cliente.LimiteAtual -= valor
+	r.debitos++
+	return cliente.LimiteAtual, nil
+}
+
+func (r *fakeLimiteRepository) ProvisionarCliente(ctx context.Context, clienteID string, limiteCentavos int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clientes[clienteID]; ok {
+		return false, nil
+	}
+	r.clientes[clienteID] = &domain.Cliente{ID: clienteID, LimiteCredit: limiteCentavos, LimiteAtual: limiteCentavos}
+	return true, nil
+}
+
+// fakeTransacaoRepository guarda transações em memória. IDs em
+// indiceAtrasado simulam o atraso de propagação do GSI em GetByClienteID.
+type fakeTransacaoRepository struct {
+	mu             sync.Mutex
+	transacoes     map[string]*domain.Transacao
+	indiceAtrasado map[string]bool
+}
+
+func newFakeTransacaoRepository(transacoes ...*domain.Transacao) *fakeTransacaoRepository {
+	repo := &fakeTransacaoRepository{
+		transacoes:     make(map[string]*domain.Transacao),
+		indiceAtrasado: make(map[string]bool),
+	}
+	for _, t := range transacoes {
+		repo.transacoes[t.ID] = t
+	}
+	return repo
+}
+
+func (r *fakeTransacaoRepository) Save(ctx context.Context, transacao *domain.Transacao) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copia := *transacao
+	r.transacoes[transacao.ID] = &copia
+	return nil
+}
+
+func (r *fakeTransacaoRepository) GetByID(ctx context.Context, transacaoID string) (*domain.Transacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transacao, ok := r.transacoes[transacaoID]
+	if !ok {
+		return nil, nil
+	}
+	copia := *transacao
+	return &copia, nil
+}
+
+func (r *fakeTransacaoRepository) GetByClienteID(ctx context.Context, clienteID string, limit int) ([]*domain.Transacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transacoes := make([]*domain.Transacao, 0)
+	for _, t := range r.transacoes {
+		if t.ClienteID == clienteID && !r.indiceAtrasado[t.ID] {
+			copia := *t
+			transacoes = append(transacoes, &copia)
+		}
+	}
+	return transacoes, nil
+}
+
+func (r *fakeTransacaoRepository) PercorrerPorClienteID(ctx context.Context, clienteID string, tamanhoPagina int, fn func([]*domain.Transacao) error) error {
+	transacoes, _ := r.GetByClienteID(ctx, clienteID, 0)
+	for inicio := 0; inicio < len(transacoes); inicio += tamanhoPagina {
+		fim := inicio + tamanhoPagina
+		if fim > len(transacoes) {
+			fim = len(transacoes)
+		}
+		if err := fn(transacoes[inicio:fim]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeTransacaoRepository) ListarAprovadasNoPeriodo(ctx context.Context, inicio, fim time.Time) ([]*domain.Transacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transacoes := make([]*domain.Transacao, 0)
+	for _, t := range r.transacoes {
+		if t.Status == domain.StatusAprovada && !t.Timestamp.Before(inicio) && !t.Timestamp.After(fim) {
+			copia := *t
+			transacoes = append(transacoes, &copia)
+		}
+	}
+	return transacoes, nil
+}
+
+func (r *fakeTransacaoRepository) ListarAgendadasAte(ctx context.Context, ate time.Time) ([]*domain.Transacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transacoes := make([]*domain.Transacao, 0)
+	for _, t := range r.transacoes {
+		if t.Status == domain.StatusAgendada && t.DataAgendamento != nil && !t.DataAgendamento.After(ate) {
+			copia := *t
+			transacoes = append(transacoes, &copia)
+		}
+	}
+	return transacoes, nil
+}
+
+func (r *fakeTransacaoRepository) IniciarExecucao(ctx context.Context, transacao *domain.Transacao) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	atual, ok := r.transacoes[transacao.ID]
+	if !ok || atual.Status != domain.StatusAgendada {
+		return domain.ErrAgendamentoJaIniciado
+	}
+	atual.Status = domain.StatusPendente
+	return nil
+}
+
+// fakeAckStore guarda confirmações de publicação em memória
+type fakeAckStore struct {
+	mu          sync.Mutex
+	confirmados map[string]bool
+}
+
+func newFakeAckStore() *fakeAckStore {
+	return &fakeAckStore{confirmados: make(map[string]bool)}
+}
+
+func (f *fakeAckStore) RegistrarConfirmacao(ctx context.Context, transacaoID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.confirmados[transacaoID] = true
+	return nil
+}
+
+func (f *fakeAckStore) Confirmados(ctx context.Context, transacaoIDs []string) (map[string]bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	resultado := make(map[string]bool)
+	for _, id := range transacaoIDs {
+		if f.confirmados[id] {
+			resultado[id] = true
+		}
+	}
+	return resultado, nil
+}
+
+func (f *fakeAckStore) total() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.confirmados)
+}
+
+// fakeEventPublisher registra os eventos publicados e sinaliza cada publicação
+type fakeEventPublisher struct {
+	mu         sync.Mutex
+	aprovados  []*domain.TransacaoEvento
+	rejeitados []*domain.TransacaoEvento
+	err        error
+	publicados chan struct{}
+}
+
+func newFakeEventPublisher() *fakeEventPublisher {
+	return &fakeEventPublisher{publicados: make(chan struct{}, 100)}
+}
+
+func (p *fakeEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	p.mu.Lock()
+	p.aprovados = append(p.aprovados, evento)
+	p.mu.Unlock()
+
+	p.publicados <- struct{}{}
+	return p.err
+}
+
+func (p *fakeEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	p.mu.Lock()
+	p.rejeitados = append(p.rejeitados, evento)
+	p.mu.Unlock()
+
+	p.publicados <- struct{}{}
+	return p.err
+}
+
+// fakeMetricsCollector registra as métricas recebidas
+type fakeMetricsCollector struct {
+	mu           sync.Mutex
+	transactions map[string]int
+	errors       map[string]int
+	outcomes     map[string]int
+	business     []businessMetric
+	eventPublish map[string]int
+	// rejectedValues guarda os valores rejeitados por motivo
+	rejectedValues map[string][]float64
+}
+
+type businessMetric struct {
+	name   string
+	value  float64
+	labels map[string]string
+}
+
+func newFakeMetricsCollector() *fakeMetricsCollector {
+	return &fakeMetricsCollector{
+		transactions: make(map[string]int),
+		errors:       make(map[string]int),
+		outcomes:     make(map[string]int),
+		eventPublish: make(map[string]int),
+
+		rejectedValues: make(map[string][]float64),
+	}
+}
+
+func (m *fakeMetricsCollector) IncrementTransactionCounter(status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transactions[status]++
+}
+
+func (m *fakeMetricsCollector) RecordTransactionLatency(duration float64) {}
+
+func (m *fakeMetricsCollector) RecordBusinessMetric(metricName string, value float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.business = append(m.business, businessMetric{name: metricName, value: value, labels: labels})
+}
+
+func (m *fakeMetricsCollector) IncrementErrorCounter(errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[errorType]++
+}
+
+func (m *fakeMetricsCollector) IncrementOutcomeCounter(outcome string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[outcome]++
+}
+
+func (m *fakeMetricsCollector) RecordEventPublish(result string, duration float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventPublish[result]++
+}
+
+func (m *fakeMetricsCollector) RecordRejectedValue(reason string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rejectedValues[reason] = append(m.rejectedValues[reason], value)
+}
+
+func (m *fakeMetricsCollector) eventPublishCount(result string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.eventPublish[result]
+}
+
+func (m *fakeMetricsCollector) errorCount(errorType string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.errors[errorType]
+}
+
+func (m *fakeMetricsCollector) outcomeCount(outcome string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.outcomes[outcome]
+}
+
+// fakeTracer não emite spans
+type fakeTracer struct{}
+
+func (t *fakeTracer) StartSpan(ctx context.Context, operationName string) (context.Context, interface{}) {
+	return ctx, nil
+}
+func (t *fakeTracer) FinishSpan(span interface{}, err error)                 {}
+func (t *fakeTracer) AddTag(span interface{}, key string, value interface{}) {}
+func (t *fakeTracer) ExtractTraceID(ctx context.Context) string              { return "" }
+
+// fakeLogger descarta logs
+type fakeLogger struct{}
+
+func (l *fakeLogger) Info(ctx context.Context, msg string, fields map[string]interface{})  {}
+func (l *fakeLogger) Warn(ctx context.Context, msg string, fields map[string]interface{})  {}
+func (l *fakeLogger) Debug(ctx context.Context, msg string, fields map[string]interface{}) {}
+func (l *fakeLogger) Error(ctx context.Context, msg string, err error, fields map[string]interface{}) {
+}
+
+// dependencias agrupa os fakes usados para montar o serviço nos testes
+type dependencias struct {
+	limites    *fakeLimiteRepository
+	transacoes *fakeTransacaoRepository
+	publisher  *fakeEventPublisher
+	metrics    *fakeMetricsCollector
+	// tracer substitui o fakeTracer quando definido
+	tracer domain.DistributedTracer
+}
+
+func newDependencias(clientes ...*domain.Cliente) *dependencias {
+	return &dependencias{
+		limites:    newFakeLimiteRepository(clientes...),
+		transacoes: newFakeTransacaoRepository(),
+		publisher:  newFakeEventPublisher(),
+		metrics:    newFakeMetricsCollector(),
+	}
+}
+
+func (d *dependencias) service(opts ...Option) TransacaoService {
+	var tracer domain.DistributedTracer = &fakeTracer{}
+	if d.tracer != nil {
+		tracer = d.tracer
+	}
+	return NewTransacaoService(d.limites, d.transacoes, d.publisher, d.metrics, tracer, &fakeLogger{}, opts...)
+}
+
+// hasherFixo devolve sempre a mesma chave
+type hasherFixo string
+
+func (h hasherFixo) Chave(campos domain.CamposChave) string {
+	return string(h)
+}
+
+// chaveTrace guarda no contexto o trace do tracerRegistrador
+type chaveTrace struct{}
+
+// spanRegistrado é um span iniciado pelo tracerRegistrador
+type spanRegistrado struct {
+	operacao string
+	traceID  string
+}
+
+// tracerRegistrador propaga o trace pelo contexto e registra os spans iniciados
+type tracerRegistrador struct {
+	mu      sync.Mutex
+	proximo int
+	spans   []spanRegistrado
+}
+
+func (t *tracerRegistrador) StartSpan(ctx context.Context, operationName string) (context.Context, interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	traceID, ok := ctx.Value(chaveTrace{}).(string)
+	if !ok {
+		t.proximo++
+		traceID = fmt.Sprintf("trace-%d", t.proximo)
+	}
+	t.spans = append(t.spans, spanRegistrado{operacao: operationName, traceID: traceID})
+	return context.WithValue(ctx, chaveTrace{}, traceID), nil
+}
+func (t *tracerRegistrador) FinishSpan(span interface{}, err error)                 {}
+func (t *tracerRegistrador) AddTag(span interface{}, key string, value interface{}) {}
+func (t *tracerRegistrador) ExtractTraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(chaveTrace{}).(string)
+	return traceID
+}
+
+// traceDe retorna o trace do primeiro span da operação (vazio se ausente)
+func (t *tracerRegistrador) traceDe(operacao string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		if span.operacao == operacao {
+			return span.traceID
+		}
+	}
+	return ""
+}
+
+// autorizar devolve como erro a recusa de negócio ou a falha de
+// infraestrutura, para testes que só verificam o motivo
+func autorizar(ctx context.Context, svc TransacaoService, transacao *domain.Transacao) error {
+	resultado, err := svc.AutorizarTransacao(ctx, transacao)
+	if err != nil {
+		return err
+	}
+	return resultado.Motivo
+}
+
+// fakeIdempotencyStore guarda os registros em memória, com a expiração
+// calculada pelo relógio agora (ajustável pelos testes)
+type fakeIdempotencyStore struct {
+	mu        sync.Mutex
+	agora     time.Time
+	registros map[string]fakeRegistroIdempotencia
+	errStore  error
+}
+
+type fakeRegistroIdempotencia struct {
+	registro domain.RegistroIdempotencia
+	expiraEm time.Time
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{agora: time.Now(), registros: make(map[string]fakeRegistroIdempotencia)}
+}
+
+func (s *fakeIdempotencyStore) Get(ctx context.Context, chave string) (*domain.RegistroIdempotencia, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.errStore != nil {
+		return nil, s.errStore
+	}
+	salvo, ok := s.vigente(chave)
+	if !ok {
+		return nil, nil
+	}
+	registro := salvo.registro
+	return &registro, nil
+}
+
+func (s *fakeIdempotencyStore) Reservar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.errStore != nil {
+		return s.errStore
+	}
+	if _, ok := s.vigente(registro.Chave); ok {
+		return domain.ErrReservaIdempotencia
+	}
+	s.registros[registro.Chave] = fakeRegistroIdempotencia{registro: *registro, expiraEm: s.agora.Add(ttl)}
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Finalizar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.errStore != nil {
+		return s.errStore
+	}
+	if !s.reservadaPor(registro.Chave, registro.TransacaoID) {
+		return domain.ErrReservaIdempotencia
+	}
+	s.registros[registro.Chave] = fakeRegistroIdempotencia{registro: *registro, expiraEm: s.agora.Add(ttl)}
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Liberar(ctx context.Context, chave, transacaoID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.errStore != nil {
+		return s.errStore
+	}
+	if !s.reservadaPor(chave, transacaoID) {
+		return domain.ErrReservaIdempotencia
+	}
+	delete(s.registros, chave)
+	return nil
+}
+
+// gravar grava o registro diretamente, sem reserva
+func (s *fakeIdempotencyStore) gravar(registro domain.RegistroIdempotencia, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registros[registro.Chave] = fakeRegistroIdempotencia{registro: registro, expiraEm: s.agora.Add(ttl)}
+}
+
+func (s *fakeIdempotencyStore) vigente(chave string) (fakeRegistroIdempotencia, bool) {
+	salvo, ok := s.registros[chave]
+	return salvo, ok && s.agora.Before(salvo.expiraEm)
+}
+
+func (s *fakeIdempotencyStore) reservadaPor(chave, transacaoID string) bool {
+	salvo, ok := s.vigente(chave)
+	return ok && salvo.registro.Status == domain.StatusIdempotenciaEmProcessamento && salvo.registro.TransacaoID == transacaoID
+}
+
+// avancar move o relógio do store
+func (s *fakeIdempotencyStore) avancar(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.agora = s.agora.Add(d)
+}
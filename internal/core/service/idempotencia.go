@@ -0,0 +1,157 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"time"
+)
+
+// ttlIdempotenciaPadrao é a retenção dos registros de idempotência: longa o
+// bastante para cobrir as retentativas do cliente sem crescer sem limite
+const ttlIdempotenciaPadrao = 24 * time.Hour
+
+// chaveIdempotencia deriva a chave do registro, com escopo no cliente; vazia
+// quando a idempotência está desligada ou a requisição não trouxe chave
+func (s *transacaoService) chaveIdempotencia(transacao *domain.Transacao) string {
+	if s.idempotencia == nil || transacao.ChaveIdempotencia == "" {
+		return ""
+	}
+	return s.keyHasher.Chave(domain.CamposIdempotencia(transacao.ClienteID, transacao.ChaveIdempotencia))
+}
+
+// ttlReservaIdempotencia é a validade da reserva de uma chave em
+// processamento: cobre a duração de uma autorização e libera a chave se a
+// instância cair antes de finalizar
+const ttlReservaIdempotencia = 5 * time.Minute
+
+// reservarIdempotencia reserva a chave antes de autorizar, para que
+// requisições concorrentes com a mesma chave não sejam processadas em
+// paralelo. Devolve o Resultado a responder quando a chave já existe e se a
+// reserva foi feita. Falhas no store não bloqueiam a autorização.
+func (s *transacaoService) reservarIdempotencia(ctx context.Context, chave string, transacao *domain.Transacao) (*Resultado, bool) {
+	reserva := &domain.RegistroIdempotencia{
+		Chave:       chave,
+		TransacaoID: transacao.ID,
+		Status:      domain.StatusIdempotenciaEmProcessamento,
+		HashPayload: s.hashPayload(transacao),
+	}
+	err := s.idempotencia.Reservar(ctx, reserva, ttlReservaIdempotencia)
+	if err == nil {
+		return nil, true
+	}
+	if !errors.Is(err, domain.ErrReservaIdempotencia) {
+		s.falhaIdempotencia(ctx, "erro ao reservar chave de idempotência", err, transacao)
+		return nil, false
+	}
+	return s.resultadoAnterior(ctx, chave, transacao), false
+}
+
+// resultadoAnterior responde à chave já reservada: o desfecho original
+// aplicado à transação (ID e status originais), a recusa por conflito de
+// payload ou, enquanto a requisição original não termina, a recusa por
+// requisição em processamento.
+func (s *transacaoService) resultadoAnterior(ctx context.Context, chave string, transacao *domain.Transacao) *Resultado {
+	registro, err := s.idempotencia.Get(ctx, chave)
+	if err != nil {
+		// A chave existe: sem ler o desfecho, processar poderia debitar de novo
+		s.falhaIdempotencia(ctx, "erro ao consultar registro de idempotência", err, transacao)
+		return s.recusaIdempotencia(transacao, domain.ErrIdempotenciaEmProcessamento)
+	}
+	if registro == nil {
+		// Reserva liberada ou expirada entre a tentativa e a leitura
+		return s.recusaIdempotencia(transacao, domain.ErrIdempotenciaEmProcessamento)
+	}
+
+	if registro.HashPayload != "" && registro.HashPayload != s.hashPayload(transacao) {
+		s.logger.Warn(ctx, "chave de idempotência reutilizada com payload diferente", map[string]interface{}{
+			"transacao_id":          transacao.ID,
+			"transacao_original_id": registro.TransacaoID,
+			"cliente_id":            transacao.ClienteID,
+		})
+		s.metricsCollector.IncrementErrorCounter("idempotency_key_conflict")
+		return s.recusaIdempotencia(transacao, domain.ErrConflitoIdempotencia)
+	}
+
+	if registro.Status == domain.StatusIdempotenciaEmProcessamento {
+		s.logger.Warn(ctx, "requisição repetida com a original em processamento", map[string]interface{}{
+			"transacao_id":          transacao.ID,
+			"transacao_original_id": registro.TransacaoID,
+			"cliente_id":            transacao.ClienteID,
+		})
+		s.metricsCollector.IncrementErrorCounter("idempotency_key_in_progress")
+		return s.recusaIdempotencia(transacao, domain.ErrIdempotenciaEmProcessamento)
+	}
+
+	transacao.ID = registro.TransacaoID
+	transacao.Status = registro.Status
+	transacao.CodigoMotivo = registro.CodigoMotivo
+	transacao.LimiteDisponivel = registro.LimiteDisponivel
+
+	s.logger.Info(ctx, "requisição repetida, devolvido o desfecho original", map[string]interface{}{
+		"transacao_id": registro.TransacaoID,
+		"cliente_id":   transacao.ClienteID,
+		"status":       registro.Status,
+	})
+	s.metricsCollector.IncrementOutcomeCounter("idempotent_replay")
+
+	return &Resultado{
+		Status:           registro.Status,
+		CodigoMotivo:     registro.CodigoMotivo,
+		Motivo:           motivoDoCodigo(registro.CodigoMotivo),
+		LimiteDisponivel: registro.LimiteDisponivel,
+	}
+}
+
+// recusaIdempotencia recusa a requisição sem processá-la
+func (s *transacaoService) recusaIdempotencia(transacao *domain.Transacao, motivo error) *Resultado {
+	transacao.Status = domain.StatusRejeitada
+	transacao.CodigoMotivo = codigoMotivo(motivo)
+	return &Resultado{
+		Status:       domain.StatusRejeitada,
+		CodigoMotivo: transacao.CodigoMotivo,
+		Motivo:       motivo,
+	}
+}
+
+// concluirIdempotencia finaliza a reserva com o desfecho definitivo. Falha de
+// infraestrutura e step-up pendente liberam a chave: a repetição (com o token,
+// no step-up) deve ser processada. Escrita com resultado indeterminado mantém
+// a reserva até expirar, para que a repetição não debite de novo.
+func (s *transacaoService) concluirIdempotencia(ctx context.Context, chave string, transacao *domain.Transacao, resultado *Resultado, err error) {
+	if errors.Is(err, domain.ErrResultadoIndeterminado) {
+		return
+	}
+	if err != nil || resultado.Status == domain.StatusPendente {
+		if err := s.idempotencia.Liberar(ctx, chave, transacao.ID); err != nil {
+			s.falhaIdempotencia(ctx, "erro ao liberar chave de idempotência", err, transacao)
+		}
+		return
+	}
+
+	registro := &domain.RegistroIdempotencia{
+		Chave:            chave,
+		TransacaoID:      transacao.ID,
+		Status:           resultado.Status,
+		CodigoMotivo:     resultado.CodigoMotivo,
+		LimiteDisponivel: resultado.LimiteDisponivel,
+		HashPayload:      s.hashPayload(transacao),
+	}
+	if err := s.idempotencia.Finalizar(ctx, registro, s.ttlIdempotencia); err != nil {
+		s.falhaIdempotencia(ctx, "erro ao gravar registro de idempotência", err, transacao)
+	}
+}
+
+func (s *transacaoService) falhaIdempotencia(ctx context.Context, mensagem string, err error, transacao *domain.Transacao) {
+	s.logger.Error(ctx, mensagem, err, map[string]interface{}{
+		"transacao_id": transacao.ID,
+		"cliente_id":   transacao.ClienteID,
+	})
+	s.metricsCollector.IncrementErrorCounter("idempotency_store_error")
+}
+
+// hashPayload identifica o payload da requisição para comparar reutilizações
+// da chave de idempotência
+func (s *transacaoService) hashPayload(transacao *domain.Transacao) string {
+	return s.keyHasher.Chave(domain.CamposPayloadIdempotencia(transacao))
+}
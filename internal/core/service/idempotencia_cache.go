@@ -0,0 +1,140 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// cacheIdempotencia guarda em memória os desfechos finalizados mais recentes
+// na frente do store durável: repetições imediatas na mesma instância
+// (retentativas do cliente) são respondidas sem acessar o store. Reservas em
+// processamento nunca entram no cache, só desfechos definitivos.
+type cacheIdempotencia struct {
+	store      domain.IdempotencyStore
+	capacidade int
+	ttl        time.Duration
+	agora      func() time.Time
+
+	mu       sync.Mutex
+	entradas map[string]*list.Element
+	// recentes ordena as entradas do uso mais recente (frente) ao mais antigo
+	recentes *list.List
+}
+
+type entradaCacheIdempotencia struct {
+	registro domain.RegistroIdempotencia
+	expiraEm time.Time
+}
+
+// NewCacheIdempotencia envolve o store com um cache LRU de até capacidade
+// desfechos, cada um válido por ttl (limitado ao ttl do próprio registro).
+// Capacidade ou ttl não positivos devolvem o store sem cache.
+func NewCacheIdempotencia(store domain.IdempotencyStore, capacidade int, ttl time.Duration) domain.IdempotencyStore {
+	if capacidade <= 0 || ttl <= 0 {
+		return store
+	}
+	return &cacheIdempotencia{
+		store:      store,
+		capacidade: capacidade,
+		ttl:        ttl,
+		agora:      time.Now,
+		entradas:   make(map[string]*list.Element),
+		recentes:   list.New(),
+	}
+}
+
+func (c *cacheIdempotencia) Get(ctx context.Context, chave string) (*domain.RegistroIdempotencia, error) {
+	if registro, ok := c.obter(chave); ok {
+		return registro, nil
+	}
+
+	registro, err := c.store.Get(ctx, chave)
+	if err == nil && registro != nil {
+		c.guardar(registro, c.ttl)
+	}
+	return registro, err
+}
+
+// Reservar recusa sem acessar o store a chave com desfecho em cache: o
+// registro durável existe, então a reserva falharia de qualquer forma
+func (c *cacheIdempotencia) Reservar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	if _, ok := c.obter(registro.Chave); ok {
+		return domain.ErrReservaIdempotencia
+	}
+	return c.store.Reservar(ctx, registro, ttl)
+}
+
+func (c *cacheIdempotencia) Finalizar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	if err := c.store.Finalizar(ctx, registro, ttl); err != nil {
+		return err
+	}
+	c.guardar(registro, ttl)
+	return nil
+}
+
+func (c *cacheIdempotencia) Liberar(ctx context.Context, chave, transacaoID string) error {
+	c.remover(chave)
+	return c.store.Liberar(ctx, chave, transacaoID)
+}
+
+// obter devolve uma cópia do desfecho vigente em cache, descartando o expirado
+func (c *cacheIdempotencia) obter(chave string) (*domain.RegistroIdempotencia, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elemento, ok := c.entradas[chave]
+	if !ok {
+		return nil, false
+	}
+	entrada := elemento.Value.(*entradaCacheIdempotencia)
+	if !c.agora().Before(entrada.expiraEm) {
+		c.recentes.Remove(elemento)
+		delete(c.entradas, chave)
+		return nil, false
+	}
+
+	c.recentes.MoveToFront(elemento)
+	registro := entrada.registro
+	return &registro, true
+}
+
+// guardar inclui o desfecho definitivo, removendo o menos usado recentemente
+// quando o cache está cheio
+func (c *cacheIdempotencia) guardar(registro *domain.RegistroIdempotencia, ttl time.Duration) {
+	if registro.Status == domain.StatusIdempotenciaEmProcessamento {
+		return
+	}
+	if ttl > c.ttl {
+		ttl = c.ttl
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entrada := &entradaCacheIdempotencia{registro: *registro, expiraEm: c.agora().Add(ttl)}
+	if elemento, ok := c.entradas[registro.Chave]; ok {
+		elemento.Value = entrada
+		c.recentes.MoveToFront(elemento)
+		return
+	}
+
+	c.entradas[registro.Chave] = c.recentes.PushFront(entrada)
+	if c.recentes.Len() > c.capacidade {
+		antigo := c.recentes.Back()
+		c.recentes.Remove(antigo)
+		delete(c.entradas, antigo.Value.(*entradaCacheIdempotencia).registro.Chave)
+	}
+}
+
+func (c *cacheIdempotencia) remover(chave string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elemento, ok := c.entradas[chave]; ok {
+		c.recentes.Remove(elemento)
+		delete(c.entradas, chave)
+	}
+}
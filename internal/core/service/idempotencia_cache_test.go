@@ -0,0 +1,151 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// contadorIdempotencyStore conta os acessos ao store envolvido pelo cache
+type contadorIdempotencyStore struct {
+	*fakeIdempotencyStore
+	gets     int
+	reservas int
+}
+
+func (s *contadorIdempotencyStore) Get(ctx context.Context, chave string) (*domain.RegistroIdempotencia, error) {
+	s.gets++
+	return s.fakeIdempotencyStore.Get(ctx, chave)
+}
+
+func (s *contadorIdempotencyStore) Reservar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	s.reservas++
+	return s.fakeIdempotencyStore.Reservar(ctx, registro, ttl)
+}
+
+// novoCacheIdempotencia cria o cache com o relógio do fake store
+func novoCacheIdempotencia(capacidade int, ttl time.Duration) (*cacheIdempotencia, *contadorIdempotencyStore) {
+	store := &contadorIdempotencyStore{fakeIdempotencyStore: newFakeIdempotencyStore()}
+	cache := NewCacheIdempotencia(store, capacidade, ttl).(*cacheIdempotencia)
+	cache.agora = func() time.Time { return store.agora }
+	return cache, store
+}
+
+func finalizarNoCache(t *testing.T, cache *cacheIdempotencia, chave string) {
+	t.Helper()
+	ctx := context.Background()
+	registro := &domain.RegistroIdempotencia{Chave: chave, TransacaoID: "tx-" + chave, Status: domain.StatusIdempotenciaEmProcessamento}
+	if err := cache.Reservar(ctx, registro, time.Hour); err != nil {
+		t.Fatalf("reserva de %s: %v", chave, err)
+	}
+	registro.Status = string(domain.StatusAprovada)
+	if err := cache.Finalizar(ctx, registro, time.Hour); err != nil {
+		t.Fatalf("finalização de %s: %v", chave, err)
+	}
+}
+
+func TestNewCacheIdempotencia_SemCapacidadeDevolveStore(t *testing.T) {
+	store := newFakeIdempotencyStore()
+	if got := NewCacheIdempotencia(store, 0, time.Minute); got != domain.IdempotencyStore(store) {
+		t.Fatalf("capacidade 0 deveria devolver o store sem cache, got %T", got)
+	}
+}
+
+func TestCacheIdempotencia_RepeticaoRespondidaSemStore(t *testing.T) {
+	cache, store := novoCacheIdempotencia(10, time.Minute)
+	ctx := context.Background()
+	finalizarNoCache(t, cache, "chave-1")
+
+	registro, err := cache.Get(ctx, "chave-1")
+	if err != nil || registro == nil || registro.TransacaoID != "tx-chave-1" {
+		t.Fatalf("Get = %+v, %v; want desfecho em cache", registro, err)
+	}
+	if store.gets != 0 {
+		t.Errorf("Get consultou o store %d vezes; want 0", store.gets)
+	}
+
+	err = cache.Reservar(ctx, &domain.RegistroIdempotencia{Chave: "chave-1", TransacaoID: "tx-2"}, time.Hour)
+	if !errors.Is(err, domain.ErrReservaIdempotencia) {
+		t.Fatalf("Reservar = %v; want ErrReservaIdempotencia", err)
+	}
+	if store.reservas != 1 {
+		t.Errorf("reservas no store = %d; want 1 (só a original)", store.reservas)
+	}
+}
+
+func TestCacheIdempotencia_ReservaEmProcessamentoNaoEntraNoCache(t *testing.T) {
+	cache, store := novoCacheIdempotencia(10, time.Minute)
+	ctx := context.Background()
+	registro := &domain.RegistroIdempotencia{Chave: "chave-1", TransacaoID: "tx-1", Status: domain.StatusIdempotenciaEmProcessamento}
+	if err := cache.Reservar(ctx, registro, time.Hour); err != nil {
+		t.Fatalf("Reservar: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get(ctx, "chave-1"); err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+	}
+	if store.gets != 2 {
+		t.Errorf("gets no store = %d; want 2 (reserva em processamento não é cacheada)", store.gets)
+	}
+}
+
+func TestCacheIdempotencia_EntradaExpira(t *testing.T) {
+	cache, store := novoCacheIdempotencia(10, time.Minute)
+	ctx := context.Background()
+	finalizarNoCache(t, cache, "chave-1")
+
+	store.avancar(2 * time.Minute)
+
+	if _, err := cache.Get(ctx, "chave-1"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if store.gets != 1 {
+		t.Errorf("gets no store = %d; want 1 (entrada expirada)", store.gets)
+	}
+}
+
+func TestCacheIdempotencia_RemoveMenosUsadoAcimaDaCapacidade(t *testing.T) {
+	cache, store := novoCacheIdempotencia(2, time.Minute)
+	ctx := context.Background()
+	finalizarNoCache(t, cache, "chave-1")
+	finalizarNoCache(t, cache, "chave-2")
+
+	// chave-1 passa a ser a mais recente; chave-2 é removida pela chave-3
+	if _, err := cache.Get(ctx, "chave-1"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	finalizarNoCache(t, cache, "chave-3")
+
+	if len(cache.entradas) != 2 {
+		t.Fatalf("entradas = %d; want 2", len(cache.entradas))
+	}
+	if _, ok := cache.entradas["chave-2"]; ok {
+		t.Error("chave-2 deveria ter sido removida do cache")
+	}
+
+	if _, err := cache.Get(ctx, "chave-2"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if store.gets != 1 {
+		t.Errorf("gets no store = %d; want 1 (só a chave removida)", store.gets)
+	}
+}
+
+func TestCacheIdempotencia_LiberarRemoveDoCache(t *testing.T) {
+	cache, store := novoCacheIdempotencia(10, time.Minute)
+	ctx := context.Background()
+	store.gravar(domain.RegistroIdempotencia{Chave: "chave-1", TransacaoID: "tx-1", Status: string(domain.StatusAprovada)}, time.Hour)
+	if _, err := cache.Get(ctx, "chave-1"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	_ = cache.Liberar(ctx, "chave-1", "tx-1")
+
+	if _, ok := cache.entradas["chave-1"]; ok {
+		t.Error("Liberar deveria remover a chave do cache")
+	}
+}
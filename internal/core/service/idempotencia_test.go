@@ -0,0 +1,311 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/testutil"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// requisicaoIdempotente cria uma nova transação (novo ID) com a chave informada,
+// como faz o handler a cada requisição
+func requisicaoIdempotente(chave string) *domain.Transacao {
+	transacao := testutil.NewTransacaoBuilder().Build()
+	transacao.ID = domain.NewTransacao(transacao.ClienteID, transacao.Valor, transacao.CorrelationID).ID
+	transacao.ChaveIdempotencia = chave
+	return transacao
+}
+
+func TestAutorizarTransacao_IdempotenciaDevolveDesfechoOriginal(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	store := newFakeIdempotencyStore()
+	svc := deps.service(WithIdempotencia(store, time.Hour))
+
+	original := requisicaoIdempotente("k-1")
+	primeiro, err := svc.AutorizarTransacao(context.Background(), original)
+	if err != nil || !primeiro.Aprovada() {
+		t.Fatalf("primeira requisição deveria ser aprovada: %+v, %v", primeiro, err)
+	}
+
+	repeticao := requisicaoIdempotente("k-1")
+	segundo, err := svc.AutorizarTransacao(context.Background(), repeticao)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if deps.limites.debitos != 1 {
+		t.Errorf("a repetição não deveria debitar de novo, got %d débitos", deps.limites.debitos)
+	}
+	if repeticao.ID != original.ID || segundo.Status != domain.StatusAprovada {
+		t.Errorf("repetição deveria devolver a transação original %s, got %s (%s)", original.ID, repeticao.ID, segundo.Status)
+	}
+	if segundo.LimiteDisponivel == nil || *segundo.LimiteDisponivel != *primeiro.LimiteDisponivel {
+		t.Errorf("limite disponível deveria ser o da resposta original")
+	}
+	if deps.metrics.outcomeCount("idempotent_replay") != 1 {
+		t.Error("métrica idempotent_replay deveria ser incrementada")
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaDevolveRecusaOriginal(t *testing.T) {
+	deps := newDependencias(testutil.ClienteSemLimite())
+	svc := deps.service(WithIdempotencia(newFakeIdempotencyStore(), time.Hour))
+
+	svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	resultado, err := svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if resultado.CodigoMotivo != "insufficient_limit" || !errors.Is(resultado.Motivo, domain.ErrLimiteInsuficiente) {
+		t.Errorf("recusa original esperada, got %+v", resultado)
+	}
+	if got := len(deps.transacoes.transacoes); got != 1 {
+		t.Errorf("apenas a transação original deveria ser registrada, got %d", got)
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaChaveExpirada(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	store := newFakeIdempotencyStore()
+	svc := deps.service(WithIdempotencia(store, time.Hour))
+
+	svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	store.avancar(time.Hour)
+	svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+
+	if deps.limites.debitos != 2 {
+		t.Errorf("chave expirada deveria ser reprocessada, got %d débitos", deps.limites.debitos)
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaDesligada(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		chave string
+	}{
+		{name: "sem store", opts: []Option{WithIdempotencia(nil, 0)}, chave: "k-1"},
+		{name: "requisição sem chave", opts: []Option{WithIdempotencia(newFakeIdempotencyStore(), 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(testutil.ClientePadrao())
+			svc := deps.service(tt.opts...)
+
+			svc.AutorizarTransacao(context.Background(), requisicaoIdempotente(tt.chave))
+			svc.AutorizarTransacao(context.Background(), requisicaoIdempotente(tt.chave))
+
+			if deps.limites.debitos != 2 {
+				t.Errorf("ambas as requisições deveriam ser processadas, got %d débitos", deps.limites.debitos)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaFalhaNoStore(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	store := newFakeIdempotencyStore()
+	store.errStore = errors.New("store indisponível")
+	svc := deps.service(WithIdempotencia(store, time.Hour))
+
+	resultado, err := svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	if err != nil || !resultado.Aprovada() {
+		t.Fatalf("falha no store não deveria bloquear a autorização: %+v, %v", resultado, err)
+	}
+	if deps.metrics.errorCount("idempotency_store_error") != 1 {
+		t.Error("métrica idempotency_store_error deveria ser incrementada")
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaComparaPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		repeticao func(*testutil.TransacaoBuilder) *testutil.TransacaoBuilder
+		conflito  bool
+	}{
+		{name: "mesmo payload devolve o desfecho original", repeticao: func(b *testutil.TransacaoBuilder) *testutil.TransacaoBuilder { return b }},
+		{name: "valor diferente é conflito", repeticao: func(b *testutil.TransacaoBuilder) *testutil.TransacaoBuilder { return b.ComValor(99.90) }, conflito: true},
+		{name: "parcelas diferentes é conflito", repeticao: func(b *testutil.TransacaoBuilder) *testutil.TransacaoBuilder { return b.ComParcelas(3) }, conflito: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(testutil.ClientePadrao())
+			svc := deps.service(WithIdempotencia(newFakeIdempotencyStore(), time.Hour))
+
+			original := requisicaoIdempotente("k-1")
+			if _, err := svc.AutorizarTransacao(context.Background(), original); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			repeticao := tt.repeticao(testutil.NewTransacaoBuilder()).Build()
+			repeticao.ID = domain.NewTransacao(repeticao.ClienteID, repeticao.Valor, repeticao.CorrelationID).ID
+			repeticao.ChaveIdempotencia = "k-1"
+			idRepeticao := repeticao.ID
+
+			resultado, err := svc.AutorizarTransacao(context.Background(), repeticao)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if deps.limites.debitos != 1 {
+				t.Errorf("a repetição não deveria debitar, got %d débitos", deps.limites.debitos)
+			}
+
+			if !tt.conflito {
+				if !resultado.Aprovada() || repeticao.ID != original.ID {
+					t.Errorf("esperado o desfecho original %s, got %+v (%s)", original.ID, resultado, repeticao.ID)
+				}
+				return
+			}
+
+			if resultado.Status != domain.StatusRejeitada || resultado.CodigoMotivo != "idempotency_key_conflict" ||
+				!errors.Is(resultado.Motivo, domain.ErrConflitoIdempotencia) {
+				t.Errorf("esperado conflito de idempotência, got %+v", resultado)
+			}
+			if repeticao.ID != idRepeticao {
+				t.Error("o conflito não deveria assumir o ID da transação original")
+			}
+			if salva, _ := deps.transacoes.GetByID(context.Background(), idRepeticao); salva != nil {
+				t.Error("a requisição em conflito não deveria ser persistida")
+			}
+			if deps.metrics.errorCount("idempotency_key_conflict") != 1 {
+				t.Error("métrica idempotency_key_conflict deveria ser incrementada")
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaRegistroSemHashPayload(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	store := newFakeIdempotencyStore()
+	svc := deps.service(WithIdempotencia(store, time.Hour))
+
+	// Registro gravado antes da verificação de payload: a repetição é atendida
+	repeticao := requisicaoIdempotente("k-1")
+	chave := domain.HasherSHA256{}.Chave(domain.CamposIdempotencia(repeticao.ClienteID, "k-1"))
+	store.gravar(domain.RegistroIdempotencia{Chave: chave, TransacaoID: "tx-original", Status: domain.StatusAprovada}, time.Hour)
+
+	repeticao.Valor = 99.90
+	resultado, err := svc.AutorizarTransacao(context.Background(), repeticao)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if !resultado.Aprovada() || repeticao.ID != "tx-original" {
+		t.Errorf("registro sem hash deveria devolver o desfecho original, got %+v (%s)", resultado, repeticao.ID)
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaRequisicaoEmProcessamento(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	store := newFakeIdempotencyStore()
+	svc := deps.service(WithIdempotencia(store, time.Hour))
+
+	// Reserva da requisição original, ainda sem desfecho
+	original := requisicaoIdempotente("k-1")
+	chave := domain.HasherSHA256{}.Chave(domain.CamposIdempotencia(original.ClienteID, "k-1"))
+	store.gravar(domain.RegistroIdempotencia{
+		Chave:       chave,
+		TransacaoID: original.ID,
+		Status:      domain.StatusIdempotenciaEmProcessamento,
+		HashPayload: domain.HasherSHA256{}.Chave(domain.CamposPayloadIdempotencia(original)),
+	}, time.Minute)
+
+	resultado, err := svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if resultado.Status != domain.StatusRejeitada || resultado.CodigoMotivo != "idempotency_key_in_progress" ||
+		!errors.Is(resultado.Motivo, domain.ErrIdempotenciaEmProcessamento) {
+		t.Errorf("esperada recusa por requisição em processamento, got %+v", resultado)
+	}
+	if deps.limites.debitos != 0 {
+		t.Errorf("a repetição não deveria debitar, got %d débitos", deps.limites.debitos)
+	}
+	if deps.metrics.errorCount("idempotency_key_in_progress") != 1 {
+		t.Error("métrica idempotency_key_in_progress deveria ser incrementada")
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaRequisicoesConcorrentes(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	svc := deps.service(WithIdempotencia(newFakeIdempotencyStore(), time.Hour))
+
+	const requisicoes = 10
+	var wg sync.WaitGroup
+	for i := 0; i < requisicoes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resultado, err := svc.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+			if err != nil {
+				t.Errorf("erro inesperado: %v", err)
+				return
+			}
+			if !resultado.Aprovada() && resultado.CodigoMotivo != "idempotency_key_in_progress" {
+				t.Errorf("esperado o desfecho original ou requisição em processamento, got %+v", resultado)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if deps.limites.debitos != 1 {
+		t.Errorf("apenas uma requisição deveria debitar, got %d débitos", deps.limites.debitos)
+	}
+}
+
+func TestAutorizarTransacao_IdempotenciaFalhaLiberaReserva(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	store := newFakeIdempotencyStore()
+
+	indisponivel := NewTransacaoService(limiteIndisponivel{deps.limites}, deps.transacoes, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{},
+		WithIdempotencia(store, time.Hour))
+	if _, err := indisponivel.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1")); err == nil {
+		t.Fatal("esperada falha de infraestrutura")
+	}
+
+	// A retentativa com a mesma chave é processada
+	resultado, err := deps.service(WithIdempotencia(store, time.Hour)).AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	if err != nil || !resultado.Aprovada() {
+		t.Fatalf("retentativa deveria ser aprovada: %+v, %v", resultado, err)
+	}
+	if deps.limites.debitos != 1 {
+		t.Errorf("esperado um débito, got %d", deps.limites.debitos)
+	}
+}
+
+// limiteResultadoIndeterminado estoura o timeout do débito sem confirmação
+type limiteResultadoIndeterminado struct {
+	*fakeLimiteRepository
+}
+
+func (r limiteResultadoIndeterminado) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	return 0, fmt.Errorf("debitar limite: %w: %w", domain.ErrResultadoIndeterminado, domain.ErrTimeoutOperacao)
+}
+
+func TestAutorizarTransacao_IdempotenciaResultadoIndeterminadoMantemReserva(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	store := newFakeIdempotencyStore()
+
+	indeterminado := NewTransacaoService(limiteResultadoIndeterminado{deps.limites}, deps.transacoes, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{},
+		WithIdempotencia(store, time.Hour))
+	if _, err := indeterminado.AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1")); !errors.Is(err, domain.ErrResultadoIndeterminado) {
+		t.Fatalf("esperado resultado indeterminado, got %v", err)
+	}
+
+	// O débito pode ter sido aplicado: a repetição não é processada
+	resultado, err := deps.service(WithIdempotencia(store, time.Hour)).AutorizarTransacao(context.Background(), requisicaoIdempotente("k-1"))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if resultado.CodigoMotivo != "idempotency_key_in_progress" || deps.limites.debitos != 0 {
+		t.Errorf("repetição deveria encontrar a reserva mantida, got %+v (%d débitos)", resultado, deps.limites.debitos)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLimiteEventPublisher guarda os eventos de alteração de limite
+type fakeLimiteEventPublisher struct {
+	mu      sync.Mutex
+	eventos []*domain.LimiteAlteradoEvento
+}
+
+func (p *fakeLimiteEventPublisher) PublishLimiteAlterado(ctx context.Context, evento *domain.LimiteAlteradoEvento) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.eventos = append(p.eventos, evento)
+	return nil
+}
+
+func (p *fakeLimiteEventPublisher) total() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.eventos)
+}
+
+func TestAutorizarTransacao_EventoLimiteAlterado(t *testing.T) {
+	tests := []struct {
+		name     string
+		tier     string
+		limite   int
+		valor    float64
+		anterior int64
+		atual    int64
+	}{
+		{name: "débito", limite: 100000, valor: 10.00, anterior: 100000, atual: 99000},
+		{name: "débito no cheque especial", tier: "PLATINUM", limite: 10000, valor: 300.00, anterior: 10000, atual: -20000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", Tier: tt.tier, LimiteCredit: 100000, LimiteAtual: tt.limite})
+			publisher := &fakeLimiteEventPublisher{}
+			agora := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			svc := deps.service(politicasTierTeste(), WithEventosLimite(publisher), WithRelogio(func() time.Time { return agora }))
+
+			transacao := domain.NewTransacao("c1", tt.valor, "corr-1")
+			if err := autorizar(context.Background(), svc, transacao); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			aguardar(t, func() bool { return publisher.total() == 1 })
+
+			evento := publisher.eventos[0]
+			if evento.LimiteAnterior != tt.anterior || evento.LimiteAtual != tt.atual || evento.Delta != tt.atual-tt.anterior {
+				t.Errorf("esperado %d -> %d, got %d -> %d (delta %d)", tt.anterior, tt.atual, evento.LimiteAnterior, evento.LimiteAtual, evento.Delta)
+			}
+			if evento.Motivo != domain.MotivoLimiteDebito || evento.Evento != domain.EventoLimiteAlterado {
+				t.Errorf("evento inesperado: %+v", evento)
+			}
+			if evento.TransacaoID != transacao.ID || evento.CorrelationID != "corr-1" || evento.ClienteID != "c1" {
+				t.Errorf("identificação inesperada: %+v", evento)
+			}
+			if !evento.Timestamp.Equal(agora) {
+				t.Errorf("timestamp esperado do relógio do serviço %v, got %v", agora, evento.Timestamp)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_SemEventoLimiteSemDebito(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 1000, LimiteAtual: 1000})
+	publisher := &fakeLimiteEventPublisher{}
+	svc := deps.service(WithEventosLimite(publisher))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 50.00, "corr")); err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("esperado ErrLimiteInsuficiente, got %v", err)
+	}
+
+	// O evento de rejeição da transação é publicado; o de limite não
+	aguardar(t, func() bool { return len(deps.publisher.publicados) == 1 })
+	time.Sleep(20 * time.Millisecond)
+	if publisher.total() != 0 {
+		t.Errorf("rejeição não altera o limite e não deveria gerar evento, got %d", publisher.total())
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+)
+
+func TestAutorizarTransacao_MoedasSuportadas(t *testing.T) {
+	tests := []struct {
+		name        string
+		moeda       string
+		opts        []Option
+		expectedErr error
+	}{
+		{name: "padrão BRL", moeda: "BRL"},
+		{name: "sem moeda vale BRL", moeda: ""},
+		{name: "fora do padrão", moeda: "USD", expectedErr: domain.ErrMoedaNaoSuportada},
+		{name: "configurada", moeda: "USD", opts: []Option{WithMoedasSuportadas("BRL", "USD")}},
+		{name: "BRL fora da lista configurada", moeda: "BRL", opts: []Option{WithMoedasSuportadas("USD")}, expectedErr: domain.ErrMoedaNaoSuportada},
+		{name: "minúsculas", moeda: "usd", opts: []Option{WithMoedasSuportadas("USD")}, expectedErr: domain.ErrMoedaInvalida},
+		{name: "malformada", moeda: "REAL", expectedErr: domain.ErrMoedaInvalida},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			svc := deps.service(tt.opts...)
+
+			transacao := domain.NewTransacao("c1", 10.00, "corr")
+			transacao.Moeda = tt.moeda
+			if err := autorizar(context.Background(), svc, transacao); err != tt.expectedErr {
+				t.Fatalf("esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			// A recusa acontece antes de qualquer acesso ao limite
+			if tt.expectedErr != nil {
+				if deps.limites.debitos != 0 {
+					t.Error("moeda recusada não deveria debitar o limite")
+				}
+				if deps.metrics.errorCount("unsupported_currency") != 1 {
+					t.Error("métrica unsupported_currency deveria ser incrementada")
+				}
+			}
+		})
+	}
+}
+
+func TestAgendarTransacao_MoedaNaoSuportada(t *testing.T) {
+	deps := newDependencias()
+	svc := deps.service()
+
+	transacao := domain.NewTransacao("inexistente", 10.00, "corr")
+	transacao.Moeda = "EUR"
+
+	// Cliente inexistente daria ErrClienteNaoEncontrado se o limite fosse consultado
+	if err := svc.AgendarTransacao(context.Background(), transacao); err != domain.ErrMoedaNaoSuportada {
+		t.Errorf("esperado ErrMoedaNaoSuportada, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"time"
+)
+
+// Option configura comportamentos opcionais do TransacaoService
+type Option func(*transacaoService)
+
+// ModoDuplicidade define o que fazer ao detectar uma transação duplicada suspeita
+type ModoDuplicidade string
+
+const (
+	// DuplicidadeRejeitar rejeita a transação com ErrTransacaoDuplicadaSuspeita
+	DuplicidadeRejeitar ModoDuplicidade = "REJEITAR"
+	// DuplicidadeSinalizar aprova normalmente, mas marca a transação como suspeita
+	DuplicidadeSinalizar ModoDuplicidade = "SINALIZAR"
+)
+
+// WithDeteccaoDuplicidade habilita a detecção de transações idênticas
+// (mesmo cliente, mesmo valor) aprovadas dentro da janela informada.
+// Uma janela zero ou negativa mantém a detecção desligada.
+func WithDeteccaoDuplicidade(janela time.Duration, modo ModoDuplicidade) Option {
+	return func(s *transacaoService) {
+		s.janelaDuplicidade = janela
+		s.modoDuplicidade = modo
+	}
+}
+
+// ModoTimestampFuturo define o que fazer com timestamp no futuro além da tolerância
+type ModoTimestampFuturo string
+
+const (
+	// TimestampFuturoRejeitar recusa a transação com ErrTimestampInvalido
+	TimestampFuturoRejeitar ModoTimestampFuturo = "REJEITAR"
+	// TimestampFuturoAjustar substitui o timestamp pelo horário atual
+	TimestampFuturoAjustar ModoTimestampFuturo = "AJUSTAR"
+)
+
+// WithToleranciaTimestamp define quanto o timestamp informado pode estar à
+// frente do relógio do serviço (padrão: 5 minutos, rejeitando)
+func WithToleranciaTimestamp(tolerancia time.Duration, modo ModoTimestampFuturo) Option {
+	return func(s *transacaoService) {
+		s.toleranciaTimestamp = max(tolerancia, 0)
+		s.modoTimestamp = modo
+	}
+}
+
+// WithKeyHasher substitui o hash das chaves de deduplicação de eventos e de
+// detecção de duplicidade (padrão: domain.HasherSHA256)
+func WithKeyHasher(hasher domain.KeyHasher) Option {
+	return func(s *transacaoService) {
+		s.keyHasher = hasher
+	}
+}
+
+// OrdemPublicacao define como a publicação do evento se relaciona com a
+// persistência da transação
+type OrdemPublicacao string
+
+const (
+	// PublicarAposSalvar publica depois do Save (padrão). Uma falha entre as
+	// duas etapas perde o evento: entrega no máximo uma vez.
+	PublicarAposSalvar OrdemPublicacao = "APOS_SALVAR"
+	// PublicarViaOutbox grava o evento no outbox na mesma escrita da
+	// transação, sem publicar; o relay (OutboxRelayService) publica os
+	// pendentes e os marca como publicados: entrega ao menos uma vez
+	// (consumidores deduplicam por chave_deduplicacao).
+	PublicarViaOutbox OrdemPublicacao = "OUTBOX"
+)
+
+// WithOrdemPublicacao define a ordem entre persistência e publicação.
+// PublicarViaOutbox exige repositório com domain.TransacaoOutboxRepository;
+// sem suporte, a publicação ocorre após o Save.
+func WithOrdemPublicacao(ordem OrdemPublicacao) Option {
+	return func(s *transacaoService) {
+		s.ordemPublicacao = ordem
+	}
+}
+
+// WithPoliticaAprovacao habilita a política de aprovação carregada do
+// repositório, mantida em cache e recarregada a cada intervalo
+func WithPoliticaAprovacao(repository domain.PolicyRepository, intervalo time.Duration) Option {
+	return func(s *transacaoService) {
+		s.politicas = &politicaCache{repository: repository, intervalo: intervalo}
+	}
+}
+
+// WithParcelas define o máximo global de parcelas e o máximo por tier de
+// cliente. O máximo individual do cliente (MaxParcelas) tem precedência.
+func WithParcelas(maxGlobal int, maxPorTier map[string]int) Option {
+	return func(s *transacaoService) {
+		s.maxParcelas = maxGlobal
+		s.maxParcelasPorTier = maxPorTier
+	}
+}
+
+// WithStepUp exige confirmação adicional (step-up) para transações acima do
+// limite suave, em centavos. Um limite zero ou negativo mantém o step-up desligado.
+func WithStepUp(limiteSuaveCentavos int, verifier domain.StepUpVerifier) Option {
+	return func(s *transacaoService) {
+		s.limiteSuaveCentavos = limiteSuaveCentavos
+		s.stepUp = verifier
+	}
+}
+
+// WithLimitePayloadEvento define o tamanho máximo, em bytes, do payload
+// publicado. Eventos maiores são armazenados no store e publicados como
+// referência; sem store, são reduzidos aos campos essenciais.
+func WithLimitePayloadEvento(maxBytes int, store domain.EventPayloadStore) Option {
+	return func(s *transacaoService) {
+		s.limitePayload = limitePayload{maxBytes: maxBytes, store: store}
+	}
+}
+
+// WithConfirmacaoEventos registra no store cada evento de aprovação publicado
+// com sucesso, permitindo reconciliar eventos publicados x transações salvas
+func WithConfirmacaoEventos(store domain.EventAckStore) Option {
+	return func(s *transacaoService) {
+		s.ackStore = store
+	}
+}
+
+// WithSupressaoEventosRejeicao deixa de publicar eventos de transação
+// rejeitada; a rejeição continua persistida e contabilizada nas métricas
+func WithSupressaoEventosRejeicao() Option {
+	return func(s *transacaoService) {
+		s.suprimirEventosRejeicao = true
+	}
+}
+
+// PoliticaFalha define a decisão quando uma dependência síncrona falha
+type PoliticaFalha string
+
+const (
+	// FalhaAberta segue com a autorização (fail-open)
+	FalhaAberta PoliticaFalha = "FAIL_OPEN"
+	// FalhaFechada rejeita a transação (fail-closed)
+	FalhaFechada PoliticaFalha = "FAIL_CLOSED"
+)
+
+// WithPreAutorizacao exige aprovação do callback antes do débito. O callback
+// tem o próprio timeout; em erro ou timeout, aplica a política de falha.
+func WithPreAutorizacao(callback domain.PreAuthCallback, timeout time.Duration, politica PoliticaFalha) Option {
+	return func(s *transacaoService) {
+		s.preAuth = callback
+		s.preAuthTimeout = timeout
+		s.preAuthPolitica = politica
+	}
+}
+
+// WithVerificacaoValorZero aprova autorizações de valor zero marcadas com
+// domain.TipoVerificacao (verificação de cartão), sem debitar o limite.
+// Sem esta opção, valor zero continua rejeitado com ErrValorZero.
+func WithVerificacaoValorZero() Option {
+	return func(s *transacaoService) {
+		s.verificacaoValorZero = true
+	}
+}
+
+// WithPoliticasPorTier aplica limites por segmento do cliente (valor máximo,
+// velocidade diária e cheque especial). Tiers ausentes do mapa usam padrao.
+func WithPoliticasPorTier(politicas map[string]domain.PoliticaTier, padrao domain.PoliticaTier) Option {
+	return func(s *transacaoService) {
+		s.politicasTier = make(map[string]domain.PoliticaTier, len(politicas))
+		for tier, politica := range politicas {
+			s.politicasTier[tier] = politica
+		}
+		s.politicaTierPadrao = padrao
+	}
+}
+
+// WithProvisionamentoAutomatico cria, na primeira transação, o cliente sem
+// registro de limite com limiteCentavos, em vez de rejeitar com
+// ErrClienteNaoEncontrado. Requer repositório que implemente
+// domain.LimiteProvisionamentoRepository; zero mantém a rejeição.
+func WithProvisionamentoAutomatico(limiteCentavos int) Option {
+	return func(s *transacaoService) {
+		s.limiteProvisionamento = limiteCentavos
+	}
+}
+
+// WithRetentativaDebito habilita uma única nova tentativa de débito após
+// limite insuficiente, caso a releitura do cliente mostre saldo suficiente
+// (crédito concorrente). Releitura e nova tentativa usam no máximo prazo;
+// zero desliga (padrão).
+func WithRetentativaDebito(prazo time.Duration) Option {
+	return func(s *transacaoService) {
+		s.prazoRetentativaDebito = prazo
+	}
+}
+
+// WithEventosLimite publica um domain.LimiteAlteradoEvento a cada débito
+// de limite_atual feito pelo serviço (publicação assíncrona, como os
+// eventos de transação)
+func WithEventosLimite(publisher domain.LimiteEventPublisher) Option {
+	return func(s *transacaoService) {
+		s.limiteEventos = publisher
+	}
+}
+
+// WithMoedasSuportadas substitui as moedas aceitas (padrão: BRL). Os códigos
+// devem ser ISO 4217 já validados (domain.ValidarCodigoMoeda). Os valores não
+// são convertidos: limites e políticas valem na moeda informada.
+func WithMoedasSuportadas(moedas ...string) Option {
+	return func(s *transacaoService) {
+		s.moedasSuportadas = make(map[string]bool, len(moedas))
+		for _, moeda := range moedas {
+			s.moedasSuportadas[moeda] = true
+		}
+	}
+}
+
+// WithAuditoria grava cada decisão de autorização (aprovação, recusa ou
+// step-up pendente) na trilha de auditoria, com ator como autor. Falhas na
+// gravação não revertem a decisão: geram log de erro e a métrica
+// audit_record_error. Repositório nil desliga a auditoria.
+func WithAuditoria(repo domain.AuditRepository, ator string) Option {
+	return func(s *transacaoService) {
+		s.auditoria = repo
+		s.atorAuditoria = ator
+	}
+}
+
+// WithIdempotencia devolve o desfecho original às repetições de uma
+// requisição com a mesma chave de idempotência, sem reprocessá-la. Os
+// registros valem por ttl (padrão: 24h); depois a chave volta a ser aceita.
+// Store nil desliga a idempotência.
+func WithIdempotencia(store domain.IdempotencyStore, ttl time.Duration) Option {
+	return func(s *transacaoService) {
+		s.idempotencia = store
+		s.ttlIdempotencia = ttl
+		if ttl <= 0 {
+			s.ttlIdempotencia = ttlIdempotenciaPadrao
+		}
+	}
+}
+
+// WithRelogio substitui a fonte de tempo do serviço (útil em testes)
+func WithRelogio(agora func() time.Time) Option {
+	return func(s *transacaoService) {
+		s.agora = agora
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// RelatorioOutbox resume uma execução do relay do outbox
+type RelatorioOutbox struct {
+	Publicados int `json:"publicados"`
+	Falhas     int `json:"falhas"`
+}
+
+// OutboxRelayService publica os eventos gravados no outbox pela ordem
+// PublicarViaOutbox e os marca como publicados. Um evento só sai do outbox
+// depois de publicado: falhas ficam pendentes para a próxima execução
+// (entrega ao menos uma vez).
+type OutboxRelayService struct {
+	outbox           domain.OutboxRelayRepository
+	eventPublisher   domain.EventPublisher
+	ackStore         domain.EventAckStore
+	metricsCollector domain.MetricsCollector
+	logger           domain.Logger
+	limitePayload    limitePayload
+}
+
+// OpcaoOutboxRelay configura comportamentos opcionais do relay do outbox
+type OpcaoOutboxRelay func(*OutboxRelayService)
+
+// WithLimitePayloadRelay define o tamanho máximo do payload publicado pelo
+// relay, com o mesmo tratamento de WithLimitePayloadEvento no serviço
+func WithLimitePayloadRelay(maxBytes int, store domain.EventPayloadStore) OpcaoOutboxRelay {
+	return func(s *OutboxRelayService) {
+		s.limitePayload = limitePayload{maxBytes: maxBytes, store: store}
+	}
+}
+
+// NewOutboxRelayService cria o relay; ackStore nil desliga o registro das
+// confirmações usadas pela reconciliação de eventos
+func NewOutboxRelayService(
+	outbox domain.OutboxRelayRepository,
+	eventPublisher domain.EventPublisher,
+	ackStore domain.EventAckStore,
+	metricsCollector domain.MetricsCollector,
+	logger domain.Logger,
+	opts ...OpcaoOutboxRelay,
+) *OutboxRelayService {
+	s := &OutboxRelayService{
+		outbox:           outbox,
+		eventPublisher:   eventPublisher,
+		ackStore:         ackStore,
+		metricsCollector: metricsCollector,
+		logger:           logger,
+		limitePayload:    limitePayload{maxBytes: limitePayloadEventoPadrao},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// PublicarPendentes publica até limite eventos pendentes
+func (s *OutboxRelayService) PublicarPendentes(ctx context.Context, limite int) (*RelatorioOutbox, error) {
+	pendentes, err := s.outbox.ListarPendentes(ctx, limite)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar eventos pendentes do outbox: %w", err)
+	}
+
+	relatorio := &RelatorioOutbox{}
+	for _, pendente := range pendentes {
+		if err := s.publicar(ctx, pendente.Evento); err != nil {
+			s.logger.Error(ctx, "falha ao publicar evento do outbox", err, map[string]interface{}{
+				"outbox_id":    pendente.ID,
+				"transacao_id": pendente.Evento.TransacaoID,
+				"evento":       pendente.Evento.Evento,
+			})
+			s.metricsCollector.IncrementErrorCounter("event_publish_error")
+			relatorio.Falhas++
+			continue
+		}
+		relatorio.Publicados++
+
+		// Sem a marcação o evento é republicado na próxima execução
+		if err := s.outbox.MarcarPublicado(ctx, pendente.ID); err != nil {
+			s.logger.Error(ctx, "falha ao marcar evento do outbox como publicado", err, map[string]interface{}{
+				"outbox_id": pendente.ID,
+			})
+			s.metricsCollector.IncrementErrorCounter("outbox_mark_error")
+		}
+	}
+
+	s.metricsCollector.RecordBusinessMetric("outbox_pending_published", float64(relatorio.Publicados), nil)
+	s.logger.Info(ctx, "relay do outbox executado", map[string]interface{}{
+		"pendentes":  len(pendentes),
+		"publicados": relatorio.Publicados,
+		"falhas":     relatorio.Falhas,
+	})
+
+	return relatorio, nil
+}
+
+// publicar envia o evento pelo tipo gravado no outbox. O outbox guarda o
+// evento completo: o limite de payload é aplicado aqui, na publicação.
+func (s *OutboxRelayService) publicar(ctx context.Context, evento *domain.TransacaoEvento) error {
+	evento = s.limitePayload.ajustar(ctx, evento, s.metricsCollector, s.logger)
+
+	inicio := time.Now()
+	var err error
+	if evento.Evento == domain.EventoTransacaoAprovada {
+		err = s.eventPublisher.PublishTransacaoAprovada(ctx, evento)
+	} else {
+		err = s.eventPublisher.PublishTransacaoRejeitada(ctx, evento)
+	}
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	s.metricsCollector.RecordEventPublish(result, time.Since(inicio).Seconds())
+
+	if err == nil && evento.Evento == domain.EventoTransacaoAprovada && s.ackStore != nil {
+		if err := s.ackStore.RegistrarConfirmacao(ctx, evento.TransacaoID); err != nil {
+			s.logger.Error(ctx, "falha ao registrar confirmação de evento", err, map[string]interface{}{
+				"transacao_id": evento.TransacaoID,
+			})
+			s.metricsCollector.IncrementErrorCounter("event_ack_error")
+		}
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeOutbox guarda os eventos pendentes e os marcados como publicados
+type fakeOutbox struct {
+	pendentes  []*domain.EventoOutbox
+	publicados []string
+}
+
+func (f *fakeOutbox) ListarPendentes(ctx context.Context, limite int) ([]*domain.EventoOutbox, error) {
+	if len(f.pendentes) > limite {
+		return f.pendentes[:limite], nil
+	}
+	return f.pendentes, nil
+}
+
+func (f *fakeOutbox) MarcarPublicado(ctx context.Context, id string) error {
+	f.publicados = append(f.publicados, id)
+	restantes := f.pendentes[:0]
+	for _, pendente := range f.pendentes {
+		if pendente.ID != id {
+			restantes = append(restantes, pendente)
+		}
+	}
+	f.pendentes = restantes
+	return nil
+}
+
+func eventoOutbox(id, tipo, transacaoID string) *domain.EventoOutbox {
+	return &domain.EventoOutbox{ID: id, Evento: &domain.TransacaoEvento{Evento: tipo, TransacaoID: transacaoID}}
+}
+
+func TestOutboxRelay_PublicaEMarcaPendentes(t *testing.T) {
+	outbox := &fakeOutbox{pendentes: []*domain.EventoOutbox{
+		eventoOutbox("k1", domain.EventoTransacaoAprovada, "t1"),
+		eventoOutbox("k2", domain.EventoTransacaoRejeitada, "t2"),
+	}}
+	publisher := newFakeEventPublisher()
+	acks := newFakeAckStore()
+	metrics := newFakeMetricsCollector()
+	relay := NewOutboxRelayService(outbox, publisher, acks, metrics, &fakeLogger{})
+
+	relatorio, err := relay.PublicarPendentes(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if relatorio.Publicados != 2 || relatorio.Falhas != 0 {
+		t.Errorf("esperados 2 publicados, got %+v", relatorio)
+	}
+	if len(publisher.aprovados) != 1 || len(publisher.rejeitados) != 1 {
+		t.Errorf("cada evento deveria sair pelo seu tipo: %d aprovados, %d rejeitados", len(publisher.aprovados), len(publisher.rejeitados))
+	}
+	if len(outbox.pendentes) != 0 || len(outbox.publicados) != 2 {
+		t.Errorf("eventos publicados deveriam ser marcados, restam %d pendentes", len(outbox.pendentes))
+	}
+	if confirmados, _ := acks.Confirmados(context.Background(), []string{"t1", "t2"}); !confirmados["t1"] || confirmados["t2"] {
+		t.Errorf("apenas a aprovação deveria ser confirmada para a reconciliação, got %v", confirmados)
+	}
+}
+
+func TestOutboxRelay_FalhaMantemPendente(t *testing.T) {
+	outbox := &fakeOutbox{pendentes: []*domain.EventoOutbox{eventoOutbox("k1", domain.EventoTransacaoAprovada, "t1")}}
+	publisher := newFakeEventPublisher()
+	publisher.err = errors.New("SNS indisponível")
+	metrics := newFakeMetricsCollector()
+	relay := NewOutboxRelayService(outbox, publisher, nil, metrics, &fakeLogger{})
+
+	relatorio, err := relay.PublicarPendentes(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if relatorio.Falhas != 1 || len(outbox.pendentes) != 1 {
+		t.Errorf("evento não publicado deveria continuar pendente: %+v, %d pendentes", relatorio, len(outbox.pendentes))
+	}
+	if metrics.errorCount("event_publish_error") != 1 {
+		t.Error("métrica event_publish_error deveria ser incrementada")
+	}
+}
+
+func TestOutboxRelay_PayloadAcimaDoLimiteTruncado(t *testing.T) {
+	pendente := eventoOutbox("k1", domain.EventoTransacaoAprovada, "t1")
+	pendente.Evento.Canal = "mobile"
+	pendente.Evento.Tags = map[string]string{"campanha": strings.Repeat("x", 512)}
+	outbox := &fakeOutbox{pendentes: []*domain.EventoOutbox{pendente}}
+	publisher := newFakeEventPublisher()
+	metrics := newFakeMetricsCollector()
+	relay := NewOutboxRelayService(outbox, publisher, nil, metrics, &fakeLogger{}, WithLimitePayloadRelay(256, nil))
+
+	if _, err := relay.PublicarPendentes(context.Background(), 100); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(publisher.aprovados) != 1 {
+		t.Fatalf("esperado um evento publicado, got %d", len(publisher.aprovados))
+	}
+	publicado := publisher.aprovados[0]
+	if !publicado.Truncado || publicado.Tags != nil || publicado.TransacaoID != "t1" {
+		t.Errorf("evento do outbox deveria ser reduzido aos campos essenciais, got %+v", publicado)
+	}
+	if metrics.errorCount("event_payload_oversized_truncated") != 1 {
+		t.Error("métrica event_payload_oversized_truncated deveria ser incrementada")
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+)
+
+func TestAutorizarTransacao_Parcelas(t *testing.T) {
+	porTier := map[string]int{"GOLD": 10}
+
+	tests := []struct {
+		name        string
+		cliente     *domain.Cliente
+		parcelas    int
+		expectedErr error
+	}{
+		{
+			name:     "à vista sempre permitido",
+			cliente:  &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000, MaxParcelas: 1},
+			parcelas: 0,
+		},
+		{
+			name:     "dentro do máximo do cliente",
+			cliente:  &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000, MaxParcelas: 6},
+			parcelas: 6,
+		},
+		{
+			name:        "acima do máximo do cliente",
+			cliente:     &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000, MaxParcelas: 6},
+			parcelas:    7,
+			expectedErr: domain.ErrParcelasAcimaDoPermitido,
+		},
+		{
+			name:     "máximo derivado do tier",
+			cliente:  &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000, Tier: "GOLD"},
+			parcelas: 10,
+		},
+		{
+			name:        "acima do máximo do tier",
+			cliente:     &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000, Tier: "GOLD"},
+			parcelas:    11,
+			expectedErr: domain.ErrParcelasAcimaDoPermitido,
+		},
+		{
+			name:     "máximo do cliente tem precedência sobre o tier",
+			cliente:  &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000, Tier: "GOLD", MaxParcelas: 18},
+			parcelas: 18,
+		},
+		{
+			name:        "sem tier nem máximo próprio usa o global",
+			cliente:     &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000},
+			parcelas:    4,
+			expectedErr: domain.ErrParcelasAcimaDoPermitido,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(tt.cliente)
+			svc := deps.service(WithParcelas(3, porTier))
+
+			transacao := domain.NewTransacao("c1", 100.00, "corr")
+			transacao.Parcelas = tt.parcelas
+
+			err := autorizar(context.Background(), svc, transacao)
+			if err != tt.expectedErr {
+				t.Errorf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
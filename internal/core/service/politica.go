@@ -0,0 +1,141 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// limiteConsultaDiaria é o número de transações recentes somadas na
+// verificação do limite diário
+const limiteConsultaDiaria = 100
+
+// politicaCache mantém a política de aprovação em memória e a recarrega
+// quando o intervalo expira. A recarga é feita sob demanda (no próximo
+// acesso), o que se encaixa no modelo de execução do Lambda.
+type politicaCache struct {
+	repository domain.PolicyRepository
+	intervalo  time.Duration
+
+	mu          sync.Mutex
+	politica    *domain.PoliticaAprovacao
+	carregadaEm time.Time
+}
+
+// obter retorna a política vigente, recarregando-a se o cache expirou.
+// Se a carga falhar ou a política for inválida, mantém a última política
+// válida; sem nenhuma, usa a política padrão conservadora.
+func (c *politicaCache) obter(ctx context.Context, agora time.Time, logger domain.Logger) *domain.PoliticaAprovacao {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.politica != nil && agora.Sub(c.carregadaEm) < c.intervalo {
+		return c.politica
+	}
+
+	politica, err := c.repository.GetPoliticaAprovacao(ctx)
+	if err == nil {
+		err = politica.Valida()
+	}
+
+	if err != nil {
+		logger.Error(ctx, "falha ao carregar política de aprovação, usando fallback", err, map[string]interface{}{
+			"possui_politica_anterior": c.politica != nil,
+		})
+
+		if c.politica == nil {
+			c.politica = domain.PoliticaAprovacaoPadrao()
+		}
+		// Evita consultar a tabela a cada requisição enquanto ela estiver indisponível
+		c.carregadaEm = agora
+		return c.politica
+	}
+
+	c.politica = politica
+	c.carregadaEm = agora
+	return c.politica
+}
+
+// aplicarPolitica verifica a transação contra a política de aprovação vigente
+func (s *transacaoService) aplicarPolitica(ctx context.Context, transacao *domain.Transacao) error {
+	if s.politicas == nil {
+		return nil
+	}
+
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.aplicarPolitica")
+	defer s.tracer.FinishSpan(span, nil)
+
+	politica := s.politicas.obter(ctx, s.agora(), s.logger)
+	valorCentavos := int(transacao.ValorCentavos())
+
+	if valorCentavos > politica.ValorMaximoCentavos {
+		s.logger.Warn(ctx, "valor acima do máximo da política", map[string]interface{}{
+			"transacao_id":          transacao.ID,
+			"valor":                 transacao.Valor,
+			"valor_maximo_centavos": politica.ValorMaximoCentavos,
+		})
+		s.metricsCollector.IncrementErrorCounter("amount_above_maximum")
+		return domain.ErrValorAcimaDoMaximo
+	}
+
+	if politica.MultiplicadorLimiteDiario > 0 {
+		return s.verificarLimiteDiario(ctx, transacao, politica.MultiplicadorLimiteDiario, valorCentavos)
+	}
+
+	return nil
+}
+
+// verificarLimiteDiario soma as transações aprovadas no dia (UTC) e compara
+// com limite_credito * multiplicador
+func (s *transacaoService) verificarLimiteDiario(ctx context.Context, transacao *domain.Transacao, multiplicador float64, valorCentavos int) error {
+	if domain.SobrescritasDepuracaoDoContexto(ctx).DesligarVelocidade {
+		return nil
+	}
+
+	cliente, err := s.limiteRepository.GetCliente(ctx, transacao.ClienteID)
+	if err != nil {
+		// Cliente inexistente é tratado pelo débito atômico
+		return nil
+	}
+
+	// Leitura parcial (itens corrompidos) ainda permite somar os válidos
+	recentes, err := s.transacaoRepository.GetByClienteID(ctx, transacao.ClienteID, limiteConsultaDiaria)
+	if err != nil && !errors.Is(err, domain.ErrItensCorrompidos) {
+		s.logger.Error(ctx, "erro ao consultar transações do dia", err, map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"cliente_id":   transacao.ClienteID,
+		})
+		s.metricsCollector.IncrementErrorCounter("daily_limit_check_error")
+		return nil
+	}
+
+	inicioDia := transacao.Timestamp.UTC().Truncate(24 * time.Hour)
+	totalDia := 0
+	for _, anterior := range recentes {
+		if anterior.Status == domain.StatusAprovada && !anterior.Timestamp.Before(inicioDia) {
+			totalDia += int(anterior.ValorCentavos())
+		}
+	}
+
+	limiteDiario := int(float64(cliente.LimiteCredit) * multiplicador)
+	if totalDia+valorCentavos > limiteDiario {
+		// O acumulado só zera na virada do dia; valor acima do próprio limite
+		// diário não passa nem depois dela
+		if valorCentavos <= limiteDiario {
+			transacao.EsperaRecomendada = max(inicioDia.Add(24*time.Hour).Sub(s.agora()), time.Second)
+		}
+		s.logger.Warn(ctx, "limite diário excedido", map[string]interface{}{
+			"transacao_id":           transacao.ID,
+			"cliente_id":             transacao.ClienteID,
+			"total_dia_centavos":     totalDia,
+			"limite_diario_centavos": limiteDiario,
+			"espera_recomendada_s":   int(transacao.EsperaRecomendada.Seconds()),
+		})
+		s.metricsCollector.IncrementErrorCounter("daily_limit_exceeded")
+		return domain.ErrLimiteDiarioExcedido
+	}
+
+	return nil
+}
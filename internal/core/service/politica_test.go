@@ -0,0 +1,212 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/testutil"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePolicyRepository devolve a política configurada e conta as cargas
+type fakePolicyRepository struct {
+	mu       sync.Mutex
+	politica *domain.PoliticaAprovacao
+	err      error
+	cargas   int
+}
+
+func (r *fakePolicyRepository) GetPoliticaAprovacao(ctx context.Context) (*domain.PoliticaAprovacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cargas++
+	if r.err != nil {
+		return nil, r.err
+	}
+	copia := *r.politica
+	return &copia, nil
+}
+
+func (r *fakePolicyRepository) definir(politica *domain.PoliticaAprovacao, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.politica = politica
+	r.err = err
+}
+
+// relogioFake permite avançar o tempo manualmente
+type relogioFake struct {
+	mu    sync.Mutex
+	atual time.Time
+}
+
+func (r *relogioFake) agora() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.atual
+}
+
+func (r *relogioFake) avancar(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.atual = r.atual.Add(d)
+}
+
+func TestPolitica_CargaAplicaValorMaximo(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 1000000, LimiteAtual: 1000000})
+	repo := &fakePolicyRepository{politica: &domain.PoliticaAprovacao{ValorMaximoCentavos: 10000}}
+	svc := deps.service(WithPoliticaAprovacao(repo, time.Minute))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 100.00, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 100.01, "corr"))
+	if err != domain.ErrValorAcimaDoMaximo {
+		t.Errorf("Erro esperado %v, got %v", domain.ErrValorAcimaDoMaximo, err)
+	}
+
+	if repo.cargas != 1 {
+		t.Errorf("política deveria ser carregada uma vez dentro do intervalo, got %d cargas", repo.cargas)
+	}
+}
+
+func TestPolitica_RecarregaAposIntervalo(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 1000000, LimiteAtual: 1000000})
+	repo := &fakePolicyRepository{politica: &domain.PoliticaAprovacao{ValorMaximoCentavos: 10000}}
+	relogio := &relogioFake{atual: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)}
+	svc := deps.service(WithPoliticaAprovacao(repo, time.Minute), WithRelogio(relogio.agora))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 150.00, "corr")); err != domain.ErrValorAcimaDoMaximo {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrValorAcimaDoMaximo, err)
+	}
+
+	// Risco aumenta o máximo; ainda dentro do intervalo a política antiga vale
+	repo.definir(&domain.PoliticaAprovacao{ValorMaximoCentavos: 20000}, nil)
+	relogio.avancar(30 * time.Second)
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 150.00, "corr")); err != domain.ErrValorAcimaDoMaximo {
+		t.Fatalf("política antiga deveria valer dentro do intervalo, got %v", err)
+	}
+
+	relogio.avancar(31 * time.Second)
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 150.00, "corr")); err != nil {
+		t.Fatalf("nova política deveria valer após o intervalo, got %v", err)
+	}
+}
+
+func TestPolitica_Fallback(t *testing.T) {
+	padrao := domain.PoliticaAprovacaoPadrao()
+	acimaDoPadrao := float64(padrao.ValorMaximoCentavos+1) / 100
+
+	tests := []struct {
+		name     string
+		politica *domain.PoliticaAprovacao
+		err      error
+	}{
+		{name: "política ausente", err: errors.New("política de aprovação não encontrada")},
+		{name: "política corrompida", politica: &domain.PoliticaAprovacao{ValorMaximoCentavos: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 10000000, LimiteAtual: 10000000})
+			repo := &fakePolicyRepository{politica: tt.politica, err: tt.err}
+			svc := deps.service(WithPoliticaAprovacao(repo, time.Minute))
+
+			err := autorizar(context.Background(), svc, domain.NewTransacao("c1", acimaDoPadrao, "corr"))
+			if err != domain.ErrValorAcimaDoMaximo {
+				t.Errorf("política padrão deveria ser aplicada, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPolitica_FalhaNaRecargaMantemUltimaValida(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 10000000, LimiteAtual: 10000000})
+	repo := &fakePolicyRepository{politica: &domain.PoliticaAprovacao{ValorMaximoCentavos: 1000000}}
+	relogio := &relogioFake{atual: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)}
+	svc := deps.service(WithPoliticaAprovacao(repo, time.Minute), WithRelogio(relogio.agora))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 8000.00, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	repo.definir(nil, errors.New("timeout"))
+	relogio.avancar(2 * time.Minute)
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 8000.00, "corr")); err != nil {
+		t.Errorf("última política válida deveria ser mantida, got %v", err)
+	}
+}
+
+func TestPolitica_LimiteDiario(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 10000, LimiteAtual: 10000})
+	repo := &fakePolicyRepository{politica: &domain.PoliticaAprovacao{ValorMaximoCentavos: 100000, MultiplicadorLimiteDiario: 0.5}}
+	svc := deps.service(WithPoliticaAprovacao(repo, time.Minute))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 40.00, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 20.00, "corr"))
+	if err != domain.ErrLimiteDiarioExcedido {
+		t.Errorf("Erro esperado %v, got %v", domain.ErrLimiteDiarioExcedido, err)
+	}
+}
+
+func TestPolitica_LimiteDiarioEsperaRecomendada(t *testing.T) {
+	agora := time.Date(2024, 1, 15, 18, 30, 0, 0, time.UTC)
+	anterior := testutil.NewTransacaoBuilder().ComValor(40.00).ComTimestamp(agora.Add(-8 * time.Hour)).Aprovada().Build()
+
+	tests := []struct {
+		name   string
+		valor  float64
+		espera time.Duration
+	}{
+		// Limite diário de R$ 50,00: o acumulado de R$ 40,00 zera à meia-noite UTC
+		{name: "acumulado do dia esgota o limite", valor: 20.00, espera: 5*time.Hour + 30*time.Minute},
+		// Nem com o dia zerado o valor passaria: nova tentativa não adianta
+		{name: "valor acima do próprio limite diário", valor: 60.00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: testutil.ClienteIDPadrao, LimiteCredit: 10000, LimiteAtual: 10000})
+			deps.transacoes = newFakeTransacaoRepository(anterior)
+			repo := &fakePolicyRepository{politica: &domain.PoliticaAprovacao{ValorMaximoCentavos: 100000, MultiplicadorLimiteDiario: 0.5}}
+			svc := deps.service(WithPoliticaAprovacao(repo, time.Minute), WithRelogio(func() time.Time { return agora }))
+
+			transacao := testutil.NewTransacaoBuilder().ComValor(tt.valor).ComTimestamp(agora).Build()
+			resultado, err := svc.AutorizarTransacao(context.Background(), transacao)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if resultado.Motivo != domain.ErrLimiteDiarioExcedido {
+				t.Fatalf("motivo esperado %v, got %v", domain.ErrLimiteDiarioExcedido, resultado.Motivo)
+			}
+			if resultado.EsperaRecomendada != tt.espera {
+				t.Errorf("espera recomendada esperada %v, got %v", tt.espera, resultado.EsperaRecomendada)
+			}
+		})
+	}
+}
+
+func TestPolitica_LimiteDiarioDesligadoPorSobrescritaDeDepuracao(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 10000, LimiteAtual: 10000})
+	repo := &fakePolicyRepository{politica: &domain.PoliticaAprovacao{ValorMaximoCentavos: 100000, MultiplicadorLimiteDiario: 0.5}}
+	svc := deps.service(WithPoliticaAprovacao(repo, time.Minute))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 40.00, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	ctx := domain.ComSobrescritasDepuracao(context.Background(), domain.SobrescritasDepuracao{DesligarVelocidade: true})
+	if err := autorizar(ctx, svc, domain.NewTransacao("c1", 20.00, "corr")); err != nil {
+		t.Errorf("limite diário deveria ser ignorado com a sobrescrita, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+)
+
+// verificarPreAutorizacao consulta o callback de pré-autorização com timeout
+// próprio. Veto rejeita; erro ou timeout seguem a política de falha.
+func (s *transacaoService) verificarPreAutorizacao(ctx context.Context, transacao *domain.Transacao) error {
+	if s.preAuth == nil {
+		return nil
+	}
+
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.verificarPreAutorizacao")
+	defer s.tracer.FinishSpan(span, nil)
+
+	callbackCtx := ctx
+	if s.preAuthTimeout > 0 {
+		var cancel context.CancelFunc
+		callbackCtx, cancel = context.WithTimeout(ctx, s.preAuthTimeout)
+		defer cancel()
+	}
+
+	aprovada, err := s.preAuth.Autorizar(callbackCtx, transacao)
+	if err != nil {
+		timeout := errors.Is(err, context.DeadlineExceeded) || errors.Is(callbackCtx.Err(), context.DeadlineExceeded)
+
+		s.logger.Error(ctx, "falha no callback de pré-autorização", err, map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"timeout":      timeout,
+			"politica":     string(s.preAuthPolitica),
+		})
+		if timeout {
+			s.metricsCollector.IncrementErrorCounter("pre_auth_timeout")
+		} else {
+			s.metricsCollector.IncrementErrorCounter("pre_auth_error")
+		}
+
+		if s.preAuthPolitica == FalhaAberta {
+			s.tracer.AddTag(span, "pre_auth", "fail_open")
+			return nil
+		}
+		return domain.ErrPreAutorizacaoIndisponivel
+	}
+
+	if !aprovada {
+		s.tracer.AddTag(span, "pre_auth", "negada")
+		s.logger.Warn(ctx, "transação vetada pela pré-autorização", map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"cliente_id":   transacao.ClienteID,
+		})
+		s.metricsCollector.IncrementErrorCounter("pre_auth_denied")
+		return domain.ErrPreAutorizacaoNegada
+	}
+
+	s.tracer.AddTag(span, "pre_auth", "aprovada")
+	return nil
+}
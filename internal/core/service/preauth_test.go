@@ -0,0 +1,67 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+// fakePreAuth devolve a decisão configurada após o atraso, respeitando o contexto
+type fakePreAuth struct {
+	aprovada bool
+	atraso   time.Duration
+	chamadas int
+}
+
+func (f *fakePreAuth) Autorizar(ctx context.Context, transacao *domain.Transacao) (bool, error) {
+	f.chamadas++
+	select {
+	case <-time.After(f.atraso):
+		return f.aprovada, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func TestAutorizarTransacao_PreAutorizacao(t *testing.T) {
+	tests := []struct {
+		name         string
+		callback     *fakePreAuth
+		politica     PoliticaFalha
+		expectedErr  error
+		metricaErro  string
+		esperaDebito bool
+	}{
+		{name: "aprovada", callback: &fakePreAuth{aprovada: true}, politica: FalhaFechada, esperaDebito: true},
+		{name: "negada", callback: &fakePreAuth{aprovada: false}, politica: FalhaAberta, expectedErr: domain.ErrPreAutorizacaoNegada, metricaErro: "pre_auth_denied"},
+		{name: "timeout fail-open", callback: &fakePreAuth{aprovada: false, atraso: time.Second}, politica: FalhaAberta, metricaErro: "pre_auth_timeout", esperaDebito: true},
+		{name: "timeout fail-closed", callback: &fakePreAuth{aprovada: true, atraso: time.Second}, politica: FalhaFechada, expectedErr: domain.ErrPreAutorizacaoIndisponivel, metricaErro: "pre_auth_timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			svc := deps.service(WithPreAutorizacao(tt.callback, 20*time.Millisecond, tt.politica))
+
+			inicio := time.Now()
+			err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 10.00, "corr"))
+
+			if err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			if decorrido := time.Since(inicio); decorrido > 500*time.Millisecond {
+				t.Errorf("callback deveria ser interrompido pelo timeout, levou %s", decorrido)
+			}
+
+			if (deps.limites.debitos == 1) != tt.esperaDebito {
+				t.Errorf("débito esperado %v, got %d débitos", tt.esperaDebito, deps.limites.debitos)
+			}
+
+			if tt.metricaErro != "" && deps.metrics.errorCount(tt.metricaErro) != 1 {
+				t.Errorf("métrica %s deveria ser incrementada", tt.metricaErro)
+			}
+		})
+	}
+}
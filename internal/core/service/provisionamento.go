@@ -0,0 +1,46 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+)
+
+// provisionarCliente cria o cliente inexistente com o limite padrão
+// configurado. Retorna true se o débito deve ser repetido.
+func (s *transacaoService) provisionarCliente(ctx context.Context, transacao *domain.Transacao) bool {
+	if s.limiteProvisionamento <= 0 {
+		return false
+	}
+
+	repo, ok := s.limiteRepository.(domain.LimiteProvisionamentoRepository)
+	if !ok {
+		return false
+	}
+
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.provisionarCliente")
+	defer s.tracer.FinishSpan(span, nil)
+
+	criado, err := repo.ProvisionarCliente(ctx, transacao.ClienteID, s.limiteProvisionamento)
+	if err != nil {
+		s.logger.Error(ctx, "erro ao provisionar cliente", err, map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"cliente_id":   transacao.ClienteID,
+		})
+		s.metricsCollector.IncrementErrorCounter("client_provision_error")
+		return false
+	}
+
+	// Criado por uma requisição concorrente: basta repetir o débito
+	if !criado {
+		return true
+	}
+
+	s.logger.Info(ctx, "cliente provisionado com limite padrão", map[string]interface{}{
+		"transacao_id":    transacao.ID,
+		"cliente_id":      transacao.ClienteID,
+		"limite_centavos": s.limiteProvisionamento,
+	})
+	s.metricsCollector.IncrementOutcomeCounter("client_auto_provisioned")
+
+	return true
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+)
+
+func TestAutorizarTransacao_ClienteSemRegistroRejeitadoPorPadrao(t *testing.T) {
+	deps := newDependencias()
+	svc := deps.service()
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("novo", 100.00, "corr")); err != domain.ErrClienteNaoEncontrado {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrClienteNaoEncontrado, err)
+	}
+
+	if _, err := deps.limites.GetCliente(context.Background(), "novo"); err != domain.ErrClienteNaoEncontrado {
+		t.Error("cliente não deveria ser provisionado sem a opção")
+	}
+}
+
+func TestAutorizarTransacao_ProvisionamentoAutomatico(t *testing.T) {
+	tests := []struct {
+		name           string
+		valor          float64
+		expectedErr    error
+		limiteEsperado int
+	}{
+		{name: "primeira transação dentro do limite padrão", valor: 100.00, limiteEsperado: 40000},
+		{name: "primeira transação acima do limite padrão", valor: 600.00, expectedErr: domain.ErrLimiteInsuficiente, limiteEsperado: 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias()
+			svc := deps.service(WithProvisionamentoAutomatico(50000))
+
+			if err := autorizar(context.Background(), svc, domain.NewTransacao("novo", tt.valor, "corr")); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			cliente, err := deps.limites.GetCliente(context.Background(), "novo")
+			if err != nil {
+				t.Fatalf("cliente deveria ter sido provisionado: %v", err)
+			}
+
+			if cliente.LimiteCredit != 50000 || cliente.LimiteAtual != tt.limiteEsperado {
+				t.Errorf("limites esperados 50000/%d, got %d/%d", tt.limiteEsperado, cliente.LimiteCredit, cliente.LimiteAtual)
+			}
+
+			if deps.metrics.outcomeCount("client_auto_provisioned") != 1 {
+				t.Errorf("métrica client_auto_provisioned esperada 1, got %d", deps.metrics.outcomeCount("client_auto_provisioned"))
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_ProvisionamentoNaoAlteraClienteExistente(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 1000})
+	svc := deps.service(WithProvisionamentoAutomatico(50000))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 100.00, "corr")); err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+
+	if deps.metrics.outcomeCount("client_auto_provisioned") != 0 {
+		t.Error("cliente existente não deveria ser provisionado")
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// RelatorioReconciliacao compara transações aprovadas e eventos confirmados
+// em uma janela de tempo
+type RelatorioReconciliacao struct {
+	Inicio           time.Time `json:"inicio"`
+	Fim              time.Time `json:"fim"`
+	TotalAprovadas   int       `json:"total_aprovadas"`
+	TotalConfirmadas int       `json:"total_confirmadas"`
+	// NaoConfirmadas lista as transações aprovadas sem evento confirmado
+	NaoConfirmadas []string `json:"nao_confirmadas"`
+}
+
+// Divergente indica que há transações aprovadas sem evento confirmado
+func (r *RelatorioReconciliacao) Divergente() bool {
+	return len(r.NaoConfirmadas) > 0
+}
+
+// ReconciliacaoService detecta eventos perdidos pela publicação assíncrona
+// (fire-and-forget) comparando as transações aprovadas com as confirmações
+type ReconciliacaoService struct {
+	transacoes       domain.TransacaoPeriodoRepository
+	ackStore         domain.EventAckStore
+	metricsCollector domain.MetricsCollector
+	logger           domain.Logger
+}
+
+func NewReconciliacaoService(
+	transacoes domain.TransacaoPeriodoRepository,
+	ackStore domain.EventAckStore,
+	metricsCollector domain.MetricsCollector,
+	logger domain.Logger,
+) *ReconciliacaoService {
+	return &ReconciliacaoService{
+		transacoes:       transacoes,
+		ackStore:         ackStore,
+		metricsCollector: metricsCollector,
+		logger:           logger,
+	}
+}
+
+// Reconciliar gera o relatório da janela [inicio, fim] e publica o tamanho
+// da divergência na métrica event_reconciliation_gap
+func (s *ReconciliacaoService) Reconciliar(ctx context.Context, inicio, fim time.Time) (*RelatorioReconciliacao, error) {
+	aprovadas, err := s.transacoes.ListarAprovadasNoPeriodo(ctx, inicio, fim)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar transações aprovadas: %w", err)
+	}
+
+	ids := make([]string, 0, len(aprovadas))
+	for _, transacao := range aprovadas {
+		ids = append(ids, transacao.ID)
+	}
+
+	confirmados, err := s.ackStore.Confirmados(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar eventos confirmados: %w", err)
+	}
+
+	relatorio := &RelatorioReconciliacao{
+		Inicio:         inicio,
+		Fim:            fim,
+		TotalAprovadas: len(ids),
+		NaoConfirmadas: make([]string, 0),
+	}
+
+	for _, id := range ids {
+		if confirmados[id] {
+			relatorio.TotalConfirmadas++
+		} else {
+			relatorio.NaoConfirmadas = append(relatorio.NaoConfirmadas, id)
+		}
+	}
+
+	s.metricsCollector.RecordBusinessMetric("event_reconciliation_gap", float64(len(relatorio.NaoConfirmadas)), nil)
+
+	campos := map[string]interface{}{
+		"inicio":            inicio,
+		"fim":               fim,
+		"total_aprovadas":   relatorio.TotalAprovadas,
+		"total_confirmadas": relatorio.TotalConfirmadas,
+		"nao_confirmadas":   relatorio.NaoConfirmadas,
+	}
+	if relatorio.Divergente() {
+		s.logger.Warn(ctx, "eventos não confirmados na reconciliação", campos)
+	} else {
+		s.logger.Info(ctx, "reconciliação sem divergências", campos)
+	}
+
+	return relatorio, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// tamanhoPaginaReconciliacaoLimite é o tamanho das páginas do histórico lidas
+// na reconciliação de limite
+const tamanhoPaginaReconciliacaoLimite = 100
+
+// retencaoHistoricoPadrao é a retenção das transações na tabela (TTL de 90
+// dias): transações mais antigas não estão mais no histórico
+const retencaoHistoricoPadrao = 90 * 24 * time.Hour
+
+// correlationIDReconciliacao identifica os eventos de ajuste da reconciliação
+const correlationIDReconciliacao = "reconciliacao-limite"
+
+// RelatorioLimite compara o limite utilizado registrado no cliente
+// (limite_credito - limite_atual) com a soma das transações aprovadas
+type RelatorioLimite struct {
+	ClienteID string `json:"cliente_id"`
+	// UtilizadoRegistrado é limite_credito - limite_atual, em centavos
+	UtilizadoRegistrado int64 `json:"utilizado_registrado"`
+	// UtilizadoTransacoes é a soma das transações aprovadas, em centavos
+	UtilizadoTransacoes int64 `json:"utilizado_transacoes"`
+	// Delta é UtilizadoRegistrado - UtilizadoTransacoes: positivo quando o
+	// limite atual está abaixo do esperado
+	Delta int64 `json:"delta"`
+	// HistoricoCompleto indica que o cliente foi criado dentro da retenção
+	// do histórico; caso contrário a comparação é inconclusiva
+	HistoricoCompleto bool `json:"historico_completo"`
+	// Corrigido indica que limite_atual foi regravado com o valor esperado
+	Corrigido bool `json:"corrigido"`
+}
+
+// Divergente indica que limite_atual não bate com o histórico de transações;
+// sem histórico completo não há divergência conclusiva
+func (r *RelatorioLimite) Divergente() bool {
+	return r.HistoricoCompleto && r.Delta != 0
+}
+
+// ReconciliacaoLimiteService detecta divergências entre limite_atual e o
+// histórico de transações de um cliente. Apenas reporta, exceto com
+// WithCorrecaoLimite. É uma ferramenta administrativa, fora do caminho
+// crítico.
+type ReconciliacaoLimiteService struct {
+	limites          domain.LimiteRepository
+	historico        domain.TransacaoHistoricoRepository
+	metricsCollector domain.MetricsCollector
+	logger           domain.Logger
+
+	ajuste   domain.LimiteAjusteRepository
+	eventos  domain.LimiteEventPublisher
+	retencao time.Duration
+	agora    func() time.Time
+}
+
+// OpcaoReconciliacaoLimite configura comportamentos opcionais da
+// reconciliação de limite
+type OpcaoReconciliacaoLimite func(*ReconciliacaoLimiteService)
+
+// WithCorrecaoLimite corrige as divergências conclusivas, regravando
+// limite_atual condicionado ao valor lido, e publica o evento
+// LIMITE_ALTERADO com motivo AJUSTE (publisher opcional)
+func WithCorrecaoLimite(ajuste domain.LimiteAjusteRepository, eventos domain.LimiteEventPublisher) OpcaoReconciliacaoLimite {
+	return func(s *ReconciliacaoLimiteService) {
+		s.ajuste = ajuste
+		s.eventos = eventos
+	}
+}
+
+// WithRetencaoHistorico informa por quanto tempo as transações ficam no
+// histórico (padrão: 90 dias, o TTL da tabela)
+func WithRetencaoHistorico(retencao time.Duration) OpcaoReconciliacaoLimite {
+	return func(s *ReconciliacaoLimiteService) {
+		if retencao > 0 {
+			s.retencao = retencao
+		}
+	}
+}
+
+// WithRelogioReconciliacao substitui a fonte de tempo (útil em testes)
+func WithRelogioReconciliacao(agora func() time.Time) OpcaoReconciliacaoLimite {
+	return func(s *ReconciliacaoLimiteService) {
+		s.agora = agora
+	}
+}
+
+func NewReconciliacaoLimiteService(
+	limites domain.LimiteRepository,
+	historico domain.TransacaoHistoricoRepository,
+	metricsCollector domain.MetricsCollector,
+	logger domain.Logger,
+	opts ...OpcaoReconciliacaoLimite,
+) *ReconciliacaoLimiteService {
+	s := &ReconciliacaoLimiteService{
+		limites:          limites,
+		historico:        historico,
+		metricsCollector: metricsCollector,
+		logger:           logger,
+		retencao:         retencaoHistoricoPadrao,
+		agora:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ReconciliarLimite soma as transações aprovadas do cliente e compara com o
+// limite utilizado registrado. A comparação só é conclusiva para clientes
+// criados dentro da retenção do histórico: débitos mais antigos já expiraram.
+// Créditos e pagamentos feitos fora deste serviço não estão no histórico e
+// também aparecem como divergência. Não há estornos neste serviço: toda
+// transação aprovada conta como débito.
+func (s *ReconciliacaoLimiteService) ReconciliarLimite(ctx context.Context, clienteID string) (*RelatorioLimite, error) {
+	cliente, err := s.limites.GetCliente(ctx, clienteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var utilizado int64
+	err = s.historico.PercorrerPorClienteID(ctx, clienteID, tamanhoPaginaReconciliacaoLimite, func(pagina []*domain.Transacao) error {
+		for _, transacao := range pagina {
+			if transacao.Status == domain.StatusAprovada {
+				utilizado += transacao.ValorCentavos()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao percorrer transações do cliente %s: %w", clienteID, err)
+	}
+
+	relatorio := &RelatorioLimite{
+		ClienteID:           clienteID,
+		UtilizadoRegistrado: int64(cliente.LimiteCredit) - int64(cliente.LimiteAtual),
+		UtilizadoTransacoes: utilizado,
+		HistoricoCompleto:   !cliente.CreatedAt.IsZero() && cliente.CreatedAt.After(s.agora().Add(-s.retencao)),
+	}
+	relatorio.Delta = relatorio.UtilizadoRegistrado - relatorio.UtilizadoTransacoes
+
+	campos := map[string]interface{}{
+		"cliente_id":           clienteID,
+		"utilizado_registrado": relatorio.UtilizadoRegistrado,
+		"utilizado_transacoes": relatorio.UtilizadoTransacoes,
+		"delta":                relatorio.Delta,
+	}
+
+	if !relatorio.HistoricoCompleto {
+		s.logger.Info(ctx, "reconciliação inconclusiva: cliente anterior à retenção do histórico", campos)
+		return relatorio, nil
+	}
+
+	if !relatorio.Divergente() {
+		s.logger.Info(ctx, "limite consistente com as transações", campos)
+		return relatorio, nil
+	}
+
+	s.metricsCollector.IncrementErrorCounter("limit_reconciliation_drift")
+	s.logger.Warn(ctx, "limite divergente das transações", campos)
+
+	if s.ajuste == nil {
+		return relatorio, nil
+	}
+
+	esperado := int(int64(cliente.LimiteCredit) - utilizado)
+	if err := s.ajuste.AjustarLimite(ctx, clienteID, cliente.LimiteAtual, esperado); err != nil {
+		return relatorio, fmt.Errorf("erro ao corrigir limite do cliente %s: %w", clienteID, err)
+	}
+	relatorio.Corrigido = true
+
+	s.logger.Warn(ctx, "limite corrigido pela reconciliação", map[string]interface{}{
+		"cliente_id":      clienteID,
+		"limite_anterior": cliente.LimiteAtual,
+		"limite_atual":    esperado,
+	})
+
+	s.publicarAjuste(ctx, clienteID, cliente.LimiteAtual, esperado)
+
+	return relatorio, nil
+}
+
+// publicarAjuste publica o evento AJUSTE da correção; a falha não desfaz o
+// ajuste, apenas é registrada
+func (s *ReconciliacaoLimiteService) publicarAjuste(ctx context.Context, clienteID string, anterior, atual int) {
+	if s.eventos == nil {
+		return
+	}
+
+	evento := domain.NewLimiteAlteradoEvento(clienteID, int64(anterior), int64(atual), domain.MotivoLimiteAjuste, correlationIDReconciliacao, s.agora())
+	if err := s.eventos.PublishLimiteAlterado(ctx, evento); err != nil {
+		s.logger.Error(ctx, "falha ao publicar ajuste de limite", err, map[string]interface{}{
+			"cliente_id": clienteID,
+			"delta":      evento.Delta,
+		})
+		s.metricsCollector.IncrementErrorCounter("limit_event_publish_error")
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// agoraReconciliacao é o relógio fixo dos testes de reconciliação
+var agoraReconciliacao = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// depsReconciliacao monta um cliente criado em criadoEm com 300,00 aprovados
+// em dez transações; rejeitadas e de outros clientes não contam
+func depsReconciliacao(limiteAtual int, criadoEm time.Time) *dependencias {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: limiteAtual, CreatedAt: criadoEm})
+
+	for i := 0; i < 10; i++ {
+		aprovada := domain.NewTransacao("c1", 30.00, "corr")
+		aprovada.Aprovar()
+		deps.transacoes.Save(context.Background(), aprovada)
+	}
+	rejeitada := domain.NewTransacao("c1", 500.00, "corr")
+	rejeitada.Rejeitar()
+	deps.transacoes.Save(context.Background(), rejeitada)
+	outroCliente := domain.NewTransacao("c2", 40.00, "corr")
+	outroCliente.Aprovar()
+	deps.transacoes.Save(context.Background(), outroCliente)
+
+	return deps
+}
+
+func TestReconciliarLimite(t *testing.T) {
+	recente := agoraReconciliacao.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		limiteAtual   int
+		criadoEm      time.Time
+		corrigir      bool
+		esperaDelta   int64
+		divergente    bool
+		corrigido     bool
+		esperaLimite  int
+		esperaMetrica int
+	}{
+		{name: "cliente consistente", limiteAtual: 70000, criadoEm: recente, esperaLimite: 70000},
+		{name: "divergente apenas reporta por padrão", limiteAtual: 65000, criadoEm: recente, esperaDelta: 5000, divergente: true, esperaLimite: 65000, esperaMetrica: 1},
+		{name: "divergente com correção", limiteAtual: 72000, criadoEm: recente, corrigir: true, esperaDelta: -2000, divergente: true, corrigido: true, esperaLimite: 70000, esperaMetrica: 1},
+		{name: "cliente anterior à retenção é inconclusivo", limiteAtual: 65000, criadoEm: agoraReconciliacao.Add(-120 * 24 * time.Hour), corrigir: true, esperaDelta: 5000, esperaLimite: 65000},
+		{name: "cliente sem data de criação é inconclusivo", limiteAtual: 65000, corrigir: true, esperaDelta: 5000, esperaLimite: 65000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := depsReconciliacao(tt.limiteAtual, tt.criadoEm)
+			eventos := &fakeLimiteEventPublisher{}
+
+			opts := []OpcaoReconciliacaoLimite{WithRelogioReconciliacao(func() time.Time { return agoraReconciliacao })}
+			if tt.corrigir {
+				opts = append(opts, WithCorrecaoLimite(deps.limites, eventos))
+			}
+			reconciliacao := NewReconciliacaoLimiteService(deps.limites, deps.transacoes, deps.metrics, &fakeLogger{}, opts...)
+
+			relatorio, err := reconciliacao.ReconciliarLimite(context.Background(), "c1")
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if relatorio.UtilizadoTransacoes != 30000 {
+				t.Errorf("utilizado pelas transações esperado 30000, got %d", relatorio.UtilizadoTransacoes)
+			}
+			if relatorio.Delta != tt.esperaDelta || relatorio.Divergente() != tt.divergente {
+				t.Errorf("delta esperado %d (divergente %v), got %d (%v)", tt.esperaDelta, tt.divergente, relatorio.Delta, relatorio.Divergente())
+			}
+			if relatorio.Corrigido != tt.corrigido {
+				t.Errorf("corrigido esperado %v, got %v", tt.corrigido, relatorio.Corrigido)
+			}
+
+			cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+			if cliente.LimiteAtual != tt.esperaLimite {
+				t.Errorf("limite atual esperado %d, got %d", tt.esperaLimite, cliente.LimiteAtual)
+			}
+			if got := deps.metrics.errorCount("limit_reconciliation_drift"); got != tt.esperaMetrica {
+				t.Errorf("métrica limit_reconciliation_drift esperada %d, got %d", tt.esperaMetrica, got)
+			}
+
+			if !tt.corrigido {
+				if len(eventos.eventos) != 0 {
+					t.Errorf("sem correção não deveria haver evento, got %d", len(eventos.eventos))
+				}
+				return
+			}
+			if len(eventos.eventos) != 1 {
+				t.Fatalf("esperado um evento de ajuste, got %d", len(eventos.eventos))
+			}
+			evento := eventos.eventos[0]
+			if evento.Motivo != domain.MotivoLimiteAjuste || evento.LimiteAnterior != int64(tt.limiteAtual) || evento.LimiteAtual != int64(tt.esperaLimite) {
+				t.Errorf("evento de ajuste inesperado: %+v", evento)
+			}
+			if !evento.Timestamp.Equal(agoraReconciliacao) {
+				t.Errorf("timestamp esperado do relógio da reconciliação %v, got %v", agoraReconciliacao, evento.Timestamp)
+			}
+		})
+	}
+}
+
+// limiteComDebitoConcorrente debita o cliente logo antes do ajuste, como um
+// débito que chega entre a leitura e a correção
+type limiteComDebitoConcorrente struct {
+	*fakeLimiteRepository
+}
+
+func (r *limiteComDebitoConcorrente) AjustarLimite(ctx context.Context, clienteID string, lido, novoLimite int) error {
+	r.DebitarLimiteAtomica(ctx, clienteID, 1000)
+	return r.fakeLimiteRepository.AjustarLimite(ctx, clienteID, lido, novoLimite)
+}
+
+func TestReconciliarLimite_DebitoConcorrenteNaoEPerdido(t *testing.T) {
+	deps := depsReconciliacao(72000, agoraReconciliacao.Add(-time.Hour))
+	eventos := &fakeLimiteEventPublisher{}
+	reconciliacao := NewReconciliacaoLimiteService(deps.limites, deps.transacoes, deps.metrics, &fakeLogger{},
+		WithRelogioReconciliacao(func() time.Time { return agoraReconciliacao }),
+		WithCorrecaoLimite(&limiteComDebitoConcorrente{deps.limites}, eventos),
+	)
+
+	relatorio, err := reconciliacao.ReconciliarLimite(context.Background(), "c1")
+	if !errors.Is(err, domain.ErrLimiteAlteradoConcorrente) {
+		t.Fatalf("esperado ErrLimiteAlteradoConcorrente, got %v", err)
+	}
+	if relatorio == nil || relatorio.Corrigido {
+		t.Errorf("relatório deveria indicar que não houve correção, got %+v", relatorio)
+	}
+
+	cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+	if cliente.LimiteAtual != 71000 {
+		t.Errorf("o débito concorrente deveria ser preservado (71000), got %d", cliente.LimiteAtual)
+	}
+	if len(eventos.eventos) != 0 {
+		t.Error("ajuste não aplicado não deveria publicar evento")
+	}
+}
+
+func TestReconciliarLimite_ClienteInexistente(t *testing.T) {
+	deps := newDependencias()
+	reconciliacao := NewReconciliacaoLimiteService(deps.limites, deps.transacoes, deps.metrics, &fakeLogger{})
+
+	if _, err := reconciliacao.ReconciliarLimite(context.Background(), "c1"); err != domain.ErrClienteNaoEncontrado {
+		t.Errorf("esperado ErrClienteNaoEncontrado, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReconciliar_SinalizaEventoNaoConfirmado(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	acks := newFakeAckStore()
+	svc := deps.service(WithConfirmacaoEventos(acks))
+
+	inicio := time.Now().Add(-time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 10.00, "corr")); err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	}
+	aguardar(t, func() bool { return acks.total() == 3 })
+
+	// Transação aprovada cujo evento se perdeu (nenhuma confirmação)
+	perdida := domain.NewTransacao("c1", 20.00, "corr-perdida")
+	perdida.Aprovar()
+	deps.transacoes.Save(context.Background(), perdida)
+
+	// Rejeitadas não entram na reconciliação
+	rejeitada := domain.NewTransacao("c1", 30.00, "corr-rejeitada")
+	rejeitada.Rejeitar()
+	deps.transacoes.Save(context.Background(), rejeitada)
+
+	reconciliacao := NewReconciliacaoService(deps.transacoes, acks, deps.metrics, &fakeLogger{})
+
+	relatorio, err := reconciliacao.Reconciliar(context.Background(), inicio, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if !relatorio.Divergente() {
+		t.Fatal("relatório deveria sinalizar divergência")
+	}
+
+	if relatorio.TotalAprovadas != 4 || relatorio.TotalConfirmadas != 3 {
+		t.Errorf("esperado 4 aprovadas e 3 confirmadas, got %d e %d", relatorio.TotalAprovadas, relatorio.TotalConfirmadas)
+	}
+
+	if len(relatorio.NaoConfirmadas) != 1 || relatorio.NaoConfirmadas[0] != perdida.ID {
+		t.Errorf("não confirmadas esperado [%s], got %v", perdida.ID, relatorio.NaoConfirmadas)
+	}
+
+	deps.metrics.mu.Lock()
+	defer deps.metrics.mu.Unlock()
+	ultima := deps.metrics.business[len(deps.metrics.business)-1]
+	if ultima.name != "event_reconciliation_gap" || ultima.value != 1 {
+		t.Errorf("métrica event_reconciliation_gap esperada 1, got %+v", ultima)
+	}
+}
+
+func TestReconciliar_SemDivergencia(t *testing.T) {
+	deps := newDependencias()
+	acks := newFakeAckStore()
+
+	transacao := domain.NewTransacao("c1", 10.00, "corr")
+	transacao.Aprovar()
+	deps.transacoes.Save(context.Background(), transacao)
+	acks.RegistrarConfirmacao(context.Background(), transacao.ID)
+
+	reconciliacao := NewReconciliacaoService(deps.transacoes, acks, deps.metrics, &fakeLogger{})
+
+	relatorio, err := reconciliacao.Reconciliar(context.Background(), time.Now().Add(-time.Minute), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if relatorio.Divergente() {
+		t.Errorf("relatório não deveria sinalizar divergência: %v", relatorio.NaoConfirmadas)
+	}
+}
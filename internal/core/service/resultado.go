@@ -0,0 +1,115 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"errors"
+	"time"
+)
+
+// Resultado é o desfecho de negócio de uma autorização. Recusas (validação,
+// política, limite, step-up) são valores, não erros: o erro retornado junto
+// fica reservado a falhas de infraestrutura (timeout, throttling, configuração).
+type Resultado struct {
+	// Status é APROVADA, REJEITADA ou PENDENTE (aguardando step-up)
+	Status string
+	// CodigoMotivo identifica a recusa (ex.: insufficient_limit); vazio se aprovada
+	CodigoMotivo string
+	// Motivo é o erro de domínio da recusa, para errors.Is/As (ex.: desafio de step-up)
+	Motivo error
+	// LimiteDisponivel é o limite em centavos após o débito, quando o
+	// repositório o informa
+	LimiteDisponivel *int
+	// LimiteRestante é o limite disponível somado ao cheque especial
+	// concedido no débito; nil quando não houve débito nesta autorização
+	LimiteRestante *int
+	// EsperaRecomendada é o tempo até a janela da recusa liberar (velocidade);
+	// zero quando uma nova tentativa não mudaria o desfecho
+	EsperaRecomendada time.Duration
+}
+
+// Aprovada indica transação autorizada
+func (r *Resultado) Aprovada() bool {
+	return r.Status == domain.StatusAprovada
+}
+
+// motivosRecusa são as recusas de negócio com seu código estável; qualquer
+// outro erro é tratado como falha de infraestrutura
+var motivosRecusa = []struct {
+	err    error
+	codigo string
+}{
+	{domain.ErrLimiteInsuficiente, "insufficient_limit"},
+	{domain.ErrClienteNaoEncontrado, "client_not_found"},
+	{domain.ErrClienteInvalido, "invalid_client"},
+	{domain.ErrValorNegativo, "invalid_amount"},
+	{domain.ErrValorZero, "invalid_amount"},
+	{domain.ErrPrecisaoInvalida, "invalid_precision"},
+	{domain.ErrMoedaInvalida, "invalid_currency"},
+	{domain.ErrMoedaNaoSuportada, "unsupported_currency"},
+	{domain.ErrParcelasInvalidas, "invalid_installments"},
+	{domain.ErrTagsExcedidas, "invalid_tags"},
+	{domain.ErrTagInvalida, "invalid_tags"},
+	{domain.ErrDataAgendamentoInvalida, "invalid_schedule_date"},
+	{domain.ErrTimestampInvalido, "invalid_timestamp"},
+	{domain.ErrParcelasAcimaDoPermitido, "installments_above_allowance"},
+	{domain.ErrValorAcimaDoMaximo, "amount_above_maximum"},
+	{domain.ErrLimiteDiarioExcedido, "daily_limit_exceeded"},
+	{domain.ErrTransacaoDuplicadaSuspeita, "suspected_duplicate"},
+	{domain.ErrPreAutorizacaoNegada, "pre_authorization_denied"},
+	{domain.ErrStepUpNecessario, "step_up_required"},
+	{domain.ErrConflitoIdempotencia, "idempotency_key_conflict"},
+	{domain.ErrIdempotenciaEmProcessamento, "idempotency_key_in_progress"},
+}
+
+// novoResultado classifica o desfecho do fluxo de autorização
+func novoResultado(transacao *domain.Transacao, err error) (*Resultado, error) {
+	if err == nil {
+		return &Resultado{Status: transacao.Status, LimiteDisponivel: transacao.LimiteDisponivel, LimiteRestante: transacao.LimiteRestante}, nil
+	}
+
+	codigo, ok := codigoRecusa(err)
+	if !ok {
+		return nil, err
+	}
+
+	// O step-up não rejeita: a transação aguarda a confirmação do cliente
+	status := domain.StatusRejeitada
+	if errors.Is(err, domain.ErrStepUpNecessario) {
+		status = domain.StatusPendente
+	}
+	return &Resultado{Status: status, CodigoMotivo: codigo, Motivo: err, EsperaRecomendada: transacao.EsperaRecomendada}, nil
+}
+
+// codigoRecusa retorna o código da recusa de negócio; ok é falso para
+// falhas de infraestrutura
+func codigoRecusa(err error) (codigo string, ok bool) {
+	for _, recusa := range motivosRecusa {
+		if errors.Is(err, recusa.err) {
+			return recusa.codigo, true
+		}
+	}
+	return "", false
+}
+
+// motivoDoCodigo reconstrói o erro de domínio de uma recusa registrada pelo
+// código (o primeiro erro com aquele código); nil se não houver
+func motivoDoCodigo(codigo string) error {
+	for _, recusa := range motivosRecusa {
+		if recusa.codigo == codigo {
+			return recusa.err
+		}
+	}
+	return nil
+}
+
+// codigoMotivo identifica o motivo de qualquer rejeição; falhas de
+// infraestrutura usam codigoMotivoInterno
+func codigoMotivo(err error) string {
+	if codigo, ok := codigoRecusa(err); ok {
+		return codigo
+	}
+	return codigoMotivoInterno
+}
+
+// codigoMotivoInterno marca rejeições por falha de infraestrutura
+const codigoMotivoInterno = "internal_error"
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// limiteIndisponivel estoura o timeout em todo débito
+type limiteIndisponivel struct {
+	*fakeLimiteRepository
+}
+
+func (r limiteIndisponivel) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	return 0, fmt.Errorf("debitar limite: %w", domain.ErrTimeoutOperacao)
+}
+
+func (r limiteIndisponivel) DebitarLimiteComChequeEspecial(ctx context.Context, clienteID string, valor, chequeEspecial int) (int, error) {
+	return r.DebitarLimiteAtomica(ctx, clienteID, valor)
+}
+
+func TestAutorizarTransacao_Resultado(t *testing.T) {
+	tests := []struct {
+		name         string
+		valor        float64
+		indisponivel bool
+		opts         []Option
+		status       string
+		codigo       string
+		motivo       error
+		esperaErr    error
+	}{
+		{name: "aprovada", valor: 10.00, status: domain.StatusAprovada},
+		{name: "limite insuficiente", valor: 5000.00, status: domain.StatusRejeitada, codigo: "insufficient_limit", motivo: domain.ErrLimiteInsuficiente},
+		{name: "validação", valor: -1, status: domain.StatusRejeitada, codigo: "invalid_amount", motivo: domain.ErrValorNegativo},
+		{name: "step-up pendente", valor: 500.00, opts: []Option{WithStepUp(10000, &fakeStepUp{})},
+			status: domain.StatusPendente, codigo: "step_up_required", motivo: domain.ErrStepUpNecessario},
+		{name: "falha de infraestrutura", valor: 10.00, indisponivel: true, esperaErr: domain.ErrTimeoutOperacao},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			var limites domain.LimiteRepository = deps.limites
+			if tt.indisponivel {
+				limites = limiteIndisponivel{deps.limites}
+			}
+			svc := NewTransacaoService(limites, deps.transacoes, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{}, tt.opts...)
+
+			resultado, err := svc.AutorizarTransacao(context.Background(), domain.NewTransacao("c1", tt.valor, "corr"))
+
+			if tt.esperaErr != nil {
+				if !errors.Is(err, tt.esperaErr) || resultado != nil {
+					t.Fatalf("esperado só o erro %v, got %+v, %v", tt.esperaErr, resultado, err)
+				}
+				return
+			}
+
+			// Recusa de negócio não é erro
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if resultado.Status != tt.status || resultado.CodigoMotivo != tt.codigo {
+				t.Errorf("esperado %s/%q, got %s/%q", tt.status, tt.codigo, resultado.Status, resultado.CodigoMotivo)
+			}
+			if !errors.Is(resultado.Motivo, tt.motivo) || (tt.motivo == nil && resultado.Motivo != nil) {
+				t.Errorf("motivo esperado %v, got %v", tt.motivo, resultado.Motivo)
+			}
+			if resultado.Aprovada() != (tt.status == domain.StatusAprovada) {
+				t.Error("Aprovada inconsistente com o status")
+			}
+			if resultado.Aprovada() && (resultado.LimiteDisponivel == nil || *resultado.LimiteDisponivel != 99000) {
+				t.Errorf("limite disponível esperado 99000, got %v", resultado.LimiteDisponivel)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+)
+
+// retentarDebito relê o cliente após a falha condicional e repete o débito
+// uma única vez se um crédito concorrente tornou o saldo suficiente. Sem
+// saldo na releitura, a falha original é mantida sem nova escrita.
+func (s *transacaoService) retentarDebito(ctx context.Context, transacao *domain.Transacao, valorCentavos, chequeEspecial int, falha error) error {
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.retentarDebito")
+	defer s.tracer.FinishSpan(span, nil)
+
+	ctx, cancel := context.WithTimeout(ctx, s.prazoRetentativaDebito)
+	defer cancel()
+
+	cliente, err := s.limiteRepository.GetCliente(ctx, transacao.ClienteID)
+	if err != nil || !(domain.LimitePolicy{ChequeEspecial: chequeEspecial}).PermiteLimiteAtual(cliente.LimiteAtual, valorCentavos) {
+		s.tracer.AddTag(span, "retentativa", "descartada")
+		return falha
+	}
+
+	s.tracer.AddTag(span, "retentativa", "executada")
+	err = s.debitar(ctx, transacao, valorCentavos, chequeEspecial)
+
+	resultado := "debit_retry_approved"
+	if err != nil {
+		resultado = "debit_retry_rejected"
+	}
+	s.logger.Info(ctx, "débito repetido após crédito concorrente", map[string]interface{}{
+		"transacao_id": transacao.ID,
+		"cliente_id":   transacao.ClienteID,
+		"limite_atual": cliente.LimiteAtual,
+		"aprovado":     err == nil,
+	})
+	s.metricsCollector.IncrementOutcomeCounter(resultado)
+
+	return err
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/testutil"
+	"context"
+	"testing"
+	"time"
+)
+
+// creditoConcorrenteRepository falha o primeiro débito como se o limite
+// fosse insuficiente e, logo em seguida, aplica um crédito concorrente
+type creditoConcorrenteRepository struct {
+	*fakeLimiteRepository
+	credito    int
+	tentativas int
+}
+
+func (r *creditoConcorrenteRepository) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	r.tentativas++
+	if r.tentativas == 1 {
+		cliente, _ := r.fakeLimiteRepository.GetCliente(ctx, clienteID)
+		r.fakeLimiteRepository.UpdateLimite(ctx, clienteID, cliente.LimiteAtual+r.credito)
+		return 0, domain.ErrLimiteInsuficiente
+	}
+	return r.fakeLimiteRepository.DebitarLimiteAtomica(ctx, clienteID, valor)
+}
+
+func TestAutorizarTransacao_RetentativaDebitoAposCreditoConcorrente(t *testing.T) {
+	tests := []struct {
+		name               string
+		opts               []Option
+		credito            int
+		expectedErr        error
+		tentativasEsperada int
+		limiteFinal        int
+	}{
+		{name: "desligada por padrão", credito: 50000, expectedErr: domain.ErrLimiteInsuficiente, tentativasEsperada: 1, limiteFinal: 60000},
+		{name: "crédito concorrente aprova na nova tentativa", opts: []Option{WithRetentativaDebito(50 * time.Millisecond)}, credito: 50000, tentativasEsperada: 2, limiteFinal: 10000},
+		{name: "saldo ainda insuficiente não repete o débito", opts: []Option{WithRetentativaDebito(50 * time.Millisecond)}, credito: 1000, expectedErr: domain.ErrLimiteInsuficiente, tentativasEsperada: 1, limiteFinal: 11000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias()
+			limites := &creditoConcorrenteRepository{
+				fakeLimiteRepository: newFakeLimiteRepository(testutil.NewClienteBuilder().ComLimiteAtual(10000).Build()),
+				credito:              tt.credito,
+			}
+			svc := NewTransacaoService(limites, deps.transacoes, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{}, tt.opts...)
+
+			transacao := testutil.NewTransacaoBuilder().ComValor(500.00).Build()
+			if err := autorizar(context.Background(), svc, transacao); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			if limites.tentativas != tt.tentativasEsperada {
+				t.Errorf("tentativas de débito esperadas %d, got %d", tt.tentativasEsperada, limites.tentativas)
+			}
+
+			cliente, _ := limites.GetCliente(context.Background(), testutil.ClienteIDPadrao)
+			if cliente.LimiteAtual != tt.limiteFinal {
+				t.Errorf("limite final esperado %d, got %d", tt.limiteFinal, cliente.LimiteAtual)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_RetentativaDebitoUnica(t *testing.T) {
+	// Releitura sempre mostra saldo, mas o débito sempre falha: no máximo 2 tentativas
+	deps := newDependencias()
+	limites := &falhaSempreRepository{fakeLimiteRepository: newFakeLimiteRepository(testutil.ClientePadrao())}
+	svc := NewTransacaoService(limites, deps.transacoes, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{},
+		WithRetentativaDebito(50*time.Millisecond))
+
+	if err := autorizar(context.Background(), svc, testutil.NewTransacaoBuilder().Build()); err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+
+	if limites.tentativas != 2 {
+		t.Errorf("esperadas 2 tentativas de débito, got %d", limites.tentativas)
+	}
+
+	if deps.metrics.outcomeCount("debit_retry_rejected") != 1 {
+		t.Errorf("métrica debit_retry_rejected esperada 1, got %d", deps.metrics.outcomeCount("debit_retry_rejected"))
+	}
+}
+
+// falhaSempreRepository rejeita todo débito por limite insuficiente
+type falhaSempreRepository struct {
+	*fakeLimiteRepository
+	tentativas int
+}
+
+func (r *falhaSempreRepository) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	r.tentativas++
+	return 0, domain.ErrLimiteInsuficiente
+}
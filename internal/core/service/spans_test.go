@@ -0,0 +1,68 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/observability/tracing"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAutorizarTransacao_ArvoreDeSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		valor    float64
+		filhos   string
+		decisiva interface{}
+	}{
+		{
+			name: "aprovada", valor: 10.00,
+			filhos: "TransacaoService.validarTransacao,TransacaoService.processarLimite,TransacaoService.aprovarTransacao",
+		},
+		{
+			name: "recusada pelo limite", valor: 5000.00, decisiva: "limite",
+			filhos: "TransacaoService.validarTransacao,TransacaoService.processarLimite,TransacaoService.rejeitarTransacao",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			tracer := tracing.NewRecordingTracer()
+			deps.tracer = tracer
+
+			autorizar(context.Background(), deps.service(), domain.NewTransacao("c1", tt.valor, "corr-1"))
+
+			raiz, ok := tracer.Span("TransacaoService.AutorizarTransacao")
+			if !ok {
+				t.Fatal("span raiz TransacaoService.AutorizarTransacao não foi criado")
+			}
+			if raiz.ParentSpanID != "" {
+				t.Errorf("span raiz não deveria ter pai, got %s", raiz.ParentSpanID)
+			}
+			if raiz.Tags["cliente_id"] != "c1" || raiz.Tags["correlation_id"] != "corr-1" {
+				t.Errorf("tags do span raiz inesperadas: %v", raiz.Tags)
+			}
+			if raiz.Tags["regra_decisiva"] != tt.decisiva {
+				t.Errorf("regra_decisiva esperada %v, got %v", tt.decisiva, raiz.Tags["regra_decisiva"])
+			}
+
+			var nomes []string
+			for _, filho := range tracer.Filhos(raiz) {
+				nomes = append(nomes, filho.OperationName)
+			}
+			if got := strings.Join(nomes, ","); got != tt.filhos {
+				t.Errorf("filhos esperados %s, got %s", tt.filhos, got)
+			}
+
+			for _, span := range tracer.Spans() {
+				if !span.Finished {
+					t.Errorf("span %s não foi finalizado", span.OperationName)
+				}
+				if span.TraceID != raiz.TraceID {
+					t.Errorf("span %s fora do trace da autorização", span.OperationName)
+				}
+			}
+		})
+	}
+}
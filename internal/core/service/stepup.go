@@ -0,0 +1,53 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+)
+
+// verificarStepUp exige token de step-up válido para valores acima do limite
+// suave. Sem token válido, emite um desafio e devolve StepUpRequeridoError.
+func (s *transacaoService) verificarStepUp(ctx context.Context, transacao *domain.Transacao) error {
+	if s.limiteSuaveCentavos <= 0 || s.stepUp == nil {
+		return nil
+	}
+
+	valorCentavos := int(transacao.ValorCentavos())
+	if valorCentavos <= s.limiteSuaveCentavos {
+		return nil
+	}
+
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.verificarStepUp")
+	defer s.tracer.FinishSpan(span, nil)
+
+	if transacao.TokenStepUp != "" && s.stepUp.VerificarToken(ctx, transacao.TokenStepUp, transacao) {
+		s.tracer.AddTag(span, "step_up", "verificado")
+		return nil
+	}
+
+	if transacao.TokenStepUp != "" {
+		s.logger.Warn(ctx, "token de step-up inválido ou expirado", map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"cliente_id":   transacao.ClienteID,
+		})
+		s.metricsCollector.IncrementErrorCounter("step_up_invalid_token")
+	}
+
+	desafio, err := s.stepUp.EmitirDesafio(ctx, transacao)
+	if err != nil {
+		return fmt.Errorf("erro ao emitir desafio de step-up: %w", err)
+	}
+
+	s.tracer.AddTag(span, "step_up", "requerido")
+	s.logger.Info(ctx, "confirmação adicional requerida", map[string]interface{}{
+		"transacao_id":          transacao.ID,
+		"cliente_id":            transacao.ClienteID,
+		"valor":                 transacao.Valor,
+		"limite_suave_centavos": s.limiteSuaveCentavos,
+		"desafio":               desafio,
+	})
+	s.metricsCollector.IncrementErrorCounter("step_up_required")
+
+	return &domain.StepUpRequeridoError{Desafio: desafio}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeStepUp aceita apenas o token configurado
+type fakeStepUp struct {
+	tokenValido string
+}
+
+func (f *fakeStepUp) EmitirDesafio(ctx context.Context, transacao *domain.Transacao) (string, error) {
+	return "desafio-1", nil
+}
+
+func (f *fakeStepUp) VerificarToken(ctx context.Context, token string, transacao *domain.Transacao) bool {
+	return token == f.tokenValido
+}
+
+func TestAutorizarTransacao_StepUp(t *testing.T) {
+	tests := []struct {
+		name         string
+		valor        float64
+		token        string
+		esperaStepUp bool
+	}{
+		{name: "abaixo do limite suave", valor: 1000.00},
+		{name: "acima do limite suave sem token", valor: 1000.01, esperaStepUp: true},
+		{name: "acima do limite suave com token inválido", valor: 1500.00, token: "invalido", esperaStepUp: true},
+		{name: "acima do limite suave com token válido", valor: 1500.00, token: "token-ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 500000, LimiteAtual: 500000})
+			svc := deps.service(WithStepUp(100000, &fakeStepUp{tokenValido: "token-ok"}))
+
+			transacao := domain.NewTransacao("c1", tt.valor, "corr")
+			transacao.TokenStepUp = tt.token
+
+			err := autorizar(context.Background(), svc, transacao)
+
+			if !tt.esperaStepUp {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				if transacao.Status != domain.StatusAprovada {
+					t.Errorf("Status esperado %s, got %s", domain.StatusAprovada, transacao.Status)
+				}
+				return
+			}
+
+			var stepUp *domain.StepUpRequeridoError
+			if !errors.As(err, &stepUp) || stepUp.Desafio != "desafio-1" {
+				t.Fatalf("esperado StepUpRequeridoError com desafio, got %v", err)
+			}
+
+			if !errors.Is(err, domain.ErrStepUpNecessario) {
+				t.Error("erro deveria satisfazer errors.Is(ErrStepUpNecessario)")
+			}
+
+			if deps.limites.debitos != 0 {
+				t.Errorf("limite não deveria ser debitado, got %d débitos", deps.limites.debitos)
+			}
+
+			if salva, _ := deps.transacoes.GetByID(context.Background(), transacao.ID); salva != nil {
+				t.Error("transação aguardando step-up não deveria ser persistida")
+			}
+		})
+	}
+}
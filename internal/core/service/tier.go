@@ -0,0 +1,110 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+)
+
+// tierPadrao rotula métricas de clientes sem tier ou com tier não mapeado
+const tierPadrao = "PADRAO"
+
+// politicaDoTier resolve a política do tier; tiers desconhecidos usam a padrão
+func (s *transacaoService) politicaDoTier(tier string) (string, domain.PoliticaTier) {
+	if politica, ok := s.politicasTier[tier]; ok {
+		return tier, politica
+	}
+	return tierPadrao, s.politicaTierPadrao
+}
+
+// aplicarPoliticaTier verifica valor máximo e velocidade (limite diário) do
+// tier do cliente e devolve a política resolvida, cujo cheque especial é
+// usado no débito. Sem políticas por tier configuradas, nada é verificado.
+func (s *transacaoService) aplicarPoliticaTier(ctx context.Context, transacao *domain.Transacao) (domain.PoliticaTier, error) {
+	if s.politicasTier == nil {
+		return domain.PoliticaTier{}, nil
+	}
+
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.aplicarPoliticaTier")
+	defer s.tracer.FinishSpan(span, nil)
+
+	cliente, err := s.limiteRepository.GetCliente(ctx, transacao.ClienteID)
+	if err != nil {
+		// Cliente inexistente é tratado pelo débito atômico
+		if errors.Is(err, domain.ErrClienteNaoEncontrado) {
+			return domain.PoliticaTier{}, nil
+		}
+		return domain.PoliticaTier{}, err
+	}
+
+	tier, politica := s.politicaDoTier(cliente.Tier)
+	transacao.Tier = tier
+	s.tracer.AddTag(span, "tier", tier)
+
+	valorCentavos := int(transacao.ValorCentavos())
+	if politica.ValorMaximoCentavos > 0 && valorCentavos > politica.ValorMaximoCentavos {
+		s.logger.Warn(ctx, "valor acima do máximo do tier", map[string]interface{}{
+			"transacao_id":          transacao.ID,
+			"tier":                  tier,
+			"valor":                 transacao.Valor,
+			"valor_maximo_centavos": politica.ValorMaximoCentavos,
+		})
+		s.metricsCollector.IncrementErrorCounter("amount_above_maximum")
+		return politica, domain.ErrValorAcimaDoMaximo
+	}
+
+	if politica.MultiplicadorLimiteDiario > 0 {
+		if err := s.verificarLimiteDiario(ctx, transacao, politica.MultiplicadorLimiteDiario, valorCentavos); err != nil {
+			return politica, err
+		}
+	}
+
+	return politica, nil
+}
+
+// debitar usa o cheque especial quando o tier o concede e o repositório
+// suporta débito além do limite atual. Com sucesso, registra na transação o
+// limite disponível após o débito e o restante (com o cheque especial).
+func (s *transacaoService) debitar(ctx context.Context, transacao *domain.Transacao, valorCentavos, chequeEspecial int) error {
+	var (
+		novoLimite int
+		err        error
+	)
+	repo, ok := s.limiteRepository.(domain.LimiteChequeEspecialRepository)
+	if chequeEspecial > 0 && ok {
+		novoLimite, err = repo.DebitarLimiteComChequeEspecial(ctx, transacao.ClienteID, valorCentavos, chequeEspecial)
+	} else {
+		chequeEspecial = 0
+		novoLimite, err = s.limiteRepository.DebitarLimiteAtomica(ctx, transacao.ClienteID, valorCentavos)
+	}
+	if err != nil {
+		return err
+	}
+	restante := novoLimite + chequeEspecial
+	transacao.LimiteDisponivel = &novoLimite
+	transacao.LimiteRestante = &restante
+
+	if s.limiteEventos != nil {
+		evento := domain.NewLimiteAlteradoEvento(transacao.ClienteID, int64(novoLimite+valorCentavos), int64(novoLimite),
+			domain.MotivoLimiteDebito, transacao.CorrelationID, s.agora())
+		evento.TransacaoID = transacao.ID
+		go s.publicarLimiteAlterado(contextoAssincrono(ctx), evento)
+	}
+	return nil
+}
+
+// publicarLimiteAlterado publica a movimentação do limite; a falha não
+// desfaz o débito, apenas é registrada
+func (s *transacaoService) publicarLimiteAlterado(ctx context.Context, evento *domain.LimiteAlteradoEvento) {
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.publicarLimiteAlterado")
+	defer s.tracer.FinishSpan(span, nil)
+
+	if err := s.limiteEventos.PublishLimiteAlterado(ctx, evento); err != nil {
+		s.logger.Error(ctx, "falha ao publicar alteração de limite", err, map[string]interface{}{
+			"cliente_id":   evento.ClienteID,
+			"transacao_id": evento.TransacaoID,
+			"delta":        evento.Delta,
+		})
+		s.metricsCollector.IncrementErrorCounter("limit_event_publish_error")
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+)
+
+func politicasTierTeste() Option {
+	return WithPoliticasPorTier(map[string]domain.PoliticaTier{
+		"PLATINUM": {ValorMaximoCentavos: 2000000, MultiplicadorLimiteDiario: 2, ChequeEspecialCentavos: 50000},
+		"BASICO":   {ValorMaximoCentavos: 50000, MultiplicadorLimiteDiario: 0.5},
+	}, domain.PoliticaTier{ValorMaximoCentavos: 500000, MultiplicadorLimiteDiario: 1})
+}
+
+func TestAutorizarTransacao_PoliticaPorTier(t *testing.T) {
+	tests := []struct {
+		name        string
+		tier        string
+		valor       float64
+		expectedErr error
+		tierMetrica string
+	}{
+		{name: "PLATINUM acima do máximo padrão", tier: "PLATINUM", valor: 8000.00, tierMetrica: "PLATINUM"},
+		{name: "sem tier usa máximo padrão", tier: "", valor: 8000.00, expectedErr: domain.ErrValorAcimaDoMaximo},
+		{name: "tier desconhecido usa máximo padrão", tier: "DIAMANTE", valor: 8000.00, expectedErr: domain.ErrValorAcimaDoMaximo},
+		{name: "tier desconhecido dentro do padrão", tier: "DIAMANTE", valor: 4000.00, tierMetrica: tierPadrao},
+		{name: "BASICO acima do próprio máximo", tier: "BASICO", valor: 600.00, expectedErr: domain.ErrValorAcimaDoMaximo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", Tier: tt.tier, LimiteCredit: 1000000, LimiteAtual: 1000000})
+			svc := deps.service(politicasTierTeste())
+
+			if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", tt.valor, "corr")); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr != nil {
+				return
+			}
+
+			if len(deps.metrics.business) != 1 || deps.metrics.business[0].labels["tier"] != tt.tierMetrica {
+				t.Errorf("label tier esperado %s, got %+v", tt.tierMetrica, deps.metrics.business)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_VelocidadePorTier(t *testing.T) {
+	// Limite diário: BASICO = 0,5 x R$ 1.000,00; PLATINUM = 2 x R$ 1.000,00
+	tests := []struct {
+		tier        string
+		expectedErr error
+	}{
+		{tier: "BASICO", expectedErr: domain.ErrLimiteDiarioExcedido},
+		{tier: "PLATINUM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tier, func(t *testing.T) {
+			anterior := domain.NewTransacao("c1", 400.00, "corr-anterior")
+			anterior.Aprovar()
+
+			deps := newDependencias(&domain.Cliente{ID: "c1", Tier: tt.tier, LimiteCredit: 100000, LimiteAtual: 60000})
+			deps.transacoes = newFakeTransacaoRepository(anterior)
+			svc := deps.service(politicasTierTeste())
+
+			if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 200.00, "corr")); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_ChequeEspecialPorTier(t *testing.T) {
+	tests := []struct {
+		tier           string
+		expectedErr    error
+		limiteEsperado int
+	}{
+		{tier: "PLATINUM", limiteEsperado: -20000},
+		{tier: "BASICO", expectedErr: domain.ErrLimiteInsuficiente, limiteEsperado: 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tier, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", Tier: tt.tier, LimiteCredit: 100000, LimiteAtual: 10000})
+			svc := deps.service(politicasTierTeste())
+
+			if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 300.00, "corr")); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+			if cliente.LimiteAtual != tt.limiteEsperado {
+				t.Errorf("Limite esperado %d, got %d", tt.limiteEsperado, cliente.LimiteAtual)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_LimiteRestanteIncluiChequeEspecial(t *testing.T) {
+	tests := []struct {
+		tier        string
+		limiteAtual int
+		disponivel  int
+		restante    int
+	}{
+		{tier: "PLATINUM", limiteAtual: 10000, disponivel: -20000, restante: 30000},
+		{tier: "PADRAO", limiteAtual: 100000, disponivel: 70000, restante: 70000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tier, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", Tier: tt.tier, LimiteCredit: 100000, LimiteAtual: tt.limiteAtual})
+			svc := deps.service(politicasTierTeste())
+
+			resultado, err := svc.AutorizarTransacao(context.Background(), domain.NewTransacao("c1", 300.00, "corr"))
+			if err != nil || !resultado.Aprovada() {
+				t.Fatalf("aprovação esperada, got %+v (%v)", resultado, err)
+			}
+
+			if resultado.LimiteDisponivel == nil || *resultado.LimiteDisponivel != tt.disponivel {
+				t.Errorf("limite disponível esperado %d, got %v", tt.disponivel, resultado.LimiteDisponivel)
+			}
+			if resultado.LimiteRestante == nil || *resultado.LimiteRestante != tt.restante {
+				t.Errorf("limite restante esperado %d, got %v", tt.restante, resultado.LimiteRestante)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"time"
+)
+
+// toleranciaTimestampPadrao absorve a diferença de relógio entre a origem e o
+// serviço; a mesma ordem de grandeza da tolerância do TTL no repositório
+const toleranciaTimestampPadrao = 5 * time.Minute
+
+// verificarTimestamp trata timestamps informados pela origem no futuro além
+// da tolerância, que distorceriam as janelas de duplicidade e limite diário e
+// o TTL. Timestamps no passado são aceitos.
+func (s *transacaoService) verificarTimestamp(ctx context.Context, transacao *domain.Transacao) error {
+	if !transacao.TimestampInformado {
+		return nil
+	}
+
+	agora := s.agora()
+	if !transacao.Timestamp.After(agora.Add(s.toleranciaTimestamp)) {
+		return nil
+	}
+
+	campos := map[string]interface{}{
+		"transacao_id": transacao.ID,
+		"timestamp":    transacao.Timestamp,
+		"adiantamento": transacao.Timestamp.Sub(agora).String(),
+	}
+
+	if s.modoTimestamp == TimestampFuturoAjustar {
+		s.logger.Warn(ctx, "timestamp no futuro ajustado para o horário atual", campos)
+		s.metricsCollector.IncrementErrorCounter("future_timestamp_clamped")
+		transacao.Timestamp = agora
+		return nil
+	}
+
+	s.logger.Warn(ctx, "timestamp no futuro além da tolerância", campos)
+	s.metricsCollector.IncrementErrorCounter("future_timestamp")
+	return domain.ErrTimestampInvalido
+}
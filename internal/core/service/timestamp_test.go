@@ -0,0 +1,67 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/testutil"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAutorizarTransacao_TimestampInformado(t *testing.T) {
+	agora := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		timestamp     time.Time
+		modo          ModoTimestampFuturo
+		expectedErr   error
+		esperaHorario time.Time
+	}{
+		{name: "dentro da tolerância", timestamp: agora.Add(2 * time.Minute), modo: TimestampFuturoRejeitar, esperaHorario: agora.Add(2 * time.Minute)},
+		{name: "no limite da tolerância", timestamp: agora.Add(5 * time.Minute), modo: TimestampFuturoRejeitar, esperaHorario: agora.Add(5 * time.Minute)},
+		{name: "no passado", timestamp: agora.Add(-48 * time.Hour), modo: TimestampFuturoRejeitar, esperaHorario: agora.Add(-48 * time.Hour)},
+		{name: "futuro excessivo rejeitado", timestamp: agora.Add(time.Hour), modo: TimestampFuturoRejeitar, expectedErr: domain.ErrTimestampInvalido},
+		{name: "futuro excessivo ajustado", timestamp: agora.Add(time.Hour), modo: TimestampFuturoAjustar, esperaHorario: agora},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(testutil.ClientePadrao())
+			svc := deps.service(
+				WithRelogio(func() time.Time { return agora }),
+				WithToleranciaTimestamp(5*time.Minute, tt.modo),
+			)
+
+			transacao := testutil.NewTransacaoBuilder().ComTimestamp(tt.timestamp).Build()
+			transacao.TimestampInformado = true
+
+			resultado, err := svc.AutorizarTransacao(context.Background(), transacao)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if resultado.Motivo != tt.expectedErr {
+				t.Fatalf("motivo esperado %v, got %v", tt.expectedErr, resultado.Motivo)
+			}
+			if tt.expectedErr != nil {
+				if resultado.CodigoMotivo != "invalid_timestamp" || deps.limites.debitos != 0 {
+					t.Errorf("recusa invalid_timestamp sem débito esperada, got %s e %d débitos", resultado.CodigoMotivo, deps.limites.debitos)
+				}
+				return
+			}
+			if !transacao.Timestamp.Equal(tt.esperaHorario) {
+				t.Errorf("timestamp esperado %v, got %v", tt.esperaHorario, transacao.Timestamp)
+			}
+		})
+	}
+}
+
+func TestAutorizarTransacao_TimestampGeradoIgnoraTolerancia(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	// Relógio atrasado: o timestamp de criação não é informado pela origem
+	svc := deps.service(WithRelogio(func() time.Time { return time.Now().Add(-time.Hour) }))
+
+	if err := autorizar(context.Background(), svc, testutil.NewTransacaoBuilder().Build()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+}
@@ -7,15 +7,80 @@ import (
 	"time"
 )
 
-type TransacaoService struct {
+// TransacaoService são os casos de uso de transações expostos aos adaptadores
+// de entrada. Preocupações transversais (log, cache, retentativa) podem ser
+// compostas como decoradores (ex.: NewDecoradorLog).
+type TransacaoService interface {
+	// AutorizarTransacao devolve recusas de negócio no Resultado; o erro
+	// indica apenas falha de infraestrutura
+	AutorizarTransacao(ctx context.Context, transacao *domain.Transacao) (*Resultado, error)
+	AgendarTransacao(ctx context.Context, transacao *domain.Transacao) error
+	ListarTransacoesCliente(ctx context.Context, clienteID string, limit int, transacaoCriadaID string) ([]*domain.Transacao, error)
+	ExplicarDecisao(ctx context.Context, transacaoID string) (*ExplicacaoDecisao, error)
+}
+
+// transacaoService é a implementação dos casos de uso sobre os repositórios
+type transacaoService struct {
 	limiteRepository    domain.LimiteRepository
 	transacaoRepository domain.TransacaoRepository
 	eventPublisher      domain.EventPublisher
 	metricsCollector    domain.MetricsCollector
 	tracer              domain.DistributedTracer
 	logger              domain.Logger
+	keyHasher           domain.KeyHasher
+
+	janelaDuplicidade time.Duration
+	modoDuplicidade   ModoDuplicidade
+	politicas         *politicaCache
+	agora             func() time.Time
+
+	toleranciaTimestamp time.Duration
+	modoTimestamp       ModoTimestampFuturo
+
+	maxParcelas        int
+	maxParcelasPorTier map[string]int
+
+	limiteSuaveCentavos int
+	stepUp              domain.StepUpVerifier
+
+	limitePayload limitePayload
+
+	ackStore        domain.EventAckStore
+	ordemPublicacao OrdemPublicacao
+
+	suprimirEventosRejeicao bool
+
+	preAuth         domain.PreAuthCallback
+	preAuthTimeout  time.Duration
+	preAuthPolitica PoliticaFalha
+
+	verificacaoValorZero bool
+
+	politicasTier      map[string]domain.PoliticaTier
+	politicaTierPadrao domain.PoliticaTier
+
+	limiteProvisionamento int
+
+	prazoRetentativaDebito time.Duration
+
+	moedasSuportadas map[string]bool
+
+	limiteEventos domain.LimiteEventPublisher
+
+	idempotencia    domain.IdempotencyStore
+	ttlIdempotencia time.Duration
+
+	auditoria     domain.AuditRepository
+	atorAuditoria string
 }
 
+// limiteConsultaDuplicidade é o número de transações recentes consultadas
+// na detecção de duplicidade
+const limiteConsultaDuplicidade = 20
+
+// maxParcelasPadrao é o máximo global de parcelas quando não configurado
+const maxParcelasPadrao = 12
+
 func NewTransacaoService(
 	limiteRepository domain.LimiteRepository,
 	transacaoRepository domain.TransacaoRepository,
@@ -23,20 +88,59 @@ func NewTransacaoService(
 	metricsCollector domain.MetricsCollector,
 	tracer domain.DistributedTracer,
 	logger domain.Logger,
-) *TransacaoService {
-	return &TransacaoService{
+	opts ...Option,
+) TransacaoService {
+	s := &transacaoService{
 		limiteRepository:    limiteRepository,
 		transacaoRepository: transacaoRepository,
 		eventPublisher:      eventPublisher,
 		metricsCollector:    metricsCollector,
 		tracer:              tracer,
 		logger:              logger,
+		keyHasher:           domain.HasherSHA256{},
+		ordemPublicacao:     PublicarAposSalvar,
+		modoDuplicidade:     DuplicidadeRejeitar,
+		agora:               time.Now,
+		toleranciaTimestamp: toleranciaTimestampPadrao,
+		modoTimestamp:       TimestampFuturoRejeitar,
+		maxParcelas:         maxParcelasPadrao,
+		limitePayload:       limitePayload{maxBytes: limitePayloadEventoPadrao},
+		moedasSuportadas:    map[string]bool{domain.MoedaPadrao: true},
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 // AutorizarTransacao implementa a lógica principal de autorização
-// com observabilidade completa e gestão de eventos assíncronos
-func (s *TransacaoService) AutorizarTransacao(ctx context.Context, transacao *domain.Transacao) error {
+// com observabilidade completa e gestão de eventos assíncronos.
+// Recusas de negócio voltam como Resultado, não como erro.
+func (s *transacaoService) AutorizarTransacao(ctx context.Context, transacao *domain.Transacao) (*Resultado, error) {
+	chave := s.chaveIdempotencia(transacao)
+	reservada := false
+	if chave != "" {
+		var anterior *Resultado
+		if anterior, reservada = s.reservarIdempotencia(ctx, chave, transacao); anterior != nil {
+			return anterior, nil
+		}
+	}
+
+	resultado, err := novoResultado(transacao, s.autorizar(ctx, transacao))
+	if err == nil {
+		s.registrarAuditoria(ctx, transacao, resultado)
+	}
+	if reservada {
+		s.concluirIdempotencia(ctx, chave, transacao, resultado, err)
+	}
+	return resultado, err
+}
+
+// autorizar executa o fluxo de autorização; recusas e falhas saem como erro
+// e são separadas por novoResultado
+func (s *transacaoService) autorizar(ctx context.Context, transacao *domain.Transacao) error {
 	startTime := time.Now()
 
 	// Inicia span de tracing distribuído
@@ -59,23 +163,99 @@ func (s *TransacaoService) AutorizarTransacao(ctx context.Context, transacao *do
 		"correlation_id": transacao.CorrelationID,
 	})
 
+	// Verificação de cartão (valor zero) não passa pelas regras de débito
+	if s.verificacaoValorZero && transacao.EhVerificacao() {
+		return s.autorizarVerificacao(ctx, transacao)
+	}
+
+	cadeia := &cadeiaRegras{}
+	defer s.registrarCadeia(ctx, span, transacao, cadeia)
+
 	// 1. Validação de negócio
-	if err := s.validarTransacao(ctx, transacao); err != nil {
+	if err := cadeia.avaliar("validacao", s.validarTransacao(ctx, transacao)); err != nil {
+		return s.rejeitarTransacao(ctx, transacao, err)
+	}
+
+	// 2. Detecção de duplicidade (clique duplo)
+	if err := cadeia.avaliar("duplicidade", s.verificarDuplicidade(ctx, transacao)); err != nil {
+		return s.rejeitarTransacao(ctx, transacao, err)
+	}
+
+	// 3. Política de aprovação (valor máximo e limite diário)
+	if err := cadeia.avaliar("politica", s.aplicarPolitica(ctx, transacao)); err != nil {
 		return s.rejeitarTransacao(ctx, transacao, err)
 	}
 
-	// 2. Verificação e débito atômico do limite
-	if err := s.processarLimite(ctx, transacao); err != nil {
+	// 4. Limites do segmento (tier) do cliente
+	politicaTier, err := s.aplicarPoliticaTier(ctx, transacao)
+	if err := cadeia.avaliar("tier", err); err != nil {
+		return s.rejeitarTransacao(ctx, transacao, err)
+	}
+
+	// 5. Parcelamento permitido para o cliente
+	if err := cadeia.avaliar("parcelas", s.verificarParcelas(ctx, transacao)); err != nil {
+		return s.rejeitarTransacao(ctx, transacao, err)
+	}
+
+	// 6. Confirmação adicional acima do limite suave. Não é uma rejeição
+	// definitiva: o cliente repete a requisição com o token de step-up.
+	// Transações agendadas confirmam o step-up no agendamento.
+	if transacao.DataAgendamento == nil {
+		if err := cadeia.avaliar("step_up", s.verificarStepUp(ctx, transacao)); err != nil {
+			return err
+		}
+	}
+
+	// 7. Veto síncrono do sistema de risco externo
+	if err := cadeia.avaliar("pre_autorizacao", s.verificarPreAutorizacao(ctx, transacao)); err != nil {
+		return s.rejeitarTransacao(ctx, transacao, err)
+	}
+
+	// 8. Verificação e débito atômico do limite (com cheque especial do tier)
+	if err := cadeia.avaliar("limite", s.processarLimite(ctx, transacao, politicaTier.ChequeEspecialCentavos)); err != nil {
+		// Débito sem confirmação pode ter sido aplicado: nada de rejeição
+		// salva ou publicada, o chamador decide como reconciliar
+		if errors.Is(err, domain.ErrResultadoIndeterminado) {
+			return err
+		}
+		return s.rejeitarTransacao(ctx, transacao, err)
+	}
+
+	// 9. Aprovação da transação
+	return s.aprovarTransacao(ctx, transacao)
+}
+
+// autorizarVerificacao aprova a verificação de cartão se o cliente existe,
+// sem debitar limite
+func (s *transacaoService) autorizarVerificacao(ctx context.Context, transacao *domain.Transacao) error {
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.autorizarVerificacao")
+	defer s.tracer.FinishSpan(span, nil)
+
+	if err := s.verificarCartao(ctx, transacao); err != nil {
 		return s.rejeitarTransacao(ctx, transacao, err)
 	}
 
-	// 3. Aprovação da transação
 	return s.aprovarTransacao(ctx, transacao)
 }
 
-func (s *TransacaoService) validarTransacao(ctx context.Context, transacao *domain.Transacao) error {
+// verificarCartao exige cliente informado e existente e moeda aceita
+func (s *transacaoService) verificarCartao(ctx context.Context, transacao *domain.Transacao) error {
+	if transacao.ClienteID == "" {
+		return domain.ErrClienteInvalido
+	}
+
+	if err := s.verificarMoeda(ctx, transacao); err != nil {
+		return err
+	}
+
+	_, err := s.limiteRepository.GetCliente(ctx, transacao.ClienteID)
+	return err
+}
+
+func (s *transacaoService) validarTransacao(ctx context.Context, transacao *domain.Transacao) error {
 	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.validarTransacao")
 	defer s.tracer.FinishSpan(span, nil)
+	defer medirEtapa(ctx, "validation", time.Now())
 
 	if err := transacao.Valida(); err != nil {
 		s.logger.Warn(ctx, "validação de transação falhou", map[string]interface{}{
@@ -87,19 +267,153 @@ func (s *TransacaoService) validarTransacao(ctx context.Context, transacao *doma
 		return err
 	}
 
+	if err := s.verificarTimestamp(ctx, transacao); err != nil {
+		return err
+	}
+
+	return s.verificarMoeda(ctx, transacao)
+}
+
+// verificarMoeda rejeita código fora do ISO 4217 ou moeda não configurada,
+// antes de qualquer acesso à persistência
+func (s *transacaoService) verificarMoeda(ctx context.Context, transacao *domain.Transacao) error {
+	moeda := transacao.MoedaOuPadrao()
+
+	err := domain.ValidarCodigoMoeda(moeda)
+	if err == nil && !s.moedasSuportadas[moeda] {
+		err = domain.ErrMoedaNaoSuportada
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "moeda da transação recusada", map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"moeda":        moeda,
+			"erro":         err.Error(),
+		})
+		s.metricsCollector.IncrementErrorCounter("unsupported_currency")
+	}
+	return err
+}
+
+// verificarDuplicidade procura transação aprovada do mesmo cliente, com o mesmo
+// valor, dentro da janela configurada. Falhas na consulta não bloqueiam a
+// autorização: a detecção é uma proteção auxiliar.
+func (s *transacaoService) verificarDuplicidade(ctx context.Context, transacao *domain.Transacao) error {
+	if s.janelaDuplicidade <= 0 || domain.SobrescritasDepuracaoDoContexto(ctx).DesligarDuplicidade {
+		return nil
+	}
+
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.verificarDuplicidade")
+	defer s.tracer.FinishSpan(span, nil)
+
+	// Leitura parcial (itens corrompidos) ainda permite verificar os válidos
+	recentes, err := s.transacaoRepository.GetByClienteID(ctx, transacao.ClienteID, limiteConsultaDuplicidade)
+	if err != nil && !errors.Is(err, domain.ErrItensCorrompidos) {
+		s.logger.Error(ctx, "erro ao consultar transações recentes", err, map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"cliente_id":   transacao.ClienteID,
+		})
+		s.metricsCollector.IncrementErrorCounter("duplicate_check_error")
+		return nil
+	}
+
+	chave := s.keyHasher.Chave(domain.CamposDuplicidade(transacao))
+	for _, anterior := range recentes {
+		if anterior.ID == transacao.ID || anterior.Status != domain.StatusAprovada {
+			continue
+		}
+
+		if s.keyHasher.Chave(domain.CamposDuplicidade(anterior)) != chave {
+			continue
+		}
+
+		intervalo := transacao.Timestamp.Sub(anterior.Timestamp)
+		if intervalo < 0 || intervalo > s.janelaDuplicidade {
+			continue
+		}
+
+		s.logger.Warn(ctx, "transação duplicada suspeita", map[string]interface{}{
+			"transacao_id":          transacao.ID,
+			"transacao_anterior_id": anterior.ID,
+			"cliente_id":            transacao.ClienteID,
+			"intervalo_ms":          intervalo.Milliseconds(),
+			"modo":                  string(s.modoDuplicidade),
+		})
+		s.metricsCollector.IncrementErrorCounter("suspected_duplicate")
+
+		if s.modoDuplicidade == DuplicidadeSinalizar {
+			transacao.SuspeitaDuplicidade = true
+			return nil
+		}
+		return domain.ErrTransacaoDuplicadaSuspeita
+	}
+
+	return nil
+}
+
+// verificarParcelas garante que o número de parcelas não excede o permitido
+// ao cliente: MaxParcelas do cliente, senão o máximo do tier, senão o global.
+// Transações à vista não consultam o cliente.
+func (s *transacaoService) verificarParcelas(ctx context.Context, transacao *domain.Transacao) error {
+	if transacao.NumeroParcelas() == 1 {
+		return nil
+	}
+
+	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.verificarParcelas")
+	defer s.tracer.FinishSpan(span, nil)
+
+	cliente, err := s.limiteRepository.GetCliente(ctx, transacao.ClienteID)
+	if err != nil {
+		// Cliente inexistente é tratado pelo débito atômico
+		if errors.Is(err, domain.ErrClienteNaoEncontrado) {
+			return nil
+		}
+		return err
+	}
+
+	maxPermitido := s.maxParcelasCliente(cliente)
+	if transacao.NumeroParcelas() > maxPermitido {
+		s.logger.Warn(ctx, "parcelas acima do permitido", map[string]interface{}{
+			"transacao_id":  transacao.ID,
+			"cliente_id":    transacao.ClienteID,
+			"parcelas":      transacao.NumeroParcelas(),
+			"max_permitido": maxPermitido,
+			"tier":          cliente.Tier,
+		})
+		s.metricsCollector.IncrementErrorCounter("installments_above_allowance")
+		return domain.ErrParcelasAcimaDoPermitido
+	}
+
 	return nil
 }
 
-func (s *TransacaoService) processarLimite(ctx context.Context, transacao *domain.Transacao) error {
+// maxParcelasCliente resolve o máximo de parcelas permitido ao cliente
+func (s *transacaoService) maxParcelasCliente(cliente *domain.Cliente) int {
+	if cliente.MaxParcelas > 0 {
+		return cliente.MaxParcelas
+	}
+	if maxTier, ok := s.maxParcelasPorTier[cliente.Tier]; ok && maxTier > 0 {
+		return maxTier
+	}
+	return s.maxParcelas
+}
+
+func (s *transacaoService) processarLimite(ctx context.Context, transacao *domain.Transacao, chequeEspecial int) error {
 	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.processarLimite")
 	defer s.tracer.FinishSpan(span, nil)
+	defer medirEtapa(ctx, "debit", time.Now())
 
 	// Converte para centavos para evitar problemas de ponto flutuante
-	valorCentavos := int(transacao.Valor * 100)
+	valorCentavos := int(transacao.ValorCentavos())
 
 	// Operação atômica: verifica limite E debita em uma única operação
 	// Isso previne race conditions usando conditional writes do DynamoDB
-	err := s.limiteRepository.DebitarLimiteAtomica(ctx, transacao.ClienteID, valorCentavos)
+	err := s.debitar(ctx, transacao, valorCentavos, chequeEspecial)
+	if errors.Is(err, domain.ErrClienteNaoEncontrado) && s.provisionarCliente(ctx, transacao) {
+		err = s.debitar(ctx, transacao, valorCentavos, chequeEspecial)
+	}
+	if errors.Is(err, domain.ErrLimiteInsuficiente) && s.prazoRetentativaDebito > 0 {
+		err = s.retentarDebito(ctx, transacao, valorCentavos, chequeEspecial, err)
+	}
 	if err != nil {
 		if errors.Is(err, domain.ErrLimiteInsuficiente) {
 			s.logger.Warn(ctx, "limite insuficiente", map[string]interface{}{
@@ -123,15 +437,18 @@ func (s *TransacaoService) processarLimite(ctx context.Context, transacao *domai
 	return nil
 }
 
-func (s *TransacaoService) aprovarTransacao(ctx context.Context, transacao *domain.Transacao) error {
+func (s *transacaoService) aprovarTransacao(ctx context.Context, transacao *domain.Transacao) error {
 	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.aprovarTransacao")
 	defer s.tracer.FinishSpan(span, nil)
 
 	// Marca transação como aprovada
 	transacao.Aprovar()
 
-	// Persiste a transação
-	if err := s.transacaoRepository.Save(ctx, transacao); err != nil {
+	// Persiste a transação (com o evento, na ordem via outbox)
+	inicioSave := time.Now()
+	viaOutbox, err := s.salvarTransacao(ctx, transacao, true)
+	medirEtapa(ctx, "save", inicioSave)
+	if err != nil {
 		s.logger.Error(ctx, "erro ao salvar transação", err, map[string]interface{}{
 			"transacao_id": transacao.ID,
 		})
@@ -139,9 +456,13 @@ func (s *TransacaoService) aprovarTransacao(ctx context.Context, transacao *doma
 		return err
 	}
 
-	// Publica evento de forma assíncrona
-	// Em uma implementação real, isso seria feito em uma goroutine ou queue
-	go s.publicarEvento(context.Background(), transacao)
+	// Publica evento de forma assíncrona; via outbox, quem publica é o relay
+	// Server-Timing: a etapa publish mede apenas o despacho
+	if !viaOutbox {
+		inicioPublish := time.Now()
+		go s.publicarEvento(contextoAssincrono(ctx), transacao)
+		medirEtapa(ctx, "publish", inicioPublish)
+	}
 
 	s.logger.Info(ctx, "transação aprovada com sucesso", map[string]interface{}{
 		"transacao_id": transacao.ID,
@@ -150,30 +471,41 @@ func (s *TransacaoService) aprovarTransacao(ctx context.Context, transacao *doma
 	})
 
 	s.metricsCollector.IncrementTransactionCounter(domain.StatusAprovada)
+	// cliente_id fica em logs e traces: como label, criaria uma série por cliente
 	s.metricsCollector.RecordBusinessMetric("transaction_value", transacao.Valor, map[string]string{
-		"status":     domain.StatusAprovada,
-		"cliente_id": transacao.ClienteID,
+		"status": domain.StatusAprovada,
+		"canal":  transacao.Canal,
+		"tier":   transacao.Tier,
 	})
 
 	return nil
 }
 
-func (s *TransacaoService) rejeitarTransacao(ctx context.Context, transacao *domain.Transacao, motivo error) error {
+func (s *transacaoService) rejeitarTransacao(ctx context.Context, transacao *domain.Transacao, motivo error) error {
 	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.rejeitarTransacao")
 	defer s.tracer.FinishSpan(span, nil)
 
 	// Marca transação como rejeitada
 	transacao.Rejeitar()
+	transacao.CodigoMotivo = codigoMotivo(motivo)
 
 	// Persiste a transação rejeitada para auditoria
-	if err := s.transacaoRepository.Save(ctx, transacao); err != nil {
+	inicioSave := time.Now()
+	viaOutbox, err := s.salvarTransacao(ctx, transacao, !s.suprimirEventosRejeicao)
+	medirEtapa(ctx, "save", inicioSave)
+	if err != nil {
 		s.logger.Error(ctx, "erro ao salvar transação rejeitada", err, map[string]interface{}{
 			"transacao_id": transacao.ID,
 		})
 	}
 
-	// Publica evento de rejeição
-	go s.publicarEventoRejeicao(context.Background(), transacao, motivo)
+	// Publica evento de rejeição, salvo se suprimido por configuração ou
+	// gravado no outbox (publicado pelo relay)
+	if !s.suprimirEventosRejeicao && !viaOutbox {
+		inicioPublish := time.Now()
+		go s.publicarEventoRejeicao(contextoAssincrono(ctx), transacao, motivo)
+		medirEtapa(ctx, "publish", inicioPublish)
+	}
 
 	s.logger.Info(ctx, "transação rejeitada", map[string]interface{}{
 		"transacao_id": transacao.ID,
@@ -182,17 +514,22 @@ func (s *TransacaoService) rejeitarTransacao(ctx context.Context, transacao *dom
 	})
 
 	s.metricsCollector.IncrementTransactionCounter(domain.StatusRejeitada)
+	s.metricsCollector.RecordRejectedValue(transacao.CodigoMotivo, transacao.Valor)
 
 	return motivo
 }
 
-func (s *TransacaoService) publicarEvento(ctx context.Context, transacao *domain.Transacao) {
+func (s *transacaoService) publicarEvento(ctx context.Context, transacao *domain.Transacao) {
 	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.publicarEvento")
 	defer s.tracer.FinishSpan(span, nil)
 
-	evento := transacao.ToEvento()
+	evento := s.ajustarTamanhoEvento(ctx, s.novoEvento(transacao))
+
+	inicio := time.Now()
+	err := s.eventPublisher.PublishTransacaoAprovada(ctx, evento)
+	s.registrarPublicacao(inicio, err)
 
-	if err := s.eventPublisher.PublishTransacaoAprovada(ctx, evento); err != nil {
+	if err != nil {
 		s.logger.Error(ctx, "falha ao publicar evento de transação aprovada", err, map[string]interface{}{
 			"transacao_id": transacao.ID,
 			"evento":       evento.Evento,
@@ -203,16 +540,62 @@ func (s *TransacaoService) publicarEvento(ctx context.Context, transacao *domain
 			"transacao_id": transacao.ID,
 			"evento":       evento.Evento,
 		})
+		s.confirmarPublicacao(ctx, transacao)
+	}
+}
+
+// contextoAssincrono desvincula a publicação do cancelamento e do prazo da
+// requisição, preservando trace, span pai e correlação: o span da publicação
+// continua no trace de origem
+func contextoAssincrono(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+// salvarTransacao persiste a transação. Na ordem via outbox, o evento a
+// publicar é gravado na mesma escrita e a publicação fica com o relay
+// (viaOutbox); do contrário, o chamador publica.
+func (s *transacaoService) salvarTransacao(ctx context.Context, transacao *domain.Transacao, publicar bool) (viaOutbox bool, err error) {
+	if publicar && s.ordemPublicacao == PublicarViaOutbox {
+		if repo, ok := s.transacaoRepository.(domain.TransacaoOutboxRepository); ok {
+			return true, repo.SaveComOutbox(ctx, transacao, s.novoEvento(transacao))
+		}
+	}
+	return false, s.transacaoRepository.Save(ctx, transacao)
+}
+
+// novoEvento converte a transação em evento com a chave de deduplicação
+func (s *transacaoService) novoEvento(transacao *domain.Transacao) *domain.TransacaoEvento {
+	evento := transacao.ToEvento()
+	evento.ChaveDeduplicacao = s.keyHasher.Chave(domain.CamposDeduplicacaoEvento(evento))
+	return evento
+}
+
+// confirmarPublicacao registra a publicação confirmada para a reconciliação.
+// A falha aqui só gera um falso positivo no relatório, então não é propagada.
+func (s *transacaoService) confirmarPublicacao(ctx context.Context, transacao *domain.Transacao) {
+	if s.ackStore == nil {
+		return
+	}
+
+	if err := s.ackStore.RegistrarConfirmacao(ctx, transacao.ID); err != nil {
+		s.logger.Error(ctx, "falha ao registrar confirmação de evento", err, map[string]interface{}{
+			"transacao_id": transacao.ID,
+		})
+		s.metricsCollector.IncrementErrorCounter("event_ack_error")
 	}
 }
 
-func (s *TransacaoService) publicarEventoRejeicao(ctx context.Context, transacao *domain.Transacao, motivo error) {
+func (s *transacaoService) publicarEventoRejeicao(ctx context.Context, transacao *domain.Transacao, motivo error) {
 	ctx, span := s.tracer.StartSpan(ctx, "TransacaoService.publicarEventoRejeicao")
 	defer s.tracer.FinishSpan(span, nil)
 
-	evento := transacao.ToEvento()
+	evento := s.ajustarTamanhoEvento(ctx, s.novoEvento(transacao))
+
+	inicio := time.Now()
+	err := s.eventPublisher.PublishTransacaoRejeitada(ctx, evento)
+	s.registrarPublicacao(inicio, err)
 
-	if err := s.eventPublisher.PublishTransacaoRejeitada(ctx, evento); err != nil {
+	if err != nil {
 		s.logger.Error(ctx, "falha ao publicar evento de transação rejeitada", err, map[string]interface{}{
 			"transacao_id": transacao.ID,
 			"motivo":       motivo.Error(),
@@ -220,3 +603,19 @@ func (s *TransacaoService) publicarEventoRejeicao(ctx context.Context, transacao
 		s.metricsCollector.IncrementErrorCounter("event_publish_error")
 	}
 }
+
+// registrarPublicacao registra latência e resultado da publicação de eventos,
+// dando visibilidade a falhas silenciosas do downstream
+func (s *transacaoService) registrarPublicacao(inicio time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	s.metricsCollector.RecordEventPublish(result, time.Since(inicio).Seconds())
+}
+
+// medirEtapa registra a duração da etapa na temporização da requisição
+// (header Server-Timing), quando o handler a habilitou no contexto
+func medirEtapa(ctx context.Context, etapa string, inicio time.Time) {
+	domain.TemporizacaoDoContexto(ctx).Registrar(etapa, time.Since(inicio))
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/testutil"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAutorizarTransacao_DuplicidadeDentroDaJanela(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	svc := deps.service(WithDeteccaoDuplicidade(10*time.Second, DuplicidadeRejeitar))
+
+	anterior := testutil.NewTransacaoBuilder().ComValor(49.90).Aprovada().Build()
+	deps.transacoes.Save(context.Background(), anterior)
+
+	transacao := testutil.NewTransacaoBuilder().ComValor(49.90).Apos(anterior, 5*time.Second).Build()
+
+	err := autorizar(context.Background(), svc, transacao)
+	if err != domain.ErrTransacaoDuplicadaSuspeita {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrTransacaoDuplicadaSuspeita, err)
+	}
+
+	if transacao.Status != domain.StatusRejeitada {
+		t.Errorf("Status esperado %s, got %s", domain.StatusRejeitada, transacao.Status)
+	}
+
+	if deps.limites.debitos != 0 {
+		t.Errorf("limite não deveria ser debitado, got %d débitos", deps.limites.debitos)
+	}
+
+	if deps.metrics.errorCount("suspected_duplicate") != 1 {
+		t.Error("métrica suspected_duplicate deveria ser incrementada")
+	}
+}
+
+func TestAutorizarTransacao_DuplicidadeForaDaJanela(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	svc := deps.service(WithDeteccaoDuplicidade(10*time.Second, DuplicidadeRejeitar))
+
+	anterior := testutil.NewTransacaoBuilder().ComValor(49.90).Aprovada().Build()
+	deps.transacoes.Save(context.Background(), anterior)
+
+	transacao := testutil.NewTransacaoBuilder().ComValor(49.90).Apos(anterior, 10*time.Second+time.Millisecond).Build()
+
+	if err := autorizar(context.Background(), svc, transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if transacao.Status != domain.StatusAprovada {
+		t.Errorf("Status esperado %s, got %s", domain.StatusAprovada, transacao.Status)
+	}
+}
+
+func TestAutorizarTransacao_DuplicidadeSinalizada(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service(WithDeteccaoDuplicidade(10*time.Second, DuplicidadeSinalizar))
+
+	anterior := domain.NewTransacao("c1", 49.90, "corr-1")
+	anterior.Status = domain.StatusAprovada
+	deps.transacoes.Save(context.Background(), anterior)
+
+	transacao := domain.NewTransacao("c1", 49.90, "corr-2")
+	transacao.Timestamp = anterior.Timestamp.Add(2 * time.Second)
+
+	if err := autorizar(context.Background(), svc, transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if transacao.Status != domain.StatusAprovada {
+		t.Errorf("Status esperado %s, got %s", domain.StatusAprovada, transacao.Status)
+	}
+
+	if !transacao.SuspeitaDuplicidade {
+		t.Error("transação deveria estar sinalizada como suspeita de duplicidade")
+	}
+
+	salva, _ := deps.transacoes.GetByID(context.Background(), transacao.ID)
+	if salva == nil || !salva.SuspeitaDuplicidade {
+		t.Error("sinalização de duplicidade deveria ser persistida")
+	}
+}
+
+func TestAutorizarTransacao_DuplicidadeDesabilitadaPorPadrao(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service()
+
+	anterior := domain.NewTransacao("c1", 49.90, "corr-1")
+	anterior.Status = domain.StatusAprovada
+	deps.transacoes.Save(context.Background(), anterior)
+
+	transacao := domain.NewTransacao("c1", 49.90, "corr-2")
+
+	if err := autorizar(context.Background(), svc, transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+}
+
+func TestAutorizarTransacao_MesmoCorrelationIDEmClientesDiferentes(t *testing.T) {
+	deps := newDependencias(
+		&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000},
+		&domain.Cliente{ID: "c2", LimiteCredit: 100000, LimiteAtual: 100000},
+	)
+	svc := deps.service(WithDeteccaoDuplicidade(10*time.Second, DuplicidadeRejeitar))
+
+	// Correlation ID não é chave única: a repetição entre clientes não é
+	// colisão nem duplicidade
+	for _, clienteID := range []string{"c1", "c2"} {
+		if err := autorizar(context.Background(), svc, domain.NewTransacao(clienteID, 49.90, "pedido-123")); err != nil {
+			t.Fatalf("transação de %s deveria ser aprovada, got %v", clienteID, err)
+		}
+	}
+
+	if deps.limites.debitos != 2 {
+		t.Errorf("esperados 2 débitos, got %d", deps.limites.debitos)
+	}
+
+	// As consultas são sempre escopadas pelo cliente
+	for _, clienteID := range []string{"c1", "c2"} {
+		transacoes, err := svc.ListarTransacoesCliente(context.Background(), clienteID, 10, "")
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		if len(transacoes) != 1 || transacoes[0].ClienteID != clienteID || transacoes[0].CorrelationID != "pedido-123" {
+			t.Errorf("histórico de %s deveria ter apenas a própria transação, got %+v", clienteID, transacoes)
+		}
+	}
+}
+
+func TestPublicarEvento_RegistraMetricaDeSucesso(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service()
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 10.00, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	aguardar(t, func() bool { return deps.metrics.eventPublishCount("success") == 1 })
+
+	if deps.metrics.eventPublishCount("failure") != 0 {
+		t.Error("nenhuma falha de publicação deveria ser registrada")
+	}
+}
+
+func TestPublicarEvento_ChaveDeduplicacaoComHasherConfigurado(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service(WithKeyHasher(hasherFixo("chave-fixa")))
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 10.00, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	aguardar(t, func() bool { return deps.metrics.eventPublishCount("success") == 1 })
+
+	deps.publisher.mu.Lock()
+	defer deps.publisher.mu.Unlock()
+	if chave := deps.publisher.aprovados[0].ChaveDeduplicacao; chave != "chave-fixa" {
+		t.Errorf("chave de deduplicação esperada chave-fixa, got %q", chave)
+	}
+}
+
+func TestPublicarEvento_RegistraMetricaDeFalha(t *testing.T) {
+	tests := []struct {
+		name  string
+		valor float64
+	}{
+		{name: "evento de aprovação", valor: 10.00},
+		{name: "evento de rejeição", valor: 5000.00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			deps.publisher.err = errors.New("sns indisponível")
+			svc := deps.service()
+
+			autorizar(context.Background(), svc, domain.NewTransacao("c1", tt.valor, "corr"))
+
+			aguardar(t, func() bool {
+				return deps.metrics.eventPublishCount("failure") == 1 &&
+					deps.metrics.errorCount("event_publish_error") == 1
+			})
+
+			if deps.metrics.eventPublishCount("success") != 0 {
+				t.Error("nenhum sucesso de publicação deveria ser registrado")
+			}
+		})
+	}
+}
+
+// aguardar espera a condição ser satisfeita (publicação de eventos é assíncrona)
+func aguardar(t *testing.T, condicao func() bool) {
+	t.Helper()
+
+	limite := time.Now().Add(time.Second)
+	for time.Now().Before(limite) {
+		if condicao() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("condição não satisfeita dentro do tempo limite")
+}
+
+func TestAutorizarTransacao_CanalEmMetricasEEventos(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service()
+
+	transacao := domain.NewTransacao("c1", 25.00, "corr")
+	transacao.DefinirCanal("pos")
+
+	if err := autorizar(context.Background(), svc, transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	aguardar(t, func() bool { return len(deps.publisher.publicados) == 1 })
+
+	deps.metrics.mu.Lock()
+	defer deps.metrics.mu.Unlock()
+
+	if len(deps.metrics.business) != 1 || deps.metrics.business[0].labels["canal"] != domain.CanalPOS {
+		t.Errorf("label canal esperado %s, got %+v", domain.CanalPOS, deps.metrics.business)
+	}
+	if _, ok := deps.metrics.business[0].labels["cliente_id"]; ok {
+		t.Error("cliente_id não deve ser label de métrica (cardinalidade por cliente)")
+	}
+
+	deps.publisher.mu.Lock()
+	defer deps.publisher.mu.Unlock()
+
+	if deps.publisher.aprovados[0].Canal != domain.CanalPOS {
+		t.Errorf("Canal do evento esperado %s, got %s", domain.CanalPOS, deps.publisher.aprovados[0].Canal)
+	}
+}
+
+func TestRejeitarTransacao_RegistraValorRejeitadoPorMotivo(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service()
+
+	autorizar(context.Background(), svc, domain.NewTransacao("c1", 1500.00, "corr"))
+	autorizar(context.Background(), svc, domain.NewTransacao("c1", 2500.50, "corr"))
+	autorizar(context.Background(), svc, domain.NewTransacao("c2", 30.00, "corr"))
+	autorizar(context.Background(), svc, domain.NewTransacao("c1", 10.00, "corr"))
+
+	deps.metrics.mu.Lock()
+	defer deps.metrics.mu.Unlock()
+
+	esperado := map[string][]float64{
+		"insufficient_limit": {1500.00, 2500.50},
+		"client_not_found":   {30.00},
+	}
+	if !reflect.DeepEqual(deps.metrics.rejectedValues, esperado) {
+		t.Errorf("valores rejeitados esperados %v, got %v", esperado, deps.metrics.rejectedValues)
+	}
+}
+
+func TestAutorizarTransacao_ClienteComLimiteZero(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 0, LimiteAtual: 0})
+	svc := deps.service()
+
+	transacao := domain.NewTransacao("c1", 0.01, "corr")
+
+	if err := autorizar(context.Background(), svc, transacao); err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+
+	if transacao.Status != domain.StatusRejeitada {
+		t.Errorf("Status esperado %s, got %s", domain.StatusRejeitada, transacao.Status)
+	}
+}
+
+// Dez débitos de 0.10 somam exatamente 1.00 em centavos; em float64 a soma
+// seria 0.9999999999999999 e a última comparação de limite derivaria
+func TestAutorizarTransacao_DebitosFracionadosSemDerivaDeFloat(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100, LimiteAtual: 100})
+	svc := deps.service()
+
+	for i := 0; i < 10; i++ {
+		if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 0.10, "corr")); err != nil {
+			t.Fatalf("débito %d: erro inesperado %v", i+1, err)
+		}
+	}
+
+	cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+	if cliente.LimiteAtual != 0 {
+		t.Fatalf("Limite esperado 0, got %d", cliente.LimiteAtual)
+	}
+
+	if err := autorizar(context.Background(), svc, domain.NewTransacao("c1", 0.01, "corr")); err != domain.ErrLimiteInsuficiente {
+		t.Errorf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+}
+
+func TestAutorizarTransacao_RegistraLimiteDisponivelAposDebito(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 10000, LimiteAtual: 10000})
+	svc := deps.service()
+
+	transacao := domain.NewTransacao("c1", 25.50, "corr")
+	if err := autorizar(context.Background(), svc, transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if transacao.LimiteDisponivel == nil || *transacao.LimiteDisponivel != 7450 {
+		t.Errorf("LimiteDisponivel esperado 7450, got %v", transacao.LimiteDisponivel)
+	}
+}
+
+func TestAutorizarTransacao_DebitoIndeterminadoNaoRejeita(t *testing.T) {
+	deps := newDependencias(testutil.ClientePadrao())
+	svc := NewTransacaoService(limiteResultadoIndeterminado{deps.limites}, deps.transacoes, deps.publisher, deps.metrics, &fakeTracer{}, &fakeLogger{})
+
+	transacao := testutil.NewTransacaoBuilder().Build()
+	if _, err := svc.AutorizarTransacao(context.Background(), transacao); !errors.Is(err, domain.ErrResultadoIndeterminado) {
+		t.Fatalf("esperado resultado indeterminado, got %v", err)
+	}
+
+	select {
+	case <-deps.publisher.publicados:
+		t.Fatal("evento de rejeição não deveria ser publicado")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if salva, _ := deps.transacoes.GetByID(context.Background(), transacao.ID); salva != nil {
+		t.Errorf("transação não deveria ser salva como rejeitada, got %+v", salva)
+	}
+
+	deps.metrics.mu.Lock()
+	defer deps.metrics.mu.Unlock()
+	if deps.metrics.transactions[domain.StatusRejeitada] != 0 || len(deps.metrics.rejectedValues) != 0 {
+		t.Errorf("nenhuma rejeição deveria ser contabilizada, got %v / %v", deps.metrics.transactions, deps.metrics.rejectedValues)
+	}
+}
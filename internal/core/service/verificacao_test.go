@@ -0,0 +1,78 @@
+package service
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+)
+
+func novaVerificacao(clienteID string) *domain.Transacao {
+	transacao := domain.NewTransacao(clienteID, 0, "corr")
+	transacao.Tipo = domain.TipoVerificacao
+	return transacao
+}
+
+func TestAutorizarTransacao_VerificacaoValorZeroHabilitada(t *testing.T) {
+	deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	svc := deps.service(WithVerificacaoValorZero())
+
+	transacao := novaVerificacao("c1")
+
+	if err := autorizar(context.Background(), svc, transacao); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if transacao.Status != domain.StatusAprovada {
+		t.Errorf("Status esperado %s, got %s", domain.StatusAprovada, transacao.Status)
+	}
+
+	if deps.limites.debitos != 0 {
+		t.Errorf("verificação não deveria debitar limite, got %d débitos", deps.limites.debitos)
+	}
+
+	cliente, _ := deps.limites.GetCliente(context.Background(), "c1")
+	if cliente.LimiteAtual != 100000 {
+		t.Errorf("Limite esperado 100000, got %d", cliente.LimiteAtual)
+	}
+}
+
+func TestAutorizarTransacao_VerificacaoClienteInexistente(t *testing.T) {
+	deps := newDependencias()
+	svc := deps.service(WithVerificacaoValorZero())
+
+	transacao := novaVerificacao("inexistente")
+
+	if err := autorizar(context.Background(), svc, transacao); err != domain.ErrClienteNaoEncontrado {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrClienteNaoEncontrado, err)
+	}
+
+	if transacao.Status != domain.StatusRejeitada {
+		t.Errorf("Status esperado %s, got %s", domain.StatusRejeitada, transacao.Status)
+	}
+}
+
+func TestAutorizarTransacao_ValorZeroRejeitadoPorPadrao(t *testing.T) {
+	tests := []struct {
+		name      string
+		transacao *domain.Transacao
+		opts      []Option
+	}{
+		{name: "verificação sem opção", transacao: novaVerificacao("c1")},
+		{name: "valor zero sem tipo", transacao: domain.NewTransacao("c1", 0, "corr"), opts: []Option{WithVerificacaoValorZero()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDependencias(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			svc := deps.service(tt.opts...)
+
+			if err := autorizar(context.Background(), svc, tt.transacao); err != domain.ErrValorZero {
+				t.Fatalf("Erro esperado %v, got %v", domain.ErrValorZero, err)
+			}
+
+			if tt.transacao.Status != domain.StatusRejeitada {
+				t.Errorf("Status esperado %s, got %s", domain.StatusRejeitada, tt.transacao.Status)
+			}
+		})
+	}
+}
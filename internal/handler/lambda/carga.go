@@ -0,0 +1,72 @@
+package awslambda
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// limitadorGlobal é um token bucket compartilhado por todas as requisições
+// da instância: repõe porSegundo fichas por segundo, acumulando até rajada
+type limitadorGlobal struct {
+	mu         sync.Mutex
+	porSegundo float64
+	rajada     float64
+	fichas     float64
+	ultimo     time.Time
+	agora      func() time.Time
+}
+
+func newLimitadorGlobal(porSegundo float64, rajada int) *limitadorGlobal {
+	return &limitadorGlobal{
+		porSegundo: porSegundo,
+		rajada:     float64(rajada),
+		fichas:     float64(rajada),
+		agora:      time.Now,
+	}
+}
+
+// admitir consome uma ficha; sem fichas, retorna quanto falta para a próxima
+func (l *limitadorGlobal) admitir() (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	agora := l.agora()
+	if !l.ultimo.IsZero() {
+		l.fichas = math.Min(l.rajada, l.fichas+agora.Sub(l.ultimo).Seconds()*l.porSegundo)
+	}
+	l.ultimo = agora
+
+	if l.fichas >= 1 {
+		l.fichas--
+		return true, 0
+	}
+
+	falta := (1 - l.fichas) / l.porSegundo
+	return false, time.Duration(falta * float64(time.Second))
+}
+
+// admitirRequisicao aplica o limite global de requisições (load shedding);
+// sem limitador configurado, todas são admitidas
+func (h *LambdaHandler) admitirRequisicao(ctx context.Context, response *events.APIGatewayProxyResponse) bool {
+	if h.limitador == nil {
+		return true
+	}
+
+	admitida, espera := h.limitador.admitir()
+	if admitida {
+		return true
+	}
+
+	h.metricsCollector.IncrementErrorCounter("service_overloaded")
+	*response = h.writeErrorResponse(ctx, http.StatusTooManyRequests, ErrorResponse{
+		Error:             "service_overloaded",
+		Message:           "Serviço sobrecarregado, tente novamente",
+		RetryAfterSeconds: segundosRetryAfter(espera),
+	})
+	return false
+}
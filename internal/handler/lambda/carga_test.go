@@ -0,0 +1,82 @@
+package awslambda
+
+import (
+	"authorizer/internal/observability/tracing"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHandleRequest_LimiteGlobal(t *testing.T) {
+	handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithLimiteGlobal(2, 3)})
+	instante := time.Now()
+	handler.limitador.agora = func() time.Time { return instante }
+
+	requisitar := func() events.APIGatewayProxyResponse {
+		response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+		return response
+	}
+
+	// A rajada inteira é admitida
+	for i := 0; i < 3; i++ {
+		if response := requisitar(); response.StatusCode != http.StatusOK {
+			t.Fatalf("requisição %d: status esperado %d, got %d", i+1, http.StatusOK, response.StatusCode)
+		}
+	}
+
+	response := requisitar()
+	if response.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Status esperado %d acima do limite, got %d", http.StatusTooManyRequests, response.StatusCode)
+	}
+
+	var body ErrorResponse
+	json.Unmarshal([]byte(response.Body), &body)
+	if body.Error != "service_overloaded" {
+		t.Errorf("erro esperado service_overloaded, got %s", body.Error)
+	}
+	if got := response.Headers["Retry-After"]; got != "1" {
+		t.Errorf("Retry-After esperado 1, got %q", got)
+	}
+	if body.RetryAfterSeconds != 1 {
+		t.Errorf("retry_after_seconds esperado 1, got %d", body.RetryAfterSeconds)
+	}
+
+	// Meio segundo repõe uma ficha (2 por segundo)
+	instante = instante.Add(500 * time.Millisecond)
+	if response := requisitar(); response.StatusCode != http.StatusOK {
+		t.Errorf("Status esperado %d após reposição, got %d", http.StatusOK, response.StatusCode)
+	}
+	if response := requisitar(); response.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Status esperado %d sem fichas, got %d", http.StatusTooManyRequests, response.StatusCode)
+	}
+}
+
+func TestHandleRequest_LimiteGlobalDesligadoPorPadrao(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer())
+
+	for i := 0; i < 100; i++ {
+		response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("requisição %d: status esperado %d, got %d", i+1, http.StatusOK, response.StatusCode)
+		}
+	}
+}
+
+func TestLimitadorGlobal_EsperaAteProximaFicha(t *testing.T) {
+	limitador := newLimitadorGlobal(0.25, 1)
+	instante := time.Now()
+	limitador.agora = func() time.Time { return instante }
+
+	limitador.admitir()
+	admitida, espera := limitador.admitir()
+	if admitida {
+		t.Fatal("segunda requisição não deveria ser admitida")
+	}
+	if espera != 4*time.Second {
+		t.Errorf("espera esperada 4s, got %s", espera)
+	}
+}
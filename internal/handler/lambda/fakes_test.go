@@ -0,0 +1,200 @@
+package awslambda
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/core/service"
+	"context"
+	"sync"
+)
+
+// fakeLimiteRepository mantém limites em memória (em centavos)
+type fakeLimiteRepository struct {
+	mu       sync.Mutex
+	clientes map[string]*domain.Cliente
+}
+
+func newFakeLimiteRepository(clientes ...*domain.Cliente) *fakeLimiteRepository {
+	repo := &fakeLimiteRepository{clientes: make(map[string]*domain.Cliente)}
+	for _, c := range clientes {
+		repo.clientes[c.ID] = c
+	}
+	return repo
+}
+
+func (r *fakeLimiteRepository) GetCliente(ctx context.Context, clienteID string) (*domain.Cliente, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok {
+		return nil, domain.ErrClienteNaoEncontrado
+	}
+	copia := *cliente
+	return &copia, nil
+}
+
+func (r *fakeLimiteRepository) UpdateLimite(ctx context.Context, clienteID string, novoLimite int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok {
+		return domain.ErrClienteNaoEncontrado
+	}
+	cliente.LimiteAtual = novoLimite
+	return nil
+}
+
+func (r *fakeLimiteRepository) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cliente, ok := r.clientes[clienteID]
+	if !ok {
+		return 0, domain.ErrClienteNaoEncontrado
+	}
+	if cliente.LimiteAtual < valor {
+		return 0, domain.ErrLimiteInsuficiente
+	}
+	cliente.LimiteAtual -= valor
+	return cliente.LimiteAtual, nil
+}
+
+// fakeTransacaoRepository guarda transações em memória
+type fakeTransacaoRepository struct {
+	mu         sync.Mutex
+	transacoes map[string]*domain.Transacao
+}
+
+func newFakeTransacaoRepository() *fakeTransacaoRepository {
+	return &fakeTransacaoRepository{transacoes: make(map[string]*domain.Transacao)}
+}
+
+func (r *fakeTransacaoRepository) Save(ctx context.Context, transacao *domain.Transacao) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copia := *transacao
+	r.transacoes[transacao.ID] = &copia
+	return nil
+}
+
+func (r *fakeTransacaoRepository) GetByID(ctx context.Context, transacaoID string) (*domain.Transacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transacao, ok := r.transacoes[transacaoID]
+	if !ok {
+		return nil, nil
+	}
+	copia := *transacao
+	return &copia, nil
+}
+
+func (r *fakeTransacaoRepository) GetByClienteID(ctx context.Context, clienteID string, limit int) ([]*domain.Transacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transacoes := make([]*domain.Transacao, 0)
+	for _, t := range r.transacoes {
+		if t.ClienteID == clienteID {
+			copia := *t
+			transacoes = append(transacoes, &copia)
+		}
+	}
+	return transacoes, nil
+}
+
+// fakeEventPublisher descarta eventos
+type fakeEventPublisher struct{}
+
+func (p *fakeEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return nil
+}
+
+func (p *fakeEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return nil
+}
+
+// fakeMetricsCollector descarta métricas
+type fakeMetricsCollector struct{}
+
+func (m *fakeMetricsCollector) IncrementTransactionCounter(status string) {}
+func (m *fakeMetricsCollector) RecordTransactionLatency(duration float64) {}
+func (m *fakeMetricsCollector) RecordBusinessMetric(metricName string, value float64, labels map[string]string) {
+}
+func (m *fakeMetricsCollector) IncrementErrorCounter(errorType string)             {}
+func (m *fakeMetricsCollector) IncrementOutcomeCounter(outcome string)             {}
+func (m *fakeMetricsCollector) RecordEventPublish(result string, duration float64) {}
+func (m *fakeMetricsCollector) RecordRejectedValue(reason string, value float64)   {}
+
+// fakeLogger descarta logs
+type fakeLogger struct{}
+
+func (l *fakeLogger) Info(ctx context.Context, msg string, fields map[string]interface{})  {}
+func (l *fakeLogger) Warn(ctx context.Context, msg string, fields map[string]interface{})  {}
+func (l *fakeLogger) Debug(ctx context.Context, msg string, fields map[string]interface{}) {}
+func (l *fakeLogger) Error(ctx context.Context, msg string, err error, fields map[string]interface{}) {
+}
+
+// cabecalhoJSON é o Content-Type exigido em POST /transacoes
+var cabecalhoJSON = map[string]string{"Content-Type": "application/json"}
+
+// newTestHandler monta um handler com dependências em memória
+func newTestHandler(tracer domain.DistributedTracer, clientes ...*domain.Cliente) *LambdaHandler {
+	return newTestHandlerComOpcoes(tracer, nil, clientes...)
+}
+
+// newTestHandlerComOpcoes monta um handler com dependências em memória e opções
+func newTestHandlerComOpcoes(tracer domain.DistributedTracer, opts []Option, clientes ...*domain.Cliente) *LambdaHandler {
+	metrics := &fakeMetricsCollector{}
+	logger := &fakeLogger{}
+
+	transacaoService := service.NewTransacaoService(
+		newFakeLimiteRepository(clientes...),
+		newFakeTransacaoRepository(),
+		&fakeEventPublisher{},
+		metrics,
+		tracer,
+		logger,
+	)
+
+	return NewLambdaHandler(transacaoService, logger, tracer, metrics, opts...)
+}
+
+// falhaLimiteRepository simula indisponibilidade no débito do limite
+type falhaLimiteRepository struct {
+	*fakeLimiteRepository
+	err error
+}
+
+func (r *falhaLimiteRepository) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	return 0, r.err
+}
+
+// registroErro é uma chamada capturada de Logger.Error
+type registroErro struct {
+	msg    string
+	err    error
+	fields map[string]interface{}
+}
+
+// capturingLogger guarda as chamadas de Error e as mensagens de Warn
+type capturingLogger struct {
+	fakeLogger
+	mu     sync.Mutex
+	erros  []registroErro
+	avisos []string
+}
+
+func (l *capturingLogger) Warn(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.avisos = append(l.avisos, msg)
+}
+
+func (l *capturingLogger) Error(ctx context.Context, msg string, err error, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.erros = append(l.erros, registroErro{msg: msg, err: err, fields: fields})
+}
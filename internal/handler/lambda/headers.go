@@ -0,0 +1,153 @@
+package awslambda
+
+import (
+	"authorizer/internal/core/domain"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// getHeader resolve um header ignorando maiúsculas/minúsculas, consultando
+// Headers e depois MultiValueHeaders (primeiro valor não vazio). O API Gateway
+// pode entregar o header em apenas um dos mapas e com qualquer capitalização.
+func getHeader(request events.APIGatewayProxyRequest, name string) string {
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) && value != "" {
+			return value
+		}
+	}
+
+	for key, values := range request.MultiValueHeaders {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+		for _, value := range values {
+			if value != "" {
+				return value
+			}
+		}
+	}
+
+	return ""
+}
+
+// conteudoJSON indica se o Content-Type da requisição é application/json,
+// aceitando parâmetros (ex.: charset=utf-8). Header ausente não é JSON.
+func conteudoJSON(request events.APIGatewayProxyRequest) bool {
+	tipo, _, err := mime.ParseMediaType(getHeader(request, "Content-Type"))
+	return err == nil && tipo == "application/json"
+}
+
+// maxCorpoDescomprimido limita a expansão de corpos gzip (o payload do
+// API Gateway tem no máximo 10 MB)
+const maxCorpoDescomprimido = 10 << 20
+
+// Cabeçalhos de sobrescrita de depuração (aceitos só com WithCabecalhosDepuracao)
+const (
+	cabecalhoDesligarVelocidade  = "X-Debug-Disable-Velocity"
+	cabecalhoDesligarDuplicidade = "X-Debug-Disable-Duplicate-Check"
+)
+
+// aplicarSobrescritasDepuracao lê os cabeçalhos X-Debug-* ("true" ativa) e
+// registra em log as sobrescritas aplicadas. Desabilitado, os cabeçalhos
+// são ignorados.
+func (h *LambdaHandler) aplicarSobrescritasDepuracao(ctx context.Context, request events.APIGatewayProxyRequest) context.Context {
+	if !h.cabecalhosDepuracao {
+		return ctx
+	}
+
+	sobrescritas := domain.SobrescritasDepuracao{
+		DesligarVelocidade:  getHeader(request, cabecalhoDesligarVelocidade) == "true",
+		DesligarDuplicidade: getHeader(request, cabecalhoDesligarDuplicidade) == "true",
+	}
+	if !sobrescritas.Ativas() {
+		return ctx
+	}
+
+	h.logger.Warn(ctx, "sobrescritas de depuração aplicadas", map[string]interface{}{
+		"desligar_velocidade":  sobrescritas.DesligarVelocidade,
+		"desligar_duplicidade": sobrescritas.DesligarDuplicidade,
+	})
+	return domain.ComSobrescritasDepuracao(ctx, sobrescritas)
+}
+
+// corpoRequisicao devolve o corpo bruto da requisição: decodifica base64
+// quando o API Gateway marca IsBase64Encoded (binary media types) e
+// descomprime quando Content-Encoding é gzip
+func corpoRequisicao(request events.APIGatewayProxyRequest) ([]byte, error) {
+	corpo := []byte(request.Body)
+	if request.IsBase64Encoded {
+		decodificado, err := base64.StdEncoding.DecodeString(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("base64 inválido: %w", err)
+		}
+		corpo = decodificado
+	}
+
+	if strings.EqualFold(getHeader(request, "Content-Encoding"), "gzip") {
+		leitor, err := gzip.NewReader(bytes.NewReader(corpo))
+		if err != nil {
+			return nil, fmt.Errorf("gzip inválido: %w", err)
+		}
+		defer leitor.Close()
+
+		descomprimido, err := io.ReadAll(io.LimitReader(leitor, maxCorpoDescomprimido+1))
+		if err != nil {
+			return nil, fmt.Errorf("gzip inválido: %w", err)
+		}
+		if len(descomprimido) > maxCorpoDescomprimido {
+			return nil, fmt.Errorf("corpo descomprimido acima de %d bytes", maxCorpoDescomprimido)
+		}
+		corpo = descomprimido
+	}
+
+	return corpo, nil
+}
+
+// definirOrcamentoTimeout informa em X-Timeout-Budget-Ms quanto tempo resta
+// até o deadline do Lambda, descontada a margem de segurança. Sem deadline
+// no contexto o header é omitido.
+func (h *LambdaHandler) definirOrcamentoTimeout(ctx context.Context, response *events.APIGatewayProxyResponse) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+
+	orcamento := time.Until(deadline) - h.margemTimeout
+	if orcamento < 0 {
+		orcamento = 0
+	}
+
+	if response.Headers == nil {
+		response.Headers = make(map[string]string)
+	}
+	response.Headers["X-Timeout-Budget-Ms"] = strconv.FormatInt(orcamento.Milliseconds(), 10)
+}
+
+// definirServerTiming publica as etapas medidas no formato Server-Timing
+// (ex.: "validation;dur=0.012, debit;dur=3.481"), com durações em ms
+func definirServerTiming(temporizacao *domain.Temporizacao, response *events.APIGatewayProxyResponse) {
+	segmentos := temporizacao.Segmentos()
+	if len(segmentos) == 0 {
+		return
+	}
+
+	partes := make([]string, 0, len(segmentos))
+	for _, segmento := range segmentos {
+		partes = append(partes, fmt.Sprintf("%s;dur=%.3f", segmento.Nome, float64(segmento.Duracao.Microseconds())/1000))
+	}
+
+	if response.Headers == nil {
+		response.Headers = make(map[string]string)
+	}
+	response.Headers["Server-Timing"] = strings.Join(partes, ", ")
+}
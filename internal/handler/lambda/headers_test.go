@@ -0,0 +1,354 @@
+package awslambda
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/core/service"
+	"authorizer/internal/observability/tracing"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+)
+
+func TestGetHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  events.APIGatewayProxyRequest
+		esperado string
+	}{
+		{
+			name:     "apenas em Headers",
+			request:  events.APIGatewayProxyRequest{Headers: map[string]string{"X-Correlation-ID": "corr-1"}},
+			esperado: "corr-1",
+		},
+		{
+			name:     "capitalização diferente",
+			request:  events.APIGatewayProxyRequest{Headers: map[string]string{"x-correlation-id": "corr-2"}},
+			esperado: "corr-2",
+		},
+		{
+			name:     "apenas em MultiValueHeaders",
+			request:  events.APIGatewayProxyRequest{MultiValueHeaders: map[string][]string{"X-Correlation-Id": {"corr-3", "corr-4"}}},
+			esperado: "corr-3",
+		},
+		{
+			name: "Headers tem precedência",
+			request: events.APIGatewayProxyRequest{
+				Headers:           map[string]string{"X-CORRELATION-ID": "corr-5"},
+				MultiValueHeaders: map[string][]string{"x-correlation-id": {"corr-6"}},
+			},
+			esperado: "corr-5",
+		},
+		{
+			name:     "ausente",
+			request:  events.APIGatewayProxyRequest{Headers: map[string]string{"Content-Type": "application/json"}},
+			esperado: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getHeader(tt.request, "X-Correlation-ID"); got != tt.esperado {
+				t.Errorf("esperado %q, got %q", tt.esperado, got)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_CorrelationIDDeMultiValueHeaders(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer())
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:        "GET",
+		Path:              "/nao-existe",
+		MultiValueHeaders: map[string][]string{"x-correlation-id": {"corr-multi"}},
+	})
+
+	if response.Headers["X-Correlation-ID"] != "corr-multi" {
+		t.Errorf("X-Correlation-ID esperado corr-multi, got %s", response.Headers["X-Correlation-ID"])
+	}
+}
+
+func TestHandleRequest_CorrelationIDComCapitalizacaoVariada(t *testing.T) {
+	for _, nome := range []string{"X-Correlation-ID", "x-correlation-id", "X-Correlation-Id", "X-CORRELATION-ID"} {
+		t.Run(nome, func(t *testing.T) {
+			handler := newTestHandler(tracing.NewRecordingTracer())
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "GET",
+				Path:       "/transacoes",
+				Headers:    map[string]string{nome: "corr-cliente"},
+			})
+
+			if response.Headers["X-Correlation-ID"] != "corr-cliente" {
+				t.Errorf("X-Correlation-ID esperado corr-cliente, got %s", response.Headers["X-Correlation-ID"])
+			}
+		})
+	}
+}
+
+func TestHandleRequest_OrcamentoTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        events.APIGatewayProxyRequest
+		expectedStatus int
+	}{
+		{
+			name:           "sucesso",
+			request:        events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/transacoes", Headers: cabecalhoJSON, Body: `{"cliente_id":"c1","valor":50.00}`},
+			expectedStatus: 200,
+		},
+		{
+			name:           "erro",
+			request:        events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/transacoes", Headers: cabecalhoJSON, Body: `{"cliente_id":"inexistente","valor":50.00}`},
+			expectedStatus: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithMargemTimeout(200 * time.Millisecond)},
+				&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			response, _ := handler.HandleRequest(ctx, tt.request)
+
+			if response.StatusCode != tt.expectedStatus {
+				t.Fatalf("Status esperado %d, got %d", tt.expectedStatus, response.StatusCode)
+			}
+
+			orcamento, err := strconv.Atoi(response.Headers["X-Timeout-Budget-Ms"])
+			if err != nil {
+				t.Fatalf("X-Timeout-Budget-Ms inválido: %q", response.Headers["X-Timeout-Budget-Ms"])
+			}
+
+			// 3s de deadline menos 200ms de margem, com folga para a execução
+			if orcamento > 2800 || orcamento < 2000 {
+				t.Errorf("Orçamento esperado entre 2000 e 2800ms, got %d", orcamento)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_OrcamentoTimeoutEsgotado(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	response, _ := handler.HandleRequest(ctx, events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+
+	if response.Headers["X-Timeout-Budget-Ms"] != "0" {
+		t.Errorf("Orçamento abaixo da margem deveria ser 0, got %q", response.Headers["X-Timeout-Budget-Ms"])
+	}
+}
+
+func TestHandleRequest_OrcamentoTimeoutSemDeadline(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer())
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+
+	if _, ok := response.Headers["X-Timeout-Budget-Ms"]; ok {
+		t.Error("Sem deadline no contexto o header não deveria ser enviado")
+	}
+}
+
+func TestHandleRequest_ValidacaoCorrelationID(t *testing.T) {
+	tests := []struct {
+		name       string
+		valor      string
+		substituir bool
+	}{
+		{name: "UUID", valor: "3f2b8c1e-6a4d-4e8f-9b21-0c7d5e6f7a8b"},
+		{name: "alfanumérico", valor: "pedido_123.retry-2"},
+		{name: "muito longo", valor: strings.Repeat("a", 129), substituir: true},
+		{name: "caracteres de controle", valor: "corr\r\nSet-Cookie: sessao=1", substituir: true},
+		{name: "espaço", valor: "corr 1", substituir: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &capturingLogger{}
+			metrics := &fakeMetricsCollector{}
+			tracer := tracing.NewRecordingTracer()
+			transacaoService := service.NewTransacaoService(newFakeLimiteRepository(), newFakeTransacaoRepository(), &fakeEventPublisher{}, metrics, tracer, logger)
+			handler := NewLambdaHandler(transacaoService, logger, tracer, metrics)
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "GET",
+				Path:       "/transacoes",
+				Headers:    map[string]string{"X-Correlation-ID": tt.valor},
+			})
+
+			got := response.Headers["X-Correlation-ID"]
+			if !tt.substituir {
+				if got != tt.valor {
+					t.Errorf("X-Correlation-ID esperado %q, got %q", tt.valor, got)
+				}
+				return
+			}
+
+			if _, err := uuid.Parse(got); err != nil {
+				t.Errorf("valor inválido deveria ser substituído por UUID, got %q", got)
+			}
+
+			logger.mu.Lock()
+			defer logger.mu.Unlock()
+			if !slices.Contains(logger.avisos, "correlation ID inválido substituído") {
+				t.Errorf("substituição deveria ser registrada no log, got %v", logger.avisos)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_CorrelationIDNaoStringNoContexto(t *testing.T) {
+	tests := []struct {
+		name  string
+		valor interface{}
+	}{
+		{name: "inteiro", valor: 42},
+		{name: "ausente", valor: nil},
+		{name: "string vazia", valor: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(tracing.NewRecordingTracer(), &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+			// Simula um middleware que gravou o valor com outro tipo
+			ctx := context.Background()
+			if tt.valor != nil {
+				ctx = context.WithValue(ctx, "correlation_id", tt.valor)
+			}
+
+			response, err := handler.handlePostTransacoes(ctx, events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       `{"cliente_id":"c1","valor":10.00}`,
+			})
+			if err != nil || response.StatusCode != http.StatusOK {
+				t.Fatalf("esperado 200 sem erro, got %d %v", response.StatusCode, err)
+			}
+
+			var body TransacaoResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.CorrelationID == "" || response.Headers["X-Correlation-ID"] != body.CorrelationID {
+				t.Errorf("correlation ID deveria ser gerado e usado na resposta, got corpo %q header %q",
+					body.CorrelationID, response.Headers["X-Correlation-ID"])
+			}
+		})
+	}
+}
+
+func TestHandleRequest_ServerTiming(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		body      string
+		segmentos []string
+	}{
+		{name: "desabilitado por padrão", body: `{"cliente_id":"c1","valor":50.00}`},
+		{name: "aprovação", opts: []Option{WithServerTiming(true)}, body: `{"cliente_id":"c1","valor":50.00}`, segmentos: []string{"validation", "debit", "save", "publish"}},
+		{name: "rejeição por limite", opts: []Option{WithServerTiming(true)}, body: `{"cliente_id":"c1","valor":5000.00}`, segmentos: []string{"validation", "debit", "save", "publish"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), tt.opts,
+				&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       tt.body,
+			})
+
+			header, ok := response.Headers["Server-Timing"]
+			if len(tt.segmentos) == 0 {
+				if ok {
+					t.Errorf("Server-Timing não deveria ser enviado, got %q", header)
+				}
+				return
+			}
+
+			partes := strings.Split(header, ", ")
+			if len(partes) != len(tt.segmentos) {
+				t.Fatalf("segmentos esperados %v, got %q", tt.segmentos, header)
+			}
+			for i, segmento := range tt.segmentos {
+				if !strings.HasPrefix(partes[i], segmento+";dur=") {
+					t.Errorf("segmento %d esperado %s;dur=..., got %q", i, segmento, partes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRequest_CabecalhosDepuracao(t *testing.T) {
+	tests := []struct {
+		name       string
+		habilitado bool
+		status     int
+		logado     bool
+	}{
+		{name: "habilitado aplica a sobrescrita", habilitado: true, status: http.StatusOK, logado: true},
+		{name: "desabilitado ignora o cabeçalho", habilitado: false, status: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := tracing.NewRecordingTracer()
+			logger := &capturingLogger{}
+			transacaoService := service.NewTransacaoService(
+				newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000}),
+				newFakeTransacaoRepository(),
+				&fakeEventPublisher{},
+				&fakeMetricsCollector{},
+				tracer,
+				logger,
+				service.WithDeteccaoDuplicidade(time.Minute, service.DuplicidadeRejeitar),
+			)
+			handler := NewLambdaHandler(transacaoService, logger, tracer, &fakeMetricsCollector{}, WithCabecalhosDepuracao(tt.habilitado))
+
+			requisicao := func(headers map[string]string) events.APIGatewayProxyResponse {
+				response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+					HTTPMethod: "POST",
+					Path:       "/transacoes",
+					Headers:    headers,
+					Body:       `{"cliente_id":"c1","valor":10.00}`,
+				})
+				return response
+			}
+
+			if response := requisicao(cabecalhoJSON); response.StatusCode != http.StatusOK {
+				t.Fatalf("primeira transação: status esperado %d, got %d", http.StatusOK, response.StatusCode)
+			}
+
+			// Transação idêntica seria rejeitada como duplicada sem a sobrescrita
+			response := requisicao(map[string]string{
+				"Content-Type":                    "application/json",
+				"X-Debug-Disable-Duplicate-Check": "true",
+			})
+			if response.StatusCode != tt.status {
+				t.Fatalf("Status esperado %d, got %d", tt.status, response.StatusCode)
+			}
+
+			logger.mu.Lock()
+			defer logger.mu.Unlock()
+			if got := slices.Contains(logger.avisos, "sobrescritas de depuração aplicadas"); got != tt.logado {
+				t.Errorf("log das sobrescritas esperado %v, got %v", tt.logado, got)
+			}
+		})
+	}
+}
@@ -1,30 +1,75 @@
 package awslambda
 
 import (
+	"authorizer/internal/buildinfo"
 	"authorizer/internal/core/domain"
 	"authorizer/internal/core/service"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/google/uuid"
 )
 
 // LambdaHandler é o handler principal para AWS Lambda
 type LambdaHandler struct {
-	transacaoService service.TransacaoService
-	logger           domain.Logger
-	tracer           domain.DistributedTracer
-	metricsCollector domain.MetricsCollector
+	transacaoService   service.TransacaoService
+	logger             domain.Logger
+	tracer             domain.DistributedTracer
+	metricsCollector   domain.MetricsCollector
+	modoPrecisao       ModoPrecisao
+	modoArredondamento domain.ModoArredondamento
+	margemTimeout      time.Duration
+	padraoCorrelacao   *regexp.Regexp
+	serverTiming       bool
+	geradorID          domain.IDGenerator
+
+	retryAfterThrottling       time.Duration
+	statusClienteNaoEncontrado int
+	limitador                  *limitadorGlobal
+	cabecalhosDepuracao        bool
+	saldoNaResposta            bool
+	limiteRestanteNaResposta   bool
+	indiceRotas                bool
+
+	rotas *roteador
 }
 
 // TransacaoRequest representa o payload da requisição
 type TransacaoRequest struct {
-	ClienteID string  `json:"cliente_id"`
-	Valor     float64 `json:"valor"`
+	ClienteID string `json:"cliente_id"`
+	// Valor em reais (decimal) ou ValorCentavos (inteiro): exatamente uma
+	// das representações; as duas juntas retornam ambiguous_amount
+	Valor         *float64 `json:"valor,omitempty"`
+	ValorCentavos *int64   `json:"valor_centavos,omitempty"`
+	// Moeda (ISO 4217) é opcional; ausente significa BRL
+	Moeda string `json:"moeda,omitempty"`
+	// Canal de origem (WEB, MOBILE, POS); opcional
+	Canal string `json:"canal,omitempty"`
+	// Parcelas é opcional; ausente significa à vista
+	Parcelas int `json:"parcelas,omitempty"`
+	// Tipo "VERIFICACAO" com valor zero verifica o cartão (quando habilitado)
+	Tipo string `json:"tipo,omitempty"`
+	// StepUpToken confirma transações acima do limite suave; opcional
+	StepUpToken string `json:"step_up_token,omitempty"`
+	// Timestamp (RFC3339) é o horário da transação na origem; ausente = horário
+	// de recebimento. No futuro além da tolerância é recusado ou ajustado.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// DataAgendamento (RFC3339) agenda a autorização; ausente = imediata
+	DataAgendamento *time.Time `json:"data_agendamento,omitempty"`
+	// Tags são metadados livres repassados à transação e aos eventos; opcional
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // TransacaoResponse representa a resposta da API
@@ -35,6 +80,13 @@ type TransacaoResponse struct {
 	Valor         float64   `json:"valor"`
 	Timestamp     time.Time `json:"timestamp"`
 	CorrelationID string    `json:"correlation_id"`
+	// DataAgendamento é preenchida apenas para transações agendadas
+	DataAgendamento *time.Time `json:"data_agendamento,omitempty"`
+	// SaldoDisponivel é o limite após o débito, presente só com WithSaldoNaResposta
+	SaldoDisponivel *domain.Reais `json:"saldo_disponivel,omitempty"`
+	// LimiteRestante é quanto ainda pode ser gasto (saldo mais o cheque
+	// especial do tier), presente só com WithLimiteRestanteNaResposta
+	LimiteRestante *domain.Reais `json:"limite_restante,omitempty"`
 }
 
 // ErrorResponse representa uma resposta de erro
@@ -42,22 +94,62 @@ type ErrorResponse struct {
 	Error         string `json:"error"`
 	Message       string `json:"message"`
 	CorrelationID string `json:"correlation_id"`
+	TraceID       string `json:"trace_id"`
 	Timestamp     string `json:"timestamp"`
+	// ChallengeID é a referência do desafio quando o erro é step_up_required
+	ChallengeID string `json:"challenge_id,omitempty"`
+	// ErrorID referencia a ocorrência no log em erros internos (500)
+	ErrorID string `json:"error_id,omitempty"`
+	// RetryAfterSeconds é a espera recomendada antes de tentar de novo, nas
+	// recusas por janela (velocidade, sobrecarga); também no header Retry-After
+	RetryAfterSeconds int `json:"retry_after_seconds,omitempty"`
+	// Endpoints lista as rotas disponíveis no 404 de GET, quando o índice de
+	// rotas está habilitado
+	Endpoints []EndpointDisponivel `json:"endpoints,omitempty"`
 }
 
 // Dependências injetadas via construtor
 func NewLambdaHandler(
-	transacaoService *service.TransacaoService,
+	transacaoService service.TransacaoService,
 	logger domain.Logger,
 	tracer domain.DistributedTracer,
 	metricsCollector domain.MetricsCollector,
+	opts ...Option,
 ) *LambdaHandler {
-	return &LambdaHandler{
-		transacaoService: *transacaoService,
-		logger:           logger,
-		tracer:           tracer,
-		metricsCollector: metricsCollector,
+	h := &LambdaHandler{
+		transacaoService:   transacaoService,
+		logger:             logger,
+		tracer:             tracer,
+		metricsCollector:   metricsCollector,
+		modoPrecisao:       PrecisaoLeniente,
+		modoArredondamento: domain.ArredondamentoMeioParaCima,
+		margemTimeout:      margemTimeoutPadrao,
+		padraoCorrelacao:   padraoCorrelationIDPadrao,
+		geradorID:          domain.GeradorUUID{},
+
+		retryAfterThrottling:       retryAfterThrottlingPadrao,
+		statusClienteNaoEncontrado: http.StatusNotFound,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.rotas = h.registrarRotas()
+
+	return h
+}
+
+// registrarRotas declara as rotas atendidas pelo handler
+func (h *LambdaHandler) registrarRotas() *roteador {
+	rotas := &roteador{}
+	rotas.registrar(http.MethodPost, "/transacoes", func(ctx context.Context, request events.APIGatewayProxyRequest, _ map[string]string) (events.APIGatewayProxyResponse, error) {
+		return h.handlePostTransacoes(ctx, request)
+	})
+	rotas.registrar(http.MethodGet, "/health", func(ctx context.Context, _ events.APIGatewayProxyRequest, _ map[string]string) (events.APIGatewayProxyResponse, error) {
+		return h.handleHealthCheck(ctx)
+	})
+	return rotas
 }
 
 // HandleRequest é o ponto de entrada principal do Lambda
@@ -65,7 +157,7 @@ func (h *LambdaHandler) HandleRequest(ctx context.Context, request events.APIGat
 	startTime := time.Now()
 
 	// Gera correlation ID a partir do trace ID ou cria um novo
-	correlationID := h.extractOrGenerateCorrelationID(request)
+	correlationID := h.extractOrGenerateCorrelationID(ctx, request)
 	ctx = context.WithValue(ctx, "correlation_id", correlationID)
 
 	// Inicia span de tracing distribuído
@@ -75,6 +167,17 @@ func (h *LambdaHandler) HandleRequest(ctx context.Context, request events.APIGat
 	h.tracer.AddTag(span, "http.method", request.HTTPMethod)
 	h.tracer.AddTag(span, "http.path", request.Path)
 	h.tracer.AddTag(span, "correlation_id", correlationID)
+	// Liga o correlation ID ao request ID da AWS (suporte e CloudWatch)
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		h.tracer.AddTag(span, "aws_request_id", lc.AwsRequestID)
+	}
+	coldStart := h.registrarPartidaAFrio(ctx, span)
+
+	var temporizacao *domain.Temporizacao
+	if h.serverTiming {
+		temporizacao = &domain.Temporizacao{}
+		ctx = domain.ComTemporizacao(ctx, temporizacao)
+	}
 
 	// Log da requisição
 	h.logger.Info(ctx, "requisição recebida", map[string]interface{}{
@@ -82,59 +185,125 @@ func (h *LambdaHandler) HandleRequest(ctx context.Context, request events.APIGat
 		"path":      request.Path,
 		"source_ip": request.RequestContext.Identity.SourceIP,
 	})
+	ctx = h.aplicarSobrescritasDepuracao(ctx, request)
 
 	// Roteamento baseado no método e path
 	var response events.APIGatewayProxyResponse
 	var err error
 
-	switch {
-	case request.HTTPMethod == "POST" && request.Path == "/transacoes":
-		response, err = h.handlePostTransacoes(ctx, request)
-	case request.HTTPMethod == "GET" && request.Path == "/health":
-		response, err = h.handleHealthCheck(ctx)
-	default:
-		response = h.createErrorResponse(http.StatusNotFound, "endpoint_not_found", "Endpoint não encontrado", correlationID)
+	// Com a carga global acima do limite, a resposta 429 já vem montada
+	if h.admitirRequisicao(ctx, &response) {
+		response, err = h.despachar(ctx, request)
 	}
 
+	h.definirOrcamentoTimeout(ctx, &response)
+	definirServerTiming(temporizacao, &response)
+
 	// Registra métricas de latência
 	duration := time.Since(startTime).Seconds()
 	h.metricsCollector.RecordTransactionLatency(duration)
 
 	// Log da resposta
-	h.logger.Info(ctx, "resposta enviada", map[string]interface{}{
+	campos := map[string]interface{}{
 		"status_code": response.StatusCode,
 		"duration_ms": duration * 1000,
-	})
+	}
+	if coldStart {
+		campos["cold_start"] = true
+	}
+	h.logger.Info(ctx, "resposta enviada", campos)
 
 	return response, err
 }
 
+// despachar encaminha a requisição ao manipulador da rota ou responde
+// 404/405 quando nenhuma rota atende
+func (h *LambdaHandler) despachar(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	manipulador, params, permitidos := h.rotas.resolver(request.HTTPMethod, request.Path)
+	if manipulador == nil {
+		return h.createRouteNotMatchedResponse(ctx, request, permitidos), nil
+	}
+	return manipulador(ctx, request, params)
+}
+
+// createRouteNotMatchedResponse distingue path desconhecido (404) de path
+// conhecido com método não suportado (405 com header Allow). Com o índice de
+// rotas habilitado, o 404 de GET lista os endpoints disponíveis.
+func (h *LambdaHandler) createRouteNotMatchedResponse(ctx context.Context, request events.APIGatewayProxyRequest, permitidos []string) events.APIGatewayProxyResponse {
+	if len(permitidos) == 0 {
+		errorResponse := ErrorResponse{Error: "endpoint_not_found", Message: "Endpoint não encontrado"}
+		if h.indiceRotas && request.HTTPMethod == http.MethodGet {
+			errorResponse.Endpoints = h.rotas.endpoints()
+		}
+		return h.writeErrorResponse(ctx, http.StatusNotFound, errorResponse)
+	}
+
+	response := h.createErrorResponse(ctx, http.StatusMethodNotAllowed, "method_not_allowed",
+		fmt.Sprintf("Método %s não suportado em %s", request.HTTPMethod, request.Path))
+	response.Headers["Allow"] = strings.Join(permitidos, ", ")
+	return response
+}
+
 // handlePostTransacoes processa POST /transacoes
 func (h *LambdaHandler) handlePostTransacoes(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// O timestamp da transação pode vir do cliente: o tempo de resposta é
+	// medido a partir da chegada da requisição
+	inicio := time.Now()
+
 	ctx, span := h.tracer.StartSpan(ctx, "handler.post_transacoes")
 	defer h.tracer.FinishSpan(span, nil)
 
-	correlationID := ctx.Value("correlation_id").(string)
+	ctx, correlationID := h.garantirCorrelationID(ctx)
+
+	// Content-Type: só JSON é aceito (parâmetros como charset são ignorados)
+	if !conteudoJSON(request) {
+		h.metricsCollector.IncrementErrorCounter("unsupported_media_type")
+		return h.createErrorResponse(ctx, http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Content-Type deve ser application/json"), nil
+	}
+
+	corpo, err := corpoRequisicao(request)
+	if err != nil {
+		h.logger.Warn(ctx, "erro ao decodificar o corpo da requisição", map[string]interface{}{
+			"error": err.Error(),
+		})
+		h.metricsCollector.IncrementErrorCounter("body_decode_error")
+		return h.createErrorResponse(ctx, http.StatusBadRequest, "invalid_body", "Corpo da requisição inválido"), nil
+	}
 
 	// Parse do JSON
 	var req TransacaoRequest
-	if err := json.Unmarshal([]byte(request.Body), &req); err != nil {
+	if err := json.Unmarshal(corpo, &req); err != nil {
 		h.logger.Warn(ctx, "erro ao fazer parse do JSON", map[string]interface{}{
 			"error": err.Error(),
 			"body":  request.Body,
 		})
 		h.metricsCollector.IncrementErrorCounter("json_parse_error")
-		return h.createErrorResponse(http.StatusBadRequest, "invalid_json", "JSON inválido", correlationID), nil
+		return h.createErrorResponse(ctx, http.StatusBadRequest, "invalid_json", "JSON inválido"), nil
 	}
 
 	h.tracer.AddTag(span, "cliente_id", req.ClienteID)
-	h.tracer.AddTag(span, "valor", req.Valor)
-
-	// Cria transação
-	transacao := domain.NewTransacao(req.ClienteID, req.Valor, correlationID)
 
-	// Processa transação
-	err := h.transacaoService.AutorizarTransacao(ctx, transacao)
+	valor, err := h.valorRequisicao(&req)
+	if err != nil {
+		statusCode, errorCode, message := h.categorizeError(err)
+		return h.createErrorResponse(ctx, statusCode, errorCode, message), nil
+	}
+	h.tracer.AddTag(span, "valor", valor)
+
+	transacao := h.novaTransacao(&req, valor, correlationID)
+	transacao.ChaveIdempotencia = getHeader(request, "Idempotency-Key")
+	h.tracer.AddTag(span, "canal", transacao.Canal)
+
+	// Processa transação: com data de agendamento apenas registra, sem debitar.
+	// Na autorização a recusa de negócio chega no Resultado e o erro só
+	// indica falha de infraestrutura; ambos seguem para o mapeamento HTTP.
+	var resultado *service.Resultado
+	if transacao.DataAgendamento != nil {
+		err = h.transacaoService.AgendarTransacao(ctx, transacao)
+	} else if resultado, err = h.transacaoService.AutorizarTransacao(ctx, transacao); err == nil && !resultado.Aprovada() {
+		err = resultado.Motivo
+	}
 	if err != nil {
 		// Determina o tipo de erro e status HTTP
 		statusCode, errorCode, message := h.categorizeError(err)
@@ -145,7 +314,22 @@ func (h *LambdaHandler) handlePostTransacoes(ctx context.Context, request events
 			"error_code":   errorCode,
 		})
 
-		return h.createErrorResponse(statusCode, errorCode, message, correlationID), nil
+		var stepUp *domain.StepUpRequeridoError
+		if errors.As(err, &stepUp) {
+			return h.createStepUpResponse(ctx, stepUp.Desafio), nil
+		}
+
+		if statusCode == http.StatusInternalServerError {
+			return h.createInternalErrorResponse(ctx, err, map[string]interface{}{
+				"transacao_id": transacao.ID,
+			}), nil
+		}
+
+		errorResponse := ErrorResponse{Error: errorCode, Message: message}
+		if resultado != nil && resultado.EsperaRecomendada > 0 {
+			errorResponse.RetryAfterSeconds = segundosRetryAfter(resultado.EsperaRecomendada)
+		}
+		return h.writeErrorResponse(ctx, statusCode, errorResponse), nil
 	}
 
 	// Resposta de sucesso
@@ -156,28 +340,49 @@ func (h *LambdaHandler) handlePostTransacoes(ctx context.Context, request events
 		Valor:         transacao.Valor,
 		Timestamp:     transacao.Timestamp,
 		CorrelationID: correlationID,
+
+		DataAgendamento: transacao.DataAgendamento,
+	}
+	if h.saldoNaResposta && resultado != nil && resultado.LimiteDisponivel != nil {
+		saldo := domain.ReaisDeCentavos(int64(*resultado.LimiteDisponivel))
+		response.SaldoDisponivel = &saldo
+	}
+	if h.limiteRestanteNaResposta && resultado != nil && resultado.LimiteRestante != nil {
+		restante := domain.ReaisDeCentavos(int64(*resultado.LimiteRestante))
+		response.LimiteRestante = &restante
 	}
 
 	responseBody, _ := json.Marshal(response)
 
+	statusCode := http.StatusOK
+	if transacao.Status == domain.StatusAgendada {
+		statusCode = http.StatusAccepted
+	}
+
 	return events.APIGatewayProxyResponse{
-		StatusCode: http.StatusOK,
+		StatusCode: statusCode,
 		Headers: map[string]string{
 			"Content-Type":     "application/json",
 			"X-Correlation-ID": correlationID,
-			"X-Response-Time":  fmt.Sprintf("%.3fms", time.Since(transacao.Timestamp).Seconds()*1000),
+			"X-Trace-ID":       h.tracer.ExtractTraceID(ctx),
+			"X-Response-Time":  fmt.Sprintf("%.3fms", time.Since(inicio).Seconds()*1000),
 		},
 		Body: string(responseBody),
 	}, nil
 }
 
-// handleHealthCheck responde ao health check
+// handleHealthCheck responde ao health check com os metadados do build,
+// permitindo confirmar o que está efetivamente em produção
 func (h *LambdaHandler) handleHealthCheck(ctx context.Context) (events.APIGatewayProxyResponse, error) {
 	healthResponse := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now().Format(time.RFC3339),
-		"version":   "1.0.0",
-		"service":   "transaction-authorizer",
+		"status":     "healthy",
+		"timestamp":  time.Now().Format(time.RFC3339),
+		"version":    buildinfo.Version,
+		"git_commit": buildinfo.GitCommit,
+		"build_time": buildinfo.BuildTime,
+		"go_version": runtime.Version(),
+		"region":     os.Getenv("AWS_REGION"),
+		"service":    "transaction-authorizer",
 	}
 
 	responseBody, _ := json.Marshal(healthResponse)
@@ -191,48 +396,219 @@ func (h *LambdaHandler) handleHealthCheck(ctx context.Context) (events.APIGatewa
 	}, nil
 }
 
+// novaTransacao cria a transação a partir da requisição, com o valor já
+// normalizado por valorRequisicao
+func (h *LambdaHandler) novaTransacao(req *TransacaoRequest, valor float64, correlationID string) *domain.Transacao {
+	transacao := domain.NewTransacaoComGerador(h.geradorID, req.ClienteID, valor, correlationID)
+	transacao.DefinirCanal(req.Canal)
+	if req.Moeda != "" {
+		transacao.Moeda = req.Moeda
+	}
+	transacao.Parcelas = req.Parcelas
+	transacao.TokenStepUp = req.StepUpToken
+	transacao.Tipo = req.Tipo
+	transacao.DataAgendamento = req.DataAgendamento
+	transacao.Tags = req.Tags
+	if req.Timestamp != nil {
+		transacao.Timestamp = *req.Timestamp
+		transacao.TimestampInformado = true
+	}
+	return transacao
+}
+
+// valorRequisicao normaliza o valor informado em reais ou em centavos.
+// Centavos são exatos; em reais vale o modo de precisão: o estrito rejeita
+// mais de duas casas decimais, o leniente arredonda para centavos pelo modo
+// de arredondamento configurado.
+// Sem nenhuma das representações o valor é zero e a validação de domínio decide.
+func (h *LambdaHandler) valorRequisicao(req *TransacaoRequest) (float64, error) {
+	switch {
+	case req.Valor != nil && req.ValorCentavos != nil:
+		h.metricsCollector.IncrementErrorCounter("ambiguous_amount")
+		return 0, domain.ErrValorAmbiguo
+	case req.ValorCentavos != nil:
+		return float64(*req.ValorCentavos) / 100, nil
+	case req.Valor == nil:
+		return 0, nil
+	}
+
+	valor := *req.Valor
+	if err := domain.ValidarPrecisao(valor); err != nil {
+		if h.modoPrecisao == PrecisaoEstrita {
+			h.metricsCollector.IncrementErrorCounter("invalid_precision")
+			return 0, err
+		}
+		valor = float64(domain.ValorEmCentavosComModo(valor, h.modoArredondamento)) / 100
+	}
+	return valor, nil
+}
+
 // categorizeError categoriza erros em códigos HTTP e tipos de erro
 func (h *LambdaHandler) categorizeError(err error) (int, string, string) {
 	switch {
 	case err == domain.ErrLimiteInsuficiente:
 		return http.StatusUnprocessableEntity, "insufficient_limit", "Limite insuficiente"
 	case err == domain.ErrClienteNaoEncontrado:
-		return http.StatusNotFound, "client_not_found", "Cliente não encontrado"
+		return h.statusClienteNaoEncontrado, "client_not_found", "Cliente não encontrado"
 	case err == domain.ErrValorNegativo || err == domain.ErrValorZero:
 		return http.StatusBadRequest, "invalid_amount", "Valor inválido"
+	case err == domain.ErrValorAmbiguo:
+		return http.StatusBadRequest, "ambiguous_amount", "Informe valor (reais) ou valor_centavos, não ambos"
+	case err == domain.ErrMoedaInvalida:
+		return http.StatusBadRequest, "invalid_currency", "Código de moeda inválido (ISO 4217)"
+	case err == domain.ErrMoedaNaoSuportada:
+		return http.StatusUnprocessableEntity, "unsupported_currency", "Moeda não suportada"
+	case err == domain.ErrPrecisaoInvalida:
+		return http.StatusBadRequest, "invalid_precision", "Valor deve ter no máximo duas casas decimais"
 	case err == domain.ErrClienteInvalido:
 		return http.StatusBadRequest, "invalid_client", "Cliente inválido"
+	case err == domain.ErrDataAgendamentoInvalida:
+		return http.StatusBadRequest, "invalid_schedule_date", "Data de agendamento deve estar no futuro"
+	case err == domain.ErrTimestampInvalido:
+		return http.StatusBadRequest, "invalid_timestamp", "Timestamp da transação no futuro"
+	case err == domain.ErrParcelasInvalidas:
+		return http.StatusBadRequest, "invalid_installments", "Número de parcelas inválido"
+	case err == domain.ErrTagsExcedidas || err == domain.ErrTagInvalida:
+		return http.StatusBadRequest, "invalid_tags", "Tags inválidas"
+	case err == domain.ErrParcelasAcimaDoPermitido:
+		return http.StatusUnprocessableEntity, "installments_above_allowance", "Número de parcelas acima do permitido"
+	case err == domain.ErrValorAcimaDoMaximo:
+		return http.StatusUnprocessableEntity, "amount_above_maximum", "Valor acima do máximo permitido"
+	case err == domain.ErrLimiteDiarioExcedido:
+		return http.StatusUnprocessableEntity, "daily_limit_exceeded", "Limite diário excedido"
+	case err == domain.ErrTransacaoDuplicadaSuspeita:
+		return http.StatusConflict, "suspected_duplicate", "Transação idêntica aprovada recentemente"
+	case err == domain.ErrConflitoIdempotencia:
+		return http.StatusConflict, "idempotency_key_conflict", "Chave de idempotência já usada com outro payload"
+	case err == domain.ErrIdempotenciaEmProcessamento:
+		return http.StatusConflict, "idempotency_key_in_progress", "Requisição com a mesma chave de idempotência em processamento"
+	case err == domain.ErrPreAutorizacaoNegada:
+		return http.StatusUnprocessableEntity, "pre_authorization_denied", "Transação vetada pela análise de risco"
+	case err == domain.ErrPreAutorizacaoIndisponivel:
+		return http.StatusServiceUnavailable, "pre_authorization_unavailable", "Análise de risco indisponível"
+	case errors.Is(err, domain.ErrStepUpNecessario):
+		return http.StatusUnauthorized, "step_up_required", "Confirmação adicional necessária para este valor"
+	case errors.Is(err, domain.ErrResultadoIndeterminado):
+		return http.StatusInternalServerError, "outcome_unknown", "Resultado da operação indeterminado; não repita automaticamente"
+	case errors.Is(err, domain.ErrTimeoutOperacao):
+		return http.StatusServiceUnavailable, "dependency_timeout", "Tempo limite excedido ao acessar dependência"
+	case errors.Is(err, domain.ErrThrottled):
+		return http.StatusTooManyRequests, "throttled", "Capacidade temporariamente excedida, tente novamente"
+	case errors.Is(err, domain.ErrConfiguracaoInvalida):
+		return http.StatusInternalServerError, "configuration_error", "Serviço com configuração inválida"
 	default:
 		return http.StatusInternalServerError, "internal_error", "Erro interno do servidor"
 	}
 }
 
-// createErrorResponse cria uma resposta de erro padronizada
-func (h *LambdaHandler) createErrorResponse(statusCode int, errorCode, message, correlationID string) events.APIGatewayProxyResponse {
-	errorResponse := ErrorResponse{
-		Error:         errorCode,
-		Message:       message,
-		CorrelationID: correlationID,
-		Timestamp:     time.Now().Format(time.RFC3339),
-	}
+// createErrorResponse cria uma resposta de erro padronizada.
+// Correlation ID e trace ID são extraídos do contexto da requisição para que
+// o suporte consiga ir do erro recebido pelo cliente direto ao trace.
+func (h *LambdaHandler) createErrorResponse(ctx context.Context, statusCode int, errorCode, message string) events.APIGatewayProxyResponse {
+	return h.writeErrorResponse(ctx, statusCode, ErrorResponse{Error: errorCode, Message: message})
+}
+
+// createStepUpResponse cria a resposta 401 step_up_required com a referência
+// do desafio no corpo e no header WWW-Authenticate
+func (h *LambdaHandler) createStepUpResponse(ctx context.Context, desafio string) events.APIGatewayProxyResponse {
+	statusCode, errorCode, message := h.categorizeError(domain.ErrStepUpNecessario)
+
+	response := h.writeErrorResponse(ctx, statusCode, ErrorResponse{
+		Error:       errorCode,
+		Message:     message,
+		ChallengeID: desafio,
+	})
+	response.Headers["WWW-Authenticate"] = fmt.Sprintf(`StepUp challenge="%s"`, desafio)
+	return response
+}
+
+// createInternalErrorResponse gera um error_id único e o registra no log de
+// erro junto com a causa, para que o suporte localize a ocorrência exata
+func (h *LambdaHandler) createInternalErrorResponse(ctx context.Context, err error, fields map[string]interface{}) events.APIGatewayProxyResponse {
+	errorID := uuid.New().String()
+
+	fields["error_id"] = errorID
+	h.logger.Error(ctx, "erro interno ao processar requisição", err, fields)
+
+	statusCode, errorCode, message := h.categorizeError(err)
+	return h.writeErrorResponse(ctx, statusCode, ErrorResponse{
+		Error:   errorCode,
+		Message: message,
+		ErrorID: errorID,
+	})
+}
+
+// writeErrorResponse completa o corpo com correlation ID, trace ID e timestamp
+func (h *LambdaHandler) writeErrorResponse(ctx context.Context, statusCode int, errorResponse ErrorResponse) events.APIGatewayProxyResponse {
+	correlationID, _ := ctx.Value("correlation_id").(string)
+	traceID := h.tracer.ExtractTraceID(ctx)
+
+	errorResponse.CorrelationID = correlationID
+	errorResponse.TraceID = traceID
+	errorResponse.Timestamp = time.Now().Format(time.RFC3339)
 
 	responseBody, _ := json.Marshal(errorResponse)
 
-	return events.APIGatewayProxyResponse{
+	response := events.APIGatewayProxyResponse{
 		StatusCode: statusCode,
 		Headers: map[string]string{
 			"Content-Type":     "application/json",
 			"X-Correlation-ID": correlationID,
+			"X-Trace-ID":       traceID,
 		},
 		Body: string(responseBody),
 	}
+
+	// Orienta o cliente a recuar antes de tentar novamente: pela janela da
+	// recusa quando conhecida, senão pelo intervalo fixo de throttling
+	if errorResponse.RetryAfterSeconds > 0 {
+		response.Headers["Retry-After"] = strconv.Itoa(errorResponse.RetryAfterSeconds)
+	} else if statusCode == http.StatusTooManyRequests {
+		response.Headers["Retry-After"] = strconv.Itoa(int(math.Ceil(h.retryAfterThrottling.Seconds())))
+	}
+
+	return response
+}
+
+// segundosRetryAfter arredonda a espera para cima, em segundos inteiros (mínimo 1)
+func segundosRetryAfter(espera time.Duration) int {
+	return max(1, int(math.Ceil(espera.Seconds())))
+}
+
+// garantirCorrelationID lê o correlation ID do contexto. Ausente ou de outro
+// tipo (ex.: definido por um middleware), gera um novo e o grava no contexto
+// devolvido, para que logs e respostas usem o mesmo valor.
+func (h *LambdaHandler) garantirCorrelationID(ctx context.Context) (context.Context, string) {
+	valor := ctx.Value("correlation_id")
+	if correlationID, ok := valor.(string); ok && correlationID != "" {
+		return ctx, correlationID
+	}
+
+	correlationID := uuid.New().String()
+	ctx = context.WithValue(ctx, "correlation_id", correlationID)
+	h.logger.Warn(ctx, "correlation ID ausente ou inválido no contexto, gerado um novo", map[string]interface{}{
+		"tipo_original": fmt.Sprintf("%T", valor),
+	})
+	h.metricsCollector.IncrementErrorCounter("invalid_correlation_id")
+	return ctx, correlationID
 }
 
 // extractOrGenerateCorrelationID extrai correlation ID do header ou gera um novo
-func (h *LambdaHandler) extractOrGenerateCorrelationID(request events.APIGatewayProxyRequest) string {
-	// Tenta extrair do header
-	if correlationID := request.Headers["X-Correlation-ID"]; correlationID != "" {
-		return correlationID
+func (h *LambdaHandler) extractOrGenerateCorrelationID(ctx context.Context, request events.APIGatewayProxyRequest) string {
+	// Tenta extrair do header. O valor vai para logs e headers de resposta,
+	// então fora do padrão seguro é substituído (risco de header injection).
+	if correlationID := getHeader(request, "X-Correlation-ID"); correlationID != "" {
+		if h.padraoCorrelacao.MatchString(correlationID) {
+			return correlationID
+		}
+
+		novoID := uuid.New().String()
+		h.logger.Warn(context.WithValue(ctx, "correlation_id", novoID), "correlation ID inválido substituído", map[string]interface{}{
+			"tamanho_original": len(correlationID),
+			"prefixo_original": fmt.Sprintf("%.32q", correlationID),
+		})
+		h.metricsCollector.IncrementErrorCounter("invalid_correlation_id")
+		return novoID
 	}
 
 	// Tenta extrair do request ID do API Gateway
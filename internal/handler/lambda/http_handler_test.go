@@ -0,0 +1,1050 @@
+package awslambda
+
+import (
+	"authorizer/internal/buildinfo"
+	"authorizer/internal/core/domain"
+	"authorizer/internal/core/service"
+	"authorizer/internal/observability/tracing"
+	"authorizer/internal/security/stepup"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func TestHandleRequest_ErrorResponseContemTraceID(t *testing.T) {
+	tests := []struct {
+		name    string
+		request events.APIGatewayProxyRequest
+		status  int
+	}{
+		{
+			name:    "JSON inválido",
+			request: events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/transacoes", Headers: cabecalhoJSON, Body: "{invalido"},
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "endpoint inexistente",
+			request: events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/nao-existe"},
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "limite insuficiente",
+			request: events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/transacoes", Headers: cabecalhoJSON, Body: `{"cliente_id":"c1","valor":50.00}`},
+			status:  http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := tracing.NewRecordingTracer()
+			handler := newTestHandler(tracer, &domain.Cliente{ID: "c1", LimiteCredit: 1000, LimiteAtual: 1000})
+
+			response, err := handler.HandleRequest(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if response.StatusCode != tt.status {
+				t.Fatalf("Status esperado %d, got %d", tt.status, response.StatusCode)
+			}
+
+			var body ErrorResponse
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("erro ao decodificar resposta: %v", err)
+			}
+
+			root, ok := tracer.Span("lambda.handle_request")
+			if !ok {
+				t.Fatal("span raiz não foi iniciado")
+			}
+
+			if body.TraceID == "" {
+				t.Error("trace_id não deve estar vazio")
+			}
+
+			if body.TraceID != root.TraceID {
+				t.Errorf("trace_id esperado %s, got %s", root.TraceID, body.TraceID)
+			}
+
+			if response.Headers["X-Trace-ID"] != root.TraceID {
+				t.Errorf("X-Trace-ID esperado %s, got %s", root.TraceID, response.Headers["X-Trace-ID"])
+			}
+		})
+	}
+}
+
+func TestHandleRequest_SucessoContemTraceIDNoHeader(t *testing.T) {
+	tracer := tracing.NewRecordingTracer()
+	handler := newTestHandler(tracer, &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+	response, err := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":99.90}`,
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Status esperado %d, got %d (%s)", http.StatusOK, response.StatusCode, response.Body)
+	}
+
+	root, ok := tracer.Span("lambda.handle_request")
+	if !ok {
+		t.Fatal("span raiz não foi iniciado")
+	}
+
+	if response.Headers["X-Trace-ID"] != root.TraceID {
+		t.Errorf("X-Trace-ID esperado %s, got %s", root.TraceID, response.Headers["X-Trace-ID"])
+	}
+}
+
+func TestHandleRequest_SpanRaizContemAWSRequestID(t *testing.T) {
+	tracer := tracing.NewRecordingTracer()
+	handler := newTestHandler(tracer)
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-123"})
+	handler.HandleRequest(ctx, events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+
+	root, ok := tracer.Span("lambda.handle_request")
+	if !ok {
+		t.Fatal("span raiz não foi iniciado")
+	}
+	if got := root.Tags["aws_request_id"]; got != "req-123" {
+		t.Errorf("aws_request_id esperado req-123, got %v", got)
+	}
+}
+
+// servicoContador conta as autorizações recebidas pela instância
+type servicoContador struct {
+	service.TransacaoService
+	autorizacoes int
+}
+
+func (s *servicoContador) AutorizarTransacao(ctx context.Context, transacao *domain.Transacao) (*service.Resultado, error) {
+	s.autorizacoes++
+	return s.TransacaoService.AutorizarTransacao(ctx, transacao)
+}
+
+func TestNewLambdaHandler_CompartilhaInstanciaDoServico(t *testing.T) {
+	metrics := &fakeMetricsCollector{}
+	logger := &fakeLogger{}
+	tracer := tracing.NewRecordingTracer()
+
+	servico := &servicoContador{TransacaoService: service.NewTransacaoService(
+		newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000}),
+		newFakeTransacaoRepository(),
+		&fakeEventPublisher{},
+		metrics,
+		tracer,
+		logger,
+	)}
+	handler := NewLambdaHandler(servico, logger, tracer, metrics)
+
+	if handler.transacaoService != service.TransacaoService(servico) {
+		t.Fatal("handler deveria guardar a própria instância do serviço, não uma cópia")
+	}
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":10.00}`,
+	})
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Status esperado %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	if servico.autorizacoes != 1 {
+		t.Errorf("a autorização deveria chegar à instância passada ao handler, got %d chamadas", servico.autorizacoes)
+	}
+}
+
+func TestCategorizeError_TimeoutDeOperacao(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer())
+
+	err := fmt.Errorf("erro ao debitar limite do cliente c1: %w", domain.ErrTimeoutOperacao)
+	status, code, _ := handler.categorizeError(err)
+
+	if status != http.StatusServiceUnavailable || code != "dependency_timeout" {
+		t.Errorf("esperado 503 dependency_timeout, got %d %s", status, code)
+	}
+}
+
+func TestCategorizeError_EscritaComResultadoIndeterminado(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer())
+
+	err := fmt.Errorf("erro ao debitar limite do cliente c1: %w: %w", domain.ErrResultadoIndeterminado, domain.ErrTimeoutOperacao)
+	status, code, _ := handler.categorizeError(err)
+
+	if status != http.StatusInternalServerError || code != "outcome_unknown" {
+		t.Errorf("esperado 500 outcome_unknown (sem retentativa), got %d %s", status, code)
+	}
+}
+
+func TestHandlePostTransacoes_SaldoNaResposta(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		saldo string
+	}{
+		{name: "desligado por padrão"},
+		{name: "habilitado", opts: []Option{WithSaldoNaResposta(true)}, saldo: "74.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), tt.opts,
+				&domain.Cliente{ID: "c1", LimiteCredit: 10000, LimiteAtual: 10000})
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       `{"cliente_id":"c1","valor":25.50}`,
+			})
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("Status esperado 200, got %d: %s", response.StatusCode, response.Body)
+			}
+
+			var body map[string]json.RawMessage
+			json.Unmarshal([]byte(response.Body), &body)
+			saldo, presente := body["saldo_disponivel"]
+			if tt.saldo == "" {
+				if presente {
+					t.Errorf("saldo_disponivel não esperado, got %s", saldo)
+				}
+				return
+			}
+			if string(saldo) != tt.saldo {
+				t.Errorf("saldo_disponivel esperado %s, got %s", tt.saldo, saldo)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_StatusClienteNaoEncontrado(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		status int
+	}{
+		{name: "padrão", status: http.StatusNotFound},
+		{name: "configurado 422", opts: []Option{WithStatusClienteNaoEncontrado(http.StatusUnprocessableEntity)}, status: http.StatusUnprocessableEntity},
+		{name: "configurado 404", opts: []Option{WithStatusClienteNaoEncontrado(http.StatusNotFound)}, status: http.StatusNotFound},
+		{name: "status não suportado mantém 404", opts: []Option{WithStatusClienteNaoEncontrado(http.StatusTeapot)}, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), tt.opts)
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       `{"cliente_id":"inexistente","valor":10.00}`,
+			})
+
+			if response.StatusCode != tt.status {
+				t.Fatalf("Status esperado %d, got %d", tt.status, response.StatusCode)
+			}
+
+			var body ErrorResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.Error != "client_not_found" {
+				t.Errorf("erro esperado client_not_found, got %s", body.Error)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_ThrottlingRetorna429(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		retryAfter string
+	}{
+		{name: "intervalo padrão", retryAfter: "1"},
+		{name: "intervalo configurado", opts: []Option{WithRetryAfterThrottling(1500 * time.Millisecond)}, retryAfter: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &fakeMetricsCollector{}
+			tracer := tracing.NewRecordingTracer()
+
+			limites := &falhaLimiteRepository{
+				fakeLimiteRepository: newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000}),
+				err:                  fmt.Errorf("erro ao debitar limite do cliente c1: %w", domain.ErrThrottled),
+			}
+			transacaoService := service.NewTransacaoService(limites, newFakeTransacaoRepository(), &fakeEventPublisher{}, metrics, tracer, &fakeLogger{})
+			handler := NewLambdaHandler(transacaoService, &fakeLogger{}, tracer, metrics, tt.opts...)
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       `{"cliente_id":"c1","valor":10.00}`,
+			})
+
+			if response.StatusCode != http.StatusTooManyRequests {
+				t.Fatalf("Status esperado %d, got %d", http.StatusTooManyRequests, response.StatusCode)
+			}
+
+			var body ErrorResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.Error != "throttled" {
+				t.Errorf("erro esperado throttled, got %s", body.Error)
+			}
+
+			if got := response.Headers["Retry-After"]; got != tt.retryAfter {
+				t.Errorf("Retry-After esperado %s, got %q", tt.retryAfter, got)
+			}
+		})
+	}
+}
+
+func TestHandleHealthCheck_ReportaBuildInfo(t *testing.T) {
+	versao, commit, buildTime := buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime
+	defer func() {
+		buildinfo.Version, buildinfo.GitCommit, buildinfo.BuildTime = versao, commit, buildTime
+	}()
+
+	buildinfo.Version = "1.4.0"
+	buildinfo.GitCommit = "abc1234"
+	buildinfo.BuildTime = "2024-01-15T10:30:00Z"
+	t.Setenv("AWS_REGION", "sa-east-1")
+
+	handler := newTestHandler(tracing.NewRecordingTracer())
+
+	response, err := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+		t.Fatalf("erro ao decodificar resposta: %v", err)
+	}
+
+	esperado := map[string]string{
+		"version":    "1.4.0",
+		"git_commit": "abc1234",
+		"build_time": "2024-01-15T10:30:00Z",
+		"go_version": runtime.Version(),
+		"region":     "sa-east-1",
+	}
+
+	for campo, valor := range esperado {
+		if body[campo] != valor {
+			t.Errorf("%s esperado %s, got %v", campo, valor, body[campo])
+		}
+	}
+}
+
+func TestHandlePostTransacoes_ModoPrecisao(t *testing.T) {
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":19.999}`,
+	}
+
+	t.Run("estrito rejeita", func(t *testing.T) {
+		handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithModoPrecisao(PrecisaoEstrita)},
+			&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+		response, _ := handler.HandleRequest(context.Background(), request)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Fatalf("Status esperado %d, got %d", http.StatusBadRequest, response.StatusCode)
+		}
+
+		var body ErrorResponse
+		json.Unmarshal([]byte(response.Body), &body)
+		if body.Error != "invalid_precision" {
+			t.Errorf("erro esperado invalid_precision, got %s", body.Error)
+		}
+	})
+
+	t.Run("leniente arredonda", func(t *testing.T) {
+		handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithModoPrecisao(PrecisaoLeniente)},
+			&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+		response, _ := handler.HandleRequest(context.Background(), request)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Status esperado %d, got %d", http.StatusOK, response.StatusCode)
+		}
+
+		var body TransacaoResponse
+		json.Unmarshal([]byte(response.Body), &body)
+		if body.Valor != 20.00 {
+			t.Errorf("valor esperado 20.00, got %v", body.Valor)
+		}
+	})
+
+	t.Run("leniente usa o modo de arredondamento", func(t *testing.T) {
+		handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithModoArredondamento(domain.ArredondamentoTruncar)},
+			&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+		response, _ := handler.HandleRequest(context.Background(), request)
+
+		var body TransacaoResponse
+		json.Unmarshal([]byte(response.Body), &body)
+		if body.Valor != 19.99 {
+			t.Errorf("valor esperado 19.99, got %v", body.Valor)
+		}
+	})
+
+	t.Run("estrito aceita duas casas", func(t *testing.T) {
+		handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithModoPrecisao(PrecisaoEstrita)},
+			&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+		response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Path:       "/transacoes",
+			Headers:    cabecalhoJSON,
+			Body:       `{"cliente_id":"c1","valor":19.99}`,
+		})
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("Status esperado %d, got %d", http.StatusOK, response.StatusCode)
+		}
+	})
+}
+
+func TestHandlePostTransacoes_TimestampNoFuturo(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer(), &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+	futuro := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":10.00,"timestamp":"` + futuro + `"}`,
+	})
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Status esperado %d, got %d", http.StatusBadRequest, response.StatusCode)
+	}
+
+	var body ErrorResponse
+	json.Unmarshal([]byte(response.Body), &body)
+	if body.Error != "invalid_timestamp" {
+		t.Errorf("erro esperado invalid_timestamp, got %s", body.Error)
+	}
+}
+
+func TestHandlePostTransacoes_TempoDeRespostaIgnoraTimestampDoCliente(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer(), &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":10.00,"timestamp":"2020-01-01T00:00:00Z"}`,
+	})
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Status esperado %d, got %d: %s", http.StatusOK, response.StatusCode, response.Body)
+	}
+
+	ms, err := strconv.ParseFloat(strings.TrimSuffix(response.Headers["X-Response-Time"], "ms"), 64)
+	if err != nil || ms > 1000 {
+		t.Errorf("X-Response-Time deveria medir o processamento da requisição, got %q", response.Headers["X-Response-Time"])
+	}
+}
+
+// servicoRecusa devolve sempre o resultado configurado
+type servicoRecusa struct {
+	service.TransacaoService
+	resultado *service.Resultado
+}
+
+func (s *servicoRecusa) AutorizarTransacao(ctx context.Context, transacao *domain.Transacao) (*service.Resultado, error) {
+	return s.resultado, nil
+}
+
+func TestHandlePostTransacoes_RecusaComEsperaRecomendada(t *testing.T) {
+	tests := []struct {
+		name       string
+		espera     time.Duration
+		retryAfter string
+	}{
+		{name: "velocidade com janela", espera: 90*time.Minute + 500*time.Millisecond, retryAfter: "5401"},
+		{name: "sem janela conhecida"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servico := &servicoRecusa{resultado: &service.Resultado{
+				Status:            domain.StatusRejeitada,
+				CodigoMotivo:      "daily_limit_exceeded",
+				Motivo:            domain.ErrLimiteDiarioExcedido,
+				EsperaRecomendada: tt.espera,
+			}}
+			handler := NewLambdaHandler(servico, &fakeLogger{}, tracing.NewRecordingTracer(), &fakeMetricsCollector{})
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       `{"cliente_id":"c1","valor":10.00}`,
+			})
+			if response.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("Status esperado %d, got %d", http.StatusUnprocessableEntity, response.StatusCode)
+			}
+
+			var body ErrorResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if got := response.Headers["Retry-After"]; got != tt.retryAfter {
+				t.Errorf("Retry-After esperado %q, got %q", tt.retryAfter, got)
+			}
+			if got := fmt.Sprint(body.RetryAfterSeconds); tt.retryAfter != "" && got != tt.retryAfter {
+				t.Errorf("retry_after_seconds esperado %s, got %s", tt.retryAfter, got)
+			}
+			if tt.retryAfter == "" && strings.Contains(response.Body, "retry_after_seconds") {
+				t.Errorf("retry_after_seconds não deveria ser enviado: %s", response.Body)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_UnidadeDoValor(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		erro       string
+		valor      float64
+	}{
+		{name: "reais", body: `{"cliente_id":"c1","valor":19.99}`, statusCode: http.StatusOK, valor: 19.99},
+		{name: "centavos", body: `{"cliente_id":"c1","valor_centavos":1999}`, statusCode: http.StatusOK, valor: 19.99},
+		{name: "ambos", body: `{"cliente_id":"c1","valor":19.99,"valor_centavos":1999}`, statusCode: http.StatusBadRequest, erro: "ambiguous_amount"},
+		{name: "centavos fracionários", body: `{"cliente_id":"c1","valor_centavos":19.99}`, statusCode: http.StatusBadRequest, erro: "invalid_json"},
+		{name: "nenhum", body: `{"cliente_id":"c1"}`, statusCode: http.StatusBadRequest, erro: "invalid_amount"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithModoPrecisao(PrecisaoEstrita)},
+				&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       tt.body,
+			})
+			if response.StatusCode != tt.statusCode {
+				t.Fatalf("Status esperado %d, got %d: %s", tt.statusCode, response.StatusCode, response.Body)
+			}
+
+			if tt.erro != "" {
+				var body ErrorResponse
+				json.Unmarshal([]byte(response.Body), &body)
+				if body.Error != tt.erro {
+					t.Errorf("erro esperado %s, got %s", tt.erro, body.Error)
+				}
+				return
+			}
+
+			var body TransacaoResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.Valor != tt.valor {
+				t.Errorf("valor esperado %v, got %v", tt.valor, body.Valor)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_StepUpRequerido(t *testing.T) {
+	metrics := &fakeMetricsCollector{}
+	logger := &fakeLogger{}
+	tracer := tracing.NewRecordingTracer()
+
+	transacaoService := service.NewTransacaoService(
+		newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 500000, LimiteAtual: 500000}),
+		newFakeTransacaoRepository(),
+		&fakeEventPublisher{},
+		metrics,
+		tracer,
+		logger,
+		// Sem token o store de desafios resgatados não é consultado
+		service.WithStepUp(100000, stepup.NewHMACStepUp([]byte("segredo"), time.Minute, nil)),
+	)
+	handler := NewLambdaHandler(transacaoService, logger, tracer, metrics)
+
+	response, err := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":1500.00}`,
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Status esperado %d, got %d", http.StatusUnauthorized, response.StatusCode)
+	}
+
+	var body ErrorResponse
+	json.Unmarshal([]byte(response.Body), &body)
+
+	if body.Error != "step_up_required" || body.ChallengeID == "" {
+		t.Errorf("esperado step_up_required com challenge_id, got %+v", body)
+	}
+
+	if response.Headers["WWW-Authenticate"] != fmt.Sprintf(`StepUp challenge="%s"`, body.ChallengeID) {
+		t.Errorf("WWW-Authenticate inesperado: %s", response.Headers["WWW-Authenticate"])
+	}
+}
+
+func TestHandleRequest_ContagemDeSpansPorNivel(t *testing.T) {
+	contar := func(nivel tracing.Nivel) int {
+		recorder := tracing.NewRecordingTracer()
+		handler := newTestHandler(tracing.NewNivelTracer(recorder, nivel),
+			&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+		response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Path:       "/transacoes",
+			Headers:    cabecalhoJSON,
+			Body:       `{"cliente_id":"c1","valor":10.00}`,
+		})
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Status esperado %d, got %d", http.StatusOK, response.StatusCode)
+		}
+
+		// Exclui spans da publicação assíncrona, que roda fora da requisição
+		total := 0
+		for _, span := range recorder.Spans() {
+			if span.OperationName != "TransacaoService.publicarEvento" {
+				total++
+			}
+		}
+		return total
+	}
+
+	if got := contar(tracing.NivelMinimo); got != 1 {
+		t.Errorf("nível minimal deveria emitir apenas o span raiz, got %d", got)
+	}
+
+	if got := contar(tracing.NivelDetalhado); got < 5 {
+		t.Errorf("nível verbose deveria emitir spans por etapa, got %d", got)
+	}
+}
+
+func TestHandleRequest_RotaNaoEncontrada(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		status    int
+		errorCode string
+		allow     string
+	}{
+		{name: "método errado em path conhecido", method: "GET", path: "/transacoes", status: http.StatusMethodNotAllowed, errorCode: "method_not_allowed", allow: "POST"},
+		{name: "POST em /health", method: "POST", path: "/health", status: http.StatusMethodNotAllowed, errorCode: "method_not_allowed", allow: "GET"},
+		{name: "PUT em /transacoes", method: "PUT", path: "/transacoes", status: http.StatusMethodNotAllowed, errorCode: "method_not_allowed", allow: "POST"},
+		{name: "DELETE em /health", method: "DELETE", path: "/health", status: http.StatusMethodNotAllowed, errorCode: "method_not_allowed", allow: "GET"},
+		{name: "path desconhecido", method: "GET", path: "/nao-existe", status: http.StatusNotFound, errorCode: "endpoint_not_found"},
+		{name: "barra final", method: "POST", path: "/transacoes/", status: http.StatusNotFound, errorCode: "endpoint_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(tracing.NewRecordingTracer())
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: tt.method, Path: tt.path})
+			if response.StatusCode != tt.status {
+				t.Fatalf("Status esperado %d, got %d", tt.status, response.StatusCode)
+			}
+
+			var body ErrorResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.Error != tt.errorCode {
+				t.Errorf("erro esperado %s, got %s", tt.errorCode, body.Error)
+			}
+
+			if response.Headers["Allow"] != tt.allow {
+				t.Errorf("Allow esperado %q, got %q", tt.allow, response.Headers["Allow"])
+			}
+		})
+	}
+}
+
+func TestHandleRequest_IndiceRotas(t *testing.T) {
+	tests := []struct {
+		name            string
+		habilitado      bool
+		method          string
+		esperaEndpoints bool
+	}{
+		{name: "desabilitado (padrão)", method: "GET"},
+		{name: "habilitado em GET", habilitado: true, method: "GET", esperaEndpoints: true},
+		{name: "habilitado só vale para GET", habilitado: true, method: "POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), []Option{WithIndiceRotas(tt.habilitado)})
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: tt.method, Path: "/nao-existe"})
+			if response.StatusCode != http.StatusNotFound {
+				t.Fatalf("Status esperado %d, got %d", http.StatusNotFound, response.StatusCode)
+			}
+
+			var body ErrorResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.Error != "endpoint_not_found" || body.CorrelationID == "" {
+				t.Errorf("envelope de erro inesperado: %+v", body)
+			}
+
+			if !tt.esperaEndpoints {
+				if body.Endpoints != nil {
+					t.Errorf("endpoints não deveriam ser listados, got %+v", body.Endpoints)
+				}
+				return
+			}
+			esperado := []EndpointDisponivel{
+				{Path: "/transacoes", Metodos: []string{"POST"}},
+				{Path: "/health", Metodos: []string{"GET"}},
+			}
+			if !reflect.DeepEqual(body.Endpoints, esperado) {
+				t.Errorf("endpoints esperados %+v, got %+v", esperado, body.Endpoints)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_ContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+	}{
+		{name: "ausente", contentType: "", status: http.StatusUnsupportedMediaType},
+		{name: "formulário", contentType: "application/x-www-form-urlencoded", status: http.StatusUnsupportedMediaType},
+		{name: "xml", contentType: "application/xml", status: http.StatusUnsupportedMediaType},
+		{name: "json", contentType: "application/json", status: http.StatusOK},
+		{name: "json com charset", contentType: "Application/JSON; charset=utf-8", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(tracing.NewRecordingTracer(),
+				&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+			headers := map[string]string{}
+			if tt.contentType != "" {
+				headers["content-type"] = tt.contentType
+			}
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    headers,
+				Body:       `{"cliente_id":"c1","valor":10.00}`,
+			})
+
+			if response.StatusCode != tt.status {
+				t.Fatalf("Status esperado %d, got %d (%s)", tt.status, response.StatusCode, response.Body)
+			}
+			if tt.status != http.StatusUnsupportedMediaType {
+				return
+			}
+
+			var body ErrorResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.Error != "unsupported_media_type" {
+				t.Errorf("erro esperado unsupported_media_type, got %s", body.Error)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_CorpoCodificado(t *testing.T) {
+	jsonBody := `{"cliente_id":"c1","valor":10.00}`
+
+	var comprimido bytes.Buffer
+	escritor := gzip.NewWriter(&comprimido)
+	escritor.Write([]byte(jsonBody))
+	escritor.Close()
+
+	tests := []struct {
+		name      string
+		body      string
+		base64    bool
+		encoding  string
+		status    int
+		errorCode string
+	}{
+		{name: "texto puro", body: jsonBody, status: http.StatusOK},
+		{name: "base64", body: base64.StdEncoding.EncodeToString([]byte(jsonBody)), base64: true, status: http.StatusOK},
+		{name: "base64 com gzip", body: base64.StdEncoding.EncodeToString(comprimido.Bytes()), base64: true, encoding: "gzip", status: http.StatusOK},
+		{name: "base64 inválido", body: "não é base64!", base64: true, status: http.StatusBadRequest, errorCode: "invalid_body"},
+		{name: "gzip inválido", body: base64.StdEncoding.EncodeToString([]byte(jsonBody)), base64: true, encoding: "gzip", status: http.StatusBadRequest, errorCode: "invalid_body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(tracing.NewRecordingTracer(),
+				&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+			headers := map[string]string{"Content-Type": "application/json"}
+			if tt.encoding != "" {
+				headers["Content-Encoding"] = tt.encoding
+			}
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:      "POST",
+				Path:            "/transacoes",
+				Headers:         headers,
+				Body:            tt.body,
+				IsBase64Encoded: tt.base64,
+			})
+
+			if response.StatusCode != tt.status {
+				t.Fatalf("Status esperado %d, got %d (%s)", tt.status, response.StatusCode, response.Body)
+			}
+
+			if tt.errorCode != "" {
+				var body ErrorResponse
+				json.Unmarshal([]byte(response.Body), &body)
+				if body.Error != tt.errorCode {
+					t.Errorf("erro esperado %s, got %s", tt.errorCode, body.Error)
+				}
+				return
+			}
+
+			var body TransacaoResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.ClienteID != "c1" || body.Valor != 10.00 {
+				t.Errorf("corpo decodificado incorretamente: %+v", body)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_ErroInternoComErrorID(t *testing.T) {
+	metrics := &fakeMetricsCollector{}
+	logger := &capturingLogger{}
+	tracer := tracing.NewRecordingTracer()
+
+	limites := &falhaLimiteRepository{
+		fakeLimiteRepository: newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000}),
+		err:                  errors.New("dynamodb indisponível"),
+	}
+	transacaoService := service.NewTransacaoService(limites, newFakeTransacaoRepository(), &fakeEventPublisher{}, metrics, tracer, logger)
+	handler := NewLambdaHandler(transacaoService, logger, tracer, metrics)
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":10.00}`,
+	})
+
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Status esperado %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	}
+
+	var body ErrorResponse
+	json.Unmarshal([]byte(response.Body), &body)
+	if body.ErrorID == "" {
+		t.Fatal("error_id deveria estar presente na resposta")
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	encontrado := false
+	for _, registro := range logger.erros {
+		if registro.fields["error_id"] == body.ErrorID {
+			encontrado = true
+			if registro.err == nil {
+				t.Error("log de erro deveria conter a causa")
+			}
+		}
+	}
+	if !encontrado {
+		t.Errorf("error_id %s não encontrado nos logs de erro", body.ErrorID)
+	}
+}
+
+func TestHandlePostTransacoes_ConfiguracaoInvalida(t *testing.T) {
+	metrics := &fakeMetricsCollector{}
+	tracer := tracing.NewRecordingTracer()
+
+	limites := &falhaLimiteRepository{
+		fakeLimiteRepository: newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000}),
+		err:                  fmt.Errorf("erro ao debitar limite do cliente c1: %w", domain.ErrConfiguracaoInvalida),
+	}
+	transacaoService := service.NewTransacaoService(limites, newFakeTransacaoRepository(), &fakeEventPublisher{}, metrics, tracer, &fakeLogger{})
+	handler := NewLambdaHandler(transacaoService, &fakeLogger{}, tracer, metrics)
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":10.00}`,
+	})
+
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Status esperado %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	}
+
+	var body ErrorResponse
+	json.Unmarshal([]byte(response.Body), &body)
+	if body.Error != "configuration_error" {
+		t.Errorf("erro esperado configuration_error, got %s", body.Error)
+	}
+	if body.ErrorID == "" {
+		t.Error("error_id deveria estar presente na resposta")
+	}
+}
+
+func TestHandlePostTransacoes_ClienteComLimiteZero(t *testing.T) {
+	handler := newTestHandler(tracing.NewRecordingTracer(), &domain.Cliente{ID: "c1", LimiteCredit: 0, LimiteAtual: 0})
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    cabecalhoJSON,
+		Body:       `{"cliente_id":"c1","valor":0.01}`,
+	})
+
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Status esperado %d, got %d", http.StatusUnprocessableEntity, response.StatusCode)
+	}
+
+	var body ErrorResponse
+	json.Unmarshal([]byte(response.Body), &body)
+	if body.Error != "insufficient_limit" {
+		t.Errorf("erro esperado insufficient_limit, got %s", body.Error)
+	}
+}
+
+func TestHandlePostTransacoes_Agendamento(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           time.Time
+		expectedStatus int
+	}{
+		{name: "data futura", data: time.Now().Add(24 * time.Hour), expectedStatus: http.StatusAccepted},
+		{name: "data no passado", data: time.Now().Add(-time.Hour), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(tracing.NewRecordingTracer(), &domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       fmt.Sprintf(`{"cliente_id":"c1","valor":50.00,"data_agendamento":%q}`, tt.data.Format(time.RFC3339)),
+			})
+
+			if response.StatusCode != tt.expectedStatus {
+				t.Fatalf("Status esperado %d, got %d (%s)", tt.expectedStatus, response.StatusCode, response.Body)
+			}
+
+			if tt.expectedStatus != http.StatusAccepted {
+				return
+			}
+
+			var body TransacaoResponse
+			json.Unmarshal([]byte(response.Body), &body)
+			if body.Status != domain.StatusAgendada || body.DataAgendamento == nil {
+				t.Errorf("resposta deveria indicar transação agendada, got %+v", body)
+			}
+		})
+	}
+}
+
+func TestHandlePostTransacoes_ConflitoDeIdempotencia(t *testing.T) {
+	servico := &servicoRecusa{resultado: &service.Resultado{
+		Status:       domain.StatusRejeitada,
+		CodigoMotivo: "idempotency_key_conflict",
+		Motivo:       domain.ErrConflitoIdempotencia,
+	}}
+	handler := NewLambdaHandler(servico, &fakeLogger{}, tracing.NewRecordingTracer(), &fakeMetricsCollector{})
+
+	response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Path:       "/transacoes",
+		Headers:    map[string]string{"Content-Type": "application/json", "Idempotency-Key": "k-1"},
+		Body:       `{"cliente_id":"c1","valor":99.90}`,
+	})
+	if response.StatusCode != http.StatusConflict {
+		t.Fatalf("Status esperado %d, got %d", http.StatusConflict, response.StatusCode)
+	}
+
+	var body ErrorResponse
+	json.Unmarshal([]byte(response.Body), &body)
+	if body.Error != "idempotency_key_conflict" {
+		t.Errorf("código esperado idempotency_key_conflict, got %s", body.Error)
+	}
+}
+
+func TestHandlePostTransacoes_LimiteRestanteNaResposta(t *testing.T) {
+	agendamento := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		corpo    string
+		status   int
+		restante string
+	}{
+		{name: "desligado por padrão", corpo: `{"cliente_id":"c1","valor":25.50}`, status: http.StatusOK},
+		{name: "independente do saldo", opts: []Option{WithSaldoNaResposta(true)}, corpo: `{"cliente_id":"c1","valor":25.50}`, status: http.StatusOK},
+		{name: "habilitado", opts: []Option{WithLimiteRestanteNaResposta(true)}, corpo: `{"cliente_id":"c1","valor":25.50}`, status: http.StatusOK, restante: "74.50"},
+		{name: "agendada sem débito omite o limite restante", opts: []Option{WithLimiteRestanteNaResposta(true)},
+			corpo: fmt.Sprintf(`{"cliente_id":"c1","valor":25.50,"data_agendamento":%q}`, agendamento), status: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandlerComOpcoes(tracing.NewRecordingTracer(), tt.opts,
+				&domain.Cliente{ID: "c1", LimiteCredit: 10000, LimiteAtual: 10000})
+
+			response, _ := handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod: "POST",
+				Path:       "/transacoes",
+				Headers:    cabecalhoJSON,
+				Body:       tt.corpo,
+			})
+			if response.StatusCode != tt.status {
+				t.Fatalf("Status esperado %d, got %d: %s", tt.status, response.StatusCode, response.Body)
+			}
+
+			var body map[string]json.RawMessage
+			json.Unmarshal([]byte(response.Body), &body)
+			restante, presente := body["limite_restante"]
+			if tt.restante == "" {
+				if presente {
+					t.Errorf("limite_restante não esperado, got %s", restante)
+				}
+				return
+			}
+			if string(restante) != tt.restante {
+				t.Errorf("limite_restante esperado %s, got %s", tt.restante, restante)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package awslambda
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/core/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// MensagemTransacao é o corpo de uma mensagem de fila (SQS, Kafka): o mesmo
+// payload do POST /transacoes, com o correlation ID opcional do produtor
+type MensagemTransacao struct {
+	TransacaoRequest
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// prefixoChaveMensagem separa as chaves derivadas de mensagens das
+// informadas no header Idempotency-Key
+const prefixoChaveMensagem = "mensagem:"
+
+// ProcessarMensagem autoriza (ou agenda) a transação de uma mensagem de fila
+// com as mesmas regras do endpoint HTTP. Retorna erro apenas quando a
+// mensagem deve ser reprocessada (falha de infraestrutura, throttling);
+// mensagens malformadas e recusas de negócio são registradas e consumidas.
+// O ID da mensagem, estável entre reentregas, é a chave de idempotência: a
+// reentrega de uma mensagem já autorizada devolve o resultado gravado em vez
+// de debitar de novo.
+func (h *LambdaHandler) ProcessarMensagem(ctx context.Context, mensagemID string, corpo []byte) error {
+	ctx, span := h.tracer.StartSpan(ctx, "handler.processar_mensagem")
+	defer h.tracer.FinishSpan(span, nil)
+
+	var mensagem MensagemTransacao
+	if err := json.Unmarshal(corpo, &mensagem); err != nil {
+		h.logger.Warn(ctx, "mensagem com JSON inválido descartada", map[string]interface{}{
+			"error": err.Error(),
+		})
+		h.metricsCollector.IncrementErrorCounter("json_parse_error")
+		return nil
+	}
+
+	correlationID := mensagem.CorrelationID
+	if correlationID == "" || !h.padraoCorrelacao.MatchString(correlationID) {
+		if correlationID != "" {
+			h.metricsCollector.IncrementErrorCounter("invalid_correlation_id")
+		}
+		correlationID = uuid.New().String()
+	}
+	ctx = context.WithValue(ctx, "correlation_id", correlationID)
+
+	h.tracer.AddTag(span, "cliente_id", mensagem.ClienteID)
+
+	valor, err := h.valorRequisicao(&mensagem.TransacaoRequest)
+	if err != nil {
+		return h.tratarErroMensagem(ctx, "", err)
+	}
+
+	transacao := h.novaTransacao(&mensagem.TransacaoRequest, valor, correlationID)
+	if mensagemID != "" {
+		transacao.ChaveIdempotencia = prefixoChaveMensagem + mensagemID
+	}
+
+	var resultado *service.Resultado
+	if transacao.DataAgendamento != nil {
+		err = h.transacaoService.AgendarTransacao(ctx, transacao)
+	} else if resultado, err = h.transacaoService.AutorizarTransacao(ctx, transacao); err == nil && !resultado.Aprovada() {
+		err = resultado.Motivo
+	}
+	if err != nil {
+		return h.tratarErroMensagem(ctx, transacao.ID, err)
+	}
+
+	h.logger.Info(ctx, "mensagem processada", map[string]interface{}{
+		"transacao_id": transacao.ID,
+		"status":       transacao.Status,
+	})
+	return nil
+}
+
+// tratarErroMensagem devolve o erro apenas quando a fila deve reentregar a
+// mensagem: os mesmos casos em que o endpoint HTTP responde 429 ou 5xx, e a
+// reentrega que encontra a mesma mensagem ainda em processamento (que não
+// pode ser descartada antes de o primeiro processamento concluir)
+func (h *LambdaHandler) tratarErroMensagem(ctx context.Context, transacaoID string, err error) error {
+	statusCode, errorCode, _ := h.categorizeError(err)
+	campos := map[string]interface{}{
+		"transacao_id": transacaoID,
+		"error":        err.Error(),
+		"error_code":   errorCode,
+	}
+
+	if statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError ||
+		errors.Is(err, domain.ErrIdempotenciaEmProcessamento) {
+		h.logger.Error(ctx, "falha ao processar mensagem, será reprocessada", err, campos)
+		return err
+	}
+
+	h.logger.Warn(ctx, "transação da mensagem rejeitada", campos)
+	return nil
+}
@@ -0,0 +1,122 @@
+package awslambda
+
+import (
+	"authorizer/internal/core/domain"
+	"authorizer/internal/core/service"
+	"authorizer/internal/observability/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestProcessarMensagem(t *testing.T) {
+	tests := []struct {
+		name         string
+		corpo        string
+		errDebito    error
+		esperaErro   bool
+		esperaDebito bool
+	}{
+		{name: "aprovada", corpo: `{"cliente_id":"c1","valor_centavos":1000}`, esperaDebito: true},
+		{name: "recusa de negócio é consumida", corpo: `{"cliente_id":"c1","valor":5000.00}`},
+		{name: "payload inválido é consumido", corpo: `{"cliente_id":"c1","valor":-1}`},
+		{name: "JSON malformado é consumido", corpo: `{"cliente_id":`},
+		{name: "throttling é reprocessado", corpo: `{"cliente_id":"c1","valor":10.00}`, errDebito: fmt.Errorf("erro ao debitar: %w", domain.ErrThrottled), esperaErro: true},
+		{name: "falha de infraestrutura é reprocessada", corpo: `{"cliente_id":"c1","valor":10.00}`, errDebito: errors.New("dynamodb indisponível"), esperaErro: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limites domain.LimiteRepository = newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+			if tt.errDebito != nil {
+				limites = &falhaLimiteRepository{fakeLimiteRepository: limites.(*fakeLimiteRepository), err: tt.errDebito}
+			}
+			transacoes := newFakeTransacaoRepository()
+			transacaoService := service.NewTransacaoService(limites, transacoes, &fakeEventPublisher{}, &fakeMetricsCollector{}, tracing.NewRecordingTracer(), &fakeLogger{})
+			handler := NewLambdaHandler(transacaoService, &fakeLogger{}, tracing.NewRecordingTracer(), &fakeMetricsCollector{})
+
+			err := handler.ProcessarMensagem(context.Background(), "m1", []byte(tt.corpo))
+			if (err != nil) != tt.esperaErro {
+				t.Fatalf("erro esperado %v, got %v", tt.esperaErro, err)
+			}
+
+			cliente, _ := limites.GetCliente(context.Background(), "c1")
+			if debitado := cliente.LimiteAtual != 100000; debitado != tt.esperaDebito {
+				t.Errorf("débito esperado %v, limite atual %d", tt.esperaDebito, cliente.LimiteAtual)
+			}
+		})
+	}
+}
+
+func TestProcessarMensagem_CorrelationIDDoProdutor(t *testing.T) {
+	transacoes := newFakeTransacaoRepository()
+	transacaoService := service.NewTransacaoService(
+		newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000}),
+		transacoes, &fakeEventPublisher{}, &fakeMetricsCollector{}, tracing.NewRecordingTracer(), &fakeLogger{},
+	)
+	handler := NewLambdaHandler(transacaoService, &fakeLogger{}, tracing.NewRecordingTracer(), &fakeMetricsCollector{})
+
+	corpo := `{"cliente_id":"c1","valor":10.00,"correlation_id":"pedido-123"}`
+	if err := handler.ProcessarMensagem(context.Background(), "m1", []byte(corpo)); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	salvas, _ := transacoes.GetByClienteID(context.Background(), "c1", 10)
+	if len(salvas) != 1 || salvas[0].CorrelationID != "pedido-123" {
+		t.Errorf("transação deveria usar o correlation ID da mensagem, got %+v", salvas)
+	}
+}
+
+// memoriaIdempotencia é um IdempotencyStore em memória, sem expiração
+type memoriaIdempotencia struct {
+	registros map[string]domain.RegistroIdempotencia
+}
+
+func (m *memoriaIdempotencia) Get(ctx context.Context, chave string) (*domain.RegistroIdempotencia, error) {
+	registro, ok := m.registros[chave]
+	if !ok {
+		return nil, nil
+	}
+	return &registro, nil
+}
+
+func (m *memoriaIdempotencia) Reservar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	if _, ok := m.registros[registro.Chave]; ok {
+		return domain.ErrReservaIdempotencia
+	}
+	m.registros[registro.Chave] = *registro
+	return nil
+}
+
+func (m *memoriaIdempotencia) Finalizar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	m.registros[registro.Chave] = *registro
+	return nil
+}
+
+func (m *memoriaIdempotencia) Liberar(ctx context.Context, chave, transacaoID string) error {
+	delete(m.registros, chave)
+	return nil
+}
+
+func TestProcessarMensagem_ReentregaNaoDebitaDeNovo(t *testing.T) {
+	limites := newFakeLimiteRepository(&domain.Cliente{ID: "c1", LimiteCredit: 100000, LimiteAtual: 100000})
+	transacaoService := service.NewTransacaoService(
+		limites, newFakeTransacaoRepository(), &fakeEventPublisher{}, &fakeMetricsCollector{}, tracing.NewRecordingTracer(), &fakeLogger{},
+		service.WithIdempotencia(&memoriaIdempotencia{registros: map[string]domain.RegistroIdempotencia{}}, time.Hour),
+	)
+	handler := NewLambdaHandler(transacaoService, &fakeLogger{}, tracing.NewRecordingTracer(), &fakeMetricsCollector{})
+
+	corpo := []byte(`{"cliente_id":"c1","valor_centavos":1000}`)
+	for _, mensagemID := range []string{"m1", "m1", "m2"} {
+		if err := handler.ProcessarMensagem(context.Background(), mensagemID, corpo); err != nil {
+			t.Fatalf("erro inesperado na mensagem %s: %v", mensagemID, err)
+		}
+	}
+
+	cliente, _ := limites.GetCliente(context.Background(), "c1")
+	if cliente.LimiteAtual != 98000 {
+		t.Errorf("esperados dois débitos (m1 reentregue uma vez, m2), limite atual %d", cliente.LimiteAtual)
+	}
+}
@@ -0,0 +1,147 @@
+package awslambda
+
+import (
+	"authorizer/internal/core/domain"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Option configura comportamentos opcionais do LambdaHandler
+type Option func(*LambdaHandler)
+
+// margemTimeoutPadrao é descontada do tempo restante do Lambda no header
+// X-Timeout-Budget-Ms, cobrindo a serialização e o retorno da resposta
+const margemTimeoutPadrao = 100 * time.Millisecond
+
+// WithMargemTimeout define a margem de segurança descontada do tempo
+// restante do Lambda ao calcular o header X-Timeout-Budget-Ms
+func WithMargemTimeout(margem time.Duration) Option {
+	return func(h *LambdaHandler) {
+		h.margemTimeout = margem
+	}
+}
+
+// retryAfterThrottlingPadrao é o valor do header Retry-After nas respostas
+// 429 por throttling da persistência
+const retryAfterThrottlingPadrao = time.Second
+
+// WithRetryAfterThrottling define o intervalo sugerido no header Retry-After
+// (arredondado para cima, em segundos) quando a persistência responde com throttling
+func WithRetryAfterThrottling(intervalo time.Duration) Option {
+	return func(h *LambdaHandler) {
+		h.retryAfterThrottling = intervalo
+	}
+}
+
+// WithStatusClienteNaoEncontrado define o status de ErrClienteNaoEncontrado:
+// 404 (padrão, recurso inexistente) ou 422 (recusa de negócio). O código
+// client_not_found não muda; outros status são ignorados.
+func WithStatusClienteNaoEncontrado(status int) Option {
+	return func(h *LambdaHandler) {
+		if status == http.StatusNotFound || status == http.StatusUnprocessableEntity {
+			h.statusClienteNaoEncontrado = status
+		}
+	}
+}
+
+// WithLimiteGlobal descarta carga acima de porSegundo requisições por segundo
+// (com rajadas de até rajada), respondendo 429 service_overloaded com
+// Retry-After. O limite vale por instância do Lambda; porSegundo ou rajada
+// não positivos mantêm o limite desligado (padrão).
+func WithLimiteGlobal(porSegundo float64, rajada int) Option {
+	return func(h *LambdaHandler) {
+		if porSegundo > 0 && rajada > 0 {
+			h.limitador = newLimitadorGlobal(porSegundo, rajada)
+		}
+	}
+}
+
+// WithCabecalhosDepuracao aceita cabeçalhos X-Debug-* que desligam regras
+// numa requisição (X-Debug-Disable-Velocity, X-Debug-Disable-Duplicate-Check).
+// Destinado a QA: nunca habilitar em produção.
+func WithCabecalhosDepuracao(habilitado bool) Option {
+	return func(h *LambdaHandler) {
+		h.cabecalhosDepuracao = habilitado
+	}
+}
+
+// WithIndiceRotas faz o 404 de GET em path desconhecido listar os endpoints
+// disponíveis (métodos por path), para descoberta fora de produção. O corpo
+// continua sendo o envelope de erro endpoint_not_found.
+func WithIndiceRotas(habilitado bool) Option {
+	return func(h *LambdaHandler) {
+		h.indiceRotas = habilitado
+	}
+}
+
+// WithSaldoNaResposta inclui saldo_disponivel (limite após o débito, em
+// reais) na resposta de transações aprovadas. Desligado por padrão: o saldo
+// é dado sensível do cliente.
+func WithSaldoNaResposta(habilitado bool) Option {
+	return func(h *LambdaHandler) {
+		h.saldoNaResposta = habilitado
+	}
+}
+
+// WithLimiteRestanteNaResposta inclui limite_restante (saldo após o débito
+// mais o cheque especial do tier, em reais) na resposta de transações
+// aprovadas. Desligado por padrão, como o saldo.
+func WithLimiteRestanteNaResposta(habilitado bool) Option {
+	return func(h *LambdaHandler) {
+		h.limiteRestanteNaResposta = habilitado
+	}
+}
+
+// padraoCorrelationIDPadrao aceita UUIDs e identificadores alfanuméricos
+// (com ".", "_" e "-") de até 128 caracteres
+var padraoCorrelationIDPadrao = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// WithPadraoCorrelationID substitui o padrão aceito para X-Correlation-ID;
+// valores fora dele são trocados por um UUID novo
+func WithPadraoCorrelationID(padrao *regexp.Regexp) Option {
+	return func(h *LambdaHandler) {
+		h.padraoCorrelacao = padrao
+	}
+}
+
+// WithServerTiming habilita o header Server-Timing com a duração das etapas
+// da autorização (validation, debit, save, publish). Destinado a depuração.
+func WithServerTiming(habilitado bool) Option {
+	return func(h *LambdaHandler) {
+		h.serverTiming = habilitado
+	}
+}
+
+// WithGeradorID substitui o gerador de IDs de transação (padrão UUIDv4);
+// domain.GeradorULID produz IDs ordenáveis pelo horário de criação
+func WithGeradorID(gerador domain.IDGenerator) Option {
+	return func(h *LambdaHandler) {
+		h.geradorID = gerador
+	}
+}
+
+// ModoPrecisao define como valores com mais de duas casas decimais são tratados
+type ModoPrecisao string
+
+const (
+	// PrecisaoLeniente arredonda o valor para centavos
+	PrecisaoLeniente ModoPrecisao = "lenient"
+	// PrecisaoEstrita rejeita o valor com ErrPrecisaoInvalida (400)
+	PrecisaoEstrita ModoPrecisao = "strict"
+)
+
+// WithModoPrecisao define o tratamento de valores com precisão excessiva
+func WithModoPrecisao(modo ModoPrecisao) Option {
+	return func(h *LambdaHandler) {
+		h.modoPrecisao = modo
+	}
+}
+
+// WithModoArredondamento define como o modo leniente arredonda frações de
+// centavo (padrão: HALF_UP)
+func WithModoArredondamento(modo domain.ModoArredondamento) Option {
+	return func(h *LambdaHandler) {
+		h.modoArredondamento = modo
+	}
+}
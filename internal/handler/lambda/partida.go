@@ -0,0 +1,33 @@
+package awslambda
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// partidaAFrio é verdadeiro até a primeira invocação do processo: no Lambda,
+// cada ambiente de execução novo (cold start) recomeça com o valor inicial
+var partidaAFrio atomic.Bool
+
+func init() {
+	partidaAFrio.Store(true)
+}
+
+// consumirPartidaAFrio indica se esta é a primeira invocação do processo;
+// apenas uma requisição recebe true, mesmo com invocações concorrentes
+func consumirPartidaAFrio() bool {
+	return partidaAFrio.CompareAndSwap(true, false)
+}
+
+// registrarPartidaAFrio marca o span da primeira invocação e incrementa a
+// métrica cold_start_total, separando a latência de inicialização da de
+// regime nos dashboards
+func (h *LambdaHandler) registrarPartidaAFrio(ctx context.Context, span interface{}) bool {
+	if !consumirPartidaAFrio() {
+		return false
+	}
+
+	h.tracer.AddTag(span, "cold_start", true)
+	h.metricsCollector.IncrementErrorCounter("cold_start_total")
+	return true
+}
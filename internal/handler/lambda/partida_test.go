@@ -0,0 +1,86 @@
+package awslambda
+
+import (
+	"authorizer/internal/observability/tracing"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// contadorMetricas conta as chamadas de IncrementErrorCounter por tipo
+type contadorMetricas struct {
+	fakeMetricsCollector
+	mu        sync.Mutex
+	contagens map[string]int
+}
+
+func (m *contadorMetricas) IncrementErrorCounter(errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.contagens == nil {
+		m.contagens = make(map[string]int)
+	}
+	m.contagens[errorType]++
+}
+
+// registroInfo guarda os campos de cada Info pela mensagem
+type registroInfo struct {
+	fakeLogger
+	mu     sync.Mutex
+	campos map[string][]map[string]interface{}
+}
+
+func (l *registroInfo) Info(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.campos == nil {
+		l.campos = make(map[string][]map[string]interface{})
+	}
+	l.campos[msg] = append(l.campos[msg], fields)
+}
+
+func TestHandleRequest_PartidaAFrioApenasNaPrimeiraInvocacao(t *testing.T) {
+	partidaAFrio.Store(true)
+	t.Cleanup(func() { partidaAFrio.Store(false) })
+
+	tracer := tracing.NewRecordingTracer()
+	handler := newTestHandler(tracer)
+	metrics := &contadorMetricas{}
+	logger := &registroInfo{}
+	handler.metricsCollector = metrics
+	handler.logger = logger
+
+	for i := 0; i < 2; i++ {
+		handler.HandleRequest(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/health"})
+	}
+
+	if partidaAFrio.Load() {
+		t.Error("flag de partida a frio deveria ser desligada após a primeira invocação")
+	}
+	if metrics.contagens["cold_start_total"] != 1 {
+		t.Errorf("métrica cold_start_total esperada 1, got %d", metrics.contagens["cold_start_total"])
+	}
+
+	respostas := logger.campos["resposta enviada"]
+	if len(respostas) != 2 {
+		t.Fatalf("esperados 2 logs de resposta, got %d", len(respostas))
+	}
+	if respostas[0]["cold_start"] != true {
+		t.Errorf("primeira resposta deveria ter cold_start=true, got %v", respostas[0])
+	}
+	if _, ok := respostas[1]["cold_start"]; ok {
+		t.Errorf("segunda resposta não deveria ter cold_start, got %v", respostas[1])
+	}
+
+	var raizes []interface{}
+	for _, span := range tracer.Spans() {
+		if span.OperationName == "lambda.handle_request" {
+			raizes = append(raizes, span.Tags["cold_start"])
+		}
+	}
+	if len(raizes) != 2 || raizes[0] != true || raizes[1] != nil {
+		t.Errorf("apenas o primeiro span deveria ter cold_start, got %v", raizes)
+	}
+}
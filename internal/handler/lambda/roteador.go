@@ -0,0 +1,110 @@
+package awslambda
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// manipulador trata uma rota; params traz os segmentos {nome} do path
+type manipulador func(ctx context.Context, request events.APIGatewayProxyRequest, params map[string]string) (events.APIGatewayProxyResponse, error)
+
+// rota associa método e padrão de path (ex.: /clientes/{id}) a um manipulador
+type rota struct {
+	metodo      string
+	segmentos   []string
+	manipulador manipulador
+}
+
+// roteador resolve a rota de uma requisição. A comparação é exata por
+// segmento: barra final ou segmento vazio não casam com o padrão.
+type roteador struct {
+	rotas []rota
+}
+
+// registrar adiciona uma rota; a ordem de registro define a ordem do header
+// Allow nas respostas 405
+func (r *roteador) registrar(metodo, padrao string, m manipulador) {
+	r.rotas = append(r.rotas, rota{
+		metodo:      metodo,
+		segmentos:   strings.Split(padrao, "/"),
+		manipulador: m,
+	})
+}
+
+// resolver devolve o manipulador e os parâmetros do path. Sem manipulador,
+// permitidos lista os métodos registrados para o path (vazio = path
+// desconhecido, 404; caso contrário, 405).
+func (r *roteador) resolver(metodo, path string) (m manipulador, params map[string]string, permitidos []string) {
+	segmentos := strings.Split(path, "/")
+
+	for _, rota := range r.rotas {
+		valores, ok := casarSegmentos(rota.segmentos, segmentos)
+		if !ok {
+			continue
+		}
+		if rota.metodo == metodo {
+			return rota.manipulador, valores, nil
+		}
+		permitidos = append(permitidos, rota.metodo)
+	}
+
+	return nil, nil, permitidos
+}
+
+// EndpointDisponivel descreve uma rota registrada no índice de rotas
+type EndpointDisponivel struct {
+	Path    string   `json:"path"`
+	Metodos []string `json:"metodos"`
+}
+
+// endpoints lista os paths registrados com seus métodos, na ordem de registro
+func (r *roteador) endpoints() []EndpointDisponivel {
+	var lista []EndpointDisponivel
+	indice := make(map[string]int)
+
+	for _, rota := range r.rotas {
+		path := strings.Join(rota.segmentos, "/")
+		i, ok := indice[path]
+		if !ok {
+			i = len(lista)
+			indice[path] = i
+			lista = append(lista, EndpointDisponivel{Path: path})
+		}
+		lista[i].Metodos = append(lista[i].Metodos, rota.metodo)
+	}
+
+	return lista
+}
+
+// casarSegmentos compara o path com o padrão, extraindo os parâmetros {nome}
+func casarSegmentos(padrao, path []string) (map[string]string, bool) {
+	if len(padrao) != len(path) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segmento := range padrao {
+		if nome, ok := parametroDoSegmento(segmento); ok {
+			if path[i] == "" {
+				return nil, false
+			}
+			params[nome] = path[i]
+			continue
+		}
+		if segmento != path[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// parametroDoSegmento reconhece segmentos de parâmetro no formato {nome}
+func parametroDoSegmento(segmento string) (string, bool) {
+	if len(segmento) > 2 && strings.HasPrefix(segmento, "{") && strings.HasSuffix(segmento, "}") {
+		return segmento[1 : len(segmento)-1], true
+	}
+	return "", false
+}
@@ -0,0 +1,86 @@
+package awslambda
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// manipuladorNomeado devolve o nome da rota no corpo, para identificar o casamento
+func manipuladorNomeado(nome string) manipulador {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest, params map[string]string) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{Body: nome}, nil
+	}
+}
+
+func TestRoteador_Resolver(t *testing.T) {
+	rotas := &roteador{}
+	rotas.registrar("POST", "/transacoes", manipuladorNomeado("criar"))
+	rotas.registrar("GET", "/transacoes/{id}", manipuladorNomeado("consultar"))
+	rotas.registrar("DELETE", "/transacoes/{id}", manipuladorNomeado("cancelar"))
+	rotas.registrar("GET", "/clientes/{cliente_id}/transacoes/{id}", manipuladorNomeado("consultar_do_cliente"))
+
+	tests := []struct {
+		name       string
+		metodo     string
+		path       string
+		rota       string
+		params     map[string]string
+		permitidos []string
+	}{
+		{name: "path estático", metodo: "POST", path: "/transacoes", rota: "criar", params: map[string]string{}},
+		{name: "parâmetro no path", metodo: "GET", path: "/transacoes/t-1", rota: "consultar", params: map[string]string{"id": "t-1"}},
+		{name: "vários parâmetros", metodo: "GET", path: "/clientes/c1/transacoes/t-1", rota: "consultar_do_cliente", params: map[string]string{"cliente_id": "c1", "id": "t-1"}},
+		{name: "método não registrado no path", metodo: "PUT", path: "/transacoes/t-1", permitidos: []string{"GET", "DELETE"}},
+		{name: "path desconhecido", metodo: "GET", path: "/nao-existe"},
+		{name: "barra final não casa", metodo: "POST", path: "/transacoes/"},
+		{name: "barra final após parâmetro não casa", metodo: "GET", path: "/transacoes/t-1/"},
+		{name: "parâmetro vazio não casa", metodo: "GET", path: "/clientes//transacoes/t-1"},
+		{name: "segmento a mais não casa", metodo: "GET", path: "/transacoes/t-1/itens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, params, permitidos := rotas.resolver(tt.metodo, tt.path)
+
+			if tt.rota == "" {
+				if m != nil {
+					t.Fatalf("nenhuma rota deveria casar com %s %s", tt.metodo, tt.path)
+				}
+				if strings.Join(permitidos, ",") != strings.Join(tt.permitidos, ",") {
+					t.Errorf("métodos permitidos esperados %v, got %v", tt.permitidos, permitidos)
+				}
+				return
+			}
+
+			if m == nil {
+				t.Fatalf("rota %s deveria casar com %s %s", tt.rota, tt.metodo, tt.path)
+			}
+			response, _ := m(context.Background(), events.APIGatewayProxyRequest{}, params)
+			if response.Body != tt.rota {
+				t.Errorf("rota esperada %s, got %s", tt.rota, response.Body)
+			}
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("parâmetros esperados %v, got %v", tt.params, params)
+			}
+		})
+	}
+}
+
+func TestRoteador_Endpoints(t *testing.T) {
+	rotas := &roteador{}
+	rotas.registrar("POST", "/transacoes", manipuladorNomeado("criar"))
+	rotas.registrar("GET", "/transacoes/{id}", manipuladorNomeado("consultar"))
+	rotas.registrar("DELETE", "/transacoes/{id}", manipuladorNomeado("cancelar"))
+
+	esperado := []EndpointDisponivel{
+		{Path: "/transacoes", Metodos: []string{"POST"}},
+		{Path: "/transacoes/{id}", Metodos: []string{"GET", "DELETE"}},
+	}
+	if got := rotas.endpoints(); !reflect.DeepEqual(got, esperado) {
+		t.Errorf("endpoints esperados %+v, got %+v", esperado, got)
+	}
+}
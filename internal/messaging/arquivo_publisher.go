@@ -0,0 +1,130 @@
+package messaging
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	_ domain.EventPublisher       = (*ArquivoEventPublisher)(nil)
+	_ domain.LimiteEventPublisher = (*ArquivoEventPublisher)(nil)
+)
+
+// ArquivoEventPublisher grava cada evento como uma linha JSON (JSONL) num
+// arquivo, para acompanhar (tail -f) e reprocessar os eventos no
+// desenvolvimento local. O tipo vem no campo "evento" de cada linha. As
+// gravações são serializadas: linhas de eventos concorrentes não se misturam.
+type ArquivoEventPublisher struct {
+	mu            sync.Mutex
+	caminho       string
+	arquivo       *os.File
+	tamanho       int64
+	tamanhoMaximo int64
+}
+
+// Option configura comportamentos opcionais do ArquivoEventPublisher
+type Option func(*ArquivoEventPublisher)
+
+// WithTamanhoMaximo rotaciona o arquivo ao atingir bytes: o atual passa a
+// <caminho>.1 (substituindo a rotação anterior) e um novo é iniciado. Zero
+// desliga a rotação (padrão).
+func WithTamanhoMaximo(bytes int64) Option {
+	return func(p *ArquivoEventPublisher) {
+		p.tamanhoMaximo = bytes
+	}
+}
+
+// NewArquivoEventPublisher abre (ou cria) o arquivo em modo de inclusão;
+// eventos de execuções anteriores são preservados
+func NewArquivoEventPublisher(caminho string, opts ...Option) (*ArquivoEventPublisher, error) {
+	p := &ArquivoEventPublisher{caminho: caminho}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.abrir(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *ArquivoEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return p.gravar(evento)
+}
+
+func (p *ArquivoEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return p.gravar(evento)
+}
+
+func (p *ArquivoEventPublisher) PublishLimiteAlterado(ctx context.Context, evento *domain.LimiteAlteradoEvento) error {
+	return p.gravar(evento)
+}
+
+// Close fecha o arquivo; publicações posteriores falham
+func (p *ArquivoEventPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.arquivo.Close()
+}
+
+// gravar serializa o evento e o inclui como uma linha, numa única escrita
+func (p *ArquivoEventPublisher) gravar(evento interface{}) error {
+	linha, err := json.Marshal(evento)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+	linha = append(linha, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tamanhoMaximo > 0 && p.tamanho > 0 && p.tamanho+int64(len(linha)) > p.tamanhoMaximo {
+		if err := p.rotacionar(); err != nil {
+			return err
+		}
+	}
+
+	n, err := p.arquivo.Write(linha)
+	p.tamanho += int64(n)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar evento em %s: %w", p.caminho, err)
+	}
+	return nil
+}
+
+func (p *ArquivoEventPublisher) abrir() error {
+	arquivo, err := os.OpenFile(p.caminho, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de eventos %s: %w", p.caminho, err)
+	}
+
+	info, err := arquivo.Stat()
+	if err != nil {
+		arquivo.Close()
+		return fmt.Errorf("erro ao abrir arquivo de eventos %s: %w", p.caminho, err)
+	}
+
+	p.arquivo = arquivo
+	p.tamanho = info.Size()
+	return nil
+}
+
+// rotacionar move o arquivo atual para <caminho>.1 e abre um novo. Se a
+// renomeação falhar, o arquivo atual é reaberto e continua em uso: a
+// publicação seguinte tenta rotacionar de novo.
+func (p *ArquivoEventPublisher) rotacionar() error {
+	if err := p.arquivo.Close(); err != nil {
+		return fmt.Errorf("erro ao rotacionar arquivo de eventos %s: %w", p.caminho, err)
+	}
+	if err := os.Rename(p.caminho, p.caminho+".1"); err != nil {
+		if errAbrir := p.abrir(); errAbrir != nil {
+			return fmt.Errorf("erro ao rotacionar arquivo de eventos %s: %w (%v)", p.caminho, err, errAbrir)
+		}
+		return fmt.Errorf("erro ao rotacionar arquivo de eventos %s: %w", p.caminho, err)
+	}
+	return p.abrir()
+}
@@ -0,0 +1,162 @@
+package messaging
+
+import (
+	"authorizer/internal/core/domain"
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// linhasJSON lê o arquivo e decodifica cada linha como um evento
+func linhasJSON(t *testing.T, caminho string) []map[string]interface{} {
+	t.Helper()
+
+	arquivo, err := os.Open(caminho)
+	if err != nil {
+		t.Fatalf("erro ao abrir %s: %v", caminho, err)
+	}
+	defer arquivo.Close()
+
+	var linhas []map[string]interface{}
+	scanner := bufio.NewScanner(arquivo)
+	for scanner.Scan() {
+		var linha map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &linha); err != nil {
+			t.Fatalf("linha não é JSON válido (%q): %v", scanner.Text(), err)
+		}
+		linhas = append(linhas, linha)
+	}
+	return linhas
+}
+
+func TestArquivoEventPublisher_UmaLinhaPorEvento(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "eventos.jsonl")
+	publisher, err := NewArquivoEventPublisher(caminho)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	defer publisher.Close()
+
+	aprovada := domain.NewTransacao("c1", 10.00, "corr-1")
+	aprovada.Status = domain.StatusAprovada
+	rejeitada := domain.NewTransacao("c1", 5000.00, "corr-2")
+	rejeitada.Status = domain.StatusRejeitada
+
+	if err := publisher.PublishTransacaoAprovada(context.Background(), aprovada.ToEvento()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if err := publisher.PublishTransacaoRejeitada(context.Background(), rejeitada.ToEvento()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	linhas := linhasJSON(t, caminho)
+	if len(linhas) != 2 {
+		t.Fatalf("esperadas 2 linhas, got %d", len(linhas))
+	}
+	if linhas[0]["evento"] != domain.EventoTransacaoAprovada || linhas[0]["transacao_id"] != aprovada.ID {
+		t.Errorf("primeira linha inesperada: %v", linhas[0])
+	}
+	if linhas[1]["evento"] != domain.EventoTransacaoRejeitada || linhas[1]["correlation_id"] != "corr-2" {
+		t.Errorf("segunda linha inesperada: %v", linhas[1])
+	}
+}
+
+func TestArquivoEventPublisher_GravacoesConcorrentes(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "eventos.jsonl")
+	publisher, err := NewArquivoEventPublisher(caminho)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	defer publisher.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			publisher.PublishTransacaoAprovada(context.Background(), domain.NewTransacao("c1", 10.00, "corr").ToEvento())
+		}()
+	}
+	wg.Wait()
+
+	if got := len(linhasJSON(t, caminho)); got != 50 {
+		t.Errorf("esperadas 50 linhas íntegras, got %d", got)
+	}
+}
+
+func TestArquivoEventPublisher_Rotacao(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "eventos.jsonl")
+	evento := domain.NewTransacao("c1", 10.00, "corr").ToEvento()
+	linha, _ := json.Marshal(evento)
+
+	// Cabem duas linhas por arquivo
+	publisher, err := NewArquivoEventPublisher(caminho, WithTamanhoMaximo(int64(2*(len(linha)+1))))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	defer publisher.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := publisher.PublishTransacaoAprovada(context.Background(), evento); err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	}
+
+	if got := len(linhasJSON(t, caminho+".1")); got != 2 {
+		t.Errorf("arquivo rotacionado deveria ter 2 linhas, got %d", got)
+	}
+	if got := len(linhasJSON(t, caminho)); got != 1 {
+		t.Errorf("arquivo atual deveria ter 1 linha, got %d", got)
+	}
+}
+
+func TestArquivoEventPublisher_RotacaoFalhaMantemArquivo(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "eventos.jsonl")
+	evento := domain.NewTransacao("c1", 10.00, "corr").ToEvento()
+	linha, _ := json.Marshal(evento)
+
+	// Diretório não vazio no destino impede a renomeação
+	if err := os.MkdirAll(filepath.Join(caminho+".1", "ocupado"), 0o755); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	publisher, err := NewArquivoEventPublisher(caminho, WithTamanhoMaximo(int64(len(linha)+1)))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	defer publisher.Close()
+
+	if err := publisher.PublishTransacaoAprovada(context.Background(), evento); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if err := publisher.PublishTransacaoAprovada(context.Background(), evento); err == nil {
+		t.Fatal("esperado erro da rotação")
+	}
+
+	// Liberado o destino, o arquivo original segue aberto e a rotação conclui
+	if err := os.RemoveAll(caminho + ".1"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if err := publisher.PublishTransacaoAprovada(context.Background(), evento); err != nil {
+		t.Fatalf("publicação após falha na rotação: %v", err)
+	}
+
+	if got := len(linhasJSON(t, caminho+".1")); got != 1 {
+		t.Errorf("arquivo rotacionado deveria ter 1 linha, got %d", got)
+	}
+	if got := len(linhasJSON(t, caminho)); got != 1 {
+		t.Errorf("arquivo atual deveria ter 1 linha, got %d", got)
+	}
+}
+
+func TestNewArquivoEventPublisher_CaminhoInvalido(t *testing.T) {
+	_, err := NewArquivoEventPublisher(filepath.Join(t.TempDir(), "inexistente", "eventos.jsonl"))
+	if err == nil || !strings.Contains(err.Error(), "eventos.jsonl") {
+		t.Errorf("esperado erro de abertura citando o arquivo, got %v", err)
+	}
+}
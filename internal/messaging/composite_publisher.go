@@ -0,0 +1,41 @@
+package messaging
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CompositeEventPublisher replica cada evento para vários publishers (ex.: log
+// e SNS durante a migração). Publica em todos, mesmo que algum falhe, e
+// devolve os erros combinados.
+type CompositeEventPublisher struct {
+	publishers []domain.EventPublisher
+}
+
+func NewCompositeEventPublisher(publishers ...domain.EventPublisher) *CompositeEventPublisher {
+	return &CompositeEventPublisher{publishers: publishers}
+}
+
+func (c *CompositeEventPublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return c.publicar(func(p domain.EventPublisher) error {
+		return p.PublishTransacaoAprovada(ctx, evento)
+	})
+}
+
+func (c *CompositeEventPublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return c.publicar(func(p domain.EventPublisher) error {
+		return p.PublishTransacaoRejeitada(ctx, evento)
+	})
+}
+
+func (c *CompositeEventPublisher) publicar(fn func(domain.EventPublisher) error) error {
+	var errs []error
+	for i, publisher := range c.publishers {
+		if err := fn(publisher); err != nil {
+			errs = append(errs, fmt.Errorf("publisher %d (%T): %w", i, publisher, err))
+		}
+	}
+	return errors.Join(errs...)
+}
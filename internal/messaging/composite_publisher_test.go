@@ -0,0 +1,60 @@
+package messaging
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakePublisher conta os eventos recebidos e devolve o erro configurado
+type fakePublisher struct {
+	aprovados  int
+	rejeitados int
+	err        error
+}
+
+func (p *fakePublisher) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	p.aprovados++
+	return p.err
+}
+
+func (p *fakePublisher) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	p.rejeitados++
+	return p.err
+}
+
+func TestCompositeEventPublisher_FalhaParcial(t *testing.T) {
+	errSNS := errors.New("sns indisponível")
+	falha := &fakePublisher{err: errSNS}
+	sucesso := &fakePublisher{}
+
+	// O publisher que falha vem primeiro: o seguinte ainda deve receber o evento
+	composite := NewCompositeEventPublisher(falha, sucesso)
+	evento := domain.NewTransacao("c1", 10.00, "corr").ToEvento()
+
+	err := composite.PublishTransacaoAprovada(context.Background(), evento)
+	if !errors.Is(err, errSNS) {
+		t.Fatalf("Erro esperado envolvendo %v, got %v", errSNS, err)
+	}
+
+	if falha.aprovados != 1 || sucesso.aprovados != 1 {
+		t.Errorf("todos os publishers deveriam receber o evento, got %d e %d", falha.aprovados, sucesso.aprovados)
+	}
+
+	if err := composite.PublishTransacaoRejeitada(context.Background(), evento); !errors.Is(err, errSNS) {
+		t.Errorf("Erro esperado envolvendo %v, got %v", errSNS, err)
+	}
+
+	if sucesso.rejeitados != 1 {
+		t.Errorf("publisher saudável deveria receber a rejeição, got %d", sucesso.rejeitados)
+	}
+}
+
+func TestCompositeEventPublisher_TodosComSucesso(t *testing.T) {
+	composite := NewCompositeEventPublisher(&fakePublisher{}, &fakePublisher{})
+
+	if err := composite.PublishTransacaoAprovada(context.Background(), domain.NewTransacao("c1", 10.00, "corr").ToEvento()); err != nil {
+		t.Errorf("erro inesperado: %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package messaging
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+)
+
+// RoteadorRejeicoes escolhe o publisher (tópico) dos eventos de rejeição pelo
+// código do motivo (ex.: pre_authorization_denied para o tópico de fraude).
+// Aprovações e motivos sem rota vão para o publisher padrão.
+type RoteadorRejeicoes struct {
+	padrao domain.EventPublisher
+	rotas  map[string]domain.EventPublisher
+}
+
+func NewRoteadorRejeicoes(padrao domain.EventPublisher, rotas map[string]domain.EventPublisher) *RoteadorRejeicoes {
+	return &RoteadorRejeicoes{padrao: padrao, rotas: rotas}
+}
+
+func (r *RoteadorRejeicoes) PublishTransacaoAprovada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	return r.padrao.PublishTransacaoAprovada(ctx, evento)
+}
+
+func (r *RoteadorRejeicoes) PublishTransacaoRejeitada(ctx context.Context, evento *domain.TransacaoEvento) error {
+	if publisher, ok := r.rotas[evento.CodigoMotivo]; ok {
+		return publisher.PublishTransacaoRejeitada(ctx, evento)
+	}
+	return r.padrao.PublishTransacaoRejeitada(ctx, evento)
+}
@@ -0,0 +1,52 @@
+package messaging
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+)
+
+func TestRoteadorRejeicoes(t *testing.T) {
+	tests := []struct {
+		name         string
+		codigoMotivo string
+		fraude       int
+		padrao       int
+	}{
+		{name: "fraude vai para o tópico de fraude", codigoMotivo: "pre_authorization_denied", fraude: 1},
+		{name: "limite vai para o padrão", codigoMotivo: "insufficient_limit", padrao: 1},
+		{name: "sem motivo vai para o padrão", padrao: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			padrao, fraude := &fakePublisher{}, &fakePublisher{}
+			roteador := NewRoteadorRejeicoes(padrao, map[string]domain.EventPublisher{
+				"pre_authorization_denied": fraude,
+			})
+
+			transacao := domain.NewTransacao("c1", 10.00, "corr")
+			transacao.Rejeitar()
+			transacao.CodigoMotivo = tt.codigoMotivo
+
+			if err := roteador.PublishTransacaoRejeitada(context.Background(), transacao.ToEvento()); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if fraude.rejeitados != tt.fraude || padrao.rejeitados != tt.padrao {
+				t.Errorf("esperado fraude=%d padrão=%d, got fraude=%d padrão=%d", tt.fraude, tt.padrao, fraude.rejeitados, padrao.rejeitados)
+			}
+		})
+	}
+}
+
+func TestRoteadorRejeicoes_AprovacaoNoPadrao(t *testing.T) {
+	padrao, fraude := &fakePublisher{}, &fakePublisher{}
+	roteador := NewRoteadorRejeicoes(padrao, map[string]domain.EventPublisher{"pre_authorization_denied": fraude})
+
+	roteador.PublishTransacaoAprovada(context.Background(), domain.NewTransacao("c1", 10.00, "corr").ToEvento())
+
+	if padrao.aprovados != 1 || fraude.aprovados != 0 {
+		t.Errorf("aprovação deveria ir só para o padrão, got padrão=%d fraude=%d", padrao.aprovados, fraude.aprovados)
+	}
+}
@@ -2,8 +2,11 @@ package logger
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
 )
 
 // StructuredLogger implementa domain.Logger usando log/slog
@@ -12,33 +15,53 @@ type StructuredLogger struct {
 }
 
 func NewStructuredLogger() *StructuredLogger {
-	// Configuração do logger estruturado
+	return NewStructuredLoggerWithLevel(slog.LevelDebug)
+}
+
+// NewStructuredLoggerWithLevel cria logger com nível específico
+func NewStructuredLoggerWithLevel(level slog.Level) *StructuredLogger {
+	return newStructuredLogger(os.Stdout, level)
+}
+
+func newStructuredLogger(w io.Writer, level slog.Level) *StructuredLogger {
 	opts := &slog.HandlerOptions{
-		Level:     slog.LevelDebug,
+		Level:     level,
 		AddSource: true,
 	}
 
 	// Handler JSON para produção
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-	logger := slog.New(handler)
+	handler := slog.NewJSONHandler(w, opts)
 
 	return &StructuredLogger{
-		logger: logger,
+		logger: slog.New(handler),
 	}
 }
 
-// NewStructuredLoggerWithLevel cria logger com nível específico
-func NewStructuredLoggerWithLevel(level slog.Level) *StructuredLogger {
-	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: true,
-	}
+// CamposEstaticos identificam a origem dos logs na agregação multiambiente
+type CamposEstaticos struct {
+	Env     string
+	Region  string
+	Service string
+	Version string
+}
 
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-	logger := slog.New(handler)
+// WithCamposEstaticos retorna um logger que anexa env, region, service e
+// version a todas as linhas; campos vazios são omitidos
+func (l *StructuredLogger) WithCamposEstaticos(campos CamposEstaticos) *StructuredLogger {
+	var args []any
+	for _, campo := range []struct{ chave, valor string }{
+		{"env", campos.Env},
+		{"region", campos.Region},
+		{"service", campos.Service},
+		{"version", campos.Version},
+	} {
+		if campo.valor != "" {
+			args = append(args, slog.String(campo.chave, campo.valor))
+		}
+	}
 
 	return &StructuredLogger{
-		logger: logger,
+		logger: l.logger.With(args...),
 	}
 }
 
@@ -77,6 +100,14 @@ func (l *StructuredLogger) logWithFields(ctx context.Context, level slog.Level,
 		fields["correlation_id"] = correlationID
 	}
 
+	// Request ID do Lambda, usado pelo suporte da AWS e pelo CloudWatch
+	if requestID := extractAWSRequestID(ctx); requestID != "" {
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+		fields["aws_request_id"] = requestID
+	}
+
 	// Converte map para slog.Attr
 	attrs := make([]slog.Attr, 0, len(fields))
 	for key, value := range fields {
@@ -96,6 +127,15 @@ func extractCorrelationID(ctx context.Context) string {
 	return ""
 }
 
+// extractAWSRequestID extrai o request ID da invocação do Lambda presente no
+// contexto (vazio fora do Lambda)
+func extractAWSRequestID(ctx context.Context) string {
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.AwsRequestID
+	}
+	return ""
+}
+
 // WithCorrelationID adiciona correlation ID ao contexto
 func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
 	return context.WithValue(ctx, "correlation_id", correlationID)
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+func TestStructuredLogger_CamposEstaticosEmTodasAsLinhas(t *testing.T) {
+	var saida bytes.Buffer
+	log := newStructuredLogger(&saida, slog.LevelDebug).WithCamposEstaticos(CamposEstaticos{
+		Env:     "prod",
+		Region:  "sa-east-1",
+		Service: "transaction-authorizer",
+		Version: "1.4.0",
+	})
+
+	ctx := WithCorrelationID(context.Background(), "corr")
+	log.Info(ctx, "info", map[string]interface{}{"cliente_id": "c1"})
+	log.Warn(ctx, "warn", nil)
+	log.Error(ctx, "error", errors.New("falha"), nil)
+	log.Debug(ctx, "debug", nil)
+
+	linhas := strings.Split(strings.TrimSpace(saida.String()), "\n")
+	if len(linhas) != 4 {
+		t.Fatalf("Esperava 4 linhas de log, got %d", len(linhas))
+	}
+
+	esperados := map[string]string{
+		"env":            "prod",
+		"region":         "sa-east-1",
+		"service":        "transaction-authorizer",
+		"version":        "1.4.0",
+		"correlation_id": "corr",
+	}
+	for _, linha := range linhas {
+		var registro map[string]interface{}
+		if err := json.Unmarshal([]byte(linha), &registro); err != nil {
+			t.Fatalf("Linha de log não é JSON válido: %v", err)
+		}
+		for chave, valor := range esperados {
+			if registro[chave] != valor {
+				t.Errorf("Campo %s esperado %q, got %v (linha %s)", chave, valor, registro[chave], registro["msg"])
+			}
+		}
+	}
+}
+
+func TestStructuredLogger_CamposEstaticosVaziosOmitidos(t *testing.T) {
+	var saida bytes.Buffer
+	log := newStructuredLogger(&saida, slog.LevelDebug).WithCamposEstaticos(CamposEstaticos{Service: "transaction-authorizer"})
+
+	log.Info(context.Background(), "info", nil)
+
+	var registro map[string]interface{}
+	if err := json.Unmarshal(saida.Bytes(), &registro); err != nil {
+		t.Fatalf("Linha de log não é JSON válido: %v", err)
+	}
+	if registro["service"] != "transaction-authorizer" {
+		t.Errorf("Campo service esperado, got %v", registro["service"])
+	}
+	for _, chave := range []string{"env", "region", "version"} {
+		if _, ok := registro[chave]; ok {
+			t.Errorf("Campo %s vazio não deveria aparecer", chave)
+		}
+	}
+}
+
+func TestStructuredLogger_AWSRequestIDDoContextoLambda(t *testing.T) {
+	var saida bytes.Buffer
+	log := newStructuredLogger(&saida, slog.LevelDebug)
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-123"})
+	log.Info(ctx, "com lambda", nil)
+	log.Error(ctx, "erro com lambda", errors.New("falha"), map[string]interface{}{"cliente_id": "c1"})
+	log.Info(context.Background(), "fora do lambda", nil)
+
+	linhas := strings.Split(strings.TrimSpace(saida.String()), "\n")
+	if len(linhas) != 3 {
+		t.Fatalf("Esperava 3 linhas de log, got %d", len(linhas))
+	}
+
+	for i, linha := range linhas {
+		var registro map[string]interface{}
+		if err := json.Unmarshal([]byte(linha), &registro); err != nil {
+			t.Fatalf("Linha de log não é JSON válido: %v", err)
+		}
+		requestID, presente := registro["aws_request_id"]
+		if i < 2 && requestID != "req-123" {
+			t.Errorf("aws_request_id esperado req-123, got %v (linha %s)", requestID, registro["msg"])
+		}
+		if i == 2 && presente {
+			t.Errorf("aws_request_id não deveria aparecer fora do Lambda, got %v", requestID)
+		}
+	}
+}
@@ -1,22 +1,81 @@
 package metrics
 
 import (
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// LabelsNegocioPadrao são os labels de business_metrics por padrão. Todos
+// têm cardinalidade limitada; cliente_id fica apenas em logs e traces.
+var LabelsNegocioPadrao = []string{"status", "canal", "tier"}
+
 // PrometheusCollector implementa domain.MetricsCollector usando Prometheus
 type PrometheusCollector struct {
 	transactionCounter *prometheus.CounterVec
 	transactionLatency prometheus.Histogram
 	businessMetrics    *prometheus.GaugeVec
 	errorCounter       *prometheus.CounterVec
+	outcomeCounter     *prometheus.CounterVec
+	eventPublishTotal  *prometheus.CounterVec
+	eventPublishTime   prometheus.Histogram
+	rejectedValue      *prometheus.HistogramVec
+
+	labelsNegocio []string
+	registry      *prometheus.Registry
+}
+
+// Option configura o PrometheusCollector
+type Option func(*opcoes)
+
+type opcoes struct {
+	registerer    prometheus.Registerer
+	labelsNegocio []string
+}
+
+// WithLabelsNegocio define os labels de business_metrics (além de
+// metric_name); labels recebidos fora da lista são descartados. Incluir um
+// label por entidade (ex.: cliente_id) cria uma série por valor: use apenas
+// com cardinalidade sabidamente limitada.
+func WithLabelsNegocio(labels ...string) Option {
+	return func(o *opcoes) {
+		o.labelsNegocio = labels
+	}
 }
 
-func NewPrometheusCollector() *PrometheusCollector {
+// WithRegisterer registra as métricas em outro registry (padrão: um registry
+// próprio do collector). Collectors que compartilham o registry compartilham
+// as métricas.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *opcoes) {
+		o.registerer = registerer
+	}
+}
+
+// NewPrometheusCollector cria o collector e registra suas métricas. Pode ser
+// chamado mais de uma vez (inclusive concorrentemente) sobre o mesmo
+// registry: métricas já registradas são reaproveitadas em vez de causar panic.
+func NewPrometheusCollector(opts ...Option) *PrometheusCollector {
+	o := opcoes{labelsNegocio: LabelsNegocioPadrao}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var registry *prometheus.Registry
+	if o.registerer == nil {
+		registry = prometheus.NewRegistry()
+		o.registerer = registry
+	} else if r, ok := o.registerer.(*prometheus.Registry); ok {
+		registry = r
+	}
+	factory := fabrica{o.registerer}
+
 	return &PrometheusCollector{
+		labelsNegocio: o.labelsNegocio,
+		registry:      registry,
+
 		// Contador de transações por status
-		transactionCounter: promauto.NewCounterVec(
+		transactionCounter: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "transactions_total",
 				Help: "Total number of processed transactions",
@@ -25,7 +84,7 @@ func NewPrometheusCollector() *PrometheusCollector {
 		),
 
 		// Histograma de latência das transações
-		transactionLatency: promauto.NewHistogram(
+		transactionLatency: factory.NewHistogram(
 			prometheus.HistogramOpts{
 				Name:    "transaction_duration_seconds",
 				Help:    "Transaction processing duration in seconds",
@@ -34,23 +93,100 @@ func NewPrometheusCollector() *PrometheusCollector {
 		),
 
 		// Métricas de negócio (valores, limites, etc.)
-		businessMetrics: promauto.NewGaugeVec(
+		businessMetrics: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "business_metrics",
 				Help: "Business-specific metrics",
 			},
-			[]string{"metric_name", "status", "cliente_id"},
+			append([]string{"metric_name"}, o.labelsNegocio...),
 		),
 
 		// Contador de erros por tipo
-		errorCounter: promauto.NewCounterVec(
+		errorCounter: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "errors_total",
 				Help: "Total number of errors by type",
 			},
 			[]string{"error_type"},
 		),
+
+		// Contador de desfechos que não são erro
+		outcomeCounter: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "outcomes_total",
+				Help: "Total number of non-error outcomes by type",
+			},
+			[]string{"outcome"},
+		),
+
+		// Contador de publicações de eventos por resultado
+		eventPublishTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "event_publish_total",
+				Help: "Total number of event publish attempts by result",
+			},
+			[]string{"result"},
+		),
+
+		// Histograma de latência da publicação de eventos
+		eventPublishTime: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "event_publish_duration_seconds",
+				Help:    "Event publishing duration in seconds",
+				Buckets: prometheus.ExponentialBuckets(0.001, 2, 15), // 1ms to ~32s
+			},
+		),
+
+		// Distribuição do valor das transações rejeitadas por motivo
+		rejectedValue: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "rejected_transaction_value",
+				Help:    "Value of rejected transactions by reason code",
+				Buckets: prometheus.ExponentialBuckets(1, 2, 17), // R$ 1 to ~R$ 65.536
+			},
+			[]string{"reason"},
+		),
+	}
+}
+
+// fabrica cria e registra as métricas, reaproveitando a já registrada no
+// registry com o mesmo descritor
+type fabrica struct {
+	registerer prometheus.Registerer
+}
+
+func (f fabrica) NewCounterVec(opts prometheus.CounterOpts, labels []string) *prometheus.CounterVec {
+	return registrar(f.registerer, prometheus.NewCounterVec(opts, labels))
+}
+
+func (f fabrica) NewGaugeVec(opts prometheus.GaugeOpts, labels []string) *prometheus.GaugeVec {
+	return registrar(f.registerer, prometheus.NewGaugeVec(opts, labels))
+}
+
+func (f fabrica) NewHistogram(opts prometheus.HistogramOpts) prometheus.Histogram {
+	return registrar(f.registerer, prometheus.NewHistogram(opts))
+}
+
+func (f fabrica) NewHistogramVec(opts prometheus.HistogramOpts, labels []string) *prometheus.HistogramVec {
+	return registrar(f.registerer, prometheus.NewHistogramVec(opts, labels))
+}
+
+// registrar registra a métrica ou devolve a existente (AlreadyRegisteredError).
+// Descritor incompatível com o já registrado (ex.: outros labels) é erro de
+// configuração e causa panic, como em MustRegister.
+func registrar[T prometheus.Collector](registerer prometheus.Registerer, metrica T) T {
+	err := registerer.Register(metrica)
+	if err == nil {
+		return metrica
+	}
+
+	var jaRegistrada prometheus.AlreadyRegisteredError
+	if errors.As(err, &jaRegistrada) {
+		if existente, ok := jaRegistrada.ExistingCollector.(T); ok {
+			return existente
+		}
 	}
+	panic(err)
 }
 
 // IncrementTransactionCounter incrementa contador de transações
@@ -63,13 +199,16 @@ func (c *PrometheusCollector) RecordTransactionLatency(duration float64) {
 	c.transactionLatency.Observe(duration)
 }
 
-// RecordBusinessMetric registra métricas de negócio
+// RecordBusinessMetric registra métricas de negócio apenas com os labels
+// configurados, mantendo a cardinalidade limitada
 func (c *PrometheusCollector) RecordBusinessMetric(metricName string, value float64, labels map[string]string) {
-	// Extrai labels específicos
-	status := labels["status"]
-	clienteID := labels["cliente_id"]
+	valores := make([]string, 0, len(c.labelsNegocio)+1)
+	valores = append(valores, metricName)
+	for _, label := range c.labelsNegocio {
+		valores = append(valores, labels[label])
+	}
 
-	c.businessMetrics.WithLabelValues(metricName, status, clienteID).Set(value)
+	c.businessMetrics.WithLabelValues(valores...).Set(value)
 }
 
 // IncrementErrorCounter incrementa contador de erros
@@ -77,7 +216,24 @@ func (c *PrometheusCollector) IncrementErrorCounter(errorType string) {
 	c.errorCounter.WithLabelValues(errorType).Inc()
 }
 
-// GetRegistry retorna o registry padrão do Prometheus
+// IncrementOutcomeCounter incrementa contador de desfechos que não são erro
+func (c *PrometheusCollector) IncrementOutcomeCounter(outcome string) {
+	c.outcomeCounter.WithLabelValues(outcome).Inc()
+}
+
+// RecordEventPublish registra latência e resultado da publicação de eventos
+func (c *PrometheusCollector) RecordEventPublish(result string, duration float64) {
+	c.eventPublishTotal.WithLabelValues(result).Inc()
+	c.eventPublishTime.Observe(duration)
+}
+
+// RecordRejectedValue registra o valor de uma transação rejeitada por motivo
+func (c *PrometheusCollector) RecordRejectedValue(reason string, value float64) {
+	c.rejectedValue.WithLabelValues(reason).Observe(value)
+}
+
+// GetRegistry retorna o registry das métricas, para exposição (ex.:
+// promhttp.HandlerFor); nil se WithRegisterer recebeu outro Registerer
 func (c *PrometheusCollector) GetRegistry() *prometheus.Registry {
-	return prometheus.DefaultRegisterer.(*prometheus.Registry)
+	return c.registry
 }
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func seriesBusinessMetrics(t *testing.T, registry *prometheus.Registry) []map[string]string {
+	t.Helper()
+
+	familias, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("erro ao coletar métricas: %v", err)
+	}
+
+	var series []map[string]string
+	for _, familia := range familias {
+		if familia.GetName() != "business_metrics" {
+			continue
+		}
+		for _, metrica := range familia.GetMetric() {
+			labels := make(map[string]string)
+			for _, par := range metrica.GetLabel() {
+				labels[par.GetName()] = par.GetValue()
+			}
+			series = append(series, labels)
+		}
+	}
+	return series
+}
+
+func TestRecordBusinessMetric_CardinalidadeLimitada(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		esperaSeries  int
+		esperaCliente bool
+	}{
+		{name: "labels padrão descartam cliente_id", esperaSeries: 1},
+		{name: "cliente_id configurado explicitamente", opts: []Option{WithLabelsNegocio("status", "cliente_id")}, esperaSeries: 1000, esperaCliente: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			collector := NewPrometheusCollector(append(tt.opts, WithRegisterer(registry))...)
+
+			for i := 0; i < 1000; i++ {
+				collector.RecordBusinessMetric("transaction_value", 10, map[string]string{
+					"status":     "APROVADA",
+					"cliente_id": fmt.Sprintf("cliente-%d", i),
+					"canal":      "mobile",
+					"tier":       "GOLD",
+				})
+			}
+
+			series := seriesBusinessMetrics(t, registry)
+			if len(series) != tt.esperaSeries {
+				t.Fatalf("esperadas %d séries, got %d", tt.esperaSeries, len(series))
+			}
+			if _, ok := series[0]["cliente_id"]; ok != tt.esperaCliente {
+				t.Errorf("label cliente_id presente: %v, esperado %v", ok, tt.esperaCliente)
+			}
+		})
+	}
+}
+
+func TestRecordRejectedValue_PorMotivo(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector := NewPrometheusCollector(WithRegisterer(registry))
+
+	collector.RecordRejectedValue("insufficient_limit", 1500)
+	collector.RecordRejectedValue("insufficient_limit", 2500)
+	collector.RecordRejectedValue("amount_above_maximum", 90000)
+
+	familias, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("erro ao coletar métricas: %v", err)
+	}
+
+	somas := make(map[string]float64)
+	contagens := make(map[string]uint64)
+	for _, familia := range familias {
+		if familia.GetName() != "rejected_transaction_value" {
+			continue
+		}
+		for _, metrica := range familia.GetMetric() {
+			for _, par := range metrica.GetLabel() {
+				if par.GetName() == "reason" {
+					somas[par.GetValue()] = metrica.GetHistogram().GetSampleSum()
+					contagens[par.GetValue()] = metrica.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+
+	if contagens["insufficient_limit"] != 2 || somas["insufficient_limit"] != 4000 {
+		t.Errorf("insufficient_limit esperado 2 amostras somando 4000, got %d e %.2f", contagens["insufficient_limit"], somas["insufficient_limit"])
+	}
+	if contagens["amount_above_maximum"] != 1 || somas["amount_above_maximum"] != 90000 {
+		t.Errorf("amount_above_maximum esperado 1 amostra de 90000, got %d e %.2f", contagens["amount_above_maximum"], somas["amount_above_maximum"])
+	}
+}
+
+func TestNewPrometheusCollector_ConstrucaoRepetida(t *testing.T) {
+	// Registry próprio por padrão: dois collectors independentes
+	primeiro, segundo := NewPrometheusCollector(), NewPrometheusCollector()
+	if primeiro.GetRegistry() == segundo.GetRegistry() {
+		t.Error("cada collector deveria ter o próprio registry")
+	}
+
+	// Registry global, que antes causava panic por registro duplicado
+	NewPrometheusCollector(WithRegisterer(prometheus.DefaultRegisterer))
+	NewPrometheusCollector(WithRegisterer(prometheus.DefaultRegisterer))
+
+	// Mesmo registry, inclusive concorrentemente: as métricas são reaproveitadas
+	registry := prometheus.NewRegistry()
+	collectors := make([]*PrometheusCollector, 8)
+	var wg sync.WaitGroup
+	for i := range collectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			collectors[i] = NewPrometheusCollector(WithRegisterer(registry))
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range collectors {
+		c.IncrementErrorCounter("timeout")
+	}
+
+	familias, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("erro ao coletar métricas: %v", err)
+	}
+	for _, familia := range familias {
+		if familia.GetName() == "errors_total" {
+			if got := familia.GetMetric()[0].GetCounter().GetValue(); got != float64(len(collectors)) {
+				t.Errorf("errors_total esperado %d, got %v", len(collectors), got)
+			}
+			return
+		}
+	}
+	t.Error("errors_total não registrada")
+}
+
+func TestIncrementOutcomeCounter_NaoContaComoErro(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	c := NewPrometheusCollector(WithRegisterer(registry))
+
+	c.IncrementOutcomeCounter("idempotent_replay")
+
+	familias, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("erro ao coletar métricas: %v", err)
+	}
+	var desfechos float64
+	for _, familia := range familias {
+		switch familia.GetName() {
+		case "outcomes_total":
+			desfechos = familia.GetMetric()[0].GetCounter().GetValue()
+		case "errors_total":
+			t.Errorf("desfecho não deveria gerar série em errors_total")
+		}
+	}
+	if desfechos != 1 {
+		t.Errorf("outcomes_total esperado 1, got %v", desfechos)
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Pushgateway envia as métricas de um job em lote (Lambda agendada,
+// consumidor de fila) ao Pushgateway. Esses processos não expõem endpoint de
+// scrape e terminam entre execuções: sem o envio, as métricas se perdem.
+type Pushgateway struct {
+	url       string
+	job       string
+	collector *PrometheusCollector
+}
+
+// NewPushgateway cria o envio das métricas do collector para o job; url vazia
+// desliga o envio
+func NewPushgateway(url, job string, collector *PrometheusCollector) *Pushgateway {
+	return &Pushgateway{url: url, job: job, collector: collector}
+}
+
+// Habilitado indica se há Pushgateway configurado
+func (p *Pushgateway) Habilitado() bool {
+	return p.url != ""
+}
+
+// Enviar substitui as métricas do job no Pushgateway pelo estado atual do
+// collector. Sem URL configurada, não faz nada.
+func (p *Pushgateway) Enviar(ctx context.Context) error {
+	if !p.Habilitado() {
+		return nil
+	}
+
+	registry := p.collector.GetRegistry()
+	if registry == nil {
+		return errors.New("collector sem registry próprio para enviar ao Pushgateway")
+	}
+	return push.New(p.url, p.job).Gatherer(registry).PushContext(ctx)
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPushgateway_EnviaMetricasDoJob(t *testing.T) {
+	var metodo, caminho, corpo string
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dados, _ := io.ReadAll(r.Body)
+		metodo, caminho, corpo = r.Method, r.URL.Path, string(dados)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer servidor.Close()
+
+	collector := NewPrometheusCollector()
+	collector.RecordBusinessMetric("event_reconciliation_gap", 3, nil)
+
+	if err := NewPushgateway(servidor.URL, "event-reconciliation", collector).Enviar(context.Background()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if metodo != http.MethodPut || caminho != "/metrics/job/event-reconciliation" {
+		t.Errorf("esperado PUT das métricas do job, got %s %s", metodo, caminho)
+	}
+	if !strings.Contains(corpo, "event_reconciliation_gap") {
+		t.Error("métricas registradas deveriam ser enviadas")
+	}
+}
+
+func TestPushgateway_SemURLNaoEnvia(t *testing.T) {
+	pushgateway := NewPushgateway("", "event-reconciliation", NewPrometheusCollector())
+
+	if pushgateway.Habilitado() {
+		t.Error("sem URL o envio deveria estar desligado")
+	}
+	if err := pushgateway.Enviar(context.Background()); err != nil {
+		t.Errorf("erro inesperado: %v", err)
+	}
+}
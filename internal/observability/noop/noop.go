@@ -0,0 +1,45 @@
+// Package noop implementa as portas de observabilidade sem efeito algum, para
+// testes, benchmarks e implantações com observabilidade desligada
+package noop
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+)
+
+var (
+	_ domain.Logger            = Logger{}
+	_ domain.DistributedTracer = Tracer{}
+	_ domain.MetricsCollector  = MetricsCollector{}
+)
+
+// Logger descarta todos os logs
+type Logger struct{}
+
+func (Logger) Info(ctx context.Context, msg string, fields map[string]interface{})  {}
+func (Logger) Warn(ctx context.Context, msg string, fields map[string]interface{})  {}
+func (Logger) Debug(ctx context.Context, msg string, fields map[string]interface{}) {}
+func (Logger) Error(ctx context.Context, msg string, err error, fields map[string]interface{}) {
+}
+
+// Tracer não cria spans: devolve o próprio contexto e um span nil
+type Tracer struct{}
+
+func (Tracer) StartSpan(ctx context.Context, operationName string) (context.Context, interface{}) {
+	return ctx, nil
+}
+func (Tracer) FinishSpan(span interface{}, err error)                 {}
+func (Tracer) AddTag(span interface{}, key string, value interface{}) {}
+func (Tracer) ExtractTraceID(ctx context.Context) string              { return "" }
+
+// MetricsCollector descarta todas as métricas
+type MetricsCollector struct{}
+
+func (MetricsCollector) IncrementTransactionCounter(status string) {}
+func (MetricsCollector) RecordTransactionLatency(duration float64) {}
+func (MetricsCollector) RecordBusinessMetric(metricName string, value float64, labels map[string]string) {
+}
+func (MetricsCollector) IncrementErrorCounter(errorType string)             {}
+func (MetricsCollector) IncrementOutcomeCounter(outcome string)             {}
+func (MetricsCollector) RecordEventPublish(result string, duration float64) {}
+func (MetricsCollector) RecordRejectedValue(reason string, value float64)   {}
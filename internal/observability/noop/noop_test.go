@@ -0,0 +1,37 @@
+package noop
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNoop_TodosOsMetodos(t *testing.T) {
+	ctx := context.Background()
+
+	var logger domain.Logger = Logger{}
+	logger.Info(ctx, "info", nil)
+	logger.Warn(ctx, "warn", map[string]interface{}{"campo": 1})
+	logger.Debug(ctx, "debug", nil)
+	logger.Error(ctx, "error", errors.New("falha"), nil)
+
+	var tracer domain.DistributedTracer = Tracer{}
+	spanCtx, span := tracer.StartSpan(ctx, "operacao")
+	if spanCtx != ctx {
+		t.Error("StartSpan deveria devolver o próprio contexto")
+	}
+	tracer.AddTag(span, "chave", "valor")
+	tracer.FinishSpan(span, errors.New("falha"))
+	if id := tracer.ExtractTraceID(spanCtx); id != "" {
+		t.Errorf("trace ID vazio esperado, got %q", id)
+	}
+
+	var metrics domain.MetricsCollector = MetricsCollector{}
+	metrics.IncrementTransactionCounter("APROVADA")
+	metrics.RecordTransactionLatency(0.01)
+	metrics.RecordBusinessMetric("transaction_value", 10, map[string]string{"status": "APROVADA"})
+	metrics.IncrementErrorCounter("json_parse_error")
+	metrics.IncrementOutcomeCounter("idempotent_replay")
+	metrics.RecordEventPublish("success", 0.01)
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+)
+
+// Nivel define a verbosidade dos spans emitidos
+type Nivel string
+
+const (
+	// NivelMinimo emite apenas o span raiz de cada requisição
+	NivelMinimo Nivel = "minimal"
+	// NivelDetalhado emite também os spans de cada etapa interna
+	NivelDetalhado Nivel = "verbose"
+)
+
+// NivelTracer decora um DistributedTracer aplicando o nível de observabilidade.
+// No nível mínimo, StartSpan dentro de um span já aberto não cria span filho:
+// devolve o contexto original e um span nil, que FinishSpan e AddTag ignoram.
+type NivelTracer struct {
+	domain.DistributedTracer
+	nivel Nivel
+}
+
+func NewNivelTracer(tracer domain.DistributedTracer, nivel Nivel) *NivelTracer {
+	return &NivelTracer{DistributedTracer: tracer, nivel: nivel}
+}
+
+// StartSpan inicia o span, exceto spans filhos no nível mínimo
+func (t *NivelTracer) StartSpan(ctx context.Context, operationName string) (context.Context, interface{}) {
+	if t.nivel == NivelMinimo && ctx.Value("nivel_span_raiz") != nil {
+		return ctx, nil
+	}
+
+	ctx, span := t.DistributedTracer.StartSpan(ctx, operationName)
+	return context.WithValue(ctx, "nivel_span_raiz", true), span
+}
+
+// FinishSpan finaliza o span, ignorando spans suprimidos
+func (t *NivelTracer) FinishSpan(span interface{}, err error) {
+	if span == nil {
+		return
+	}
+	t.DistributedTracer.FinishSpan(span, err)
+}
+
+// AddTag adiciona a tag, ignorando spans suprimidos
+func (t *NivelTracer) AddTag(span interface{}, key string, value interface{}) {
+	if span == nil {
+		return
+	}
+	t.DistributedTracer.AddTag(span, key, value)
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// contadorTracer conta os spans iniciados e finalizados
+type contadorTracer struct {
+	*SimpleTracer
+	mu          sync.Mutex
+	iniciados   []string
+	finalizados int
+}
+
+func (t *contadorTracer) StartSpan(ctx context.Context, operationName string) (context.Context, interface{}) {
+	t.mu.Lock()
+	t.iniciados = append(t.iniciados, operationName)
+	t.mu.Unlock()
+	return t.SimpleTracer.StartSpan(ctx, operationName)
+}
+
+func (t *contadorTracer) FinishSpan(span interface{}, err error) {
+	t.mu.Lock()
+	t.finalizados++
+	t.mu.Unlock()
+}
+
+func TestNivelTracer_ContagemDeSpans(t *testing.T) {
+	tests := []struct {
+		nivel    Nivel
+		esperado int
+	}{
+		{nivel: NivelMinimo, esperado: 1},
+		{nivel: NivelDetalhado, esperado: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.nivel), func(t *testing.T) {
+			inner := &contadorTracer{SimpleTracer: NewSimpleTracer("test")}
+			tracer := NewNivelTracer(inner, tt.nivel)
+
+			// raiz -> etapa -> subetapa, e uma segunda etapa irmã
+			ctx, raiz := tracer.StartSpan(context.Background(), "raiz")
+			ctxEtapa, etapa := tracer.StartSpan(ctx, "etapa")
+			_, subetapa := tracer.StartSpan(ctxEtapa, "subetapa")
+			tracer.AddTag(subetapa, "chave", "valor")
+			tracer.FinishSpan(subetapa, nil)
+			tracer.FinishSpan(etapa, nil)
+			_, irma := tracer.StartSpan(ctx, "irma")
+			tracer.FinishSpan(irma, nil)
+			tracer.FinishSpan(raiz, nil)
+
+			if len(inner.iniciados) != tt.esperado {
+				t.Errorf("spans iniciados esperados %d, got %d (%v)", tt.esperado, len(inner.iniciados), inner.iniciados)
+			}
+
+			if inner.finalizados != tt.esperado {
+				t.Errorf("spans finalizados esperados %d, got %d", tt.esperado, inner.finalizados)
+			}
+
+			if inner.iniciados[0] != "raiz" {
+				t.Errorf("primeiro span esperado raiz, got %s", inner.iniciados[0])
+			}
+		})
+	}
+}
+
+func TestNivelTracer_MinimoPreservaTraceID(t *testing.T) {
+	tracer := NewNivelTracer(NewSimpleTracer("test"), NivelMinimo)
+
+	ctx, raiz := tracer.StartSpan(context.Background(), "raiz")
+	ctxFilho, _ := tracer.StartSpan(ctx, "filho")
+
+	if tracer.ExtractTraceID(ctxFilho) != raiz.(*SimpleSpan).TraceID {
+		t.Error("contexto do span suprimido deveria manter o trace ID da raiz")
+	}
+}
@@ -0,0 +1,149 @@
+package tracing
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+var _ domain.DistributedTracer = (*RecordingTracer)(nil)
+
+// RecordingTracer guarda em memória os spans, tags e eventos, sem emitir
+// nada; é o tracer de testes que precisam verificar a árvore de spans.
+// Seguro para uso concorrente.
+type RecordingTracer struct {
+	mu      sync.Mutex
+	spans   []*RecordedSpan
+	traces  int
+	proximo int
+}
+
+// RecordedSpan é um span registrado pelo RecordingTracer. Os acessores
+// devolvem cópias, que não mudam com operações posteriores no span.
+type RecordedSpan struct {
+	SpanID        string
+	TraceID       string
+	ParentSpanID  string
+	OperationName string
+	Tags          map[string]interface{}
+	Events        []SpanEvent
+	Finished      bool
+	Err           error
+}
+
+// chaveSpanGravado guarda no contexto o span aberto do RecordingTracer
+type chaveSpanGravado struct{}
+
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// StartSpan registra o span; o span aberto no contexto é o pai
+func (t *RecordingTracer) StartSpan(ctx context.Context, operationName string) (context.Context, interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.proximo++
+	span := &RecordedSpan{
+		SpanID:        fmt.Sprintf("span-%d", t.proximo),
+		OperationName: operationName,
+		Tags:          make(map[string]interface{}),
+	}
+
+	if pai, ok := ctx.Value(chaveSpanGravado{}).(*RecordedSpan); ok {
+		span.TraceID = pai.TraceID
+		span.ParentSpanID = pai.SpanID
+	} else {
+		t.traces++
+		span.TraceID = fmt.Sprintf("trace-%d", t.traces)
+	}
+
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, chaveSpanGravado{}, span), span
+}
+
+// FinishSpan marca o span como finalizado, com o erro informado
+func (t *RecordingTracer) FinishSpan(span interface{}, err error) {
+	if gravado, ok := span.(*RecordedSpan); ok {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		gravado.Finished = true
+		gravado.Err = err
+	}
+}
+
+// AddTag registra a tag no span
+func (t *RecordingTracer) AddTag(span interface{}, key string, value interface{}) {
+	if gravado, ok := span.(*RecordedSpan); ok {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		gravado.Tags[key] = value
+	}
+}
+
+// AddEvent registra um evento no span
+func (t *RecordingTracer) AddEvent(span interface{}, name string, attributes map[string]interface{}) {
+	if gravado, ok := span.(*RecordedSpan); ok {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		gravado.Events = append(gravado.Events, SpanEvent{Name: name, Timestamp: time.Now(), Attributes: attributes})
+	}
+}
+
+// ExtractTraceID retorna o trace do span aberto no contexto
+func (t *RecordingTracer) ExtractTraceID(ctx context.Context) string {
+	if span, ok := ctx.Value(chaveSpanGravado{}).(*RecordedSpan); ok {
+		return span.TraceID
+	}
+	return ""
+}
+
+// Spans retorna os spans na ordem em que foram iniciados
+func (t *RecordingTracer) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(t.spans))
+	for _, span := range t.spans {
+		spans = append(spans, span.copia())
+	}
+	return spans
+}
+
+// Span retorna o primeiro span da operação
+func (t *RecordingTracer) Span(operationName string) (RecordedSpan, bool) {
+	for _, span := range t.Spans() {
+		if span.OperationName == operationName {
+			return span, true
+		}
+	}
+	return RecordedSpan{}, false
+}
+
+// Filhos retorna os spans filhos diretos do span informado, em ordem de início
+func (t *RecordingTracer) Filhos(pai RecordedSpan) []RecordedSpan {
+	var filhos []RecordedSpan
+	for _, span := range t.Spans() {
+		if span.ParentSpanID == pai.SpanID {
+			filhos = append(filhos, span)
+		}
+	}
+	return filhos
+}
+
+// Reset descarta os spans registrados
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+func (s *RecordedSpan) copia() RecordedSpan {
+	c := *s
+	c.Tags = maps.Clone(s.Tags)
+	c.Events = append([]SpanEvent(nil), s.Events...)
+	return c
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecordingTracer(t *testing.T) {
+	tracer := NewRecordingTracer()
+
+	ctx, raiz := tracer.StartSpan(context.Background(), "raiz")
+	tracer.AddTag(raiz, "cliente_id", "c1")
+	_, filho := tracer.StartSpan(ctx, "filho")
+	tracer.AddEvent(filho, "debito", map[string]interface{}{"valor": 10})
+	tracer.FinishSpan(filho, errors.New("falha"))
+	tracer.StartSpan(context.Background(), "outra")
+
+	if got := tracer.ExtractTraceID(ctx); got != "trace-1" {
+		t.Errorf("trace esperado trace-1, got %s", got)
+	}
+
+	gravadaRaiz, ok := tracer.Span("raiz")
+	if !ok || gravadaRaiz.Tags["cliente_id"] != "c1" || gravadaRaiz.Finished {
+		t.Fatalf("span raiz inesperado: %+v", gravadaRaiz)
+	}
+
+	filhos := tracer.Filhos(gravadaRaiz)
+	if len(filhos) != 1 || filhos[0].OperationName != "filho" {
+		t.Fatalf("esperado um filho, got %+v", filhos)
+	}
+	if !filhos[0].Finished || filhos[0].Err == nil || len(filhos[0].Events) != 1 {
+		t.Errorf("filho deveria estar finalizado com erro e um evento, got %+v", filhos[0])
+	}
+
+	outra, _ := tracer.Span("outra")
+	if outra.TraceID == gravadaRaiz.TraceID || outra.ParentSpanID != "" {
+		t.Errorf("span sem pai no contexto deveria abrir novo trace, got %+v", outra)
+	}
+
+	// As cópias devolvidas não mudam com operações posteriores
+	tracer.AddTag(raiz, "status", "ok")
+	if _, ok := gravadaRaiz.Tags["status"]; ok {
+		t.Error("cópia do span não deveria refletir tags posteriores")
+	}
+
+	tracer.Reset()
+	if len(tracer.Spans()) != 0 {
+		t.Error("Reset deveria descartar os spans")
+	}
+}
@@ -3,6 +3,7 @@ package tracing
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +12,51 @@ import (
 // SimpleTracer implementa domain.DistributedTracer de forma simplificada
 type SimpleTracer struct {
 	serviceName string
+	filtro      FiltroTags
+}
+
+// Option configura comportamentos opcionais do SimpleTracer
+type Option func(*SimpleTracer)
+
+// MascaraTag substitui o valor de tags mascaradas
+const MascaraTag = "***"
+
+// FiltroTags controla quais tags chegam ao span, protegendo valores
+// sensíveis (ex.: valor, cliente_id). Omitidas prevalecem sobre Mascaradas;
+// Permitidas, quando não vazia, descarta qualquer chave fora da lista.
+// O filtro vazio mantém todas as tags (padrão).
+type FiltroTags struct {
+	Permitidas []string
+	Omitidas   []string
+	Mascaradas []string
+}
+
+// WithFiltroTags aplica o filtro às tags adicionadas com AddTag
+func WithFiltroTags(filtro FiltroTags) Option {
+	return func(t *SimpleTracer) {
+		t.filtro = filtro
+	}
+}
+
+// aplicar devolve o valor a registrar para a chave e se ela deve ser mantida
+func (f FiltroTags) aplicar(key string, value interface{}) (interface{}, bool) {
+	if len(f.Permitidas) > 0 && !slices.Contains(f.Permitidas, key) {
+		return nil, false
+	}
+	if slices.Contains(f.Omitidas, key) {
+		return nil, false
+	}
+	if slices.Contains(f.Mascaradas, key) {
+		return MascaraTag, true
+	}
+	return value, true
 }
 
 // SimpleSpan representa um span de tracing simplificado
 type SimpleSpan struct {
 	TraceID       string                 `json:"trace_id"`
 	SpanID        string                 `json:"span_id"`
+	ParentSpanID  string                 `json:"parent_span_id,omitempty"`
 	OperationName string                 `json:"operation_name"`
 	StartTime     time.Time              `json:"start_time"`
 	EndTime       *time.Time             `json:"end_time,omitempty"`
@@ -33,10 +73,14 @@ type SpanEvent struct {
 	Attributes map[string]interface{} `json:"attributes"`
 }
 
-func NewSimpleTracer(serviceName string) *SimpleTracer {
-	return &SimpleTracer{
+func NewSimpleTracer(serviceName string, opts ...Option) *SimpleTracer {
+	t := &SimpleTracer{
 		serviceName: serviceName,
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 // StartSpan inicia um novo span de tracing
@@ -58,6 +102,11 @@ func (t *SimpleTracer) StartSpan(ctx context.Context, operationName string) (con
 		Status: "started",
 	}
 
+	// Span já presente no contexto é o pai (inclusive em trabalho assíncrono)
+	if pai, ok := ctx.Value("span").(*SimpleSpan); ok {
+		span.ParentSpanID = pai.SpanID
+	}
+
 	// Injeta span no contexto
 	spanCtx := context.WithValue(ctx, "span", span)
 	spanCtx = context.WithValue(spanCtx, "trace_id", traceID)
@@ -87,7 +136,9 @@ func (t *SimpleTracer) FinishSpan(span interface{}, err error) {
 // AddTag adiciona uma tag/atributo ao span
 func (t *SimpleTracer) AddTag(span interface{}, key string, value interface{}) {
 	if simpleSpan, ok := span.(*SimpleSpan); ok {
-		simpleSpan.Tags[key] = value
+		if valor, manter := t.filtro.aplicar(key, value); manter {
+			simpleSpan.Tags[key] = valor
+		}
 	}
 }
 
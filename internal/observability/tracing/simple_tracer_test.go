@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// spanEmitido finaliza o span e devolve sua representação JSON
+func spanEmitido(t *testing.T, tracer *SimpleTracer, tags map[string]interface{}) string {
+	t.Helper()
+
+	_, span := tracer.StartSpan(context.Background(), "operacao")
+	for chave, valor := range tags {
+		tracer.AddTag(span, chave, valor)
+	}
+	tracer.FinishSpan(span, nil)
+
+	saida, err := json.Marshal(span)
+	if err != nil {
+		t.Fatalf("erro ao serializar span: %v", err)
+	}
+	return string(saida)
+}
+
+func TestSimpleTracer_FiltroTags(t *testing.T) {
+	tags := map[string]interface{}{"cliente_id": "cliente-sensivel", "valor": 1234.56, "canal": "WEB"}
+
+	tests := []struct {
+		name      string
+		filtro    FiltroTags
+		presentes []string
+		ausentes  []string
+	}{
+		{
+			name:      "sem filtro mantém tudo",
+			presentes: []string{`"cliente_id":"cliente-sensivel"`, `"valor":1234.56`, `"canal":"WEB"`},
+		},
+		{
+			name:      "omitida",
+			filtro:    FiltroTags{Omitidas: []string{"cliente_id"}},
+			presentes: []string{`"valor":1234.56`, `"canal":"WEB"`},
+			ausentes:  []string{"cliente_id", "cliente-sensivel"},
+		},
+		{
+			name:      "mascarada",
+			filtro:    FiltroTags{Mascaradas: []string{"valor"}},
+			presentes: []string{`"valor":"***"`},
+			ausentes:  []string{"1234.56"},
+		},
+		{
+			name:     "omitida prevalece sobre mascarada",
+			filtro:   FiltroTags{Omitidas: []string{"valor"}, Mascaradas: []string{"valor"}},
+			ausentes: []string{`"valor"`},
+		},
+		{
+			name:      "apenas permitidas",
+			filtro:    FiltroTags{Permitidas: []string{"canal"}},
+			presentes: []string{`"canal":"WEB"`},
+			ausentes:  []string{"cliente_id", `"valor"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saida := spanEmitido(t, NewSimpleTracer("teste", WithFiltroTags(tt.filtro)), tags)
+
+			for _, esperado := range tt.presentes {
+				if !strings.Contains(saida, esperado) {
+					t.Errorf("span deveria conter %s: %s", esperado, saida)
+				}
+			}
+			for _, proibido := range tt.ausentes {
+				if strings.Contains(saida, proibido) {
+					t.Errorf("span não deveria conter %s: %s", proibido, saida)
+				}
+			}
+		})
+	}
+}
+
+func TestSimpleTracer_SpanFilhoHerdaTraceEPai(t *testing.T) {
+	tracer := NewSimpleTracer("teste")
+
+	ctx, raiz := tracer.StartSpan(context.Background(), "requisicao")
+	// Trabalho assíncrono desvinculado do cancelamento da requisição
+	_, filho := tracer.StartSpan(context.WithoutCancel(ctx), "publicacao")
+
+	pai, span := raiz.(*SimpleSpan), filho.(*SimpleSpan)
+	if span.TraceID != pai.TraceID {
+		t.Errorf("trace esperado %s, got %s", pai.TraceID, span.TraceID)
+	}
+	if span.ParentSpanID != pai.SpanID {
+		t.Errorf("span pai esperado %s, got %s", pai.SpanID, span.ParentSpanID)
+	}
+	if pai.ParentSpanID != "" {
+		t.Errorf("span raiz não deveria ter pai, got %s", pai.ParentSpanID)
+	}
+}
@@ -0,0 +1,42 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestLimiteRepository_ComMockDaAPI(t *testing.T) {
+	mock := &mockDynamoDB{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{
+				"id":             &types.AttributeValueMemberS{Value: "c1"},
+				"limite_credito": &types.AttributeValueMemberN{Value: "10000"},
+				"limite_atual":   &types.AttributeValueMemberN{Value: "10000"},
+			}}, nil
+		},
+		updateItem: func(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+			if *input.TableName != "clientes" {
+				t.Errorf("tabela esperada clientes, got %s", *input.TableName)
+			}
+			return nil, &types.ConditionalCheckFailedException{}
+		},
+	}
+	repo := NewLimiteRepository(mock, "clientes")
+
+	cliente, err := repo.GetCliente(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if cliente.LimiteAtual != 10000 {
+		t.Errorf("LimiteAtual esperado 10000, got %d", cliente.LimiteAtual)
+	}
+
+	// Condição falhou com o cliente existente e saldo abaixo do valor
+	if _, err := repo.DebitarLimiteAtomica(context.Background(), "c1", 20000); err != domain.ErrLimiteInsuficiente {
+		t.Errorf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+}
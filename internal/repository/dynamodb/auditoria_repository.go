@@ -0,0 +1,204 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// AuditoriaRepository implementa domain.AuditRepository numa tabela só de
+// inclusão (chave cliente_id + registro). Cada entrada é gravada com
+// attribute_not_exists: nada é sobrescrito. Com WithCadeiaHashAuditoria, as
+// entradas do cliente formam uma cadeia de hashes cuja ponta fica no item
+// registro=HEAD, atualizado na mesma transação da entrada.
+type AuditoriaRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opcoes    opcoes
+}
+
+// registroCabecaAuditoria é a chave de ordenação do item com o último hash
+const registroCabecaAuditoria = "HEAD"
+
+// formatoRegistroAuditoria tem largura fixa, para que a chave de ordenação
+// siga a ordem cronológica
+const formatoRegistroAuditoria = "2006-01-02T15:04:05.000000000Z"
+
+// maxTentativasCadeia limita as regravações quando outra decisão do mesmo
+// cliente avança a cadeia entre a leitura da ponta e a gravação
+const maxTentativasCadeia = 3
+
+// errCadeiaConcorrente indica que a ponta da cadeia mudou durante a gravação
+var errCadeiaConcorrente = errors.New("cadeia de auditoria alterada concorrentemente")
+
+func NewAuditoriaRepository(client DynamoDBAPI, tableName string, opts ...Option) *AuditoriaRepository {
+	return &AuditoriaRepository{
+		client:    client,
+		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
+	}
+}
+
+// RecordDecision grava a entrada; uma entrada já existente com a mesma chave
+// não é sobrescrita e resulta em erro
+func (r *AuditoriaRepository) RecordDecision(ctx context.Context, entry domain.AuditEntry) error {
+	if !r.opcoes.cadeiaAuditoria {
+		return r.gravarEntrada(ctx, &entry)
+	}
+
+	for tentativa := 1; ; tentativa++ {
+		err := r.gravarEncadeada(ctx, entry)
+		if !errors.Is(err, errCadeiaConcorrente) || tentativa == maxTentativasCadeia {
+			return err
+		}
+	}
+}
+
+func (r *AuditoriaRepository) gravarEntrada(ctx context.Context, entry *domain.AuditEntry) error {
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                itemAuditoria(entry),
+		ConditionExpression: aws.String("attribute_not_exists(registro)"),
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return r.client.PutItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("entrada de auditoria %s já registrada", registroAuditoria(entry))
+		}
+		return fmt.Errorf("erro ao gravar entrada de auditoria: %w", err)
+	}
+
+	return nil
+}
+
+// gravarEncadeada lê a ponta da cadeia do cliente, encadeia a entrada e grava
+// entrada e nova ponta atomicamente, condicionada à ponta lida
+func (r *AuditoriaRepository) gravarEncadeada(ctx context.Context, entry domain.AuditEntry) error {
+	hashAnterior, existe, err := r.pontaCadeia(ctx, entry.ClienteID)
+	if err != nil {
+		return err
+	}
+	entry.Encadear(hashAnterior)
+
+	cabeca := &types.Put{
+		TableName: aws.String(r.tableName),
+		Item: map[string]types.AttributeValue{
+			"cliente_id":      &types.AttributeValueMemberS{Value: entry.ClienteID},
+			"registro":        &types.AttributeValueMemberS{Value: registroCabecaAuditoria},
+			"hash":            &types.AttributeValueMemberS{Value: entry.Hash},
+			"ultimo_registro": &types.AttributeValueMemberS{Value: registroAuditoria(&entry)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(registro)"),
+	}
+	if existe {
+		cabeca.ConditionExpression = aws.String("#hash = :anterior")
+		cabeca.ExpressionAttributeNames = map[string]string{"#hash": "hash"}
+		cabeca.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":anterior": &types.AttributeValueMemberS{Value: hashAnterior},
+		}
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           aws.String(r.tableName),
+				Item:                itemAuditoria(&entry),
+				ConditionExpression: aws.String("attribute_not_exists(registro)"),
+			}},
+			{Put: cabeca},
+		},
+	}
+
+	_, err = executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "TransactWriteItems", func(ctx context.Context) (*dynamodb.TransactWriteItemsOutput, error) {
+		return r.client.TransactWriteItems(ctx, input)
+	})
+	if err != nil {
+		// Razões na ordem dos itens: entrada, ponta da cadeia
+		var cancelada *types.TransactionCanceledException
+		if errors.As(err, &cancelada) && len(cancelada.CancellationReasons) == 2 {
+			if aws.ToString(cancelada.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+				return fmt.Errorf("entrada de auditoria %s já registrada", registroAuditoria(&entry))
+			}
+			if aws.ToString(cancelada.CancellationReasons[1].Code) == "ConditionalCheckFailed" {
+				return errCadeiaConcorrente
+			}
+		}
+		return fmt.Errorf("erro ao gravar entrada de auditoria: %w", err)
+	}
+
+	return nil
+}
+
+// pontaCadeia devolve o hash da última entrada do cliente e se a ponta existe
+func (r *AuditoriaRepository) pontaCadeia(ctx context.Context, clienteID string) (string, bool, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"cliente_id": &types.AttributeValueMemberS{Value: clienteID},
+			"registro":   &types.AttributeValueMemberS{Value: registroCabecaAuditoria},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.GetItem, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return r.client.GetItem(ctx, input)
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("erro ao ler ponta da cadeia de auditoria: %w", err)
+	}
+	if result.Item == nil {
+		return "", false, nil
+	}
+
+	hash, ok := result.Item["hash"].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", false, fmt.Errorf("ponta da cadeia de auditoria do cliente %s sem hash: %w", clienteID, domain.ErrItensCorrompidos)
+	}
+	return hash.Value, true, nil
+}
+
+// registroAuditoria é a chave de ordenação da entrada: instante da decisão
+// seguido do ID da transação
+func registroAuditoria(entry *domain.AuditEntry) string {
+	return entry.Timestamp.UTC().Format(formatoRegistroAuditoria) + "#" + entry.TransacaoID
+}
+
+func itemAuditoria(entry *domain.AuditEntry) map[string]types.AttributeValue {
+	item := map[string]types.AttributeValue{
+		"cliente_id":     &types.AttributeValueMemberS{Value: entry.ClienteID},
+		"registro":       &types.AttributeValueMemberS{Value: registroAuditoria(entry)},
+		"transacao_id":   &types.AttributeValueMemberS{Value: entry.TransacaoID},
+		"valor_centavos": &types.AttributeValueMemberN{Value: strconv.FormatInt(entry.ValorCentavos, 10)},
+		"moeda":          &types.AttributeValueMemberS{Value: entry.Moeda},
+		"parcelas":       &types.AttributeValueMemberN{Value: strconv.Itoa(entry.Parcelas)},
+		"decisao":        &types.AttributeValueMemberS{Value: entry.Decisao},
+		"ator":           &types.AttributeValueMemberS{Value: entry.Ator},
+		"timestamp":      &types.AttributeValueMemberS{Value: entry.Timestamp.UTC().Format(formatoRegistroAuditoria)},
+	}
+
+	opcionais := map[string]string{
+		"canal":          entry.Canal,
+		"tipo":           entry.Tipo,
+		"correlation_id": entry.CorrelationID,
+		"codigo_motivo":  entry.CodigoMotivo,
+		"hash_anterior":  entry.HashAnterior,
+		"hash":           entry.Hash,
+	}
+	for nome, valor := range opcionais {
+		if valor != "" {
+			item[nome] = &types.AttributeValueMemberS{Value: valor}
+		}
+	}
+
+	return item
+}
@@ -0,0 +1,230 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// tabelaAuditoria é uma tabela em memória (cliente_id + registro) que avalia
+// as condições usadas pelo AuditoriaRepository. antesDaTransacao roda antes
+// de cada TransactWriteItems (ex.: simular outra decisão concorrente).
+type tabelaAuditoria struct {
+	DynamoDBAPI
+	mu               sync.Mutex
+	itens            map[string]map[string]types.AttributeValue
+	antesDaTransacao func(t *tabelaAuditoria)
+}
+
+func newTabelaAuditoria() *tabelaAuditoria {
+	return &tabelaAuditoria{itens: make(map[string]map[string]types.AttributeValue)}
+}
+
+func chaveItemAuditoria(item map[string]types.AttributeValue) string {
+	return item["cliente_id"].(*types.AttributeValueMemberS).Value + "|" + item["registro"].(*types.AttributeValueMemberS).Value
+}
+
+// condicaoAtendida avalia attribute_not_exists(registro) e #hash = :anterior
+func (t *tabelaAuditoria) condicaoAtendida(put *types.Put) bool {
+	atual, existe := t.itens[chaveItemAuditoria(put.Item)]
+	switch aws.ToString(put.ConditionExpression) {
+	case "attribute_not_exists(registro)":
+		return !existe
+	case "#hash = :anterior":
+		return existe && atributoSDe(atual, "hash") == put.ExpressionAttributeValues[":anterior"].(*types.AttributeValueMemberS).Value
+	}
+	return true
+}
+
+func (t *tabelaAuditoria) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: t.itens[chaveItemAuditoria(params.Key)]}, nil
+}
+
+func (t *tabelaAuditoria) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	put := &types.Put{Item: params.Item, ConditionExpression: params.ConditionExpression}
+	if !t.condicaoAtendida(put) {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("falha simulada")}
+	}
+	t.itens[chaveItemAuditoria(params.Item)] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (t *tabelaAuditoria) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	if t.antesDaTransacao != nil {
+		t.antesDaTransacao(t)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	razoes := make([]types.CancellationReason, len(params.TransactItems))
+	cancelada := false
+	for i, item := range params.TransactItems {
+		razoes[i].Code = aws.String("None")
+		if !t.condicaoAtendida(item.Put) {
+			razoes[i].Code = aws.String("ConditionalCheckFailed")
+			cancelada = true
+		}
+	}
+	if cancelada {
+		return nil, &types.TransactionCanceledException{Message: aws.String("falha simulada"), CancellationReasons: razoes}
+	}
+
+	for _, item := range params.TransactItems {
+		t.itens[chaveItemAuditoria(item.Put.Item)] = item.Put.Item
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// entradas devolve as entradas do cliente em ordem de registro, sem a ponta
+func (t *tabelaAuditoria) entradas(clienteID string) []map[string]types.AttributeValue {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var chaves []string
+	for chave := range t.itens {
+		if strings.HasPrefix(chave, clienteID+"|") && !strings.HasSuffix(chave, "|"+registroCabecaAuditoria) {
+			chaves = append(chaves, chave)
+		}
+	}
+	slices.Sort(chaves)
+
+	var itens []map[string]types.AttributeValue
+	for _, chave := range chaves {
+		itens = append(itens, t.itens[chave])
+	}
+	return itens
+}
+
+func atributoSDe(item map[string]types.AttributeValue, nome string) string {
+	if valor, ok := item[nome].(*types.AttributeValueMemberS); ok {
+		return valor.Value
+	}
+	return ""
+}
+
+func entradaAuditoria(transacaoID string, instante time.Time) domain.AuditEntry {
+	return domain.AuditEntry{
+		TransacaoID:   transacaoID,
+		ClienteID:     "c1",
+		ValorCentavos: 1000,
+		Moeda:         "BRL",
+		Decisao:       domain.StatusAprovada,
+		Ator:          "transaction-authorizer",
+		Timestamp:     instante,
+	}
+}
+
+func TestAuditoriaRepository_SomenteInclusao(t *testing.T) {
+	tabela := newTabelaAuditoria()
+	repo := NewAuditoriaRepository(tabela, "auditoria")
+	instante := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	entrada := entradaAuditoria("tx-1", instante)
+	entrada.Decisao = domain.StatusRejeitada
+	entrada.CodigoMotivo = "insufficient_limit"
+	if err := repo.RecordDecision(context.Background(), entrada); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	gravadas := tabela.entradas("c1")
+	if len(gravadas) != 1 {
+		t.Fatalf("esperada uma entrada, got %d", len(gravadas))
+	}
+	item := gravadas[0]
+	if atributoSDe(item, "registro") != "2024-01-15T10:30:00.000000000Z#tx-1" ||
+		atributoSDe(item, "decisao") != domain.StatusRejeitada ||
+		atributoSDe(item, "codigo_motivo") != "insufficient_limit" ||
+		atributoSDe(item, "ator") != "transaction-authorizer" {
+		t.Errorf("entrada gravada inesperada: %v", item)
+	}
+	if _, ok := item["hash"]; ok {
+		t.Error("sem cadeia de hashes, a entrada não deveria ter hash")
+	}
+
+	// A mesma entrada não é sobrescrita
+	entrada.Decisao = domain.StatusAprovada
+	if err := repo.RecordDecision(context.Background(), entrada); err == nil {
+		t.Error("regravar a mesma entrada deveria falhar")
+	}
+	if atributoSDe(tabela.entradas("c1")[0], "decisao") != domain.StatusRejeitada {
+		t.Error("entrada original não deveria ser alterada")
+	}
+}
+
+func TestAuditoriaRepository_CadeiaDeHashes(t *testing.T) {
+	tabela := newTabelaAuditoria()
+	repo := NewAuditoriaRepository(tabela, "auditoria", WithCadeiaHashAuditoria())
+	instante := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	for i, id := range []string{"tx-1", "tx-2", "tx-3"} {
+		if err := repo.RecordDecision(context.Background(), entradaAuditoria(id, instante.Add(time.Duration(i)*time.Second))); err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	}
+
+	gravadas := tabela.entradas("c1")
+	if len(gravadas) != 3 {
+		t.Fatalf("esperadas 3 entradas, got %d", len(gravadas))
+	}
+
+	anterior := ""
+	for i, item := range gravadas {
+		if atributoSDe(item, "hash_anterior") != anterior {
+			t.Errorf("entrada %d deveria apontar para o hash anterior %q, got %q", i, anterior, atributoSDe(item, "hash_anterior"))
+		}
+
+		// O hash é recalculável a partir dos campos gravados
+		recalculada := entradaAuditoria(atributoSDe(item, "transacao_id"), instante.Add(time.Duration(i)*time.Second))
+		recalculada.Encadear(anterior)
+		if atributoSDe(item, "hash") != recalculada.Hash {
+			t.Errorf("hash da entrada %d não confere", i)
+		}
+		anterior = atributoSDe(item, "hash")
+	}
+
+	cabeca, _ := tabela.GetItem(context.Background(), &dynamodb.GetItemInput{Key: map[string]types.AttributeValue{
+		"cliente_id": &types.AttributeValueMemberS{Value: "c1"},
+		"registro":   &types.AttributeValueMemberS{Value: registroCabecaAuditoria},
+	}})
+	if atributoSDe(cabeca.Item, "hash") != anterior {
+		t.Error("ponta da cadeia deveria ter o hash da última entrada")
+	}
+}
+
+func TestAuditoriaRepository_CadeiaConcorrenteRegravaNaPonta(t *testing.T) {
+	tabela := newTabelaAuditoria()
+	repo := NewAuditoriaRepository(tabela, "auditoria", WithCadeiaHashAuditoria())
+	instante := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	// Outra decisão do cliente avança a cadeia entre a leitura da ponta e a gravação
+	concorrente := NewAuditoriaRepository(tabela, "auditoria", WithCadeiaHashAuditoria())
+	tabela.antesDaTransacao = func(t *tabelaAuditoria) {
+		t.antesDaTransacao = nil
+		concorrente.RecordDecision(context.Background(), entradaAuditoria("tx-concorrente", instante))
+	}
+
+	if err := repo.RecordDecision(context.Background(), entradaAuditoria("tx-1", instante.Add(time.Second))); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	gravadas := tabela.entradas("c1")
+	if len(gravadas) != 2 {
+		t.Fatalf("esperadas 2 entradas, got %d", len(gravadas))
+	}
+	if atributoSDe(gravadas[1], "hash_anterior") != atributoSDe(gravadas[0], "hash") {
+		t.Error("entrada regravada deveria se encadear à entrada concorrente")
+	}
+}
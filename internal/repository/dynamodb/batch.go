@@ -0,0 +1,87 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// tamanhoLoteBatchGet é o máximo de chaves aceito por BatchGetItem
+const tamanhoLoteBatchGet = 100
+
+// maxTentativasBatchGet limita as retentativas de UnprocessedKeys por lote
+const maxTentativasBatchGet = 5
+
+// batchGet busca os itens cuja chave de partição (atributo S) está em ids,
+// em lotes de 100 chaves, retentando UnprocessedKeys com backoff exponencial.
+// IDs duplicados são ignorados (BatchGetItem rejeita chaves repetidas).
+func batchGet(ctx context.Context, client DynamoDBAPI, tableName, chave string, ids []string, o *opcoes) ([]map[string]types.AttributeValue, error) {
+	unicos := make([]string, 0, len(ids))
+	vistos := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !vistos[id] {
+			vistos[id] = true
+			unicos = append(unicos, id)
+		}
+	}
+
+	itens := make([]map[string]types.AttributeValue, 0, len(unicos))
+	for inicio := 0; inicio < len(unicos); inicio += tamanhoLoteBatchGet {
+		fim := min(inicio+tamanhoLoteBatchGet, len(unicos))
+
+		lote, err := batchGetLote(ctx, client, tableName, chave, unicos[inicio:fim], o)
+		if err != nil {
+			return nil, err
+		}
+		itens = append(itens, lote...)
+	}
+
+	return itens, nil
+}
+
+// batchGetLote busca um lote de até 100 chaves, retentando as não processadas
+func batchGetLote(ctx context.Context, client DynamoDBAPI, tableName, chave string, ids []string, o *opcoes) ([]map[string]types.AttributeValue, error) {
+	chaves := make([]map[string]types.AttributeValue, 0, len(ids))
+	for _, id := range ids {
+		chaves = append(chaves, map[string]types.AttributeValue{
+			chave: &types.AttributeValueMemberS{Value: id},
+		})
+	}
+
+	pendentes := map[string]types.KeysAndAttributes{
+		tableName: {Keys: chaves, ConsistentRead: aws.Bool(true)},
+	}
+
+	var itens []map[string]types.AttributeValue
+	for tentativa := 0; len(pendentes) > 0; tentativa++ {
+		if tentativa == maxTentativasBatchGet {
+			return nil, fmt.Errorf("chaves não processadas após %d tentativas", maxTentativasBatchGet)
+		}
+
+		if tentativa > 0 {
+			// Backoff exponencial: 10ms, 20ms, 40ms...
+			select {
+			case <-time.After(time.Duration(10<<(tentativa-1)) * time.Millisecond):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		input := &dynamodb.BatchGetItemInput{RequestItems: pendentes}
+		result, err := executar(ctx, o, o.timeouts.BatchGetItem, "BatchGetItem", func(ctx context.Context) (*dynamodb.BatchGetItemOutput, error) {
+			return client.BatchGetItem(ctx, input)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		itens = append(itens, result.Responses[tableName]...)
+		pendentes = result.UnprocessedKeys
+	}
+
+	return itens, nil
+}
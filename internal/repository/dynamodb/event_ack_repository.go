@@ -0,0 +1,78 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// EventAckRepository guarda as confirmações de publicação de eventos,
+// uma por transação (chave "transacao_id")
+type EventAckRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opcoes    opcoes
+}
+
+type EventAckItem struct {
+	TransacaoID  string `dynamodbav:"transacao_id"`
+	ConfirmadoEm string `dynamodbav:"confirmado_em"`
+	TTL          int64  `dynamodbav:"ttl"`
+}
+
+func NewEventAckRepository(client DynamoDBAPI, tableName string, opts ...Option) *EventAckRepository {
+	return &EventAckRepository{
+		client:    client,
+		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
+	}
+}
+
+// RegistrarConfirmacao grava a confirmação (idempotente: sobrescreve a anterior)
+func (r *EventAckRepository) RegistrarConfirmacao(ctx context.Context, transacaoID string) error {
+	agora := time.Now().UTC()
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item: map[string]types.AttributeValue{
+			"transacao_id":  &types.AttributeValueMemberS{Value: transacaoID},
+			"confirmado_em": &types.AttributeValueMemberS{Value: agora.Format(time.RFC3339)},
+			// Mesma retenção das transações (90 dias)
+			"ttl": &types.AttributeValueMemberN{Value: strconv.FormatInt(agora.Unix()+90*24*60*60, 10)},
+		},
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return r.client.PutItem(ctx, input)
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao registrar confirmação do evento %s: %w", transacaoID, err)
+	}
+
+	return nil
+}
+
+// Confirmados retorna quais transações possuem confirmação registrada
+func (r *EventAckRepository) Confirmados(ctx context.Context, transacaoIDs []string) (map[string]bool, error) {
+	itens, err := batchGet(ctx, r.client, r.tableName, "transacao_id", transacaoIDs, &r.opcoes)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar confirmações de eventos: %w", err)
+	}
+
+	confirmados := make(map[string]bool, len(itens))
+	for _, av := range itens {
+		var item EventAckItem
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return nil, fmt.Errorf("erro ao deserializar confirmação: %w", err)
+		}
+		confirmados[item.TransacaoID] = true
+	}
+
+	return confirmados, nil
+}
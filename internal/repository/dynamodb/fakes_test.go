@@ -0,0 +1,127 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// fakeHTTPClient simula o DynamoDB: aguarda o atraso configurado
+// (respeitando o cancelamento do contexto), registra a requisição e devolve
+// o corpo JSON informado (ou o gerado por responder, quando definido).
+// Operações presentes em excecoes falham com HTTP 400 e o tipo informado.
+// aposResposta, quando definido, roda depois de cada resposta pronta.
+type fakeHTTPClient struct {
+	atraso       time.Duration
+	corpo        string
+	responder    func(operacao string, payload map[string]interface{}) string
+	excecoes     map[string]string
+	aposResposta func(operacao string)
+
+	mu          sync.Mutex
+	requisicoes []requisicaoCapturada
+}
+
+// requisicaoCapturada guarda a operação (X-Amz-Target) e o payload enviado
+type requisicaoCapturada struct {
+	operacao string
+	payload  map[string]interface{}
+}
+
+func (c *fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	corpo := c.corpo
+	status := http.StatusOK
+
+	operacao := ""
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		var payload map[string]interface{}
+		json.Unmarshal(body, &payload)
+
+		operacao = req.Header.Get("X-Amz-Target")
+		operacao = operacao[strings.LastIndex(operacao, ".")+1:]
+
+		c.mu.Lock()
+		c.requisicoes = append(c.requisicoes, requisicaoCapturada{operacao: operacao, payload: payload})
+		if c.responder != nil {
+			corpo = c.responder(operacao, payload)
+		}
+		if excecao, ok := c.excecoes[operacao]; ok {
+			status = http.StatusBadRequest
+			corpo = `{"__type":"com.amazonaws.dynamodb.v20120810#` + excecao + `","message":"falha simulada"}`
+		}
+		c.mu.Unlock()
+	}
+
+	select {
+	case <-time.After(c.atraso):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	if c.aposResposta != nil {
+		c.aposResposta(operacao)
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(corpo)),
+		Request:    req,
+	}, nil
+}
+
+// ultimaRequisicao retorna a última requisição capturada
+func (c *fakeHTTPClient) ultimaRequisicao() requisicaoCapturada {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requisicoes[len(c.requisicoes)-1]
+}
+
+func newFakeDynamoClient(httpClient *fakeHTTPClient) *dynamodb.Client {
+	return dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		Credentials:      aws.AnonymousCredentials{},
+		HTTPClient:       httpClient,
+		RetryMaxAttempts: 1,
+	})
+}
+
+// mockDynamoDB implementa DynamoDBAPI sem passar pelo SDK: cada operação
+// delega à função configurada; operações não configuradas causam panic
+type mockDynamoDB struct {
+	DynamoDBAPI
+	getItem    func(*dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
+	updateItem func(*dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error)
+}
+
+func (m *mockDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return m.getItem(params)
+}
+
+func (m *mockDynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return m.updateItem(params)
+}
+
+// registroAvisos guarda mensagens e campos de Warn; demais níveis são descartados
+type registroAvisos struct {
+	mu     sync.Mutex
+	avisos []map[string]interface{}
+}
+
+func (l *registroAvisos) Info(ctx context.Context, msg string, fields map[string]interface{}) {}
+func (l *registroAvisos) Error(ctx context.Context, msg string, err error, fields map[string]interface{}) {
+}
+func (l *registroAvisos) Debug(ctx context.Context, msg string, fields map[string]interface{}) {}
+func (l *registroAvisos) Warn(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.avisos = append(l.avisos, fields)
+}
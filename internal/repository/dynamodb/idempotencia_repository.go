@@ -0,0 +1,199 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// IdempotenciaRepository implementa domain.IdempotencyStore (chave "chave").
+// A expiração usa o TTL nativo do DynamoDB no atributo "ttl"; como a remoção
+// é assíncrona, a leitura também descarta itens já vencidos.
+type IdempotenciaRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opcoes    opcoes
+}
+
+type IdempotenciaItem struct {
+	Chave            string `dynamodbav:"chave"`
+	TransacaoID      string `dynamodbav:"transacao_id"`
+	Status           string `dynamodbav:"status"`
+	CodigoMotivo     string `dynamodbav:"codigo_motivo,omitempty"`
+	LimiteDisponivel *int   `dynamodbav:"limite_disponivel,omitempty"`
+	HashPayload      string `dynamodbav:"hash_payload,omitempty"`
+	TTL              int64  `dynamodbav:"ttl"`
+}
+
+func NewIdempotenciaRepository(client DynamoDBAPI, tableName string, opts ...Option) *IdempotenciaRepository {
+	return &IdempotenciaRepository{
+		client:    client,
+		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
+	}
+}
+
+// Get busca o registro da chave; (nil, nil) se não existir ou tiver expirado
+func (r *IdempotenciaRepository) Get(ctx context.Context, chave string) (*domain.RegistroIdempotencia, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"chave": &types.AttributeValueMemberS{Value: chave},
+		},
+		// Uma repetição logo após a requisição original precisa ver o registro
+		ConsistentRead: aws.Bool(true),
+	}
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.GetItem, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return r.client.GetItem(ctx, input)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar registro de idempotência: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item IdempotenciaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar registro de idempotência: %w", err)
+	}
+
+	if item.TTL <= time.Now().Unix() {
+		return nil, nil
+	}
+
+	return &domain.RegistroIdempotencia{
+		Chave:            item.Chave,
+		TransacaoID:      item.TransacaoID,
+		Status:           item.Status,
+		CodigoMotivo:     item.CodigoMotivo,
+		LimiteDisponivel: item.LimiteDisponivel,
+		HashPayload:      item.HashPayload,
+	}, nil
+}
+
+// Reservar grava a reserva da chave com escrita condicional: falha com
+// domain.ErrReservaIdempotencia se a chave existir e ainda não tiver expirado
+func (r *IdempotenciaRepository) Reservar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	agora := time.Now()
+	item := map[string]types.AttributeValue{
+		"chave":        &types.AttributeValueMemberS{Value: registro.Chave},
+		"transacao_id": &types.AttributeValueMemberS{Value: registro.TransacaoID},
+		"status":       &types.AttributeValueMemberS{Value: registro.Status},
+		"ttl":          &types.AttributeValueMemberN{Value: strconv.FormatInt(agora.Add(ttl).Unix(), 10)},
+	}
+	if registro.HashPayload != "" {
+		item["hash_payload"] = &types.AttributeValueMemberS{Value: registro.HashPayload}
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+		// Itens vencidos ainda não removidos pelo TTL podem ser substituídos
+		ConditionExpression:      aws.String("attribute_not_exists(chave) OR #ttl <= :agora"),
+		ExpressionAttributeNames: map[string]string{"#ttl": "ttl"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":agora": &types.AttributeValueMemberN{Value: strconv.FormatInt(agora.Unix(), 10)},
+		},
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return r.client.PutItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return domain.ErrReservaIdempotencia
+		}
+		return fmt.Errorf("erro ao reservar chave de idempotência: %w", err)
+	}
+
+	return nil
+}
+
+// Finalizar grava o desfecho com expiração em agora + ttl, condicionado à
+// reserva da mesma transação ainda em processamento
+func (r *IdempotenciaRepository) Finalizar(ctx context.Context, registro *domain.RegistroIdempotencia, ttl time.Duration) error {
+	atribuicoes := []string{"#status = :status", "#ttl = :ttl"}
+	valores := map[string]types.AttributeValue{
+		":status":           &types.AttributeValueMemberS{Value: registro.Status},
+		":ttl":              &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)},
+		":em_processamento": &types.AttributeValueMemberS{Value: domain.StatusIdempotenciaEmProcessamento},
+		":transacao_id":     &types.AttributeValueMemberS{Value: registro.TransacaoID},
+	}
+	if registro.CodigoMotivo != "" {
+		atribuicoes = append(atribuicoes, "codigo_motivo = :codigo_motivo")
+		valores[":codigo_motivo"] = &types.AttributeValueMemberS{Value: registro.CodigoMotivo}
+	}
+	if registro.HashPayload != "" {
+		atribuicoes = append(atribuicoes, "hash_payload = :hash_payload")
+		valores[":hash_payload"] = &types.AttributeValueMemberS{Value: registro.HashPayload}
+	}
+	if registro.LimiteDisponivel != nil {
+		atribuicoes = append(atribuicoes, "limite_disponivel = :limite_disponivel")
+		valores[":limite_disponivel"] = &types.AttributeValueMemberN{Value: strconv.Itoa(*registro.LimiteDisponivel)}
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"chave": &types.AttributeValueMemberS{Value: registro.Chave},
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(atribuicoes, ", ")),
+		ConditionExpression:       aws.String(condicaoReservada),
+		ExpressionAttributeNames:  map[string]string{"#status": "status", "#ttl": "ttl"},
+		ExpressionAttributeValues: valores,
+	}
+
+	return r.atualizarReserva(ctx, input, "erro ao gravar registro de idempotência")
+}
+
+// Liberar expira a reserva da transação, que o Get passa a ignorar e uma
+// nova reserva pode substituir
+func (r *IdempotenciaRepository) Liberar(ctx context.Context, chave, transacaoID string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"chave": &types.AttributeValueMemberS{Value: chave},
+		},
+		UpdateExpression:         aws.String("SET #ttl = :ttl"),
+		ConditionExpression:      aws.String(condicaoReservada),
+		ExpressionAttributeNames: map[string]string{"#status": "status", "#ttl": "ttl"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ttl":              &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+			":em_processamento": &types.AttributeValueMemberS{Value: domain.StatusIdempotenciaEmProcessamento},
+			":transacao_id":     &types.AttributeValueMemberS{Value: transacaoID},
+		},
+	}
+
+	return r.atualizarReserva(ctx, input, "erro ao liberar chave de idempotência")
+}
+
+// condicaoReservada exige a reserva em processamento da própria transação
+const condicaoReservada = "#status = :em_processamento AND transacao_id = :transacao_id"
+
+func (r *IdempotenciaRepository) atualizarReserva(ctx context.Context, input *dynamodb.UpdateItemInput, mensagem string) error {
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return domain.ErrReservaIdempotencia
+		}
+		return fmt.Errorf("%s: %w", mensagem, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestIdempotenciaRepository_Reservar(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewIdempotenciaRepository(newFakeDynamoClient(httpClient), "idempotencia")
+
+	registro := &domain.RegistroIdempotencia{Chave: "k", TransacaoID: "t1", Status: domain.StatusIdempotenciaEmProcessamento, HashPayload: "h1"}
+	if err := repo.Reservar(context.Background(), registro, time.Hour); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	payload := httpClient.ultimaRequisicao().payload
+	item := payload["Item"].(map[string]interface{})
+	if atributoS(item, "chave") != "k" || atributoS(item, "status") != domain.StatusIdempotenciaEmProcessamento || atributoS(item, "hash_payload") != "h1" {
+		t.Errorf("item inesperado: %v", item)
+	}
+	if cond := payload["ConditionExpression"]; cond != "attribute_not_exists(chave) OR #ttl <= :agora" {
+		t.Errorf("reserva deveria ser condicional à chave inexistente ou vencida, got %v", cond)
+	}
+
+	ttl, _ := strconv.ParseInt(item["ttl"].(map[string]interface{})["N"].(string), 10, 64)
+	if esperado := time.Now().Add(time.Hour).Unix(); ttl < esperado-5 || ttl > esperado+5 {
+		t.Errorf("ttl esperado ~%d, got %d", esperado, ttl)
+	}
+}
+
+func TestIdempotenciaRepository_ReservarChaveExistente(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}", excecoes: map[string]string{"PutItem": "ConditionalCheckFailedException"}}
+	repo := NewIdempotenciaRepository(newFakeDynamoClient(httpClient), "idempotencia")
+
+	registro := &domain.RegistroIdempotencia{Chave: "k", TransacaoID: "t2", Status: domain.StatusIdempotenciaEmProcessamento}
+	if err := repo.Reservar(context.Background(), registro, time.Hour); err != domain.ErrReservaIdempotencia {
+		t.Fatalf("erro esperado %v, got %v", domain.ErrReservaIdempotencia, err)
+	}
+}
+
+func TestIdempotenciaRepository_Finalizar(t *testing.T) {
+	tests := []struct {
+		name     string
+		excecoes map[string]string
+		espera   error
+	}{
+		{name: "reserva da transação"},
+		{name: "reserva perdida", excecoes: map[string]string{"UpdateItem": "ConditionalCheckFailedException"}, espera: domain.ErrReservaIdempotencia},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: "{}", excecoes: tt.excecoes}
+			repo := NewIdempotenciaRepository(newFakeDynamoClient(httpClient), "idempotencia")
+
+			limite := 9000
+			registro := &domain.RegistroIdempotencia{Chave: "k", TransacaoID: "t1", Status: domain.StatusAprovada, LimiteDisponivel: &limite, HashPayload: "h1"}
+			if err := repo.Finalizar(context.Background(), registro, time.Hour); err != tt.espera {
+				t.Fatalf("erro esperado %v, got %v", tt.espera, err)
+			}
+
+			payload := httpClient.ultimaRequisicao().payload
+			if cond := payload["ConditionExpression"]; cond != condicaoReservada {
+				t.Errorf("finalização deveria ser condicional à reserva, got %v", cond)
+			}
+			valores := payload["ExpressionAttributeValues"].(map[string]interface{})
+			if atributoS(valores, ":status") != domain.StatusAprovada || atributoS(valores, ":transacao_id") != "t1" {
+				t.Errorf("valores inesperados: %v", valores)
+			}
+			if _, ok := valores[":codigo_motivo"]; ok {
+				t.Errorf("codigo_motivo vazio não deveria ser gravado: %v", valores)
+			}
+		})
+	}
+}
+
+func TestIdempotenciaRepository_Liberar(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewIdempotenciaRepository(newFakeDynamoClient(httpClient), "idempotencia")
+
+	if err := repo.Liberar(context.Background(), "k", "t1"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	payload := httpClient.ultimaRequisicao().payload
+	if payload["ConditionExpression"] != condicaoReservada {
+		t.Errorf("liberação deveria ser condicional à reserva, got %v", payload["ConditionExpression"])
+	}
+	valores := payload["ExpressionAttributeValues"].(map[string]interface{})
+	ttl, _ := strconv.ParseInt(valores[":ttl"].(map[string]interface{})["N"].(string), 10, 64)
+	if ttl > time.Now().Unix() {
+		t.Errorf("reserva liberada deveria estar expirada, ttl %d", ttl)
+	}
+}
+
+func TestIdempotenciaRepository_Get(t *testing.T) {
+	item := func(ttl int64) string {
+		return fmt.Sprintf(`{"Item":{"chave":{"S":"k"},"transacao_id":{"S":"t1"},"status":{"S":"REJEITADA"},"codigo_motivo":{"S":"insufficient_limit"},"hash_payload":{"S":"h1"},"ttl":{"N":"%d"}}}`, ttl)
+	}
+
+	tests := []struct {
+		name        string
+		corpo       string
+		esperaAchar bool
+	}{
+		{name: "vigente", corpo: item(time.Now().Add(time.Hour).Unix()), esperaAchar: true},
+		{name: "expirado e ainda não removido", corpo: item(time.Now().Add(-time.Minute).Unix())},
+		{name: "inexistente", corpo: "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: tt.corpo}
+			repo := NewIdempotenciaRepository(newFakeDynamoClient(httpClient), "idempotencia")
+
+			registro, err := repo.Get(context.Background(), "k")
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if (registro != nil) != tt.esperaAchar {
+				t.Fatalf("registro esperado %v, got %+v", tt.esperaAchar, registro)
+			}
+			if registro != nil && (registro.TransacaoID != "t1" || registro.CodigoMotivo != "insufficient_limit" || registro.HashPayload != "h1") {
+				t.Errorf("registro inesperado: %+v", registro)
+			}
+			if httpClient.ultimaRequisicao().payload["ConsistentRead"] != true {
+				t.Error("leitura deveria ser consistente")
+			}
+		})
+	}
+}
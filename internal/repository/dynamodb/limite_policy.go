@@ -0,0 +1,64 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// expressaoDebito é a tradução de uma domain.LimitePolicy para UpdateItem:
+// condição e atualização compõem limite atual e acumulados numa única escrita
+type expressaoDebito struct {
+	condicao    string
+	atualizacao string
+	nomes       map[string]string
+	valores     map[string]types.AttributeValue
+}
+
+// novaExpressaoDebito monta a expressão do débito de valor sob a política.
+// Condition expressions não aceitam aritmética: os mínimos e máximos
+// exigidos antes do débito são calculados aqui.
+func novaExpressaoDebito(politica domain.LimitePolicy, valor int, agora string) expressaoDebito {
+	condicoes := []string{"attribute_exists(id)", "limite_atual >= :minimo"}
+	atualizacoes := []string{"limite_atual = limite_atual - :valor", "updated_at = :now"}
+	valores := map[string]types.AttributeValue{
+		":valor":  numero(valor),
+		":minimo": numero(politica.MinimoLimiteAtual(valor)),
+		":now":    &types.AttributeValueMemberS{Value: agora},
+	}
+
+	var nomes map[string]string
+	for i, acumulado := range politica.Acumulados {
+		if nomes == nil {
+			nomes = make(map[string]string, len(politica.Acumulados))
+			valores[":zero"] = numero(0)
+		}
+		nome := fmt.Sprintf("#acumulado%d", i)
+		maximo := fmt.Sprintf(":maximo_acumulado%d", i)
+		nomes[nome] = acumulado.Atributo
+		valores[maximo] = numero(acumulado.MaximoAntesDoDebito(valor))
+
+		// Acumulador ausente vale zero, mas só passa se o débito couber no
+		// máximo; sem a alternativa, a comparação falha para atributo ausente
+		if acumulado.MaximoAntesDoDebito(valor) >= 0 {
+			condicoes = append(condicoes, fmt.Sprintf("(attribute_not_exists(%s) OR %s <= %s)", nome, nome, maximo))
+		} else {
+			condicoes = append(condicoes, fmt.Sprintf("%s <= %s", nome, maximo))
+		}
+		atualizacoes = append(atualizacoes, fmt.Sprintf("%s = if_not_exists(%s, :zero) + :valor", nome, nome))
+	}
+
+	return expressaoDebito{
+		condicao:    strings.Join(condicoes, " AND "),
+		atualizacao: "SET " + strings.Join(atualizacoes, ", "),
+		nomes:       nomes,
+		valores:     valores,
+	}
+}
+
+func numero(valor int) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(valor)}
+}
@@ -0,0 +1,304 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestNovaExpressaoDebito(t *testing.T) {
+	tests := []struct {
+		name        string
+		politica    domain.LimitePolicy
+		condicao    string
+		atualizacao string
+		nomes       map[string]string
+		valores     map[string]string
+	}{
+		{
+			name:        "sem cheque especial",
+			politica:    domain.LimitePolicy{},
+			condicao:    "attribute_exists(id) AND limite_atual >= :minimo",
+			atualizacao: "SET limite_atual = limite_atual - :valor, updated_at = :now",
+			valores:     map[string]string{":valor": "3000", ":minimo": "3000"},
+		},
+		{
+			name:        "cheque especial",
+			politica:    domain.LimitePolicy{ChequeEspecial: 5000},
+			condicao:    "attribute_exists(id) AND limite_atual >= :minimo",
+			atualizacao: "SET limite_atual = limite_atual - :valor, updated_at = :now",
+			valores:     map[string]string{":valor": "3000", ":minimo": "-2000"},
+		},
+		{
+			name: "cheque especial e limites por categoria",
+			politica: domain.LimitePolicy{
+				ChequeEspecial: 5000,
+				Acumulados: []domain.LimiteAcumulado{
+					{Atributo: "gasto_categoria_mercado", Maximo: 20000},
+					{Atributo: "gasto_categoria_viagem", Maximo: 10000},
+				},
+			},
+			condicao: "attribute_exists(id) AND limite_atual >= :minimo" +
+				" AND (attribute_not_exists(#acumulado0) OR #acumulado0 <= :maximo_acumulado0)" +
+				" AND (attribute_not_exists(#acumulado1) OR #acumulado1 <= :maximo_acumulado1)",
+			atualizacao: "SET limite_atual = limite_atual - :valor, updated_at = :now" +
+				", #acumulado0 = if_not_exists(#acumulado0, :zero) + :valor" +
+				", #acumulado1 = if_not_exists(#acumulado1, :zero) + :valor",
+			nomes: map[string]string{"#acumulado0": "gasto_categoria_mercado", "#acumulado1": "gasto_categoria_viagem"},
+			valores: map[string]string{
+				":valor": "3000", ":minimo": "-2000", ":zero": "0",
+				":maximo_acumulado0": "17000", ":maximo_acumulado1": "7000",
+			},
+		},
+		{
+			name: "débito acima do máximo exige acumulado existente",
+			politica: domain.LimitePolicy{
+				Acumulados: []domain.LimiteAcumulado{{Atributo: "gasto_categoria_viagem", Maximo: 2000}},
+			},
+			condicao: "attribute_exists(id) AND limite_atual >= :minimo" +
+				" AND #acumulado0 <= :maximo_acumulado0",
+			atualizacao: "SET limite_atual = limite_atual - :valor, updated_at = :now" +
+				", #acumulado0 = if_not_exists(#acumulado0, :zero) + :valor",
+			nomes: map[string]string{"#acumulado0": "gasto_categoria_viagem"},
+			valores: map[string]string{
+				":valor": "3000", ":minimo": "3000", ":zero": "0",
+				":maximo_acumulado0": "-1000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expressao := novaExpressaoDebito(tt.politica, 3000, "1700000000000")
+
+			if expressao.condicao != tt.condicao {
+				t.Errorf("condição esperada\n%s\ngot\n%s", tt.condicao, expressao.condicao)
+			}
+			if expressao.atualizacao != tt.atualizacao {
+				t.Errorf("atualização esperada\n%s\ngot\n%s", tt.atualizacao, expressao.atualizacao)
+			}
+
+			if len(expressao.nomes) != len(tt.nomes) {
+				t.Errorf("nomes esperados %v, got %v", tt.nomes, expressao.nomes)
+			}
+			for nome, atributo := range tt.nomes {
+				if expressao.nomes[nome] != atributo {
+					t.Errorf("%s esperado %s, got %s", nome, atributo, expressao.nomes[nome])
+				}
+			}
+
+			// Todo valor declarado é usado (o DynamoDB rejeita valores sobrando)
+			if len(expressao.valores) != len(tt.valores)+1 {
+				t.Errorf("valores esperados %v (+ :now), got %d", tt.valores, len(expressao.valores))
+			}
+			for chave, esperado := range tt.valores {
+				numero, ok := expressao.valores[chave].(*types.AttributeValueMemberN)
+				if !ok || numero.Value != esperado {
+					t.Errorf("%s esperado %s, got %v", chave, esperado, expressao.valores[chave])
+				}
+			}
+		})
+	}
+}
+
+func TestDebitarLimiteComPolitica_EnviaCondicaoComposta(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	politica := domain.LimitePolicy{Acumulados: []domain.LimiteAcumulado{{Atributo: "gasto_categoria_mercado", Maximo: 20000}}}
+	if _, err := repo.DebitarLimiteComPolitica(context.Background(), "c1", 3000, politica); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	req := httpClient.ultimaRequisicao()
+	nomes := req.payload["ExpressionAttributeNames"].(map[string]interface{})
+	if nomes["#acumulado0"] != "gasto_categoria_mercado" {
+		t.Errorf("acumulado esperado gasto_categoria_mercado, got %v", nomes["#acumulado0"])
+	}
+	if req.payload["ConditionExpression"] != novaExpressaoDebito(politica, 3000, "").condicao {
+		t.Errorf("condição inesperada: %v", req.payload["ConditionExpression"])
+	}
+}
+
+// atribuicaoDebito reconhece as atribuições do SET gerado por novaExpressaoDebito
+var atribuicaoDebito = regexp.MustCompile(`(#?\w+) = (?:if_not_exists\((#?\w+), (:\w+)\) \+ (:\w+)|(#?\w+) ([+-]) (:\w+)|(:\w+))`)
+
+// tabelaCondicional simula um item de cliente do DynamoDB avaliando o
+// subconjunto de expressões gerado por novaExpressaoDebito: o UpdateItem só
+// aplica as atualizações se todas as condições passarem, como no serviço real
+type tabelaCondicional struct {
+	item     map[string]int
+	escritas int
+}
+
+func (tb *tabelaCondicional) client() *mockDynamoDB {
+	return &mockDynamoDB{
+		getItem: func(in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			return &dynamodb.GetItemOutput{Item: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "c1"}}}, nil
+		},
+		updateItem: tb.updateItem,
+	}
+}
+
+func (tb *tabelaCondicional) updateItem(in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	tb.escritas++
+	operando := func(token string) (int, bool) {
+		switch {
+		case strings.HasPrefix(token, ":"):
+			n, ok := in.ExpressionAttributeValues[token].(*types.AttributeValueMemberN)
+			if !ok {
+				return 0, false
+			}
+			v, _ := strconv.Atoi(n.Value)
+			return v, true
+		case strings.HasPrefix(token, "#"):
+			token = in.ExpressionAttributeNames[token]
+		}
+		v, ok := tb.item[token]
+		return v, ok
+	}
+	atributo := func(token string) string {
+		if nome, ok := in.ExpressionAttributeNames[token]; ok {
+			return nome
+		}
+		return token
+	}
+
+	var avaliar func(condicao string) bool
+	avaliar = func(condicao string) bool {
+		if strings.HasPrefix(condicao, "(") && strings.HasSuffix(condicao, ")") && strings.Contains(condicao, " OR ") {
+			for _, alternativa := range strings.Split(condicao[1:len(condicao)-1], " OR ") {
+				if avaliar(alternativa) {
+					return true
+				}
+			}
+			return false
+		}
+		if strings.HasPrefix(condicao, "attribute_exists(") {
+			_, ok := operando(strings.TrimSuffix(strings.TrimPrefix(condicao, "attribute_exists("), ")"))
+			return ok
+		}
+		if strings.HasPrefix(condicao, "attribute_not_exists(") {
+			_, ok := operando(strings.TrimSuffix(strings.TrimPrefix(condicao, "attribute_not_exists("), ")"))
+			return !ok
+		}
+		// Comparação com atributo ausente é falsa, como no DynamoDB
+		partes := strings.Fields(condicao)
+		a, okA := operando(partes[0])
+		b, okB := operando(partes[2])
+		if !okA || !okB {
+			return false
+		}
+		if partes[1] == ">=" {
+			return a >= b
+		}
+		return a <= b
+	}
+
+	for _, condicao := range strings.Split(aws.ToString(in.ConditionExpression), " AND ") {
+		if !avaliar(condicao) {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	}
+
+	// Todas as atualizações são calculadas sobre o item original e aplicadas juntas
+	novos := make(map[string]int)
+	for _, m := range atribuicaoDebito.FindAllStringSubmatch(aws.ToString(in.UpdateExpression), -1) {
+		destino := atributo(m[1])
+		switch {
+		case m[2] != "": // #x = if_not_exists(#x, :zero) + :valor
+			base, ok := operando(m[2])
+			if !ok {
+				base, _ = operando(m[3])
+			}
+			delta, _ := operando(m[4])
+			novos[destino] = base + delta
+		case m[5] != "": // x = x - :valor
+			base, _ := operando(m[5])
+			delta, _ := operando(m[7])
+			if m[6] == "-" {
+				delta = -delta
+			}
+			novos[destino] = base + delta
+		default: // x = :valor (não numérico, como updated_at, é ignorado)
+			if v, ok := operando(m[8]); ok {
+				novos[destino] = v
+			}
+		}
+	}
+	for nome, valor := range novos {
+		tb.item[nome] = valor
+	}
+
+	return &dynamodb.UpdateItemOutput{Attributes: map[string]types.AttributeValue{"limite_atual": numero(tb.item["limite_atual"])}}, nil
+}
+
+func TestDebitarLimiteComPolitica_SubLimiteRecusadoNaoMoveContadores(t *testing.T) {
+	politica := domain.LimitePolicy{
+		Acumulados: []domain.LimiteAcumulado{
+			{Atributo: "gasto_categoria_mercado", Maximo: 20000},
+			{Atributo: "gasto_categoria_viagem", Maximo: 10000},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		item      map[string]int
+		valor     int
+		esperaErr error
+	}{
+		{name: "todos os limites comportam", item: map[string]int{"id": 1, "limite_atual": 50000, "gasto_categoria_mercado": 15000, "gasto_categoria_viagem": 5000}},
+		{name: "acumulados ausentes começam em zero", item: map[string]int{"id": 1, "limite_atual": 50000}},
+		{name: "limite global insuficiente", item: map[string]int{"id": 1, "limite_atual": 2000, "gasto_categoria_mercado": 0, "gasto_categoria_viagem": 0}, esperaErr: domain.ErrLimiteInsuficiente},
+		{name: "limite da categoria mercado estourado", item: map[string]int{"id": 1, "limite_atual": 50000, "gasto_categoria_mercado": 18000, "gasto_categoria_viagem": 0}, esperaErr: domain.ErrLimiteInsuficiente},
+		{name: "limite da categoria viagem estourado", item: map[string]int{"id": 1, "limite_atual": 50000, "gasto_categoria_mercado": 0, "gasto_categoria_viagem": 8000}, esperaErr: domain.ErrLimiteInsuficiente},
+		{name: "primeiro débito acima do máximo com acumulados ausentes", item: map[string]int{"id": 1, "limite_atual": 50000}, valor: 30000, esperaErr: domain.ErrLimiteInsuficiente},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			antes := make(map[string]int, len(tt.item))
+			for k, v := range tt.item {
+				antes[k] = v
+			}
+			tabela := &tabelaCondicional{item: tt.item}
+			repo := NewLimiteRepository(tabela.client(), "clientes")
+
+			valor := tt.valor
+			if valor == 0 {
+				valor = 3000
+			}
+			limite, err := repo.DebitarLimiteComPolitica(context.Background(), "c1", valor, politica)
+			if err != tt.esperaErr {
+				t.Fatalf("erro esperado %v, got %v", tt.esperaErr, err)
+			}
+			if tabela.escritas != 1 {
+				t.Errorf("o débito deveria ser uma única escrita, got %d", tabela.escritas)
+			}
+
+			if tt.esperaErr != nil {
+				if !reflect.DeepEqual(tabela.item, antes) {
+					t.Errorf("nenhum contador deveria mudar: antes %v, depois %v", antes, tabela.item)
+				}
+				return
+			}
+
+			if limite != antes["limite_atual"]-valor || tabela.item["limite_atual"] != limite {
+				t.Errorf("limite atual esperado %d, got %d", antes["limite_atual"]-valor, limite)
+			}
+			for _, acumulado := range politica.Acumulados {
+				if got := tabela.item[acumulado.Atributo]; got != antes[acumulado.Atributo]+valor {
+					t.Errorf("%s esperado %d, got %d", acumulado.Atributo, antes[acumulado.Atributo]+valor, got)
+				}
+			}
+		})
+	}
+}
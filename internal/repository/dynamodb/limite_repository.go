@@ -15,8 +15,9 @@ import (
 )
 
 type LimiteRepository struct {
-	client    *dynamodb.Client
+	client    DynamoDBAPI
 	tableName string
+	opcoes    opcoes
 }
 
 type ClienteItem struct {
@@ -25,14 +26,17 @@ type ClienteItem struct {
 	Email        string `dynamodbav:"email"`
 	LimiteCredit int    `dynamodbav:"limite_credito"`
 	LimiteAtual  int    `dynamodbav:"limite_atual"`
+	Tier         string `dynamodbav:"tier,omitempty"`
+	MaxParcelas  int    `dynamodbav:"max_parcelas,omitempty"`
 	CreatedAt    string `dynamodbav:"created_at"`
 	UpdatedAt    string `dynamodbav:"updated_at"`
 }
 
-func NewLimiteRepository(client *dynamodb.Client, tableName string) *LimiteRepository {
+func NewLimiteRepository(client DynamoDBAPI, tableName string, opts ...Option) *LimiteRepository {
 	return &LimiteRepository{
 		client:    client,
 		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
 	}
 }
 
@@ -47,7 +51,9 @@ func (r *LimiteRepository) GetCliente(ctx context.Context, clienteID string) (*d
 		ConsistentRead: aws.Bool(true),
 	}
 
-	result, err := r.client.GetItem(ctx, input)
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.GetItem, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return r.client.GetItem(ctx, input)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar cliente %s: %w", clienteID, err)
 	}
@@ -61,7 +67,99 @@ func (r *LimiteRepository) GetCliente(ctx context.Context, clienteID string) (*d
 		return nil, fmt.Errorf("erro ao deserializar cliente: %w", err)
 	}
 
-	return r.itemToCliente(&item), nil
+	cliente := r.itemToCliente(&item)
+	if cliente.LimiteAtual < 0 {
+		return r.tratarLimiteNegativo(ctx, cliente)
+	}
+
+	return cliente, nil
+}
+
+// tratarLimiteNegativo registra a anomalia e aplica a política configurada
+func (r *LimiteRepository) tratarLimiteNegativo(ctx context.Context, cliente *domain.Cliente) (*domain.Cliente, error) {
+	politica := r.opcoes.limiteNegativo
+
+	if r.opcoes.metrics != nil {
+		r.opcoes.metrics.IncrementErrorCounter("negative_limit_observed")
+	}
+	if r.opcoes.logger != nil {
+		r.opcoes.logger.Warn(ctx, "cliente com limite atual negativo", map[string]interface{}{
+			"cliente_id":   cliente.ID,
+			"limite_atual": cliente.LimiteAtual,
+			"politica":     string(politica),
+		})
+	}
+
+	switch politica {
+	case LimiteNegativoFail:
+		return nil, fmt.Errorf("cliente %s com limite_atual %d: %w", cliente.ID, cliente.LimiteAtual, domain.ErrLimiteInconsistente)
+	case LimiteNegativoHeal:
+		if err := r.zerarLimiteNegativo(ctx, cliente.ID); err != nil && r.opcoes.logger != nil {
+			// A correção na tabela é best-effort; a leitura já devolve zero
+			r.opcoes.logger.Error(ctx, "falha ao corrigir limite negativo", err, map[string]interface{}{
+				"cliente_id": cliente.ID,
+			})
+		}
+		cliente.LimiteAtual = 0
+	}
+
+	return cliente, nil
+}
+
+// zerarLimiteNegativo zera o limite apenas se ele ainda estiver negativo,
+// sem sobrescrever um débito ou crédito concorrente
+func (r *LimiteRepository) zerarLimiteNegativo(ctx context.Context, clienteID string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: clienteID},
+		},
+		UpdateExpression: aws.String("SET limite_atual = :zero, updated_at = :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":now":  &types.AttributeValueMemberS{Value: fmt.Sprintf("%d", System.currentTimeMillis())},
+		},
+		ConditionExpression: aws.String("attribute_exists(id) AND limite_atual < :zero"),
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if errors.As(err, &condErr) {
+		return nil
+	}
+	return err
+}
+
+// GetClientes busca vários clientes via BatchGetItem, em lotes de 100 chaves,
+// retentando UnprocessedKeys com backoff. Retorna os clientes encontrados
+// indexados por ID e os IDs inexistentes.
+func (r *LimiteRepository) GetClientes(ctx context.Context, ids []string) (map[string]*domain.Cliente, []string, error) {
+	itens, err := batchGet(ctx, r.client, r.tableName, "id", ids, &r.opcoes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar clientes em lote: %w", err)
+	}
+
+	encontrados := make(map[string]*domain.Cliente, len(itens))
+	for _, av := range itens {
+		var item ClienteItem
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return nil, nil, fmt.Errorf("erro ao deserializar cliente: %w", err)
+		}
+		encontrados[item.ID] = r.itemToCliente(&item)
+	}
+
+	naoEncontrados := make([]string, 0)
+	vistos := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if _, ok := encontrados[id]; !ok && !vistos[id] {
+			naoEncontrados = append(naoEncontrados, id)
+		}
+		vistos[id] = true
+	}
+
+	return encontrados, naoEncontrados, nil
 }
 
 // UpdateLimite atualiza o limite atual do cliente
@@ -80,7 +178,9 @@ func (r *LimiteRepository) UpdateLimite(ctx context.Context, clienteID string, n
 		ConditionExpression: aws.String("attribute_exists(id)"),
 	}
 
-	_, err := r.client.UpdateItem(ctx, input)
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
 	if err != nil {
 		var condErr *types.ConditionalCheckFailedException
 		if errors.As(err, &condErr) {
@@ -92,65 +192,167 @@ func (r *LimiteRepository) UpdateLimite(ctx context.Context, clienteID string, n
 	return nil
 }
 
-// DebitarLimiteAtomica realiza a operação crítica de verificar limite E debitar
-// em uma única operação atômica usando conditional writes do DynamoDB
-func (r *LimiteRepository) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) error {
-	// Esta é a operação mais crítica do sistema
-	// Usamos UpdateItem com ConditionExpression para garantir atomicidade
+// AjustarLimite regrava limite_atual somente se ele ainda for lido; débitos
+// ou créditos concorrentes fazem o ajuste falhar com
+// domain.ErrLimiteAlteradoConcorrente, sem perder a movimentação
+func (r *LimiteRepository) AjustarLimite(ctx context.Context, clienteID string, lido, novoLimite int) error {
 	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String(r.tableName),
 		Key: map[string]types.AttributeValue{
 			"id": &types.AttributeValueMemberS{Value: clienteID},
 		},
-		UpdateExpression: aws.String("SET limite_atual = limite_atual - :valor, updated_at = :now"),
+		UpdateExpression: aws.String("SET limite_atual = :novo_limite, updated_at = :now"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":valor": &types.AttributeValueMemberN{Value: strconv.Itoa(valor)},
-			":now":   &types.AttributeValueMemberS{Value: fmt.Sprintf("%d", System.currentTimeMillis())},
-			":zero":  &types.AttributeValueMemberN{Value: "0"},
+			":novo_limite": &types.AttributeValueMemberN{Value: strconv.Itoa(novoLimite)},
+			":lido":        &types.AttributeValueMemberN{Value: strconv.Itoa(lido)},
+			":now":         &types.AttributeValueMemberS{Value: fmt.Sprintf("%d", System.currentTimeMillis())},
 		},
-		// Condições críticas:
-		// 1. Cliente deve existir
-		// 2. Limite atual deve ser >= valor da transação
-		// 3. Limite atual não pode ficar negativo após a operação
-		ConditionExpression: aws.String("attribute_exists(id) AND limite_atual >= :valor AND (limite_atual - :valor) >= :zero"),
-		// Retorna os valores para debugging/auditoria
-		ReturnValues: types.ReturnValueUpdatedNew,
+		ConditionExpression: aws.String("limite_atual = :lido"),
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("ajuste do limite do cliente %s: %w", clienteID, domain.ErrLimiteAlteradoConcorrente)
+		}
+		return fmt.Errorf("erro ao ajustar limite do cliente %s: %w", clienteID, err)
 	}
 
-	result, err := r.client.UpdateItem(ctx, input)
+	return nil
+}
+
+// DebitarLimiteAtomica realiza a operação crítica de verificar limite E debitar
+// em uma única operação atômica usando conditional writes do DynamoDB.
+// Retorna o limite atual após o débito.
+func (r *LimiteRepository) DebitarLimiteAtomica(ctx context.Context, clienteID string, valor int) (int, error) {
+	// Esta é a operação mais crítica do sistema
+	// Usamos UpdateItem com ConditionExpression para garantir atomicidade.
+	// Sem cheque especial, o cliente deve existir e o limite atual não pode
+	// ficar negativo após a operação.
+	input := r.inputDebito(clienteID, valor, domain.LimitePolicy{})
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
 	if err != nil {
 		var condErr *types.ConditionalCheckFailedException
 		if errors.As(err, &condErr) {
 			// Se a condição falha, pode ser cliente inexistente OU limite insuficiente
-			// Fazemos uma verificação adicional para distinguir
+			// Fazemos uma verificação adicional para distinguir, exceto se a
+			// requisição já expirou: a leitura só consumiria tempo e capacidade
+			if ctx.Err() != nil {
+				return 0, domain.ErrLimiteInsuficiente
+			}
 			cliente, getErr := r.GetCliente(ctx, clienteID)
 			if getErr != nil {
 				if errors.Is(getErr, domain.ErrClienteNaoEncontrado) {
-					return domain.ErrClienteNaoEncontrado
+					return 0, domain.ErrClienteNaoEncontrado
 				}
 				// Se não conseguimos verificar, assumimos limite insuficiente
-				return domain.ErrLimiteInsuficiente
+				return 0, domain.ErrLimiteInsuficiente
 			}
 
 			// Cliente existe, então o problema é limite insuficiente
-			if cliente.LimiteAtual < valor {
-				return domain.ErrLimiteInsuficiente
+			if !(domain.LimitePolicy{}).PermiteLimiteAtual(cliente.LimiteAtual, valor) {
+				return 0, domain.ErrLimiteInsuficiente
 			}
 
 			// Caso raro: alguma outra condição falhou
-			return fmt.Errorf("operação atômica falhou para cliente %s: %w", clienteID, err)
+			return 0, fmt.Errorf("operação atômica falhou para cliente %s: %w", clienteID, err)
+		}
+
+		return 0, fmt.Errorf("erro ao debitar limite do cliente %s: %w", clienteID, err)
+	}
+
+	return limiteAtualizado(result.Attributes)
+}
+
+// DebitarLimiteComChequeEspecial debita atomicamente permitindo que o limite
+// atual fique negativo até chequeEspecial. Com cheque especial em uso, a
+// política de limite negativo de leitura deve ser PASSTHROUGH.
+func (r *LimiteRepository) DebitarLimiteComChequeEspecial(ctx context.Context, clienteID string, valor, chequeEspecial int) (int, error) {
+	return r.DebitarLimiteComPolitica(ctx, clienteID, valor, domain.LimitePolicy{ChequeEspecial: chequeEspecial})
+}
+
+// DebitarLimiteComPolitica debita atomicamente aplicando todas as condições
+// da política (cheque especial e acumulados) numa única escrita condicional
+func (r *LimiteRepository) DebitarLimiteComPolitica(ctx context.Context, clienteID string, valor int, politica domain.LimitePolicy) (int, error) {
+	input := r.inputDebito(clienteID, valor, politica)
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			// Distingue cliente inexistente de limite insuficiente sem passar
+			// por GetCliente, cuja política poderia corrigir o saldo negativo
+			if ctx.Err() != nil {
+				return 0, domain.ErrLimiteInsuficiente
+			}
+			existe, getErr := r.existeCliente(ctx, clienteID)
+			if getErr == nil && !existe {
+				return 0, domain.ErrClienteNaoEncontrado
+			}
+			return 0, domain.ErrLimiteInsuficiente
 		}
 
-		return fmt.Errorf("erro ao debitar limite do cliente %s: %w", clienteID, err)
+		return 0, fmt.Errorf("erro ao debitar limite do cliente %s: %w", clienteID, err)
+	}
+
+	return limiteAtualizado(result.Attributes)
+}
+
+// inputDebito monta o UpdateItem condicional do débito sob a política
+func (r *LimiteRepository) inputDebito(clienteID string, valor int, politica domain.LimitePolicy) *dynamodb.UpdateItemInput {
+	expressao := novaExpressaoDebito(politica, valor, fmt.Sprintf("%d", System.currentTimeMillis()))
+
+	return &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: clienteID},
+		},
+		UpdateExpression:          aws.String(expressao.atualizacao),
+		ConditionExpression:       aws.String(expressao.condicao),
+		ExpressionAttributeNames:  expressao.nomes,
+		ExpressionAttributeValues: expressao.valores,
+		// O novo limite atual é devolvido ao chamador
+		ReturnValues: types.ReturnValueUpdatedNew,
 	}
+}
 
-	// Log do resultado para auditoria (em produção, isso seria estruturado)
-	if result.Attributes != nil {
-		// Seria útil logar o novo limite para auditoria
-		_ = result.Attributes // placeholder para implementação de auditoria
+// limiteAtualizado extrai limite_atual dos atributos devolvidos pelo débito
+func limiteAtualizado(atributos map[string]types.AttributeValue) (int, error) {
+	var item struct {
+		LimiteAtual int `dynamodbav:"limite_atual"`
+	}
+	if err := attributevalue.UnmarshalMap(atributos, &item); err != nil {
+		return 0, fmt.Errorf("erro ao ler limite atualizado: %w", err)
 	}
+	return item.LimiteAtual, nil
+}
 
-	return nil
+// existeCliente consulta apenas a chave do cliente
+func (r *LimiteRepository) existeCliente(ctx context.Context, clienteID string) (bool, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: clienteID},
+		},
+		ProjectionExpression: aws.String("id"),
+		ConsistentRead:       aws.Bool(true),
+	}
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.GetItem, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return r.client.GetItem(ctx, input)
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.Item != nil, nil
 }
 
 // Método auxiliar para converter item do DynamoDB para entidade de domínio
@@ -161,19 +363,29 @@ func (r *LimiteRepository) itemToCliente(item *ClienteItem) *domain.Cliente {
 		Email:        item.Email,
 		LimiteCredit: item.LimiteCredit,
 		LimiteAtual:  item.LimiteAtual,
+		Tier:         item.Tier,
+		MaxParcelas:  item.MaxParcelas,
 		// CreatedAt e UpdatedAt seriam convertidos de string para time.Time
 		// em uma implementação real
 	}
 }
 
-// CreateCliente cria um novo cliente (útil para testes e setup inicial)
+// CreateCliente cria um novo cliente (útil para testes e setup inicial). O
+// e-mail é validado e gravado em minúsculas (ErrEmailInvalido antes do put).
 func (r *LimiteRepository) CreateCliente(ctx context.Context, cliente *domain.Cliente) error {
+	email, err := domain.NormalizarEmail(cliente.Email)
+	if err != nil {
+		return err
+	}
+
 	item := &ClienteItem{
 		ID:           cliente.ID,
 		Nome:         cliente.Nome,
-		Email:        cliente.Email,
+		Email:        email,
 		LimiteCredit: cliente.LimiteCredit,
 		LimiteAtual:  cliente.LimiteAtual,
+		Tier:         cliente.Tier,
+		MaxParcelas:  cliente.MaxParcelas,
 		CreatedAt:    cliente.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:    cliente.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -190,7 +402,9 @@ func (r *LimiteRepository) CreateCliente(ctx context.Context, cliente *domain.Cl
 		ConditionExpression: aws.String("attribute_not_exists(id)"),
 	}
 
-	_, err = r.client.PutItem(ctx, input)
+	_, err = executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return r.client.PutItem(ctx, input)
+	})
 	if err != nil {
 		var condErr *types.ConditionalCheckFailedException
 		if errors.As(err, &condErr) {
@@ -202,6 +416,43 @@ func (r *LimiteRepository) CreateCliente(ctx context.Context, cliente *domain.Cl
 	return nil
 }
 
+// ProvisionarCliente cria o cliente com limite de crédito e atual iguais a
+// limiteCentavos, apenas se ele ainda não existir
+func (r *LimiteRepository) ProvisionarCliente(ctx context.Context, clienteID string, limiteCentavos int) (bool, error) {
+	agora := time.Now().UTC().Format("2006-01-02T15:04:05Z07:00")
+	item := &ClienteItem{
+		ID:           clienteID,
+		LimiteCredit: limiteCentavos,
+		LimiteAtual:  limiteCentavos,
+		CreatedAt:    agora,
+		UpdatedAt:    agora,
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return false, fmt.Errorf("erro ao serializar cliente: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}
+
+	_, err = executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return r.client.PutItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("erro ao provisionar cliente %s: %w", clienteID, err)
+	}
+
+	return true, nil
+}
+
 // currentTimeMillis simula System.currentTimeMillis() do Java
 // Em uma implementação real, usaríamos time.Now().Unix() ou similar
 var System = struct {
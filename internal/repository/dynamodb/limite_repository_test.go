@@ -0,0 +1,505 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// batchGetResponder simula BatchGetItem: devolve os clientes existentes e,
+// na primeira chamada, deixa as primeiras chaves como UnprocessedKeys
+func batchGetResponder(existentes map[string]bool, naoProcessadasNaPrimeira int) func(string, map[string]interface{}) string {
+	primeira := true
+
+	return func(operacao string, payload map[string]interface{}) string {
+		requestItems := payload["RequestItems"].(map[string]interface{})
+		keys := requestItems["clientes"].(map[string]interface{})["Keys"].([]interface{})
+
+		var naoProcessadas []interface{}
+		if primeira {
+			naoProcessadas = keys[:naoProcessadasNaPrimeira]
+			keys = keys[naoProcessadasNaPrimeira:]
+			primeira = false
+		}
+
+		itens := make([]interface{}, 0)
+		for _, key := range keys {
+			id := key.(map[string]interface{})["id"].(map[string]interface{})["S"].(string)
+			if existentes[id] {
+				itens = append(itens, map[string]interface{}{
+					"id":             map[string]string{"S": id},
+					"limite_credito": map[string]string{"N": "1000"},
+					"limite_atual":   map[string]string{"N": "500"},
+				})
+			}
+		}
+
+		resposta := map[string]interface{}{
+			"Responses": map[string]interface{}{"clientes": itens},
+		}
+		if len(naoProcessadas) > 0 {
+			resposta["UnprocessedKeys"] = map[string]interface{}{
+				"clientes": map[string]interface{}{"Keys": naoProcessadas},
+			}
+		}
+
+		corpo, _ := json.Marshal(resposta)
+		return string(corpo)
+	}
+}
+
+func TestGetClientes_LotesComExistentesEInexistentes(t *testing.T) {
+	// 250 IDs (3 lotes), pares existem; inclui duplicado
+	ids := make([]string, 0, 251)
+	existentes := make(map[string]bool)
+	for i := 0; i < 250; i++ {
+		id := fmt.Sprintf("c%03d", i)
+		ids = append(ids, id)
+		if i%2 == 0 {
+			existentes[id] = true
+		}
+	}
+	ids = append(ids, "c000")
+
+	httpClient := &fakeHTTPClient{responder: batchGetResponder(existentes, 10)}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	encontrados, naoEncontrados, err := repo.GetClientes(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(encontrados) != 125 {
+		t.Errorf("esperados 125 clientes encontrados, got %d", len(encontrados))
+	}
+
+	if len(naoEncontrados) != 125 {
+		t.Errorf("esperados 125 IDs não encontrados, got %d", len(naoEncontrados))
+	}
+
+	for id := range encontrados {
+		if !existentes[id] {
+			t.Errorf("cliente %s não deveria ser encontrado", id)
+		}
+	}
+
+	sort.Strings(naoEncontrados)
+	if naoEncontrados[0] != "c001" || naoEncontrados[len(naoEncontrados)-1] != "c249" {
+		t.Errorf("IDs não encontrados inesperados: %v...", naoEncontrados[:3])
+	}
+
+	if cliente := encontrados["c000"]; cliente == nil || cliente.LimiteAtual != 500 {
+		t.Errorf("cliente c000 inesperado: %+v", cliente)
+	}
+
+	// 3 lotes + 1 retentativa das chaves não processadas
+	httpClient.mu.Lock()
+	defer httpClient.mu.Unlock()
+	if len(httpClient.requisicoes) != 4 {
+		t.Errorf("esperadas 4 chamadas BatchGetItem, got %d", len(httpClient.requisicoes))
+	}
+
+	for _, req := range httpClient.requisicoes {
+		keys := req.payload["RequestItems"].(map[string]interface{})["clientes"].(map[string]interface{})["Keys"].([]interface{})
+		if len(keys) > tamanhoLoteBatchGet {
+			t.Errorf("lote com %d chaves excede o máximo de %d", len(keys), tamanhoLoteBatchGet)
+		}
+	}
+}
+
+// contadorErros registra IncrementErrorCounter; demais métricas são descartadas
+type contadorErros struct {
+	mu     sync.Mutex
+	contas map[string]int
+}
+
+func (m *contadorErros) IncrementTransactionCounter(status string) {}
+func (m *contadorErros) RecordTransactionLatency(duration float64) {}
+func (m *contadorErros) RecordBusinessMetric(metricName string, value float64, labels map[string]string) {
+}
+func (m *contadorErros) IncrementOutcomeCounter(outcome string)             {}
+func (m *contadorErros) RecordEventPublish(result string, duration float64) {}
+func (m *contadorErros) RecordRejectedValue(reason string, value float64)   {}
+func (m *contadorErros) IncrementErrorCounter(errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.contas == nil {
+		m.contas = make(map[string]int)
+	}
+	m.contas[errorType]++
+}
+
+func TestGetCliente_PoliticaLimiteNegativo(t *testing.T) {
+	corpoNegativo := `{"Item":{"id":{"S":"c1"},"limite_credito":{"N":"1000"},"limite_atual":{"N":"-250"}}}`
+
+	tests := []struct {
+		politica      PoliticaLimiteNegativo
+		esperaErro    bool
+		limiteLido    int
+		esperaCorrige bool
+	}{
+		{politica: LimiteNegativoPassthrough, limiteLido: -250},
+		{politica: LimiteNegativoHeal, limiteLido: 0, esperaCorrige: true},
+		{politica: LimiteNegativoFail, esperaErro: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.politica), func(t *testing.T) {
+			httpClient := &fakeHTTPClient{responder: func(operacao string, payload map[string]interface{}) string {
+				if operacao == "GetItem" {
+					return corpoNegativo
+				}
+				return "{}"
+			}}
+			metrics := &contadorErros{}
+			repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes",
+				WithPoliticaLimiteNegativo(tt.politica), WithObservabilidade(metrics, nil))
+
+			cliente, err := repo.GetCliente(context.Background(), "c1")
+
+			if tt.esperaErro {
+				if !errors.Is(err, domain.ErrLimiteInconsistente) {
+					t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInconsistente, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				if cliente.LimiteAtual != tt.limiteLido {
+					t.Errorf("LimiteAtual esperado %d, got %d", tt.limiteLido, cliente.LimiteAtual)
+				}
+			}
+
+			if metrics.contas["negative_limit_observed"] != 1 {
+				t.Error("anomalia deveria ser registrada em métrica")
+			}
+
+			httpClient.mu.Lock()
+			defer httpClient.mu.Unlock()
+			corrigiu := len(httpClient.requisicoes) == 2 && httpClient.requisicoes[1].operacao == "UpdateItem"
+			if corrigiu != tt.esperaCorrige {
+				t.Errorf("correção na tabela esperada %v, got %v", tt.esperaCorrige, corrigiu)
+			}
+		})
+	}
+}
+
+func TestGetCliente_PassthroughPorPadrao(t *testing.T) {
+	corpo := `{"Item":{"id":{"S":"c1"},"limite_credito":{"N":"1000"},"limite_atual":{"N":"-1"}}}`
+	repo := NewLimiteRepository(newFakeDynamoClient(&fakeHTTPClient{corpo: corpo}), "clientes")
+
+	cliente, err := repo.GetCliente(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if cliente.LimiteAtual != -1 {
+		t.Errorf("LimiteAtual esperado -1, got %d", cliente.LimiteAtual)
+	}
+}
+
+func TestDebitarLimiteAtomica_RetornaNovoLimite(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: `{"Attributes":{"limite_atual":{"N":"7000"},"updated_at":{"S":"1"}}}`}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	novoLimite, err := repo.DebitarLimiteAtomica(context.Background(), "c1", 3000)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if novoLimite != 7000 {
+		t.Errorf("novo limite esperado 7000, got %d", novoLimite)
+	}
+	if rv := httpClient.ultimaRequisicao().payload["ReturnValues"]; rv != "UPDATED_NEW" {
+		t.Errorf("ReturnValues esperado UPDATED_NEW, got %v", rv)
+	}
+}
+
+func TestDebitarLimiteAtomica_ClienteComLimiteZero(t *testing.T) {
+	httpClient := &fakeHTTPClient{
+		corpo:    `{"Item":{"id":{"S":"c1"},"limite_credito":{"N":"0"},"limite_atual":{"N":"0"}}}`,
+		excecoes: map[string]string{"UpdateItem": "ConditionalCheckFailedException"},
+	}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	_, err := repo.DebitarLimiteAtomica(context.Background(), "c1", 1)
+	if err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+
+	// Condição falhou -> GetCliente para distinguir cliente inexistente
+	httpClient.mu.Lock()
+	defer httpClient.mu.Unlock()
+	if len(httpClient.requisicoes) != 2 || httpClient.requisicoes[1].operacao != "GetItem" {
+		t.Errorf("esperado UpdateItem seguido de GetItem, got %+v", httpClient.requisicoes)
+	}
+}
+
+func TestDebitarLimiteAtomica_ClienteInexistente(t *testing.T) {
+	httpClient := &fakeHTTPClient{
+		corpo:    `{}`,
+		excecoes: map[string]string{"UpdateItem": "ConditionalCheckFailedException"},
+	}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	if _, err := repo.DebitarLimiteAtomica(context.Background(), "c1", 1); err != domain.ErrClienteNaoEncontrado {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrClienteNaoEncontrado, err)
+	}
+}
+
+func TestDebitarLimiteComChequeEspecial(t *testing.T) {
+	tests := []struct {
+		name        string
+		corpo       string
+		excecoes    map[string]string
+		expectedErr error
+	}{
+		{name: "débito dentro do cheque especial", corpo: `{}`},
+		{
+			name:        "além do cheque especial",
+			corpo:       `{"Item":{"id":{"S":"c1"}}}`,
+			excecoes:    map[string]string{"UpdateItem": "ConditionalCheckFailedException"},
+			expectedErr: domain.ErrLimiteInsuficiente,
+		},
+		{
+			name:        "cliente inexistente",
+			corpo:       `{}`,
+			excecoes:    map[string]string{"UpdateItem": "ConditionalCheckFailedException"},
+			expectedErr: domain.ErrClienteNaoEncontrado,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: tt.corpo, excecoes: tt.excecoes}
+			repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+			if _, err := repo.DebitarLimiteComChequeEspecial(context.Background(), "c1", 30000, 50000); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			httpClient.mu.Lock()
+			defer httpClient.mu.Unlock()
+
+			update := httpClient.requisicoes[0].payload
+			valores := update["ExpressionAttributeValues"].(map[string]interface{})
+			minimo := valores[":minimo"].(map[string]interface{})["N"]
+			if minimo != "-20000" {
+				t.Errorf("mínimo do limite atual esperado -20000, got %v", minimo)
+			}
+		})
+	}
+}
+
+func TestProvisionarCliente(t *testing.T) {
+	tests := []struct {
+		name           string
+		excecoes       map[string]string
+		esperadoCriado bool
+	}{
+		{name: "cria cliente ausente", esperadoCriado: true},
+		{name: "não sobrescreve cliente existente", excecoes: map[string]string{"PutItem": "ConditionalCheckFailedException"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: "{}", excecoes: tt.excecoes}
+			repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+			criado, err := repo.ProvisionarCliente(context.Background(), "c1", 50000)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if criado != tt.esperadoCriado {
+				t.Errorf("criado esperado %v, got %v", tt.esperadoCriado, criado)
+			}
+
+			req := httpClient.ultimaRequisicao()
+			if req.payload["ConditionExpression"] != "attribute_not_exists(id)" {
+				t.Errorf("condição inesperada: %v", req.payload["ConditionExpression"])
+			}
+
+			item := req.payload["Item"].(map[string]interface{})
+			for _, atributo := range []string{"limite_credito", "limite_atual"} {
+				if valor := item[atributo].(map[string]interface{})["N"]; valor != "50000" {
+					t.Errorf("%s esperado 50000, got %v", atributo, valor)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCliente_Email(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		esperado string
+		erro     error
+	}{
+		{name: "válido", email: "fulano@exemplo.com", esperado: "fulano@exemplo.com"},
+		{name: "normalizado para minúsculas", email: "Fulano@Exemplo.COM", esperado: "fulano@exemplo.com"},
+		{name: "malformado não é gravado", email: "fulano.exemplo.com", erro: domain.ErrEmailInvalido},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: "{}"}
+			repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+			err := repo.CreateCliente(context.Background(), &domain.Cliente{ID: "c1", Email: tt.email, LimiteCredit: 50000, LimiteAtual: 50000})
+			if err != tt.erro {
+				t.Fatalf("erro esperado %v, got %v", tt.erro, err)
+			}
+
+			if tt.erro != nil {
+				if len(httpClient.requisicoes) != 0 {
+					t.Error("cliente com e-mail inválido não deveria chegar ao DynamoDB")
+				}
+				return
+			}
+
+			item := httpClient.ultimaRequisicao().payload["Item"].(map[string]interface{})
+			if email := item["email"].(map[string]interface{})["S"]; email != tt.esperado {
+				t.Errorf("e-mail gravado esperado %q, got %v", tt.esperado, email)
+			}
+		})
+	}
+}
+
+func TestDebitarLimiteAtomica_ThrottlingAlemDasRetentativas(t *testing.T) {
+	tests := []struct {
+		name     string
+		excecao  string
+		throttle bool
+	}{
+		{name: "capacidade provisionada excedida", excecao: "ProvisionedThroughputExceededException", throttle: true},
+		{name: "limite de requisições da conta", excecao: "RequestLimitExceeded", throttle: true},
+		{name: "condicional não é throttling", excecao: "ConditionalCheckFailedException"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: `{"Item":{"id":{"S":"c1"},"limite_atual":{"N":"0"}}}`, excecoes: map[string]string{"UpdateItem": tt.excecao}}
+			client := dynamodb.New(dynamodb.Options{
+				Region:      "us-east-1",
+				Credentials: aws.AnonymousCredentials{},
+				HTTPClient:  httpClient,
+				Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+					o.MaxAttempts = 3
+					o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+				}),
+			})
+			repo := NewLimiteRepository(client, "clientes")
+
+			_, err := repo.DebitarLimiteAtomica(context.Background(), "c1", 100)
+			if errors.Is(err, domain.ErrThrottled) != tt.throttle {
+				t.Fatalf("errors.Is(err, ErrThrottled) esperado %v, got %v", tt.throttle, err)
+			}
+
+			if !tt.throttle {
+				if err != domain.ErrLimiteInsuficiente {
+					t.Errorf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+				}
+				return
+			}
+
+			httpClient.mu.Lock()
+			defer httpClient.mu.Unlock()
+			if len(httpClient.requisicoes) != 3 {
+				t.Errorf("esperadas 3 tentativas antes de desistir, got %d", len(httpClient.requisicoes))
+			}
+		})
+	}
+}
+
+func TestTabelaInexistenteEhConfiguracaoInvalida(t *testing.T) {
+	httpClient := &fakeHTTPClient{excecoes: map[string]string{
+		"GetItem":    "ResourceNotFoundException",
+		"UpdateItem": "ResourceNotFoundException",
+	}}
+	metrics := &contadorErros{}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes-inexistente", WithObservabilidade(metrics, nil))
+
+	_, err := repo.GetCliente(context.Background(), "c1")
+	if !errors.Is(err, domain.ErrConfiguracaoInvalida) {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrConfiguracaoInvalida, err)
+	}
+	var causa *types.ResourceNotFoundException
+	if !errors.As(err, &causa) {
+		t.Errorf("causa original deveria ser preservada, got %v", err)
+	}
+
+	if _, err := repo.DebitarLimiteAtomica(context.Background(), "c1", 100); !errors.Is(err, domain.ErrConfiguracaoInvalida) {
+		t.Errorf("Erro esperado %v no débito, got %v", domain.ErrConfiguracaoInvalida, err)
+	}
+
+	if metrics.contas["configuration_error"] != 2 {
+		t.Errorf("métrica configuration_error esperada 2, got %d", metrics.contas["configuration_error"])
+	}
+}
+
+func TestDebitarLimiteAtomica_ContextoExpiradoPulaLeituraDeFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A requisição expira enquanto o UpdateItem condicional falha
+	httpClient := &fakeHTTPClient{
+		corpo:        `{"Item":{"id":{"S":"c1"},"limite_atual":{"N":"0"}}}`,
+		excecoes:     map[string]string{"UpdateItem": "ConditionalCheckFailedException"},
+		aposResposta: func(string) { cancel() },
+	}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	if _, err := repo.DebitarLimiteAtomica(ctx, "c1", 100); err != domain.ErrLimiteInsuficiente {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteInsuficiente, err)
+	}
+
+	httpClient.mu.Lock()
+	defer httpClient.mu.Unlock()
+	for _, req := range httpClient.requisicoes {
+		if req.operacao == "GetItem" {
+			t.Fatal("GetCliente de fallback não deveria rodar com o contexto expirado")
+		}
+	}
+}
+
+func TestAjustarLimite_CondicionalAoValorLido(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: `{}`}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	if err := repo.AjustarLimite(context.Background(), "c1", 7000, 8000); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	update := httpClient.ultimaRequisicao().payload
+	if cond := update["ConditionExpression"]; cond != "limite_atual = :lido" {
+		t.Errorf("condição esperada limite_atual = :lido, got %v", cond)
+	}
+	valores := update["ExpressionAttributeValues"].(map[string]interface{})
+	if lido := valores[":lido"].(map[string]interface{})["N"]; lido != "7000" {
+		t.Errorf("valor lido esperado 7000, got %v", lido)
+	}
+}
+
+func TestAjustarLimite_AlteradoConcorrentemente(t *testing.T) {
+	httpClient := &fakeHTTPClient{
+		corpo:    `{}`,
+		excecoes: map[string]string{"UpdateItem": "ConditionalCheckFailedException"},
+	}
+	repo := NewLimiteRepository(newFakeDynamoClient(httpClient), "clientes")
+
+	err := repo.AjustarLimite(context.Background(), "c1", 7000, 8000)
+	if !errors.Is(err, domain.ErrLimiteAlteradoConcorrente) {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrLimiteAlteradoConcorrente, err)
+	}
+}
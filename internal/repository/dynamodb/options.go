@@ -0,0 +1,274 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Timeouts define o orçamento de tempo de cada tipo de operação no DynamoDB,
+// evitando que uma leitura lenta consuma todo o prazo da requisição
+type Timeouts struct {
+	GetItem      time.Duration
+	UpdateItem   time.Duration
+	PutItem      time.Duration
+	Query        time.Duration
+	BatchGetItem time.Duration
+}
+
+// DefaultTimeouts retorna orçamentos compatíveis com a meta de latência da API
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		GetItem:      200 * time.Millisecond,
+		UpdateItem:   300 * time.Millisecond,
+		PutItem:      300 * time.Millisecond,
+		Query:        500 * time.Millisecond,
+		BatchGetItem: 500 * time.Millisecond,
+	}
+}
+
+// Option configura comportamentos opcionais dos repositórios
+type Option func(*opcoes)
+
+type opcoes struct {
+	timeouts       Timeouts
+	layout         LayoutTransacoes
+	limiteNegativo PoliticaLimiteNegativo
+	corrompidos    PoliticaItemCorrompido
+	metrics        domain.MetricsCollector
+	logger         domain.Logger
+	limiarLentidao time.Duration
+	tabelaOutbox   string
+
+	toleranciaRelogio time.Duration
+	maxConsulta       int
+	cadeiaAuditoria   bool
+}
+
+// maxTransacoesConsultaPadrao limita os itens devolvidos por GetByClienteID
+const maxTransacoesConsultaPadrao = 100
+
+func novasOpcoes(opts []Option) opcoes {
+	o := opcoes{
+		timeouts:       DefaultTimeouts(),
+		limiteNegativo: LimiteNegativoPassthrough,
+		corrompidos:    ItemCorrompidoRegistrar,
+		maxConsulta:    maxTransacoesConsultaPadrao,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTimeouts substitui os timeouts padrão por operação
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(o *opcoes) {
+		o.timeouts = timeouts
+	}
+}
+
+// WithObservabilidade permite aos repositórios registrar anomalias de dados
+func WithObservabilidade(metrics domain.MetricsCollector, logger domain.Logger) Option {
+	return func(o *opcoes) {
+		o.metrics = metrics
+		o.logger = logger
+	}
+}
+
+// WithLimiarOperacaoLenta registra um aviso estruturado e incrementa a
+// métrica slow_operation para operações que demoram mais que limiar (requer
+// WithObservabilidade). Zero desliga (padrão).
+func WithLimiarOperacaoLenta(limiar time.Duration) Option {
+	return func(o *opcoes) {
+		o.limiarLentidao = limiar
+	}
+}
+
+// WithTabelaOutbox define a tabela onde TransacaoRepository.SaveComOutbox
+// grava os eventos pendentes de publicação
+func WithTabelaOutbox(nome string) Option {
+	return func(o *opcoes) {
+		o.tabelaOutbox = nome
+	}
+}
+
+// WithToleranciaRelogioTTL define o desvio máximo aceito entre o timestamp da
+// transação e o horário do servidor no cálculo do TTL; além dele, a retenção
+// conta a partir do horário do servidor. Zero desliga a verificação (padrão),
+// mas o TTL nunca é gravado no passado.
+func WithToleranciaRelogioTTL(tolerancia time.Duration) Option {
+	return func(o *opcoes) {
+		o.toleranciaRelogio = tolerancia
+	}
+}
+
+// WithMaxTransacoesConsulta define o máximo de itens de
+// TransacaoRepository.GetByClienteID (padrão 100); limites maiores são
+// reduzidos a ele. Valores não positivos mantêm o padrão.
+func WithMaxTransacoesConsulta(max int) Option {
+	return func(o *opcoes) {
+		if max > 0 {
+			o.maxConsulta = max
+		}
+	}
+}
+
+// WithCadeiaHashAuditoria liga a cadeia de hashes por cliente no
+// AuditoriaRepository: cada entrada guarda o hash da anterior, tornando
+// detectável a alteração ou remoção de entradas
+func WithCadeiaHashAuditoria() Option {
+	return func(o *opcoes) {
+		o.cadeiaAuditoria = true
+	}
+}
+
+// PoliticaLimiteNegativo define o tratamento, na leitura, de cliente com
+// limite_atual negativo
+type PoliticaLimiteNegativo string
+
+const (
+	// LimiteNegativoPassthrough devolve o valor lido sem alteração (padrão)
+	LimiteNegativoPassthrough PoliticaLimiteNegativo = "PASSTHROUGH"
+	// LimiteNegativoHeal corrige o limite para zero, inclusive na tabela
+	LimiteNegativoHeal PoliticaLimiteNegativo = "HEAL"
+	// LimiteNegativoFail falha a leitura com domain.ErrLimiteInconsistente
+	LimiteNegativoFail PoliticaLimiteNegativo = "FAIL"
+)
+
+// WithPoliticaLimiteNegativo seleciona o tratamento de limite negativo em GetCliente
+func WithPoliticaLimiteNegativo(politica PoliticaLimiteNegativo) Option {
+	return func(o *opcoes) {
+		o.limiteNegativo = politica
+	}
+}
+
+// PoliticaItemCorrompido define o tratamento de itens que falham na
+// deserialização em leituras de múltiplos itens
+type PoliticaItemCorrompido string
+
+const (
+	// ItemCorrompidoRegistrar descarta o item, incrementando a métrica
+	// corrupt_item e registrando a chave no log (padrão)
+	ItemCorrompidoRegistrar PoliticaItemCorrompido = "REGISTRAR"
+	// ItemCorrompidoRetornar devolve os itens válidos junto de um
+	// *domain.ItensCorrompidosError com as chaves que falharam
+	ItemCorrompidoRetornar PoliticaItemCorrompido = "RETORNAR"
+)
+
+// WithPoliticaItemCorrompido seleciona o tratamento de itens corrompidos
+func WithPoliticaItemCorrompido(politica PoliticaItemCorrompido) Option {
+	return func(o *opcoes) {
+		o.corrompidos = politica
+	}
+}
+
+// LayoutTransacoes define o esquema de chaves da tabela de transações
+type LayoutTransacoes int
+
+const (
+	// LayoutChaveSimples: chave primária "id" e GSI cliente-id-index para
+	// consultas por cliente (layout original)
+	LayoutChaveSimples LayoutTransacoes = iota
+	// LayoutChaveComposta: partição "cliente_id" e ordenação "sk"
+	// (timestamp#id), com GSI id-index para buscas por ID. O histórico do
+	// cliente sai ordenado e fortemente consistente da tabela base.
+	LayoutChaveComposta
+)
+
+// WithLayoutTransacoes seleciona o esquema de chaves da tabela de transações
+func WithLayoutTransacoes(layout LayoutTransacoes) Option {
+	return func(o *opcoes) {
+		o.layout = layout
+	}
+}
+
+// operacoesEscrita são as operações cujo timeout deixa o resultado
+// indeterminado: o DynamoDB pode ter aplicado a escrita
+var operacoesEscrita = map[string]bool{
+	"PutItem":            true,
+	"UpdateItem":         true,
+	"TransactWriteItems": true,
+}
+
+// executar roda a operação com o timeout informado. Quando o orçamento da
+// operação estoura (e o contexto da requisição ainda é válido), o erro
+// retornado envolve domain.ErrTimeoutOperacao; numa escrita, também
+// domain.ErrResultadoIndeterminado. Escrita interrompida pelo cancelamento ou
+// prazo do contexto do chamador também é indeterminada. Timeout zero desliga o limite.
+// Throttling que esgotou as retentativas do SDK envolve domain.ErrThrottled;
+// tabela inexistente envolve domain.ErrConfiguracaoInvalida.
+// Operações acima do limiar de lentidão são registradas (ver WithLimiarOperacaoLenta).
+func executar[T any](ctx context.Context, o *opcoes, timeout time.Duration, operacao string, fn func(context.Context) (T, error)) (T, error) {
+	defer o.registrarLentidao(ctx, operacao, time.Now())
+
+	opCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		opCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err := fn(opCtx)
+	if err != nil && timeout > 0 && ctx.Err() == nil && errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+		if operacoesEscrita[operacao] {
+			return result, fmt.Errorf("%w: %w: %s excedeu %s", domain.ErrResultadoIndeterminado, domain.ErrTimeoutOperacao, operacao, timeout)
+		}
+		return result, fmt.Errorf("%w: %s excedeu %s", domain.ErrTimeoutOperacao, operacao, timeout)
+	}
+	if err != nil && ctx.Err() != nil && operacoesEscrita[operacao] && errors.Is(err, ctx.Err()) {
+		return result, fmt.Errorf("%w: %s interrompida: %w", domain.ErrResultadoIndeterminado, operacao, err)
+	}
+
+	return result, o.classificarErro(ctx, operacao, err)
+}
+
+// registrarLentidao avisa quando a operação iniciada em inicio excedeu o limiar
+func (o *opcoes) registrarLentidao(ctx context.Context, operacao string, inicio time.Time) {
+	duracao := time.Since(inicio)
+	if o.limiarLentidao <= 0 || duracao < o.limiarLentidao {
+		return
+	}
+
+	if o.metrics != nil {
+		o.metrics.IncrementErrorCounter("slow_operation")
+	}
+	if o.logger != nil {
+		o.logger.Warn(ctx, "operação lenta no DynamoDB", map[string]interface{}{
+			"operacao":   operacao,
+			"duracao_ms": duracao.Milliseconds(),
+			"limiar_ms":  o.limiarLentidao.Milliseconds(),
+		})
+	}
+}
+
+// classificarErro marca erros de capacidade com domain.ErrThrottled e tabela
+// inexistente com domain.ErrConfiguracaoInvalida (registrada em log de erro:
+// é falha operacional, não transitória), preservando a causa original
+// (errors.As continua funcionando)
+func (o *opcoes) classificarErro(ctx context.Context, operacao string, err error) error {
+	var capacidade *types.ProvisionedThroughputExceededException
+	var requisicoes *types.RequestLimitExceeded
+	if errors.As(err, &capacidade) || errors.As(err, &requisicoes) {
+		return fmt.Errorf("%w: %s: %w", domain.ErrThrottled, operacao, err)
+	}
+
+	var tabela *types.ResourceNotFoundException
+	if errors.As(err, &tabela) {
+		if o.metrics != nil {
+			o.metrics.IncrementErrorCounter("configuration_error")
+		}
+		if o.logger != nil {
+			o.logger.Error(ctx, "CONFIGURAÇÃO INVÁLIDA: tabela do DynamoDB inexistente", err, map[string]interface{}{
+				"operacao": operacao,
+			})
+		}
+		return fmt.Errorf("%w: %s: %w", domain.ErrConfiguracaoInvalida, operacao, err)
+	}
+
+	return err
+}
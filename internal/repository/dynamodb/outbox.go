@@ -0,0 +1,201 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// OutboxItem é um evento gravado junto da transação, pendente de publicação.
+// A chave é a chave de deduplicação do evento: regravar o mesmo evento
+// lógico não cria uma segunda entrada. Pendente (o fragmento, de 1 a
+// fragmentosOutboxPendentes) existe só até o relay publicar o evento, e é a
+// chave do índice esparso indiceOutboxPendentes.
+type OutboxItem struct {
+	ID          string `dynamodbav:"id"`
+	TransacaoID string `dynamodbav:"transacao_id"`
+	Evento      string `dynamodbav:"evento"`
+	Payload     string `dynamodbav:"payload"`
+	CreatedAt   string `dynamodbav:"created_at"`
+	Pendente    int    `dynamodbav:"pendente,omitempty"`
+	PublicadoEm string `dynamodbav:"publicado_em,omitempty"`
+	TTL         int64  `dynamodbav:"ttl"`
+}
+
+// indiceOutboxPendentes é o GSI esparso (chave "pendente") que contém
+// apenas os eventos ainda não publicados
+const indiceOutboxPendentes = "pendentes-index"
+
+// fragmentosOutboxPendentes distribui os pendentes entre partições do índice:
+// com uma chave constante, todas as gravações cairiam na mesma partição
+const fragmentosOutboxPendentes = 16
+
+// fragmentoPendente escolhe o fragmento do evento pela sua chave (1 a
+// fragmentosOutboxPendentes; zero omitiria o atributo e o item do índice)
+func fragmentoPendente(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32()%fragmentosOutboxPendentes) + 1
+}
+
+// SaveComOutbox persiste a transação e o evento no outbox numa única
+// TransactWriteItems: ou ambos são gravados, ou nenhum
+func (r *TransacaoRepository) SaveComOutbox(ctx context.Context, transacao *domain.Transacao, evento *domain.TransacaoEvento) error {
+	if r.opcoes.tabelaOutbox == "" {
+		return fmt.Errorf("%w: tabela de outbox não configurada", domain.ErrConfiguracaoInvalida)
+	}
+
+	put, err := r.inputSave(ctx, transacao)
+	if err != nil {
+		return err
+	}
+
+	outbox, err := r.itemOutbox(transacao, evento)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:                 put.TableName,
+				Item:                      put.Item,
+				ConditionExpression:       put.ConditionExpression,
+				ExpressionAttributeNames:  put.ExpressionAttributeNames,
+				ExpressionAttributeValues: put.ExpressionAttributeValues,
+			}},
+			{Put: &types.Put{
+				TableName: aws.String(r.opcoes.tabelaOutbox),
+				Item:      outbox,
+			}},
+		},
+	}
+
+	_, err = executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "TransactWriteItems", func(ctx context.Context) (*dynamodb.TransactWriteItemsOutput, error) {
+		return r.client.TransactWriteItems(ctx, input)
+	})
+	if err != nil {
+		// A primeira razão corresponde ao Put da transação
+		var cancelada *types.TransactionCanceledException
+		if errors.As(err, &cancelada) && len(cancelada.CancellationReasons) > 0 &&
+			aws.ToString(cancelada.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return fmt.Errorf("transação %s já existe", transacao.ID)
+		}
+		return fmt.Errorf("erro ao salvar transação com outbox: %w", err)
+	}
+
+	return nil
+}
+
+// itemOutbox serializa o evento completo como registro do outbox
+func (r *TransacaoRepository) itemOutbox(transacao *domain.Transacao, evento *domain.TransacaoEvento) (map[string]types.AttributeValue, error) {
+	payload, err := json.Marshal(evento)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento do outbox: %w", err)
+	}
+
+	id := evento.ChaveDeduplicacao
+	if id == "" {
+		id = evento.Evento + "#" + evento.TransacaoID
+	}
+
+	agora := time.Now().UTC()
+	item, err := attributevalue.MarshalMap(&OutboxItem{
+		ID:          id,
+		TransacaoID: transacao.ID,
+		Evento:      evento.Evento,
+		Payload:     string(payload),
+		CreatedAt:   agora.Format(time.RFC3339),
+		Pendente:    fragmentoPendente(id),
+		// Mesma retenção das transações (90 dias)
+		TTL: agora.Unix() + (90 * 24 * 60 * 60),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar item do outbox: %w", err)
+	}
+	return item, nil
+}
+
+// OutboxRepository implementa domain.OutboxRelayRepository sobre a tabela
+// de outbox gravada por SaveComOutbox
+type OutboxRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opcoes    opcoes
+}
+
+func NewOutboxRepository(client DynamoDBAPI, tableName string, opts ...Option) *OutboxRepository {
+	return &OutboxRepository{
+		client:    client,
+		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
+	}
+}
+
+// ListarPendentes lê até limite eventos do índice de pendentes (todos os
+// fragmentos, pelo Scan do índice esparso). O índice é
+// eventualmente consistente: um evento recém-publicado pode voltar uma vez,
+// e é republicado (consumidores deduplicam por chave_deduplicacao).
+func (r *OutboxRepository) ListarPendentes(ctx context.Context, limite int) ([]*domain.EventoOutbox, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+		IndexName: aws.String(indiceOutboxPendentes),
+		Limit:     aws.Int32(int32(limite)),
+	}
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.Query, "Scan", func(ctx context.Context) (*dynamodb.ScanOutput, error) {
+		return r.client.Scan(ctx, input)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar eventos pendentes do outbox: %w", err)
+	}
+
+	pendentes := make([]*domain.EventoOutbox, 0, len(result.Items))
+	for _, av := range result.Items {
+		var item OutboxItem
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return nil, fmt.Errorf("erro ao deserializar item do outbox: %w", err)
+		}
+		var evento domain.TransacaoEvento
+		if err := json.Unmarshal([]byte(item.Payload), &evento); err != nil {
+			return nil, fmt.Errorf("erro ao deserializar evento do outbox %s: %w", item.ID, err)
+		}
+		pendentes = append(pendentes, &domain.EventoOutbox{ID: item.ID, Evento: &evento})
+	}
+
+	return pendentes, nil
+}
+
+// MarcarPublicado remove o evento do índice de pendentes; o item continua
+// na tabela até o TTL
+func (r *OutboxRepository) MarcarPublicado(ctx context.Context, id string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+		},
+		UpdateExpression:    aws.String("SET publicado_em = :agora REMOVE pendente"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":agora": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao marcar evento %s do outbox como publicado: %w", id, err)
+	}
+
+	return nil
+}
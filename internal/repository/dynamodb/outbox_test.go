@@ -0,0 +1,146 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestSaveComOutbox_GravaTransacaoEEventoNaMesmaEscrita(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes", WithTabelaOutbox("outbox"))
+
+	transacao := domain.NewTransacao("c1", 10.00, "corr")
+	transacao.Aprovar()
+	evento := transacao.ToEvento()
+	evento.ChaveDeduplicacao = "chave-1"
+
+	if err := repo.SaveComOutbox(context.Background(), transacao, evento); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	req := httpClient.ultimaRequisicao()
+	if req.operacao != "TransactWriteItems" {
+		t.Fatalf("operação esperada TransactWriteItems, got %s", req.operacao)
+	}
+
+	itens := req.payload["TransactItems"].([]interface{})
+	if len(itens) != 2 {
+		t.Fatalf("esperados 2 itens na transação, got %d", len(itens))
+	}
+
+	putTransacao := itens[0].(map[string]interface{})["Put"].(map[string]interface{})
+	if putTransacao["TableName"] != "transacoes" || putTransacao["ConditionExpression"] == nil {
+		t.Errorf("put da transação inesperado: %v", putTransacao)
+	}
+
+	putOutbox := itens[1].(map[string]interface{})["Put"].(map[string]interface{})
+	if putOutbox["TableName"] != "outbox" {
+		t.Errorf("tabela do outbox esperada outbox, got %v", putOutbox["TableName"])
+	}
+	item := putOutbox["Item"].(map[string]interface{})
+	if atributoS(item, "id") != "chave-1" || atributoS(item, "transacao_id") != transacao.ID {
+		t.Errorf("chaves do outbox inesperadas: %v", item)
+	}
+
+	var publicado domain.TransacaoEvento
+	if err := json.Unmarshal([]byte(atributoS(item, "payload")), &publicado); err != nil {
+		t.Fatalf("payload do outbox inválido: %v", err)
+	}
+	if publicado.Evento != domain.EventoTransacaoAprovada || publicado.ValorCentavos != 1000 {
+		t.Errorf("evento do outbox inesperado: %+v", publicado)
+	}
+}
+
+func TestSaveComOutbox_SemTabelaConfigurada(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	transacao := domain.NewTransacao("c1", 10.00, "corr")
+	err := repo.SaveComOutbox(context.Background(), transacao, transacao.ToEvento())
+	if !errors.Is(err, domain.ErrConfiguracaoInvalida) {
+		t.Errorf("erro esperado %v, got %v", domain.ErrConfiguracaoInvalida, err)
+	}
+	if len(httpClient.requisicoes) != 0 {
+		t.Error("nenhuma escrita deveria ser feita sem tabela de outbox")
+	}
+}
+
+func TestSaveComOutbox_EventoGravadoComoPendente(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes", WithTabelaOutbox("outbox"))
+
+	transacao := domain.NewTransacao("c1", 10.00, "corr")
+	if err := repo.SaveComOutbox(context.Background(), transacao, transacao.ToEvento()); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	itens := httpClient.ultimaRequisicao().payload["TransactItems"].([]interface{})
+	item := itens[1].(map[string]interface{})["Put"].(map[string]interface{})["Item"].(map[string]interface{})
+	fragmento, _ := strconv.Atoi(item["pendente"].(map[string]interface{})["N"].(string))
+	if fragmento < 1 || fragmento > fragmentosOutboxPendentes {
+		t.Errorf("evento deveria entrar num fragmento do índice de pendentes, got %d", fragmento)
+	}
+}
+
+func TestFragmentoPendente_DistribuiEntreFragmentos(t *testing.T) {
+	usados := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		fragmento := fragmentoPendente(fmt.Sprintf("chave-%d", i))
+		if fragmento < 1 || fragmento > fragmentosOutboxPendentes {
+			t.Fatalf("fragmento fora do intervalo: %d", fragmento)
+		}
+		usados[fragmento] = true
+	}
+
+	if len(usados) != fragmentosOutboxPendentes {
+		t.Errorf("esperados %d fragmentos em uso, got %d", fragmentosOutboxPendentes, len(usados))
+	}
+	if fragmentoPendente("k1") != fragmentoPendente("k1") {
+		t.Error("o fragmento deveria ser estável para a mesma chave")
+	}
+}
+
+func TestOutboxRepository_ListarPendentes(t *testing.T) {
+	payload, _ := json.Marshal(domain.TransacaoEvento{Evento: domain.EventoTransacaoAprovada, TransacaoID: "t1"})
+	corpo, _ := json.Marshal(map[string]interface{}{
+		"Items": []interface{}{map[string]interface{}{
+			"id":       map[string]string{"S": "k1"},
+			"payload":  map[string]string{"S": string(payload)},
+			"pendente": map[string]string{"N": "1"},
+		}},
+	})
+	httpClient := &fakeHTTPClient{corpo: string(corpo)}
+	repo := NewOutboxRepository(newFakeDynamoClient(httpClient), "outbox")
+
+	pendentes, err := repo.ListarPendentes(context.Background(), 50)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(pendentes) != 1 || pendentes[0].ID != "k1" || pendentes[0].Evento.TransacaoID != "t1" {
+		t.Fatalf("pendentes inesperados: %+v", pendentes)
+	}
+	req := httpClient.ultimaRequisicao().payload
+	if req["IndexName"] != indiceOutboxPendentes || req["Limit"] != float64(50) {
+		t.Errorf("leitura deveria usar o índice de pendentes com limite, got %v", req)
+	}
+}
+
+func TestOutboxRepository_MarcarPublicado(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewOutboxRepository(newFakeDynamoClient(httpClient), "outbox")
+
+	if err := repo.MarcarPublicado(context.Background(), "k1"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	req := httpClient.ultimaRequisicao().payload
+	if expr, _ := req["UpdateExpression"].(string); expr != "SET publicado_em = :agora REMOVE pendente" {
+		t.Errorf("evento publicado deveria sair do índice de pendentes, got %q", expr)
+	}
+}
@@ -0,0 +1,66 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// politicaAprovacaoID é a chave do documento de política na tabela de configuração
+const politicaAprovacaoID = "politica_aprovacao"
+
+type PolicyRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opcoes    opcoes
+}
+
+type PoliticaItem struct {
+	ID                        string  `dynamodbav:"id"`
+	ValorMaximoCentavos       int     `dynamodbav:"valor_maximo_centavos"`
+	MultiplicadorLimiteDiario float64 `dynamodbav:"multiplicador_limite_diario"`
+}
+
+func NewPolicyRepository(client DynamoDBAPI, tableName string, opts ...Option) *PolicyRepository {
+	return &PolicyRepository{
+		client:    client,
+		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
+	}
+}
+
+// GetPoliticaAprovacao busca o documento de política na tabela de configuração
+func (r *PolicyRepository) GetPoliticaAprovacao(ctx context.Context) (*domain.PoliticaAprovacao, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: politicaAprovacaoID},
+		},
+	}
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.GetItem, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return r.client.GetItem(ctx, input)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar política de aprovação: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, fmt.Errorf("política de aprovação não encontrada na tabela %s", r.tableName)
+	}
+
+	var item PoliticaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar política de aprovação: %w", err)
+	}
+
+	return &domain.PoliticaAprovacao{
+		ValorMaximoCentavos:       item.ValorMaximoCentavos,
+		MultiplicadorLimiteDiario: item.MultiplicadorLimiteDiario,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// StepUpDesafioRepository implementa domain.StepUpDesafioStore (chave
+// "desafio"). Os itens expiram junto com o token pelo TTL nativo no
+// atributo "ttl".
+type StepUpDesafioRepository struct {
+	client    DynamoDBAPI
+	tableName string
+	opcoes    opcoes
+}
+
+func NewStepUpDesafioRepository(client DynamoDBAPI, tableName string, opts ...Option) *StepUpDesafioRepository {
+	return &StepUpDesafioRepository{
+		client:    client,
+		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
+	}
+}
+
+// Consumir grava o resgate com escrita condicional: o desafio ainda não
+// resgatado, ou resgatado pela mesma transação
+func (r *StepUpDesafioRepository) Consumir(ctx context.Context, desafio, transacaoID string, expiraEm time.Time) error {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item: map[string]types.AttributeValue{
+			"desafio":      &types.AttributeValueMemberS{Value: desafio},
+			"transacao_id": &types.AttributeValueMemberS{Value: transacaoID},
+			"ttl":          &types.AttributeValueMemberN{Value: strconv.FormatInt(expiraEm.Unix(), 10)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(desafio) OR transacao_id = :transacao_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":transacao_id": &types.AttributeValueMemberS{Value: transacaoID},
+		},
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return r.client.PutItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return domain.ErrDesafioStepUpConsumido
+		}
+		return fmt.Errorf("erro ao consumir desafio de step-up %s: %w", desafio, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStepUpDesafioRepository_Consumir(t *testing.T) {
+	tests := []struct {
+		name     string
+		excecoes map[string]string
+		espera   error
+	}{
+		{name: "primeiro resgate"},
+		{name: "já resgatado por outra transação", excecoes: map[string]string{"PutItem": "ConditionalCheckFailedException"}, espera: domain.ErrDesafioStepUpConsumido},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: "{}", excecoes: tt.excecoes}
+			repo := NewStepUpDesafioRepository(newFakeDynamoClient(httpClient), "stepup-desafios")
+
+			expiraEm := time.Unix(1700000300, 0)
+			if err := repo.Consumir(context.Background(), "desafio-1", "t1", expiraEm); err != tt.espera {
+				t.Fatalf("erro esperado %v, got %v", tt.espera, err)
+			}
+
+			payload := httpClient.ultimaRequisicao().payload
+			if cond := payload["ConditionExpression"]; cond != "attribute_not_exists(desafio) OR transacao_id = :transacao_id" {
+				t.Errorf("resgate deveria ser condicional, got %v", cond)
+			}
+			item := payload["Item"].(map[string]interface{})
+			if atributoS(item, "desafio") != "desafio-1" || atributoS(item, "transacao_id") != "t1" {
+				t.Errorf("item inesperado: %v", item)
+			}
+			if ttl := item["ttl"].(map[string]interface{})["N"]; ttl != "1700000300" {
+				t.Errorf("ttl deveria ser a expiração do token, got %v", ttl)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetCliente_TimeoutPorOperacao(t *testing.T) {
+	client := newFakeDynamoClient(&fakeHTTPClient{atraso: 200 * time.Millisecond, corpo: "{}"})
+	repo := NewLimiteRepository(client, "clientes", WithTimeouts(Timeouts{GetItem: 20 * time.Millisecond}))
+
+	inicio := time.Now()
+	_, err := repo.GetCliente(context.Background(), "c1")
+
+	if !errors.Is(err, domain.ErrTimeoutOperacao) {
+		t.Fatalf("Erro esperado %v, got %v", domain.ErrTimeoutOperacao, err)
+	}
+
+	if decorrido := time.Since(inicio); decorrido > 150*time.Millisecond {
+		t.Errorf("operação deveria ser interrompida pelo timeout, levou %s", decorrido)
+	}
+	if errors.Is(err, domain.ErrResultadoIndeterminado) {
+		t.Errorf("timeout de leitura não deixa resultado indeterminado: %v", err)
+	}
+}
+
+func TestDebitarLimiteAtomica_TimeoutDeixaResultadoIndeterminado(t *testing.T) {
+	client := newFakeDynamoClient(&fakeHTTPClient{atraso: 200 * time.Millisecond, corpo: "{}"})
+	repo := NewLimiteRepository(client, "clientes", WithTimeouts(Timeouts{UpdateItem: 20 * time.Millisecond}))
+
+	_, err := repo.DebitarLimiteAtomica(context.Background(), "c1", 100)
+
+	if !errors.Is(err, domain.ErrResultadoIndeterminado) || !errors.Is(err, domain.ErrTimeoutOperacao) {
+		t.Fatalf("esperado timeout com resultado indeterminado, got %v", err)
+	}
+}
+
+func TestGetCliente_PrazoDaRequisicaoNaoEhTimeoutDeOperacao(t *testing.T) {
+	client := newFakeDynamoClient(&fakeHTTPClient{atraso: 200 * time.Millisecond, corpo: "{}"})
+	repo := NewLimiteRepository(client, "clientes", WithTimeouts(Timeouts{GetItem: time.Second}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := repo.GetCliente(ctx, "c1")
+	if err == nil {
+		t.Fatal("erro esperado quando o prazo da requisição expira")
+	}
+
+	if errors.Is(err, domain.ErrTimeoutOperacao) {
+		t.Errorf("prazo da requisição não deve ser reportado como timeout da operação: %v", err)
+	}
+}
+
+func TestDebitarLimiteAtomica_PrazoDaRequisicaoDeixaResultadoIndeterminado(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts Timeouts
+	}{
+		{name: "com timeout da operação", timeouts: Timeouts{UpdateItem: time.Second}},
+		{name: "sem timeout da operação", timeouts: Timeouts{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeDynamoClient(&fakeHTTPClient{atraso: 200 * time.Millisecond, corpo: "{}"})
+			repo := NewLimiteRepository(client, "clientes", WithTimeouts(tt.timeouts))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			_, err := repo.DebitarLimiteAtomica(ctx, "c1", 100)
+			if !errors.Is(err, domain.ErrResultadoIndeterminado) {
+				t.Fatalf("escrita interrompida pelo prazo da requisição deveria ser indeterminada, got %v", err)
+			}
+			if errors.Is(err, domain.ErrTimeoutOperacao) {
+				t.Errorf("prazo da requisição não deve ser reportado como timeout da operação: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetCliente_DentroDoTimeout(t *testing.T) {
+	corpo := `{"Item":{"id":{"S":"c1"},"limite_credito":{"N":"1000"},"limite_atual":{"N":"400"}}}`
+	client := newFakeDynamoClient(&fakeHTTPClient{atraso: time.Millisecond, corpo: corpo})
+	repo := NewLimiteRepository(client, "clientes")
+
+	cliente, err := repo.GetCliente(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if cliente.LimiteAtual != 400 {
+		t.Errorf("LimiteAtual esperado 400, got %d", cliente.LimiteAtual)
+	}
+}
+
+func TestGetCliente_OperacaoLentaRegistrada(t *testing.T) {
+	corpo := `{"Item":{"id":{"S":"c1"},"limite_credito":{"N":"1000"},"limite_atual":{"N":"400"}}}`
+	client := newFakeDynamoClient(&fakeHTTPClient{atraso: 30 * time.Millisecond, corpo: corpo})
+	metrics := &contadorErros{}
+	logger := &registroAvisos{}
+	repo := NewLimiteRepository(client, "clientes",
+		WithObservabilidade(metrics, logger), WithLimiarOperacaoLenta(10*time.Millisecond))
+
+	if _, err := repo.GetCliente(context.Background(), "c1"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if metrics.contas["slow_operation"] != 1 {
+		t.Errorf("métrica slow_operation esperada 1, got %d", metrics.contas["slow_operation"])
+	}
+	if len(logger.avisos) != 1 {
+		t.Fatalf("1 aviso esperado, got %d", len(logger.avisos))
+	}
+	aviso := logger.avisos[0]
+	if aviso["operacao"] != "GetItem" {
+		t.Errorf("operação esperada GetItem, got %v", aviso["operacao"])
+	}
+	if duracao, _ := aviso["duracao_ms"].(int64); duracao < 30 {
+		t.Errorf("duração registrada deveria ser >= 30ms, got %v", aviso["duracao_ms"])
+	}
+}
+
+func TestGetCliente_OperacaoAbaixoDoLimiarNaoRegistrada(t *testing.T) {
+	corpo := `{"Item":{"id":{"S":"c1"},"limite_credito":{"N":"1000"},"limite_atual":{"N":"400"}}}`
+	client := newFakeDynamoClient(&fakeHTTPClient{corpo: corpo})
+	metrics := &contadorErros{}
+	logger := &registroAvisos{}
+	repo := NewLimiteRepository(client, "clientes",
+		WithObservabilidade(metrics, logger), WithLimiarOperacaoLenta(time.Second))
+
+	if _, err := repo.GetCliente(context.Background(), "c1"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if metrics.contas["slow_operation"] != 0 || len(logger.avisos) != 0 {
+		t.Errorf("operação rápida não deveria ser registrada: métrica %d, avisos %d",
+			metrics.contas["slow_operation"], len(logger.avisos))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -13,69 +14,151 @@ import (
 )
 
 type TransacaoRepository struct {
-	client    *dynamodb.Client
+	client    DynamoDBAPI
 	tableName string
+	opcoes    opcoes
 }
 
 type TransacaoItem struct {
 	ID            string  `dynamodbav:"id"`
+	SK            string  `dynamodbav:"sk,omitempty"` // Apenas no layout de chave composta
 	ClienteID     string  `dynamodbav:"cliente_id"`
 	Valor         float64 `dynamodbav:"valor"`
+	Moeda         string  `dynamodbav:"moeda,omitempty"`
 	Status        string  `dynamodbav:"status"`
 	Timestamp     string  `dynamodbav:"timestamp"`
 	CorrelationID string  `dynamodbav:"correlation_id"`
+	Canal         string  `dynamodbav:"canal"`
+	Parcelas      int     `dynamodbav:"parcelas,omitempty"`
+	Tipo          string  `dynamodbav:"tipo,omitempty"`
 	TTL           int64   `dynamodbav:"ttl"` // Para limpeza automática de dados antigos
+
+	SuspeitaDuplicidade bool `dynamodbav:"suspeita_duplicidade,omitempty"`
+	// DataAgendamento (RFC3339 em UTC) existe apenas em transações agendadas
+	DataAgendamento string `dynamodbav:"data_agendamento,omitempty"`
+	// Tags é gravado como mapa do DynamoDB (M); ausente quando vazio
+	Tags map[string]string `dynamodbav:"tags,omitempty"`
 }
 
-func NewTransacaoRepository(client *dynamodb.Client, tableName string) *TransacaoRepository {
+func NewTransacaoRepository(client DynamoDBAPI, tableName string, opts ...Option) *TransacaoRepository {
 	return &TransacaoRepository{
 		client:    client,
 		tableName: tableName,
+		opcoes:    novasOpcoes(opts),
 	}
 }
 
 // Save persiste uma transação no DynamoDB
 func (r *TransacaoRepository) Save(ctx context.Context, transacao *domain.Transacao) error {
+	input, err := r.inputSave(ctx, transacao)
+	if err != nil {
+		return err
+	}
+
+	_, err = executar(ctx, &r.opcoes, r.opcoes.timeouts.PutItem, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return r.client.PutItem(ctx, input)
+	})
+	if err != nil {
+		// Se a transação já existe, não é um erro crítico (idempotência)
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return fmt.Errorf("transação %s já existe", transacao.ID)
+		}
+		return fmt.Errorf("erro ao salvar transação: %w", err)
+	}
+
+	return nil
+}
+
+// inputSave monta o PutItem condicional da transação
+func (r *TransacaoRepository) inputSave(ctx context.Context, transacao *domain.Transacao) (*dynamodb.PutItemInput, error) {
 	// TTL para 90 dias (limpeza automática de dados antigos)
-	ttl := transacao.Timestamp.Unix() + (90 * 24 * 60 * 60)
+	ttl := r.calcularTTL(ctx, transacao)
 
 	item := &TransacaoItem{
 		ID:            transacao.ID,
 		ClienteID:     transacao.ClienteID,
 		Valor:         transacao.Valor,
+		Moeda:         transacao.Moeda,
 		Status:        transacao.Status,
 		Timestamp:     transacao.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
 		CorrelationID: transacao.CorrelationID,
+		Canal:         transacao.Canal,
+		Parcelas:      transacao.Parcelas,
+		Tipo:          transacao.Tipo,
 		TTL:           ttl,
+		SK:            r.sortKey(transacao),
+
+		SuspeitaDuplicidade: transacao.SuspeitaDuplicidade,
+		DataAgendamento:     formatarDataAgendamento(transacao.DataAgendamento),
+		Tags:                transacao.Tags,
 	}
 
 	av, err := attributevalue.MarshalMap(item)
 	if err != nil {
-		return fmt.Errorf("erro ao serializar transação: %w", err)
+		return nil, fmt.Errorf("erro ao serializar transação: %w", err)
 	}
 
-	input := &dynamodb.PutItemInput{
+	return &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
 		Item:      av,
-		// Evita sobrescrever transação existente (idempotência)
-		ConditionExpression: aws.String("attribute_not_exists(id)"),
+		// Evita sobrescrever transação existente (idempotência). A exceção é a
+		// transação agendada em execução (PENDENTE), que recebe o status final.
+		ConditionExpression: aws.String("attribute_not_exists(id) OR #status = :pendente"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pendente": &types.AttributeValueMemberS{Value: domain.StatusPendente},
+		},
+	}, nil
+}
+
+// retencaoTransacoes é o prazo até a limpeza automática (TTL) das transações
+const retencaoTransacoes = 90 * 24 * time.Hour
+
+// calcularTTL conta a retenção a partir do timestamp da transação. Timestamp
+// fora da tolerância de relógio (WithToleranciaRelogioTTL) é trocado pelo
+// horário do servidor, e o TTL nunca fica no passado: o item seria removido
+// logo após a gravação.
+func (r *TransacaoRepository) calcularTTL(ctx context.Context, transacao *domain.Transacao) int64 {
+	agora := time.Now()
+	base := transacao.Timestamp
+
+	tolerancia := r.opcoes.toleranciaRelogio
+	if tolerancia > 0 && (base.After(agora.Add(tolerancia)) || base.Before(agora.Add(-tolerancia))) {
+		r.registrarRelogioDivergente(ctx, transacao, agora)
+		base = agora
 	}
 
-	_, err = r.client.PutItem(ctx, input)
-	if err != nil {
-		// Se a transação já existe, não é um erro crítico (idempotência)
-		var condErr *types.ConditionalCheckFailedException
-		if errors.As(err, &condErr) {
-			return fmt.Errorf("transação %s já existe", transacao.ID)
-		}
-		return fmt.Errorf("erro ao salvar transação: %w", err)
+	ttl := base.Add(retencaoTransacoes).Unix()
+	if ttl <= agora.Unix() {
+		r.registrarRelogioDivergente(ctx, transacao, agora)
+		ttl = agora.Add(retencaoTransacoes).Unix()
 	}
+	return ttl
+}
 
-	return nil
+// registrarRelogioDivergente contabiliza o TTL recalculado pelo horário do servidor
+func (r *TransacaoRepository) registrarRelogioDivergente(ctx context.Context, transacao *domain.Transacao, agora time.Time) {
+	if r.opcoes.metrics != nil {
+		r.opcoes.metrics.IncrementErrorCounter("ttl_clock_skew")
+	}
+	if r.opcoes.logger != nil {
+		r.opcoes.logger.Warn(ctx, "timestamp implausível no cálculo do TTL", map[string]interface{}{
+			"transacao_id": transacao.ID,
+			"timestamp":    transacao.Timestamp.Format(time.RFC3339),
+			"desvio_s":     int64(transacao.Timestamp.Sub(agora).Seconds()),
+		})
+	}
 }
 
 // GetByID busca uma transação por ID
 func (r *TransacaoRepository) GetByID(ctx context.Context, transacaoID string) (*domain.Transacao, error) {
+	if r.opcoes.layout == LayoutChaveComposta {
+		return r.getByIDIndice(ctx, transacaoID)
+	}
+
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(r.tableName),
 		Key: map[string]types.AttributeValue{
@@ -84,7 +167,9 @@ func (r *TransacaoRepository) GetByID(ctx context.Context, transacaoID string) (
 		ConsistentRead: aws.Bool(true),
 	}
 
-	result, err := r.client.GetItem(ctx, input)
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.GetItem, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return r.client.GetItem(ctx, input)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar transação %s: %w", transacaoID, err)
 	}
@@ -101,8 +186,62 @@ func (r *TransacaoRepository) GetByID(ctx context.Context, transacaoID string) (
 	return r.itemToTransacao(&item), nil
 }
 
-// GetByClienteID busca transações de um cliente específico (útil para auditoria)
+// GetByClienteID busca transações de um cliente específico (útil para
+// auditoria). limit deve ser positivo e é reduzido ao máximo configurado
+// (WithMaxTransacoesConsulta), evitando consultas sem limite efetivo.
 func (r *TransacaoRepository) GetByClienteID(ctx context.Context, clienteID string, limit int) ([]*domain.Transacao, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("erro ao buscar transações do cliente %s: limit %d: %w", clienteID, limit, domain.ErrLimiteConsultaInvalido)
+	}
+	if limit > r.opcoes.maxConsulta {
+		limit = r.opcoes.maxConsulta
+	}
+
+	input := r.inputPorCliente(clienteID, limit)
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.Query, "Query", func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+		return r.client.Query(ctx, input)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar transações do cliente %s: %w", clienteID, err)
+	}
+
+	return r.converterItens(ctx, result.Items)
+}
+
+// PercorrerPorClienteID entrega o histórico completo do cliente página a
+// página para fn, sem acumular tudo em memória (exportações de clientes muito
+// ativos). Um erro de fn interrompe a leitura e é devolvido como está.
+func (r *TransacaoRepository) PercorrerPorClienteID(ctx context.Context, clienteID string, tamanhoPagina int, fn func(pagina []*domain.Transacao) error) error {
+	input := r.inputPorCliente(clienteID, tamanhoPagina)
+
+	for {
+		result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.Query, "Query", func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+			return r.client.Query(ctx, input)
+		})
+		if err != nil {
+			return fmt.Errorf("erro ao percorrer transações do cliente %s: %w", clienteID, err)
+		}
+
+		pagina, errCorrompidos := r.converterItens(ctx, result.Items)
+		if len(pagina) > 0 {
+			if err := fn(pagina); err != nil {
+				return err
+			}
+		}
+		if errCorrompidos != nil {
+			return errCorrompidos
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// inputPorCliente monta a consulta do histórico do cliente, mais recentes primeiro
+func (r *TransacaoRepository) inputPorCliente(clienteID string, limit int) *dynamodb.QueryInput {
 	// Assumindo que temos um GSI (Global Secondary Index) por cliente_id
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
@@ -115,35 +254,271 @@ func (r *TransacaoRepository) GetByClienteID(ctx context.Context, clienteID stri
 		ScanIndexForward: aws.Bool(false), // Ordem decrescente (mais recentes primeiro)
 	}
 
-	result, err := r.client.Query(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar transações do cliente %s: %w", clienteID, err)
+	// No layout composto o cliente é a partição da tabela base: dispensa o
+	// GSI e permite leitura fortemente consistente
+	if r.opcoes.layout == LayoutChaveComposta {
+		input.IndexName = nil
+		input.ConsistentRead = aws.Bool(true)
 	}
 
-	transacoes := make([]*domain.Transacao, 0, len(result.Items))
-	for _, item := range result.Items {
+	return input
+}
+
+// converterItens desserializa os itens de uma página; itens corrompidos não
+// impedem a leitura dos demais e seguem a política configurada
+func (r *TransacaoRepository) converterItens(ctx context.Context, itens []map[string]types.AttributeValue) ([]*domain.Transacao, error) {
+	transacoes := make([]*domain.Transacao, 0, len(itens))
+	var corrompidos domain.ItensCorrompidosError
+	for _, item := range itens {
 		var transacaoItem TransacaoItem
 		if err := attributevalue.UnmarshalMap(item, &transacaoItem); err != nil {
-			// Log do erro, mas continua processando outras transações
+			corrompidos.Chaves = append(corrompidos.Chaves, chaveItem(item))
+			corrompidos.Causas = append(corrompidos.Causas, err)
 			continue
 		}
 		transacoes = append(transacoes, r.itemToTransacao(&transacaoItem))
 	}
 
+	if len(corrompidos.Chaves) > 0 {
+		return transacoes, r.tratarItensCorrompidos(ctx, &corrompidos)
+	}
+
+	return transacoes, nil
+}
+
+// tratarItensCorrompidos aplica a política configurada: registra métrica e
+// log (descartando os itens) ou devolve o erro junto dos itens válidos
+func (r *TransacaoRepository) tratarItensCorrompidos(ctx context.Context, corrompidos *domain.ItensCorrompidosError) error {
+	if r.opcoes.corrompidos == ItemCorrompidoRetornar {
+		return corrompidos
+	}
+
+	for i, chave := range corrompidos.Chaves {
+		if r.opcoes.metrics != nil {
+			r.opcoes.metrics.IncrementErrorCounter("corrupt_item")
+		}
+		if r.opcoes.logger != nil {
+			r.opcoes.logger.Error(ctx, "item de transação corrompido", corrompidos.Causas[i], map[string]interface{}{
+				"tabela": r.tableName,
+				"chave":  chave,
+			})
+		}
+	}
+
+	return nil
+}
+
+// chaveItem identifica o item para diagnóstico: id, ou sk no layout composto
+func chaveItem(item map[string]types.AttributeValue) string {
+	for _, nome := range []string{"id", "sk"} {
+		if valor, ok := item[nome].(*types.AttributeValueMemberS); ok {
+			return valor.Value
+		}
+	}
+	return "desconhecida"
+}
+
+// ListarAprovadasNoPeriodo varre a tabela (Scan paginado) atrás das transações
+// aprovadas na janela. É custoso e serve apenas a jobs fora do caminho crítico,
+// como a reconciliação de eventos. Compara o timestamp como string RFC3339,
+// o que pressupõe gravação em UTC (padrão no Lambda).
+func (r *TransacaoRepository) ListarAprovadasNoPeriodo(ctx context.Context, inicio, fim time.Time) ([]*domain.Transacao, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("#status = :aprovada AND #ts BETWEEN :inicio AND :fim"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+			"#ts":     "timestamp",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":aprovada": &types.AttributeValueMemberS{Value: domain.StatusAprovada},
+			":inicio":   &types.AttributeValueMemberS{Value: inicio.UTC().Format("2006-01-02T15:04:05Z07:00")},
+			":fim":      &types.AttributeValueMemberS{Value: fim.UTC().Format("2006-01-02T15:04:05Z07:00")},
+		},
+	}
+
+	transacoes, err := r.scan(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar transações aprovadas: %w", err)
+	}
+	return transacoes, nil
+}
+
+// ListarAgendadasAte varre a tabela (Scan paginado) atrás das transações
+// agendadas com data até ate. Assim como ListarAprovadasNoPeriodo, serve
+// apenas a jobs fora do caminho crítico.
+func (r *TransacaoRepository) ListarAgendadasAte(ctx context.Context, ate time.Time) ([]*domain.Transacao, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("#status = :agendada AND data_agendamento <= :ate"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":agendada": &types.AttributeValueMemberS{Value: domain.StatusAgendada},
+			":ate":      &types.AttributeValueMemberS{Value: ate.UTC().Format("2006-01-02T15:04:05Z07:00")},
+		},
+	}
+
+	transacoes, err := r.scan(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar transações agendadas: %w", err)
+	}
 	return transacoes, nil
 }
 
+// IniciarExecucao muda a transação de AGENDADA para PENDENTE com escrita
+// condicional, impedindo que duas execuções concorrentes debitem o limite
+func (r *TransacaoRepository) IniciarExecucao(ctx context.Context, transacao *domain.Transacao) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 r.chave(transacao),
+		UpdateExpression:    aws.String("SET #status = :pendente"),
+		ConditionExpression: aws.String("#status = :agendada"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pendente": &types.AttributeValueMemberS{Value: domain.StatusPendente},
+			":agendada": &types.AttributeValueMemberS{Value: domain.StatusAgendada},
+		},
+	}
+
+	_, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.UpdateItem, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return r.client.UpdateItem(ctx, input)
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return domain.ErrAgendamentoJaIniciado
+		}
+		return fmt.Errorf("erro ao iniciar execução da transação agendada: %w", err)
+	}
+
+	return nil
+}
+
+// scan percorre todas as páginas do Scan convertendo os itens em transações
+func (r *TransacaoRepository) scan(ctx context.Context, input *dynamodb.ScanInput) ([]*domain.Transacao, error) {
+	transacoes := make([]*domain.Transacao, 0)
+	for {
+		result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.Query, "Scan", func(ctx context.Context) (*dynamodb.ScanOutput, error) {
+			return r.client.Scan(ctx, input)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Items {
+			var transacaoItem TransacaoItem
+			if err := attributevalue.UnmarshalMap(item, &transacaoItem); err != nil {
+				return nil, fmt.Errorf("erro ao deserializar transação: %w", err)
+			}
+			transacoes = append(transacoes, r.itemToTransacao(&transacaoItem))
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return transacoes, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// getByIDIndice busca uma transação pelo GSI id-index (layout de chave composta).
+// Leituras em GSI são eventualmente consistentes.
+func (r *TransacaoRepository) getByIDIndice(ctx context.Context, transacaoID string) (*domain.Transacao, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("id-index"),
+		KeyConditionExpression: aws.String("id = :id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": &types.AttributeValueMemberS{Value: transacaoID},
+		},
+		Limit: aws.Int32(1),
+	}
+
+	result, err := executar(ctx, &r.opcoes, r.opcoes.timeouts.Query, "Query", func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+		return r.client.Query(ctx, input)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar transação %s: %w", transacaoID, err)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, fmt.Errorf("transação %s não encontrada", transacaoID)
+	}
+
+	var item TransacaoItem
+	if err := attributevalue.UnmarshalMap(result.Items[0], &item); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar transação: %w", err)
+	}
+
+	return r.itemToTransacao(&item), nil
+}
+
+// formatoSortKey tem largura fixa em UTC para que a ordem lexicográfica da
+// sort key coincida com a ordem cronológica
+const formatoSortKey = "2006-01-02T15:04:05.000000000Z"
+
+// sortKey monta a chave de ordenação timestamp#id do layout composto
+// chave monta a chave primária da transação conforme o layout
+func (r *TransacaoRepository) chave(transacao *domain.Transacao) map[string]types.AttributeValue {
+	if r.opcoes.layout == LayoutChaveComposta {
+		return map[string]types.AttributeValue{
+			"cliente_id": &types.AttributeValueMemberS{Value: transacao.ClienteID},
+			"sk":         &types.AttributeValueMemberS{Value: r.sortKey(transacao)},
+		}
+	}
+	return map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: transacao.ID},
+	}
+}
+
+func (r *TransacaoRepository) sortKey(transacao *domain.Transacao) string {
+	if r.opcoes.layout != LayoutChaveComposta {
+		return ""
+	}
+	return transacao.Timestamp.UTC().Format(formatoSortKey) + "#" + transacao.ID
+}
+
 // Converte item do DynamoDB para entidade de domínio
 func (r *TransacaoRepository) itemToTransacao(item *TransacaoItem) *domain.Transacao {
-	// Em uma implementação real, faria o parsing do timestamp
-	// timestamp, _ := time.Parse("2006-01-02T15:04:05Z07:00", item.Timestamp)
+	// O timestamp é necessário para regras baseadas em janela de tempo
+	// (ex.: detecção de duplicidade); valores inválidos ficam zerados
+	timestamp, _ := time.Parse("2006-01-02T15:04:05Z07:00", item.Timestamp)
 
 	return &domain.Transacao{
 		ID:            item.ID,
 		ClienteID:     item.ClienteID,
 		Valor:         item.Valor,
+		Moeda:         item.Moeda,
 		Status:        item.Status,
+		Timestamp:     timestamp,
 		CorrelationID: item.CorrelationID,
-		// Timestamp:     timestamp,
+		Canal:         item.Canal,
+		Parcelas:      item.Parcelas,
+		Tipo:          item.Tipo,
+
+		DataAgendamento:     parseDataAgendamento(item.DataAgendamento),
+		SuspeitaDuplicidade: item.SuspeitaDuplicidade,
+		Tags:                item.Tags,
+	}
+}
+
+func formatarDataAgendamento(data *time.Time) string {
+	if data == nil {
+		return ""
+	}
+	return data.UTC().Format("2006-01-02T15:04:05Z07:00")
+}
+
+func parseDataAgendamento(valor string) *time.Time {
+	if valor == "" {
+		return nil
+	}
+	data, err := time.Parse("2006-01-02T15:04:05Z07:00", valor)
+	if err != nil {
+		return nil
 	}
+	return &data
 }
@@ -0,0 +1,477 @@
+package dynamodb
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// atributoS extrai o valor de um atributo string do payload JSON do DynamoDB
+func atributoS(item map[string]interface{}, nome string) string {
+	atributo, ok := item[nome].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	valor, _ := atributo["S"].(string)
+	return valor
+}
+
+func TestSave_LayoutChaveComposta(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes", WithLayoutTransacoes(LayoutChaveComposta))
+
+	transacao := domain.NewTransacao("c1", 99.90, "corr")
+	transacao.ID = "tx-1"
+	transacao.Timestamp = time.Date(2024, 1, 15, 10, 30, 0, 5, time.FixedZone("BRT", -3*60*60))
+
+	if err := repo.Save(context.Background(), transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	req := httpClient.ultimaRequisicao()
+	if req.operacao != "PutItem" {
+		t.Fatalf("operação esperada PutItem, got %s", req.operacao)
+	}
+
+	item := req.payload["Item"].(map[string]interface{})
+
+	if got := atributoS(item, "cliente_id"); got != "c1" {
+		t.Errorf("chave de partição cliente_id esperada c1, got %s", got)
+	}
+
+	if got, esperado := atributoS(item, "sk"), "2024-01-15T13:30:00.000000005Z#tx-1"; got != esperado {
+		t.Errorf("sort key esperada %s, got %s", esperado, got)
+	}
+
+	if got := atributoS(item, "id"); got != "tx-1" {
+		t.Errorf("atributo id (chave do GSI) esperado tx-1, got %s", got)
+	}
+}
+
+func TestSave_TTLComRelogioDivergente(t *testing.T) {
+	retencao := int64(retencaoTransacoes.Seconds())
+
+	tests := []struct {
+		name       string
+		tolerancia time.Duration
+		desvio     time.Duration
+		// baseNoServidor indica retenção contada do horário do servidor
+		baseNoServidor bool
+	}{
+		{name: "timestamp plausível", tolerancia: 5 * time.Minute, desvio: -time.Minute},
+		{name: "timestamp no futuro além da tolerância", tolerancia: 5 * time.Minute, desvio: 365 * 24 * time.Hour, baseNoServidor: true},
+		{name: "timestamp no passado além da tolerância", tolerancia: 5 * time.Minute, desvio: -30 * 24 * time.Hour, baseNoServidor: true},
+		{name: "sem tolerância mantém o timestamp", desvio: 365 * 24 * time.Hour},
+		{name: "sem tolerância nunca grava TTL no passado", desvio: -365 * 24 * time.Hour, baseNoServidor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: "{}"}
+			metrics := &contadorErros{}
+			repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes",
+				WithToleranciaRelogioTTL(tt.tolerancia), WithObservabilidade(metrics, &registroAvisos{}))
+
+			transacao := domain.NewTransacao("c1", 10.00, "corr")
+			transacao.Timestamp = time.Now().Add(tt.desvio)
+
+			if err := repo.Save(context.Background(), transacao); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			item := httpClient.ultimaRequisicao().payload["Item"].(map[string]interface{})
+			ttl, _ := strconv.ParseInt(item["ttl"].(map[string]interface{})["N"].(string), 10, 64)
+
+			esperado := transacao.Timestamp.Unix() + retencao
+			if tt.baseNoServidor {
+				esperado = time.Now().Unix() + retencao
+			}
+			if diferenca := ttl - esperado; diferenca < -2 || diferenca > 2 {
+				t.Errorf("TTL esperado ~%d, got %d", esperado, ttl)
+			}
+			if ttl <= time.Now().Unix() {
+				t.Errorf("TTL no passado: %d", ttl)
+			}
+
+			if tt.baseNoServidor != (metrics.contas["ttl_clock_skew"] > 0) {
+				t.Errorf("métrica ttl_clock_skew inesperada: %d", metrics.contas["ttl_clock_skew"])
+			}
+		})
+	}
+}
+
+func TestSave_LayoutChaveSimplesNaoGravaSortKey(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	if err := repo.Save(context.Background(), domain.NewTransacao("c1", 99.90, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	item := httpClient.ultimaRequisicao().payload["Item"].(map[string]interface{})
+	if _, ok := item["sk"]; ok {
+		t.Error("layout simples não deve gravar sort key")
+	}
+}
+
+func TestGetByClienteID_LimiteDaConsulta(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int
+		esperaErro bool
+		esperado   float64
+	}{
+		{name: "zero é recusado", limit: 0, esperaErro: true},
+		{name: "negativo é recusado", limit: -5, esperaErro: true},
+		{name: "dentro do máximo", limit: 30, esperado: 30},
+		{name: "acima do máximo é reduzido", limit: 500, esperado: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: `{"Items":[]}`}
+			repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes", WithMaxTransacoesConsulta(50))
+
+			_, err := repo.GetByClienteID(context.Background(), "c1", tt.limit)
+			if tt.esperaErro {
+				if !errors.Is(err, domain.ErrLimiteConsultaInvalido) {
+					t.Fatalf("esperado ErrLimiteConsultaInvalido, got %v", err)
+				}
+				if len(httpClient.requisicoes) != 0 {
+					t.Error("limite inválido não deveria consultar o DynamoDB")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if got := httpClient.ultimaRequisicao().payload["Limit"]; got != tt.esperado {
+				t.Errorf("Limit esperado %v, got %v", tt.esperado, got)
+			}
+		})
+	}
+}
+
+func TestGetByClienteID_MaximoPadrao(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: `{"Items":[]}`}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	if _, err := repo.GetByClienteID(context.Background(), "c1", 1000); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if got := httpClient.ultimaRequisicao().payload["Limit"]; got != float64(maxTransacoesConsultaPadrao) {
+		t.Errorf("Limit esperado %d, got %v", maxTransacoesConsultaPadrao, got)
+	}
+}
+
+func TestGetByClienteID_LayoutChaveComposta(t *testing.T) {
+	corpo := `{"Items":[
+		{"id":{"S":"tx-2"},"cliente_id":{"S":"c1"},"valor":{"N":"20"},"status":{"S":"APROVADA"},"timestamp":{"S":"2024-01-15T10:31:00Z"}},
+		{"id":{"S":"tx-1"},"cliente_id":{"S":"c1"},"valor":{"N":"10"},"status":{"S":"APROVADA"},"timestamp":{"S":"2024-01-15T10:30:00Z"}}
+	]}`
+	httpClient := &fakeHTTPClient{corpo: corpo}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes", WithLayoutTransacoes(LayoutChaveComposta))
+
+	transacoes, err := repo.GetByClienteID(context.Background(), "c1", 10)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(transacoes) != 2 || transacoes[0].ID != "tx-2" {
+		t.Fatalf("esperado [tx-2 tx-1], got %+v", transacoes)
+	}
+
+	req := httpClient.ultimaRequisicao()
+	if _, ok := req.payload["IndexName"]; ok {
+		t.Errorf("layout composto deve consultar a tabela base, got IndexName %v", req.payload["IndexName"])
+	}
+
+	if req.payload["ConsistentRead"] != true {
+		t.Error("consulta na tabela base deve ser fortemente consistente")
+	}
+
+	if req.payload["ScanIndexForward"] != false {
+		t.Error("consulta deve retornar as mais recentes primeiro")
+	}
+}
+
+func TestGetByID_LayoutChaveCompostaUsaIndiceGlobal(t *testing.T) {
+	corpo := `{"Items":[{"id":{"S":"tx-1"},"cliente_id":{"S":"c1"},"valor":{"N":"10"},"status":{"S":"APROVADA"},"timestamp":{"S":"2024-01-15T10:30:00Z"}}]}`
+	httpClient := &fakeHTTPClient{corpo: corpo}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes", WithLayoutTransacoes(LayoutChaveComposta))
+
+	transacao, err := repo.GetByID(context.Background(), "tx-1")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if transacao.ID != "tx-1" || transacao.ClienteID != "c1" {
+		t.Errorf("transação inesperada: %+v", transacao)
+	}
+
+	req := httpClient.ultimaRequisicao()
+	if req.operacao != "Query" || req.payload["IndexName"] != "id-index" {
+		t.Errorf("esperado Query no id-index, got %s %v", req.operacao, req.payload["IndexName"])
+	}
+}
+
+func TestListarAgendadasAte(t *testing.T) {
+	corpo := `{"Items":[{"id":{"S":"tx-1"},"cliente_id":{"S":"c1"},"valor":{"N":"10"},"status":{"S":"AGENDADA"},"timestamp":{"S":"2024-01-15T10:30:00Z"},"data_agendamento":{"S":"2024-01-20T09:00:00Z"}}]}`
+	httpClient := &fakeHTTPClient{corpo: corpo}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	ate := time.Date(2024, 1, 20, 9, 5, 0, 0, time.UTC)
+	transacoes, err := repo.ListarAgendadasAte(context.Background(), ate)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(transacoes) != 1 || transacoes[0].DataAgendamento == nil {
+		t.Fatalf("esperada uma transação com data de agendamento, got %+v", transacoes)
+	}
+
+	if esperado := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC); !transacoes[0].DataAgendamento.Equal(esperado) {
+		t.Errorf("data de agendamento esperada %s, got %s", esperado, transacoes[0].DataAgendamento)
+	}
+
+	valores := httpClient.ultimaRequisicao().payload["ExpressionAttributeValues"].(map[string]interface{})
+	if got := atributoS(valores, ":agendada"); got != domain.StatusAgendada {
+		t.Errorf("filtro de status esperado %s, got %s", domain.StatusAgendada, got)
+	}
+	if got := atributoS(valores, ":ate"); got != "2024-01-20T09:05:00Z" {
+		t.Errorf("limite do filtro esperado 2024-01-20T09:05:00Z, got %s", got)
+	}
+}
+
+func TestIniciarExecucao(t *testing.T) {
+	tests := []struct {
+		name        string
+		excecoes    map[string]string
+		expectedErr error
+	}{
+		{name: "assume a transação agendada"},
+		{name: "já iniciada por outra execução", excecoes: map[string]string{"UpdateItem": "ConditionalCheckFailedException"}, expectedErr: domain.ErrAgendamentoJaIniciado},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := &fakeHTTPClient{corpo: "{}", excecoes: tt.excecoes}
+			repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+			transacao := domain.NewTransacao("c1", 10, "corr")
+			transacao.ID = "tx-1"
+
+			if err := repo.IniciarExecucao(context.Background(), transacao); err != tt.expectedErr {
+				t.Fatalf("Erro esperado %v, got %v", tt.expectedErr, err)
+			}
+
+			req := httpClient.ultimaRequisicao()
+			chave := req.payload["Key"].(map[string]interface{})
+			if got := atributoS(chave, "id"); got != "tx-1" {
+				t.Errorf("chave id esperada tx-1, got %s", got)
+			}
+			if req.payload["ConditionExpression"] != "#status = :agendada" {
+				t.Errorf("condição inesperada: %v", req.payload["ConditionExpression"])
+			}
+		})
+	}
+}
+
+func TestSave_GravaDataAgendamento(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	transacao := domain.NewTransacao("c1", 10, "corr")
+	data := time.Date(2024, 1, 20, 6, 0, 0, 0, time.FixedZone("BRT", -3*60*60))
+	transacao.DataAgendamento = &data
+
+	if err := repo.Save(context.Background(), transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	item := httpClient.ultimaRequisicao().payload["Item"].(map[string]interface{})
+	if got := atributoS(item, "data_agendamento"); got != "2024-01-20T09:00:00Z" {
+		t.Errorf("data_agendamento esperada em UTC 2024-01-20T09:00:00Z, got %s", got)
+	}
+}
+
+func TestSaveEGetByID_TagsIdaEVolta(t *testing.T) {
+	var gravado map[string]interface{}
+	httpClient := &fakeHTTPClient{responder: func(operacao string, payload map[string]interface{}) string {
+		switch operacao {
+		case "PutItem":
+			gravado = payload["Item"].(map[string]interface{})
+		case "GetItem":
+			corpo, _ := json.Marshal(map[string]interface{}{"Item": gravado})
+			return string(corpo)
+		}
+		return "{}"
+	}}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	transacao := domain.NewTransacao("c1", 10, "corr")
+	transacao.Tags = map[string]string{"campanha": "black-friday", "dispositivo": "ios-17"}
+	if err := repo.Save(context.Background(), transacao); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if _, ok := gravado["tags"].(map[string]interface{})["M"]; !ok {
+		t.Fatalf("tags deveriam ser gravadas como mapa (M), got %v", gravado["tags"])
+	}
+
+	lida, err := repo.GetByID(context.Background(), transacao.ID)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if !reflect.DeepEqual(lida.Tags, transacao.Tags) {
+		t.Errorf("tags esperadas %v, got %v", transacao.Tags, lida.Tags)
+	}
+}
+
+func TestSave_SemTagsNaoGravaAtributo(t *testing.T) {
+	httpClient := &fakeHTTPClient{corpo: "{}"}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	if err := repo.Save(context.Background(), domain.NewTransacao("c1", 10, "corr")); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	item := httpClient.ultimaRequisicao().payload["Item"].(map[string]interface{})
+	if _, ok := item["tags"]; ok {
+		t.Errorf("atributo tags não deveria existir sem tags, got %v", item["tags"])
+	}
+}
+
+func TestGetByClienteID_ItensCorrompidos(t *testing.T) {
+	corpo := `{"Items":[
+		{"id":{"S":"tx-1"},"cliente_id":{"S":"c1"},"valor":{"N":"10"},"status":{"S":"APROVADA"},"timestamp":{"S":"2024-01-15T10:30:00Z"}},
+		{"id":{"S":"tx-corrompida"},"cliente_id":{"S":"c1"},"valor":{"S":"dez"},"status":{"S":"APROVADA"},"timestamp":{"S":"2024-01-15T10:31:00Z"}},
+		{"id":{"S":"tx-2"},"cliente_id":{"S":"c1"},"valor":{"N":"20"},"status":{"S":"APROVADA"},"timestamp":{"S":"2024-01-15T10:32:00Z"}}
+	]}`
+
+	t.Run("registrar", func(t *testing.T) {
+		metrics := &contadorErros{}
+		repo := NewTransacaoRepository(newFakeDynamoClient(&fakeHTTPClient{corpo: corpo}), "transacoes",
+			WithObservabilidade(metrics, nil))
+
+		transacoes, err := repo.GetByClienteID(context.Background(), "c1", 10)
+		if err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+
+		if len(transacoes) != 2 || transacoes[0].ID != "tx-1" || transacoes[1].ID != "tx-2" {
+			t.Fatalf("esperado [tx-1 tx-2], got %+v", transacoes)
+		}
+
+		if metrics.contas["corrupt_item"] != 1 {
+			t.Errorf("métrica corrupt_item esperada 1, got %d", metrics.contas["corrupt_item"])
+		}
+	})
+
+	t.Run("retornar", func(t *testing.T) {
+		repo := NewTransacaoRepository(newFakeDynamoClient(&fakeHTTPClient{corpo: corpo}), "transacoes",
+			WithPoliticaItemCorrompido(ItemCorrompidoRetornar))
+
+		transacoes, err := repo.GetByClienteID(context.Background(), "c1", 10)
+
+		var corrompidos *domain.ItensCorrompidosError
+		if !errors.As(err, &corrompidos) || !errors.Is(err, domain.ErrItensCorrompidos) {
+			t.Fatalf("esperado ItensCorrompidosError, got %v", err)
+		}
+
+		if len(corrompidos.Chaves) != 1 || corrompidos.Chaves[0] != "tx-corrompida" {
+			t.Errorf("chaves corrompidas esperadas [tx-corrompida], got %v", corrompidos.Chaves)
+		}
+
+		if len(transacoes) != 2 {
+			t.Errorf("itens válidos deveriam ser retornados junto do erro, got %d", len(transacoes))
+		}
+	})
+}
+
+// paginasDeCliente simula um histórico de total transações servido em páginas
+// do tamanho pedido no Limit, continuando a partir do ExclusiveStartKey
+func paginasDeCliente(total int) func(operacao string, payload map[string]interface{}) string {
+	return func(operacao string, payload map[string]interface{}) string {
+		inicio := 0
+		if chave, ok := payload["ExclusiveStartKey"].(map[string]interface{}); ok {
+			inicio, _ = strconv.Atoi(atributoS(chave, "id"))
+			inicio++
+		}
+		fim := inicio + int(payload["Limit"].(float64))
+		if fim > total {
+			fim = total
+		}
+
+		itens := make([]map[string]interface{}, 0, fim-inicio)
+		for i := inicio; i < fim; i++ {
+			itens = append(itens, map[string]interface{}{
+				"id":         map[string]string{"S": strconv.Itoa(i)},
+				"cliente_id": map[string]string{"S": "c1"},
+				"valor":      map[string]string{"N": "10"},
+				"status":     map[string]string{"S": "APROVADA"},
+				"timestamp":  map[string]string{"S": "2024-01-15T10:30:00Z"},
+			})
+		}
+
+		resposta := map[string]interface{}{"Items": itens}
+		if fim < total {
+			resposta["LastEvaluatedKey"] = map[string]interface{}{"id": map[string]string{"S": strconv.Itoa(fim - 1)}}
+		}
+		corpo, _ := json.Marshal(resposta)
+		return string(corpo)
+	}
+}
+
+func TestPercorrerPorClienteID(t *testing.T) {
+	httpClient := &fakeHTTPClient{responder: paginasDeCliente(2500)}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	var paginas, total, maiorPagina int
+	vistos := make(map[string]bool)
+	err := repo.PercorrerPorClienteID(context.Background(), "c1", 1000, func(pagina []*domain.Transacao) error {
+		paginas++
+		total += len(pagina)
+		if len(pagina) > maiorPagina {
+			maiorPagina = len(pagina)
+		}
+		for _, transacao := range pagina {
+			vistos[transacao.ID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if paginas != 3 || total != 2500 || len(vistos) != 2500 {
+		t.Errorf("esperadas 3 páginas e 2500 transações distintas, got %d páginas, %d transações, %d distintas", paginas, total, len(vistos))
+	}
+	if maiorPagina > 1000 {
+		t.Errorf("página maior que o tamanho pedido: %d", maiorPagina)
+	}
+}
+
+func TestPercorrerPorClienteID_ErroDoCallbackInterrompe(t *testing.T) {
+	httpClient := &fakeHTTPClient{responder: paginasDeCliente(500)}
+	repo := NewTransacaoRepository(newFakeDynamoClient(httpClient), "transacoes")
+
+	errEscrita := errors.New("falha ao escrever CSV")
+	err := repo.PercorrerPorClienteID(context.Background(), "c1", 100, func(pagina []*domain.Transacao) error {
+		return errEscrita
+	})
+
+	if !errors.Is(err, errEscrita) {
+		t.Fatalf("esperado erro do callback, got %v", err)
+	}
+	if n := len(httpClient.requisicoes); n != 1 {
+		t.Errorf("leitura deveria parar na primeira página, got %d consultas", n)
+	}
+}
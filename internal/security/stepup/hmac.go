@@ -0,0 +1,102 @@
+package stepup
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HMACStepUp implementa domain.StepUpVerifier com tokens assinados por HMAC-SHA256.
+//
+// O token tem o formato base64url(payload) + "." + base64url(assinatura), com
+// payload "cliente_id|valor_centavos|desafio|expira_em_unix". Ele só vale para
+// o mesmo cliente e valor, até expirar, e uma única vez: o desafio é
+// resgatado no store pela primeira transação que apresentar o token, e
+// outra transação com o mesmo token é recusada.
+//
+// Os tokens são emitidos fora do autorizador, pelo serviço que confirma o
+// desafio com o cliente (ex.: OTP no app), com o mesmo STEP_UP_SECRET;
+// EmitirToken é a referência do formato para esse serviço.
+type HMACStepUp struct {
+	segredo    []byte
+	ttl        time.Duration
+	consumidos domain.StepUpDesafioStore
+	agora      func() time.Time
+}
+
+func NewHMACStepUp(segredo []byte, ttl time.Duration, consumidos domain.StepUpDesafioStore) *HMACStepUp {
+	return &HMACStepUp{
+		segredo:    segredo,
+		ttl:        ttl,
+		consumidos: consumidos,
+		agora:      time.Now,
+	}
+}
+
+// EmitirDesafio gera a referência do desafio a ser confirmado pelo cliente
+func (s *HMACStepUp) EmitirDesafio(ctx context.Context, transacao *domain.Transacao) (string, error) {
+	return uuid.New().String(), nil
+}
+
+// EmitirToken gera o token de step-up após a confirmação do desafio
+func (s *HMACStepUp) EmitirToken(desafio, clienteID string, valorCentavos int) string {
+	expiraEm := s.agora().Add(s.ttl).Unix()
+	payload := fmt.Sprintf("%s|%d|%s|%d", clienteID, valorCentavos, desafio, expiraEm)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.assinar(payload))
+}
+
+// VerificarToken valida assinatura, expiração e vínculo com cliente e valor,
+// e resgata o desafio para a transação. Falha no store recusa o token.
+func (s *HMACStepUp) VerificarToken(ctx context.Context, token string, transacao *domain.Transacao) bool {
+	payloadCodificado, assinaturaCodificada, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(payloadCodificado)
+	if err != nil {
+		return false
+	}
+
+	assinatura, err := base64.RawURLEncoding.DecodeString(assinaturaCodificada)
+	if err != nil || !hmac.Equal(assinatura, s.assinar(string(payload))) {
+		return false
+	}
+
+	partes := strings.Split(string(payload), "|")
+	if len(partes) != 4 {
+		return false
+	}
+
+	valorCentavos, err := strconv.Atoi(partes[1])
+	if err != nil {
+		return false
+	}
+
+	expiraEm, err := strconv.ParseInt(partes[3], 10, 64)
+	if err != nil || s.agora().Unix() > expiraEm {
+		return false
+	}
+
+	if partes[0] != transacao.ClienteID || int64(valorCentavos) != transacao.ValorCentavos() {
+		return false
+	}
+
+	return s.consumidos.Consumir(ctx, partes[2], transacao.ID, time.Unix(expiraEm, 0)) == nil
+}
+
+func (s *HMACStepUp) assinar(payload string) []byte {
+	mac := hmac.New(sha256.New, s.segredo)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
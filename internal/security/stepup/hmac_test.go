@@ -0,0 +1,96 @@
+package stepup
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeDesafios guarda em memória a transação que resgatou cada desafio
+type fakeDesafios struct {
+	resgates map[string]string
+	err      error
+}
+
+func newFakeDesafios() *fakeDesafios {
+	return &fakeDesafios{resgates: make(map[string]string)}
+}
+
+func (f *fakeDesafios) Consumir(ctx context.Context, desafio, transacaoID string, expiraEm time.Time) error {
+	if f.err != nil {
+		return f.err
+	}
+	if resgatadoPor, ok := f.resgates[desafio]; ok && resgatadoPor != transacaoID {
+		return domain.ErrDesafioStepUpConsumido
+	}
+	f.resgates[desafio] = transacaoID
+	return nil
+}
+
+func TestHMACStepUp_VerificarToken(t *testing.T) {
+	agora := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	emissor := NewHMACStepUp([]byte("segredo"), 5*time.Minute, newFakeDesafios())
+	emissor.agora = func() time.Time { return agora }
+
+	token := emissor.EmitirToken("desafio-1", "c1", 150000)
+
+	tests := []struct {
+		name      string
+		token     string
+		clienteID string
+		valor     float64
+		segredo   string
+		decorrido time.Duration
+		errStore  error
+		esperado  bool
+	}{
+		{name: "token válido", token: token, clienteID: "c1", valor: 1500.00, segredo: "segredo", esperado: true},
+		{name: "outro cliente", token: token, clienteID: "c2", valor: 1500.00, segredo: "segredo"},
+		{name: "outro valor", token: token, clienteID: "c1", valor: 1500.01, segredo: "segredo"},
+		{name: "segredo diferente", token: token, clienteID: "c1", valor: 1500.00, segredo: "outro"},
+		{name: "expirado", token: token, clienteID: "c1", valor: 1500.00, segredo: "segredo", decorrido: 5*time.Minute + time.Second},
+		{name: "adulterado", token: token + "x", clienteID: "c1", valor: 1500.00, segredo: "segredo"},
+		{name: "malformado", token: "sem-ponto", clienteID: "c1", valor: 1500.00, segredo: "segredo"},
+		{name: "store indisponível", token: token, clienteID: "c1", valor: 1500.00, segredo: "segredo", errStore: errors.New("timeout")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desafios := newFakeDesafios()
+			desafios.err = tt.errStore
+			verificador := NewHMACStepUp([]byte(tt.segredo), 5*time.Minute, desafios)
+			verificador.agora = func() time.Time { return agora.Add(tt.decorrido) }
+
+			transacao := domain.NewTransacao(tt.clienteID, tt.valor, "corr")
+			if got := verificador.VerificarToken(context.Background(), tt.token, transacao); got != tt.esperado {
+				t.Errorf("esperado %v, got %v", tt.esperado, got)
+			}
+		})
+	}
+}
+
+func TestHMACStepUp_TokenValeUmaUnicaVez(t *testing.T) {
+	desafios := newFakeDesafios()
+	verificador := NewHMACStepUp([]byte("segredo"), 5*time.Minute, desafios)
+	token := verificador.EmitirToken("desafio-1", "c1", 150000)
+
+	primeira := domain.NewTransacao("c1", 1500.00, "corr")
+	if !verificador.VerificarToken(context.Background(), token, primeira) {
+		t.Fatal("primeiro uso do token deveria ser aceito")
+	}
+	if desafios.resgates["desafio-1"] != primeira.ID {
+		t.Errorf("desafio deveria ser resgatado pela transação %s, got %q", primeira.ID, desafios.resgates["desafio-1"])
+	}
+
+	// A mesma transação (ex.: agendada, confirmada de novo na execução) é aceita
+	if !verificador.VerificarToken(context.Background(), token, primeira) {
+		t.Error("nova verificação da mesma transação deveria ser aceita")
+	}
+
+	outra := domain.NewTransacao("c1", 1500.00, "corr")
+	if verificador.VerificarToken(context.Background(), token, outra) {
+		t.Error("token já resgatado não deveria valer para outra transação")
+	}
+}
@@ -0,0 +1,156 @@
+// Package testutil reúne builders e fixtures de objetos de domínio para os
+// testes. Os valores padrão formam uma transação à vista válida de um
+// cliente com limite disponível; cada teste sobrescreve só o que importa.
+package testutil
+
+import (
+	"authorizer/internal/core/domain"
+	"time"
+)
+
+// Valores padrão das fixtures
+const (
+	ClienteIDPadrao     = "c1"
+	CorrelationIDPadrao = "corr"
+	// LimitePadraoCentavos é o limite de crédito e atual do cliente padrão (R$ 1.000,00)
+	LimitePadraoCentavos = 100000
+)
+
+// TransacaoBuilder monta transações com campos consistentes
+type TransacaoBuilder struct {
+	transacao domain.Transacao
+}
+
+// NewTransacaoBuilder parte de uma transação PENDENTE de R$ 10,00 do cliente padrão
+func NewTransacaoBuilder() *TransacaoBuilder {
+	return &TransacaoBuilder{transacao: *domain.NewTransacao(ClienteIDPadrao, 10.00, CorrelationIDPadrao)}
+}
+
+func (b *TransacaoBuilder) ComID(id string) *TransacaoBuilder {
+	b.transacao.ID = id
+	return b
+}
+
+func (b *TransacaoBuilder) ComCliente(clienteID string) *TransacaoBuilder {
+	b.transacao.ClienteID = clienteID
+	return b
+}
+
+func (b *TransacaoBuilder) ComValor(valor float64) *TransacaoBuilder {
+	b.transacao.Valor = valor
+	return b
+}
+
+func (b *TransacaoBuilder) ComStatus(status string) *TransacaoBuilder {
+	b.transacao.Status = status
+	return b
+}
+
+// Aprovada é atalho para ComStatus(domain.StatusAprovada)
+func (b *TransacaoBuilder) Aprovada() *TransacaoBuilder {
+	return b.ComStatus(domain.StatusAprovada)
+}
+
+func (b *TransacaoBuilder) ComTimestamp(timestamp time.Time) *TransacaoBuilder {
+	b.transacao.Timestamp = timestamp
+	return b
+}
+
+// Apos posiciona a transação d depois de outra (útil em janelas de tempo)
+func (b *TransacaoBuilder) Apos(anterior *domain.Transacao, d time.Duration) *TransacaoBuilder {
+	return b.ComTimestamp(anterior.Timestamp.Add(d))
+}
+
+func (b *TransacaoBuilder) ComCorrelationID(correlationID string) *TransacaoBuilder {
+	b.transacao.CorrelationID = correlationID
+	return b
+}
+
+// ComCanal aplica a mesma normalização da API
+func (b *TransacaoBuilder) ComCanal(canal string) *TransacaoBuilder {
+	b.transacao.DefinirCanal(canal)
+	return b
+}
+
+func (b *TransacaoBuilder) ComParcelas(parcelas int) *TransacaoBuilder {
+	b.transacao.Parcelas = parcelas
+	return b
+}
+
+func (b *TransacaoBuilder) ComTipo(tipo string) *TransacaoBuilder {
+	b.transacao.Tipo = tipo
+	return b
+}
+
+func (b *TransacaoBuilder) AgendadaPara(data time.Time) *TransacaoBuilder {
+	b.transacao.DataAgendamento = &data
+	return b
+}
+
+// Build devolve uma cópia nova a cada chamada
+func (b *TransacaoBuilder) Build() *domain.Transacao {
+	transacao := b.transacao
+	if b.transacao.DataAgendamento != nil {
+		data := *b.transacao.DataAgendamento
+		transacao.DataAgendamento = &data
+	}
+	return &transacao
+}
+
+// ClienteBuilder monta clientes; limite atual segue o de crédito até ser definido
+type ClienteBuilder struct {
+	cliente     domain.Cliente
+	limiteAtual *int
+}
+
+// NewClienteBuilder parte do cliente padrão, sem uso do limite
+func NewClienteBuilder() *ClienteBuilder {
+	return &ClienteBuilder{cliente: domain.Cliente{ID: ClienteIDPadrao, LimiteCredit: LimitePadraoCentavos}}
+}
+
+func (b *ClienteBuilder) ComID(id string) *ClienteBuilder {
+	b.cliente.ID = id
+	return b
+}
+
+// ComLimite define o limite de crédito em centavos
+func (b *ClienteBuilder) ComLimite(centavos int) *ClienteBuilder {
+	b.cliente.LimiteCredit = centavos
+	return b
+}
+
+// ComLimiteAtual define o limite disponível em centavos
+func (b *ClienteBuilder) ComLimiteAtual(centavos int) *ClienteBuilder {
+	b.limiteAtual = &centavos
+	return b
+}
+
+func (b *ClienteBuilder) ComTier(tier string) *ClienteBuilder {
+	b.cliente.Tier = tier
+	return b
+}
+
+func (b *ClienteBuilder) ComMaxParcelas(maxParcelas int) *ClienteBuilder {
+	b.cliente.MaxParcelas = maxParcelas
+	return b
+}
+
+// Build devolve uma cópia nova a cada chamada
+func (b *ClienteBuilder) Build() *domain.Cliente {
+	cliente := b.cliente
+	cliente.LimiteAtual = cliente.LimiteCredit
+	if b.limiteAtual != nil {
+		cliente.LimiteAtual = *b.limiteAtual
+	}
+	return &cliente
+}
+
+// ClientePadrao é o cliente c1 com R$ 1.000,00 disponíveis
+func ClientePadrao() *domain.Cliente {
+	return NewClienteBuilder().Build()
+}
+
+// ClienteSemLimite é o cliente c1 com limite de crédito mas nada disponível
+func ClienteSemLimite() *domain.Cliente {
+	return NewClienteBuilder().ComLimiteAtual(0).Build()
+}
@@ -0,0 +1,49 @@
+package testutil
+
+import (
+	"authorizer/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestTransacaoBuilder_PadraoValido(t *testing.T) {
+	transacao := NewTransacaoBuilder().Build()
+
+	if err := transacao.Valida(); err != nil {
+		t.Fatalf("transação padrão deveria ser válida: %v", err)
+	}
+
+	if transacao.ClienteID != ClienteIDPadrao || transacao.Status != domain.StatusPendente || transacao.ID == "" {
+		t.Errorf("transação padrão inesperada: %+v", transacao)
+	}
+}
+
+func TestTransacaoBuilder_BuildDevolveCopias(t *testing.T) {
+	builder := NewTransacaoBuilder().ComValor(99.90).ComCliente("x").AgendadaPara(time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC))
+
+	primeira := builder.Build()
+	primeira.Valor = 1
+	*primeira.DataAgendamento = time.Time{}
+
+	segunda := builder.Build()
+	if segunda.Valor != 99.90 || segunda.ClienteID != "x" || segunda.DataAgendamento.IsZero() {
+		t.Errorf("alterar uma transação construída não deveria afetar o builder: %+v", segunda)
+	}
+}
+
+func TestTransacaoBuilder_CanalNormalizado(t *testing.T) {
+	if canal := NewTransacaoBuilder().ComCanal(" web ").Build().Canal; canal != domain.CanalWeb {
+		t.Errorf("canal esperado %s, got %s", domain.CanalWeb, canal)
+	}
+}
+
+func TestClienteBuilder_LimiteAtualSegueCredito(t *testing.T) {
+	cliente := NewClienteBuilder().ComLimite(5000).Build()
+	if cliente.LimiteAtual != 5000 {
+		t.Errorf("limite atual esperado 5000, got %d", cliente.LimiteAtual)
+	}
+
+	if semLimite := ClienteSemLimite(); semLimite.LimiteAtual != 0 || semLimite.LimiteCredit != LimitePadraoCentavos {
+		t.Errorf("ClienteSemLimite inesperado: %+v", semLimite)
+	}
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"authorizer/internal/core/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// PreAuthWebhook implementa domain.PreAuthCallback via HTTP: envia a transação
+// em JSON (POST) e espera {"aprovada": true|false}. Respostas não 2xx são
+// tratadas como erro (e seguem a política de falha do serviço).
+type PreAuthWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// preAuthRequest é o payload enviado ao endpoint de pré-autorização
+type preAuthRequest struct {
+	TransacaoID   string `json:"transacao_id"`
+	ClienteID     string `json:"cliente_id"`
+	ValorCentavos int64  `json:"valor_centavos"`
+	Moeda         string `json:"moeda"`
+	Canal         string `json:"canal"`
+	Parcelas      int    `json:"parcelas"`
+	CorrelationID string `json:"correlation_id"`
+}
+
+type preAuthResponse struct {
+	Aprovada bool `json:"aprovada"`
+}
+
+// NewPreAuthWebhook cria o cliente do webhook. O timeout é aplicado pelo
+// serviço via contexto; client nil usa http.DefaultClient.
+func NewPreAuthWebhook(url string, client *http.Client) *PreAuthWebhook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &PreAuthWebhook{url: url, client: client}
+}
+
+// Autorizar consulta o endpoint e devolve a decisão
+func (w *PreAuthWebhook) Autorizar(ctx context.Context, transacao *domain.Transacao) (bool, error) {
+	body, err := json.Marshal(preAuthRequest{
+		TransacaoID:   transacao.ID,
+		ClienteID:     transacao.ClienteID,
+		ValorCentavos: transacao.ValorCentavos(),
+		Moeda:         transacao.MoedaOuPadrao(),
+		Canal:         transacao.Canal,
+		Parcelas:      transacao.NumeroParcelas(),
+		CorrelationID: transacao.CorrelationID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("erro ao serializar pré-autorização: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("erro ao criar requisição de pré-autorização: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Correlation-ID", transacao.CorrelationID)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("erro ao chamar pré-autorização: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf("pré-autorização respondeu status %d", resp.StatusCode)
+	}
+
+	var decisao preAuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decisao); err != nil {
+		return false, fmt.Errorf("resposta de pré-autorização inválida: %w", err)
+	}
+
+	return decisao.Aprovada, nil
+}
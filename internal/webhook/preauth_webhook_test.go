@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"authorizer/internal/core/domain"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPreAuthWebhook_Autorizar(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		corpo      string
+		esperada   bool
+		esperaErro bool
+	}{
+		{name: "aprovada", status: http.StatusOK, corpo: `{"aprovada":true}`, esperada: true},
+		{name: "negada", status: http.StatusOK, corpo: `{"aprovada":false}`},
+		{name: "erro do servidor", status: http.StatusInternalServerError, corpo: `{}`, esperaErro: true},
+		{name: "resposta inválida", status: http.StatusOK, corpo: `nao-json`, esperaErro: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recebido preAuthRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&recebido)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.corpo))
+			}))
+			defer server.Close()
+
+			transacao := domain.NewTransacao("c1", 99.90, "corr")
+			aprovada, err := NewPreAuthWebhook(server.URL, server.Client()).Autorizar(context.Background(), transacao)
+
+			if (err != nil) != tt.esperaErro {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if aprovada != tt.esperada {
+				t.Errorf("decisão esperada %v, got %v", tt.esperada, aprovada)
+			}
+
+			if recebido.TransacaoID != transacao.ID || recebido.ValorCentavos != 9990 {
+				t.Errorf("payload inesperado: %+v", recebido)
+			}
+		})
+	}
+}
+
+func TestPreAuthWebhook_EnviaMoedaDaTransacao(t *testing.T) {
+	var recebido preAuthRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&recebido)
+		w.Write([]byte(`{"aprovada":true}`))
+	}))
+	defer server.Close()
+
+	webhook := NewPreAuthWebhook(server.URL, server.Client())
+	for moeda, esperada := range map[string]string{"": domain.MoedaPadrao, "USD": "USD"} {
+		transacao := domain.NewTransacao("c1", 10.00, "corr")
+		transacao.Moeda = moeda
+		if _, err := webhook.Autorizar(context.Background(), transacao); err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+		if recebido.Moeda != esperada {
+			t.Errorf("moeda %q: esperada %s no payload, got %s", moeda, esperada, recebido.Moeda)
+		}
+	}
+}